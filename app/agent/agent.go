@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Agent checks servers assigned to its location and reports results to the controller bot
+type Agent struct {
+	ControllerUrl string
+	Token         string
+	Location      string
+	Interval      time.Duration
+	Client        *http.Client
+
+	servers []checks.ProbeServer
+}
+
+func New(controllerUrl, token, location string, interval time.Duration) *Agent {
+	return &Agent{
+		ControllerUrl: strings.TrimSuffix(controllerUrl, "/"),
+		Token:         token,
+		Location:      location,
+		Interval:      interval,
+		Client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Run checks the assigned servers and reports results every interval until ctx is done,
+// the first report has no results and only gets the assigned servers
+func (a *Agent) Run(ctx context.Context) {
+	var ticker = time.NewTicker(a.Interval)
+	defer ticker.Stop()
+
+	for {
+		var report = checks.ProbeReport{Location: a.Location, Results: make(map[string]checks.ProbeResult)}
+		for _, server := range a.servers {
+			report.Results[server.Name] = checks.CheckProbeServer(server)
+		}
+
+		servers, err := a.report(ctx, report)
+		if err != nil {
+			log.Printf("[ERROR] Failed to report %d results to the controller: %v", len(report.Results), err)
+		} else {
+			a.servers = servers
+			log.Printf("[DEBUG] Reported %d results, %d servers assigned", len(report.Results), len(servers))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// report posts the report to the controller and returns the servers assigned to the agent
+func (a *Agent) report(ctx context.Context, report checks.ProbeReport) ([]checks.ProbeServer, error) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, a.ControllerUrl+"/api/probe-results", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+a.Token)
+
+	resp, err := a.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	var assignments checks.ProbeAssignments
+	if err := json.NewDecoder(resp.Body).Decode(&assignments); err != nil {
+		return nil, err
+	}
+	return assignments.Servers, nil
+}
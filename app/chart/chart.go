@@ -0,0 +1,146 @@
+package chart
+
+import (
+	"bytes"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+)
+
+const (
+	width   = 800
+	height  = 400
+	padding = 20
+)
+
+var (
+	backgroundColor = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	axisColor       = color.RGBA{R: 120, G: 120, B: 120, A: 255}
+	gridColor       = color.RGBA{R: 230, G: 230, B: 230, A: 255}
+	lineColor       = color.RGBA{R: 33, G: 150, B: 243, A: 255}
+	failureColor    = color.RGBA{R: 229, G: 57, B: 53, A: 255}
+)
+
+// point is a downsampled chart column
+type point struct {
+	responseTime time.Duration
+	hasValue     bool
+	failed       bool
+}
+
+// ResponseTimePNG renders response times of the history as a PNG line chart, failed checks are marked red.
+// History is downsampled to the chart width, so memory used doesn't depend on the history size.
+func ResponseTimePNG(history []checks.CheckResult) ([]byte, error) {
+	var points = downsample(history, width-2*padding)
+
+	var maxResponseTime time.Duration
+	for _, p := range points {
+		if p.responseTime > maxResponseTime {
+			maxResponseTime = p.responseTime
+		}
+	}
+	if maxResponseTime == 0 {
+		maxResponseTime = time.Millisecond
+	}
+	maxResponseTime += maxResponseTime / 10
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: backgroundColor}, image.Point{}, draw.Src)
+
+	var plotTop, plotBottom = padding, height - padding
+	for i := 1; i < 4; i++ {
+		y := plotBottom - (plotBottom-plotTop)*i/4
+		drawLine(img, padding, y, width-padding, y, gridColor)
+	}
+	drawLine(img, padding, plotBottom, width-padding, plotBottom, axisColor)
+	drawLine(img, padding, plotTop, padding, plotBottom, axisColor)
+
+	var prevX, prevY = -1, -1
+	for i, p := range points {
+		x := padding + i
+		if p.failed {
+			drawLine(img, x, plotBottom-10, x, plotBottom, failureColor)
+		}
+		if !p.hasValue {
+			prevX, prevY = -1, -1
+			continue
+		}
+
+		y := plotBottom - int(int64(plotBottom-plotTop)*int64(p.responseTime)/int64(maxResponseTime))
+		if prevX >= 0 {
+			drawLine(img, prevX, prevY, x, y, lineColor)
+		} else {
+			img.Set(x, y, lineColor)
+		}
+		prevX, prevY = x, y
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// downsample splits history into columns buckets, each with the max response time of successful checks
+func downsample(history []checks.CheckResult, columns int) []point {
+	if len(history) < columns {
+		columns = len(history)
+	}
+
+	var points = make([]point, columns)
+	for i, result := range history {
+		p := &points[i*columns/len(history)]
+		if result.Status == checks.StatusFailed {
+			p.failed = true
+			continue
+		}
+		if !p.hasValue || result.ResponseTime > p.responseTime {
+			p.responseTime = result.ResponseTime
+			p.hasValue = true
+		}
+	}
+
+	return points
+}
+
+// drawLine draws a line with Bresenham's algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,203 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"log"
+	"strings"
+	"time"
+)
+
+// LocalLocation is the location of checks made by the bot itself, agents can't use it
+const LocalLocation = "local"
+
+// ProbeResult is a result of the check of a server made by a probe agent
+type ProbeResult struct {
+	Time         time.Time     `json:"time"`
+	Status       CheckStatus   `json:"status"`
+	ResponseTime time.Duration `json:"responseTime"`
+	StatusCode   int           `json:"statusCode,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// ProbeServer is a server assigned to an agent with the settings its check needs
+type ProbeServer struct {
+	Name        string      `json:"name"`
+	Url         string      `json:"url"`
+	Soft404     bool        `json:"soft404,omitempty"`
+	MinProto    string      `json:"minProto,omitempty"`
+	ProtoAction ProtoAction `json:"protoAction,omitempty"`
+	BasicAuth   string      `json:"basicAuth,omitempty"`
+	JsonChecks  []JsonCheck `json:"jsonChecks,omitempty"`
+}
+
+// ProbeReport is posted by an agent with results of its checks by server name
+type ProbeReport struct {
+	Location string                 `json:"location"`
+	Results  map[string]ProbeResult `json:"results"`
+}
+
+// ProbeAssignments is the response to a report with the servers the agent checks next
+type ProbeAssignments struct {
+	Servers []ProbeServer `json:"servers"`
+}
+
+// AgentState is the last report time of a probe agent, Offline is set while the agent doesn't report
+type AgentState struct {
+	LastSeen time.Time `json:"lastSeen"`
+	Offline  bool      `json:"offline,omitempty"`
+}
+
+var ErrInvalidLocation = errors.New("invalid location")
+
+// CheckProbeServer checks the server assigned to the agent
+func CheckProbeServer(server ProbeServer) ProbeResult {
+	var result = checkServer(context.Background(), ServerCheck{Name: server.Name, Url: server.Url, Soft404: server.Soft404,
+		MinProto: server.MinProto, ProtoAction: server.ProtoAction, BasicAuth: server.BasicAuth,
+		JsonChecks: server.JsonChecks})
+
+	return ProbeResult{Time: result.Time, Status: result.Status, ResponseTime: result.ResponseTime,
+		StatusCode: result.StatusCode, Error: result.Error}
+}
+
+// RecordProbeReport stores results of the agent for servers assigned to its location
+// and returns the servers assigned to it
+func RecordProbeReport(report ProbeReport) ([]ProbeServer, error) {
+	if report.Location == "" || report.Location == LocalLocation || strings.ContainsAny(report.Location, ", ") {
+		return nil, ErrInvalidLocation
+	}
+
+	var servers []ProbeServer
+	err := UpdateChecksData(func(data *Data) {
+		if data.Agents == nil {
+			data.Agents = make(map[string]AgentState)
+		}
+		var agent = data.Agents[report.Location]
+		agent.LastSeen = time.Now()
+		data.Agents[report.Location] = agent
+
+		for name, serverCheck := range data.HealthChecks {
+			if !serverCheck.hasProbeLocation(report.Location) {
+				continue
+			}
+
+			if result, ok := report.Results[name]; ok {
+				if serverCheck.ProbeResults == nil {
+					serverCheck.ProbeResults = make(map[string]ProbeResult)
+				}
+				serverCheck.ProbeResults[report.Location] = result
+				data.HealthChecks[name] = serverCheck
+			}
+			if !serverCheck.Paused {
+				servers = append(servers, ProbeServer{Name: name, Url: serverCheck.Url, Soft404: serverCheck.Soft404,
+					MinProto: serverCheck.MinProto, ProtoAction: serverCheck.ProtoAction, BasicAuth: serverCheck.BasicAuth,
+					JsonChecks: serverCheck.JsonChecks})
+			}
+		}
+	})
+
+	return servers, err
+}
+
+func (s ServerCheck) hasProbeLocation(location string) bool {
+	for _, probeLocation := range s.ProbeLocations {
+		if probeLocation == location {
+			return true
+		}
+	}
+	return false
+}
+
+// combineProbeResults marks the server down if at least quorum locations report it down, the bot is a location too.
+// Results of agents older than heartbeat are ignored, with fewer reporting locations than quorum all must agree.
+// The server is degraded if fewer locations report it down.
+func combineProbeResults(serverCheck ServerCheck, result CheckResult, quorum int, heartbeat time.Duration) CheckResult {
+	if len(serverCheck.ProbeLocations) == 0 {
+		return result
+	}
+
+	var failures []string
+	var total = 1
+	if result.Status == StatusFailed {
+		failures = append(failures, LocalLocation+": "+result.Error)
+	}
+	for _, location := range serverCheck.ProbeLocations {
+		probe, ok := serverCheck.ProbeResults[location]
+		if !ok || result.Time.Sub(probe.Time) > heartbeat {
+			continue
+		}
+		total++
+		if probe.Status == StatusFailed {
+			failures = append(failures, location+": "+probe.Error)
+		}
+	}
+	if len(failures) == 0 {
+		return result
+	}
+
+	var down = len(failures) >= min(quorum, total)
+	result.Error = fmt.Sprintf("down from %d of %d locations: %s", len(failures), total, strings.Join(failures, "; "))
+	result.Status = StatusDegraded
+	if down {
+		result.Status = StatusFailed
+	}
+
+	return result
+}
+
+// checkAgents sends a warning when an agent doesn't report for heartbeat and a note when it reports again
+func checkAgents(options Options) {
+	var now = time.Now()
+	for location, agent := range ReadChecksData().Agents {
+		var offline = now.Sub(agent.LastSeen) > options.AgentHeartbeat
+		if offline == agent.Offline {
+			continue
+		}
+
+		err := UpdateChecksData(func(data *Data) {
+			var storedAgent = data.Agents[location]
+			storedAgent.Offline = offline
+			data.Agents[location] = storedAgent
+		})
+		if err != nil {
+			log.Printf("[ERROR] Error while saving checks data: %v", err)
+			continue
+		}
+
+		var eventType = notify.EventAgentOnline
+		if offline {
+			eventType = notify.EventAgentOffline
+			log.Printf("[WARN] Probe agent %s doesn't report since %v", location, agent.LastSeen)
+		}
+		sendEvent(options, notify.Event{
+			Type:     eventType,
+			Agent:    location,
+			Time:     now,
+			Since:    agent.LastSeen,
+			Duration: now.Sub(agent.LastSeen),
+		})
+	}
+}
+
+// SetProbeLocations sets the agent locations checking the server, results of other locations are removed
+func SetProbeLocations(name string, locations []string) error {
+	for _, location := range locations {
+		if location == "" || location == LocalLocation {
+			return ErrInvalidLocation
+		}
+	}
+
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.ProbeLocations = locations
+		for location := range serverCheck.ProbeResults {
+			if !serverCheck.hasProbeLocation(location) {
+				delete(serverCheck.ProbeResults, location)
+			}
+		}
+		if len(serverCheck.ProbeResults) == 0 {
+			serverCheck.ProbeResults = nil
+		}
+	})
+}
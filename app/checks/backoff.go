@@ -0,0 +1,61 @@
+package checks
+
+import (
+	"time"
+)
+
+// DownBackoffAfter is how long a server must be down before its checks are stretched, 0 disables it,
+// DownBackoffMax is the longest stretched interval. Main sets them from the flags.
+var DownBackoffAfter time.Duration
+var DownBackoffMax = 15 * time.Minute
+
+// BackoffSettings returns the down time after which checks of the server are stretched and the longest
+// stretched interval, the server settings override the global ones. Zero after disables the backoff.
+func (s ServerCheck) BackoffSettings() (after time.Duration, maxInterval time.Duration) {
+	if s.BackoffDisabled {
+		return 0, 0
+	}
+
+	after, maxInterval = DownBackoffAfter, DownBackoffMax
+	if s.BackoffAfter > 0 {
+		after = s.BackoffAfter
+	}
+	if s.BackoffMax > 0 {
+		maxInterval = s.BackoffMax
+	}
+	return after, maxInterval
+}
+
+// backoffDue returns true if the server should be checked by the cycle of the interval, servers in backoff
+// are checked once their stretched interval has passed. Half of the interval is tolerated, so the check
+// is not pushed to the next cycle by the cycle starting slightly earlier.
+func backoffDue(serverCheck ServerCheck, now time.Time, interval time.Duration) bool {
+	if serverCheck.BackoffInterval == 0 || serverCheck.LastChecked.IsZero() {
+		return true
+	}
+	return now.Sub(serverCheck.LastChecked) >= serverCheck.BackoffInterval-interval/2
+}
+
+// updateBackoff doubles the interval of checks of the server down longer than its backoff threshold,
+// starting with twice the interval of the cycle and up to the longest interval. Success resets it in setCheckResult.
+func updateBackoff(serverCheck *ServerCheck, result CheckResult, interval time.Duration) {
+	var after, maxInterval = serverCheck.BackoffSettings()
+	if serverCheck.IsOk || after == 0 || interval <= 0 || maxInterval <= interval ||
+		result.Time.Sub(serverCheck.FailingSince) < after {
+		serverCheck.BackoffInterval = 0
+		return
+	}
+
+	serverCheck.BackoffInterval = min(max(serverCheck.BackoffInterval*2, interval*2), maxInterval)
+}
+
+// SetBackoff sets the down time after which checks of the server are stretched and the longest stretched interval,
+// zero values use the global settings. Disabled turns the backoff off for the server.
+func SetBackoff(name string, after time.Duration, maxInterval time.Duration, disabled bool) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.BackoffAfter = after
+		serverCheck.BackoffMax = maxInterval
+		serverCheck.BackoffDisabled = disabled
+		serverCheck.BackoffInterval = 0
+	})
+}
@@ -0,0 +1,154 @@
+package checks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useBackoffSettings sets the global backoff settings for the test
+func useBackoffSettings(t *testing.T, after time.Duration, maxInterval time.Duration) {
+	var downBackoffAfter, downBackoffMax = DownBackoffAfter, DownBackoffMax
+	DownBackoffAfter, DownBackoffMax = after, maxInterval
+	t.Cleanup(func() { DownBackoffAfter, DownBackoffMax = downBackoffAfter, downBackoffMax })
+}
+
+func TestBackoffSettings(t *testing.T) {
+	useBackoffSettings(t, 10*time.Minute, 15*time.Minute)
+
+	var tests = []struct {
+		name        string
+		serverCheck ServerCheck
+		wantAfter   time.Duration
+		wantMax     time.Duration
+	}{
+		{"global", ServerCheck{}, 10 * time.Minute, 15 * time.Minute},
+		{"server after", ServerCheck{BackoffAfter: 5 * time.Minute}, 5 * time.Minute, 15 * time.Minute},
+		{"server max", ServerCheck{BackoffMax: time.Hour}, 10 * time.Minute, time.Hour},
+		{"off over server settings", ServerCheck{BackoffAfter: 5 * time.Minute, BackoffMax: time.Hour, BackoffDisabled: true}, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			after, maxInterval := test.serverCheck.BackoffSettings()
+			if after != test.wantAfter || maxInterval != test.wantMax {
+				t.Errorf("got %v and %v, want %v and %v", after, maxInterval, test.wantAfter, test.wantMax)
+			}
+		})
+	}
+}
+
+func TestUpdateBackoff(t *testing.T) {
+	useBackoffSettings(t, 10*time.Minute, 5*time.Minute)
+
+	var tests = []struct {
+		name        string
+		serverCheck ServerCheck
+		downFor     time.Duration
+		want        []time.Duration
+	}{
+		{"doubles up to the cap", ServerCheck{}, 20 * time.Minute,
+			[]time.Duration{2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}},
+		{"down shorter than the threshold", ServerCheck{}, 5 * time.Minute, []time.Duration{0, 0}},
+		{"server threshold", ServerCheck{BackoffAfter: time.Minute}, 5 * time.Minute,
+			[]time.Duration{2 * time.Minute, 4 * time.Minute}},
+		{"server cap", ServerCheck{BackoffMax: 3 * time.Minute}, 20 * time.Minute,
+			[]time.Duration{2 * time.Minute, 3 * time.Minute}},
+		{"cap not above the interval", ServerCheck{BackoffMax: time.Minute}, 20 * time.Minute, []time.Duration{0}},
+		{"off", ServerCheck{BackoffDisabled: true}, 20 * time.Minute, []time.Duration{0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var now = time.Now()
+			var serverCheck = test.serverCheck
+			serverCheck.FailingSince = now.Add(-test.downFor)
+
+			for i, want := range test.want {
+				updateBackoff(&serverCheck, CheckResult{Time: now, Status: StatusFailed}, time.Minute)
+				if serverCheck.BackoffInterval != want {
+					t.Fatalf("got interval %v after %d failures, want %v", serverCheck.BackoffInterval, i+1, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffResetOnSuccess(t *testing.T) {
+	var now = time.Now()
+	var serverCheck = ServerCheck{FailingSince: now.Add(-time.Hour), BackoffInterval: 4 * time.Minute}
+
+	setCheckResult(&serverCheck, CheckResult{Time: now, Status: StatusOk}, time.UTC)
+	if serverCheck.BackoffInterval != 0 {
+		t.Errorf("got interval %v after success, want the normal cadence", serverCheck.BackoffInterval)
+	}
+}
+
+func TestBackoffDue(t *testing.T) {
+	var now = time.Now()
+
+	var tests = []struct {
+		name            string
+		backoffInterval time.Duration
+		checkedAgo      time.Duration
+		want            bool
+	}{
+		{"normal cadence", 0, 10 * time.Second, true},
+		{"never checked", 4 * time.Minute, 0, true},
+		{"interval not passed", 4 * time.Minute, 3*time.Minute + 29*time.Second, false},
+		{"half of the cycle interval tolerated", 4 * time.Minute, 3*time.Minute + 30*time.Second, true},
+		{"interval passed", 4 * time.Minute, 5 * time.Minute, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var serverCheck = ServerCheck{BackoffInterval: test.backoffInterval}
+			if test.checkedAgo > 0 {
+				serverCheck.LastChecked = now.Add(-test.checkedAgo)
+			}
+			if got := backoffDue(serverCheck, now, time.Minute); got != test.want {
+				t.Errorf("got due %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPerformCheckSkipsServersInBackoff(t *testing.T) {
+	var requests atomic.Int32
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	t.Cleanup(func() { resetFailureCount("web") })
+
+	for _, checkedAgo := range []time.Duration{time.Minute, 5 * time.Minute} {
+		requests.Store(0)
+		useStorage(t, ServerCheck{Name: "web", Url: server.URL, FailingSince: time.Now().Add(-time.Hour),
+			LastChecked: time.Now().Add(-checkedAgo), BackoffInterval: 4 * time.Minute})
+
+		PerformCheck(context.Background(), Options{AlertThreshold: 1, Notifier: &testNotifier{}, Interval: time.Minute})
+
+		var wantChecked = checkedAgo > 4*time.Minute
+		if checked := requests.Load() > 0; checked != wantChecked {
+			t.Errorf("server checked %v ago in backoff of 4m was checked: %v, want %v", checkedAgo, checked, wantChecked)
+		}
+	}
+}
+
+func TestOldestCheckOfServersInBackoff(t *testing.T) {
+	var now = time.Now()
+	var checksData = Data{HealthChecks: map[string]ServerCheck{
+		// checked before the other server, but not due until its stretched interval passes
+		"down": {Name: "down", LastChecked: now.Add(-3 * time.Minute), BackoffInterval: 10 * time.Minute},
+		"up":   {Name: "up", IsOk: true, LastChecked: now.Add(-2 * time.Minute)},
+	}}
+
+	name, checked, ok := OldestCheck(checksData)
+	if !ok || name != "up" || !checked.Equal(now.Add(-2*time.Minute)) {
+		t.Errorf("got oldest check of %q at %v, want up", name, checked)
+	}
+}
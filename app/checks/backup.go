@@ -0,0 +1,32 @@
+package checks
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Backup returns the storage encoded as the storage file with the number of servers. Basic auth credentials are
+// encrypted if a secret key is set and removed otherwise, so the backup never contains them in plain text.
+func Backup() ([]byte, int, error) {
+	var checksData = ReadChecksData()
+	checksData.SchemaVersion = SchemaVersion
+
+	if secretKeys.current != nil {
+		checksData = encryptSecrets(checksData)
+	} else {
+		for name, serverCheck := range checksData.HealthChecks {
+			serverCheck.BasicAuth = ""
+			checksData.HealthChecks[name] = serverCheck
+		}
+	}
+
+	data, err := json.MarshalIndent(checksData, "", "  ")
+	return data, len(checksData.HealthChecks), err
+}
+
+// SetLastBackup records the time of the last sent backup
+func SetLastBackup(sentAt time.Time) error {
+	return UpdateChecksData(func(checksData *Data) {
+		checksData.LastBackupAt = sentAt
+	})
+}
@@ -1,96 +1,1097 @@
 package checks
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
 type Data struct {
+	// SchemaVersion is the storage format version, older files are migrated on load
+	SchemaVersion int `json:"schemaVersion"`
+
 	HealthChecks map[string]ServerCheck `json:"healthChecks"`
+	Settings     Settings               `json:"settings"`
+	SuperUsers   []string               `json:"superUsers,omitempty"`
+
+	// Agents are probe agents by location
+	Agents map[string]AgentState `json:"agents,omitempty"`
+
+	// Subscribers are private chats getting down and up alerts of all servers
+	Subscribers []int64 `json:"subscribers,omitempty"`
+	// TagSubscribers are private chats getting down and up alerts of servers with the tag by lowercased tag
+	TagSubscribers map[string][]int64 `json:"tagSubscribers,omitempty"`
+	// Preferences are alert preferences of subscribers by chat
+	Preferences map[int64]Preferences `json:"preferences,omitempty"`
+
+	// LastBackupAt is the time the last backup was sent to Telegram
+	LastBackupAt time.Time `json:"lastBackupAt,omitempty"`
+}
+
+// Settings are runtime overrides of the flag values, empty values mean the flag value is used
+type Settings struct {
+	ChecksCron     string `json:"checksCron,omitempty"`
+	AlertThreshold int    `json:"alertThreshold,omitempty"`
+
+	// ChatMigrations maps group chat ids to supergroup chat ids they were migrated to
+	ChatMigrations map[int64]int64 `json:"chatMigrations,omitempty"`
+
+	// ChatLanguages are languages set by /setlanguage
+	ChatLanguages map[int64]string `json:"chatLanguages,omitempty"`
+
+	// Timezone is IANA timezone name set by /settimezone
+	Timezone string `json:"timezone,omitempty"`
+
+	// MaintenanceUntil is the end of the global maintenance set by /maintenanceall, no alerts are sent until it
+	MaintenanceUntil time.Time `json:"maintenanceUntil,omitempty"`
+
+	// Heartbeat is "on" or "off" set by /heartbeat
+	Heartbeat string `json:"heartbeat,omitempty"`
+
+	// ChatListModes are modes of /list last used in the chat
+	ChatListModes map[int64]string `json:"chatListModes,omitempty"`
+}
+
+// Location returns timezone set by /settimezone or fallback
+func (s Settings) Location(fallback *time.Location) *time.Location {
+	if s.Timezone == "" {
+		return fallback
+	}
+
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		log.Printf("[WARN] Invalid stored timezone %q: %v", s.Timezone, err)
+		return fallback
+	}
+	return location
 }
+
+// Language returns language set for the chat or fallback
+func (s Settings) Language(chatId int64, fallback i18n.Lang) i18n.Lang {
+	if lang, ok := i18n.Parse(s.ChatLanguages[chatId]); ok {
+		return lang
+	}
+	return fallback
+}
+
+// list modes of /list, normal shows the icon, name and url of each server
+const (
+	ListNormal  = "normal"
+	ListCompact = "compact"
+	ListVerbose = "verbose"
+)
+
+var ListModes = []string{ListNormal, ListCompact, ListVerbose}
+
+// ListMode returns the mode of /list last used in the chat or fallback
+func (s Settings) ListMode(chatId int64, fallback string) string {
+	if mode, ok := s.ChatListModes[chatId]; ok {
+		return mode
+	}
+	return fallback
+}
+
+// MigratedChat returns id of the supergroup the chat was migrated to or the same chat id
+func (s Settings) MigratedChat(chatId int64) int64 {
+	if migratedChatId, ok := s.ChatMigrations[chatId]; ok {
+		return migratedChatId
+	}
+	return chatId
+}
+
 type ServerCheck struct {
-	Name        string    `json:"name"`
-	Url         string    `json:"url"`
-	LastFailure time.Time `json:"lastFailure"`
-	LastSuccess time.Time `json:"lastSuccess"`
-	IsOk        bool      `json:"isOk"`
+	Name         string    `json:"name"`
+	Url          string    `json:"url"`
+	Type         CheckType `json:"type,omitempty"`
+	AddedAt      time.Time `json:"addedAt,omitempty"`
+	LastChecked  time.Time `json:"lastChecked,omitempty"`
+	LastFailure  time.Time `json:"lastFailure"`
+	LastSuccess  time.Time `json:"lastSuccess"`
+	IsOk         bool      `json:"isOk"`
+	FailingSince time.Time `json:"failingSince,omitempty"`
+	SnoozedUntil time.Time `json:"snoozedUntil,omitempty"`
+	Incident     *Incident `json:"incident,omitempty"`
+	// GraceUntil is the end of the grace period of the added server, it is cleared by the first successful check
+	GraceUntil time.Time `json:"graceUntil,omitempty"`
+	// PastIncidents are closed incidents in order of start
+	PastIncidents []Incident `json:"pastIncidents,omitempty"`
+	// HistoryRetainedSince and IncidentsRetainedSince are set when older entries are pruned
+	HistoryRetainedSince   time.Time `json:"historyRetainedSince,omitempty"`
+	IncidentsRetainedSince time.Time `json:"incidentsRetainedSince,omitempty"`
+
+	History   []CheckResult `json:"history,omitempty"`
+	SslExpiry time.Time     `json:"sslExpiry,omitempty"`
+	SslIssuer string        `json:"sslIssuer,omitempty"`
+	// StartTls is the protocol upgrading the connection of the SSL check before the handshake, like smtp
+	StartTls string `json:"startTls,omitempty"`
+	// SshBanner is the identification received by the last SSH check,
+	// ExpectedBanner is the text the banner must contain
+	SshBanner      string `json:"sshBanner,omitempty"`
+	ExpectedBanner string `json:"expectedBanner,omitempty"`
+	// DailyUptime is uptime of the last 90 days, oldest first
+	DailyUptime []DayUptime `json:"dailyUptime,omitempty"`
+
+	Paused bool `json:"paused,omitempty"`
+	// AlertThreshold overrides the global alert threshold if set
+	AlertThreshold int `json:"alertThreshold,omitempty"`
+
+	// Retries is the number of retries of timeouts and dropped connections within a check, RetryDelay is the delay
+	// between them, DefaultRetryDelay if zero
+	Retries    int           `json:"retries,omitempty"`
+	RetryDelay time.Duration `json:"retryDelay,omitempty"`
+
+	// BackoffAfter and BackoffMax override the global backoff of checks of the long down server if set,
+	// BackoffDisabled turns it off. BackoffInterval is the stretched interval of checks, zero at normal cadence.
+	BackoffAfter    time.Duration `json:"backoffAfter,omitempty"`
+	BackoffMax      time.Duration `json:"backoffMax,omitempty"`
+	BackoffDisabled bool          `json:"backoffDisabled,omitempty"`
+	BackoffInterval time.Duration `json:"backoffInterval,omitempty"`
+
+	// MonthlyDowntime is downtime of finished failures by month like "2024-05", TotalDowntime is the lifetime total
+	MonthlyDowntime map[string]time.Duration `json:"monthlyDowntime,omitempty"`
+	TotalDowntime   time.Duration            `json:"totalDowntime,omitempty"`
+
+	// Tags group servers for filters of /list and /stats, like "prod"
+	Tags []string `json:"tags,omitempty"`
+
+	// Soft404 fails successful checks with a body matching an error page signature
+	Soft404 bool `json:"soft404,omitempty"`
+
+	// JsonChecks fail successful checks with a JSON body whose numbers don't satisfy them
+	JsonChecks []JsonCheck `json:"jsonChecks,omitempty"`
+
+	// MinProto is the lowest accepted protocol like h2, ProtoWarned is set while the warning about it is sent
+	MinProto    string      `json:"minProto,omitempty"`
+	ProtoAction ProtoAction `json:"protoAction,omitempty"`
+	ProtoWarned bool        `json:"protoWarned,omitempty"`
+
+	// ExpectedIps are CIDRs the server must be connected on, UnexpectedIp is the address the last warning was sent about.
+	// RemoteIp is the address connected on by the last check.
+	ExpectedIps      []string         `json:"expectedIps,omitempty"`
+	ExpectedIpAction ExpectedIpAction `json:"expectedIpAction,omitempty"`
+	UnexpectedIp     string           `json:"unexpectedIp,omitempty"`
+	RemoteIp         string           `json:"remoteIp,omitempty"`
+
+	// CompressionCheck warns when text responses are not compressed, Uncompressed is set while they are not
+	CompressionCheck bool `json:"compressionCheck,omitempty"`
+	Uncompressed     bool `json:"uncompressed,omitempty"`
+
+	// SecurityCheck audits security headers of responses, SecurityHeaders is the last audit
+	SecurityCheck   bool            `json:"securityCheck,omitempty"`
+	SecurityHeaders map[string]bool `json:"securityHeaders,omitempty"`
+
+	// SlaTarget is the availability target in percent over SlaWindow, SlaBreached is set while availability is below it
+	SlaTarget   float64       `json:"slaTarget,omitempty"`
+	SlaWindow   time.Duration `json:"slaWindow,omitempty"`
+	SlaBreached bool          `json:"slaBreached,omitempty"`
+
+	// PinnedSpki is hex SHA-256 of the pinned public key, PinPending captures it from the next check.
+	// PinMismatch is the presented fingerprint the last warning was sent about.
+	PinnedSpki  string `json:"pinnedSpki,omitempty"`
+	PinPending  bool   `json:"pinPending,omitempty"`
+	PinMismatch string `json:"pinMismatch,omitempty"`
+
+	// ProbeLocations are locations of agents checking the server, ProbeResults are their last results by location
+	ProbeLocations []string               `json:"probeLocations,omitempty"`
+	ProbeResults   map[string]ProbeResult `json:"probeResults,omitempty"`
+
+	// CheckAllIps checks each resolved address of the url, IpResults are the results of the last check
+	CheckAllIps bool       `json:"checkAllIps,omitempty"`
+	IpFailMode  IpFailMode `json:"ipFailMode,omitempty"`
+	IpResults   []IpResult `json:"ipResults,omitempty"`
+
+	// Endpoints are additional urls checked with the server url, EndpointFailMode is whether the server is down
+	// if any of them fails or all of them. EndpointResults are the results of the url and endpoints by the last check.
+	Endpoints        []Endpoint       `json:"endpoints,omitempty"`
+	EndpointFailMode IpFailMode       `json:"endpointFailMode,omitempty"`
+	EndpointResults  []EndpointResult `json:"endpointResults,omitempty"`
+
+	// BasicAuth is user info like user:password sent with checks as basic auth, escaped as in urls
+	BasicAuth string `json:"basicAuth,omitempty"`
+	// UrlCredentials is encrypted BasicAuth, it is only set in the storage file when a secret key is set
+	UrlCredentials string `json:"urlCredentials,omitempty"`
+
+	// RecentErrors are distinct errors of the last failed checks, most recently seen first
+	RecentErrors []RecentError `json:"recentErrors,omitempty"`
+
+	// UpSince is the start of the current uptime streak, the current downtime streak starts at FailingSince.
+	// LongestUptime and LongestDowntime are the records, LastUptime is the uptime streak ended by the last failure.
+	UpSince         time.Time `json:"upSince,omitempty"`
+	LongestUptime   Streak    `json:"longestUptime"`
+	LongestDowntime Streak    `json:"longestDowntime"`
+	LastUptime      Streak    `json:"lastUptime"`
+
+	// Schedule is the named schedule the server is checked on, the default schedule if empty
+	Schedule string `json:"schedule,omitempty"`
+
+	// Score is the health score of the server recomputed after each check cycle, see HealthScore.
+	// ScoreSamples are hourly scores of the last day.
+	Score        int           `json:"score,omitempty"`
+	ScoredAt     time.Time     `json:"scoredAt,omitempty"`
+	ScoreSamples []ScoreSample `json:"scoreSamples,omitempty"`
+
+	// Subscribers are private chats getting down and up alerts of the server
+	Subscribers []int64 `json:"subscribers,omitempty"`
+
+	// SimulatedCycles is the number of the next check cycles the server fails in simulation,
+	// SimulatedFailure is set while the failure was started by simulation
+	SimulatedCycles  int  `json:"simulatedCycles,omitempty"`
+	SimulatedFailure bool `json:"simulatedFailure,omitempty"`
+}
+
+// Incident is opened when the down alert is sent and closed when the server is up again
+type Incident struct {
+	Start       time.Time `json:"start"`
+	AlertedAt   time.Time `json:"alertedAt"`
+	AckBy       string    `json:"ackBy,omitempty"`
+	AckAt       time.Time `json:"ackAt,omitempty"`
+	AckComment  string    `json:"ackComment,omitempty"`
+	EscalatedAt time.Time `json:"escalatedAt,omitempty"`
+	End         time.Time `json:"end,omitempty"`
+	// Simulated incidents are opened by simulated failures, they are not kept in past incidents
+	Simulated bool `json:"simulated,omitempty"`
+
+	// FailedChecks is the number of failed checks of the incident, Errors are distinct errors of the failed checks
+	FailedChecks int      `json:"failedChecks,omitempty"`
+	Errors       []string `json:"errors,omitempty"`
+
+	// EndedUptime is the uptime streak ended by the incident if it was long enough to mention,
+	// EndedRecord is set if it was the longest one
+	EndedUptime time.Duration `json:"endedUptime,omitempty"`
+	EndedRecord bool          `json:"endedRecord,omitempty"`
+}
+
+var ErrServerNotExists = errors.New("server not exists")
+var ErrServerExists = errors.New("server already exists")
+var ErrServerLimit = errors.New("limit of servers reached")
+
+// MaxServers is the maximum number of servers, main sets it from the flag, 0 is unlimited
+var MaxServers int
+
+// UserAgent is sent with check requests, main adds the version
+var UserAgent = "server-healthcheck-telegram-bot"
+
+// checkTransport is shared by checks, HTTP/2 is negotiated with TLS servers, so the served protocol is checked
+var checkTransport = func() *http.Transport {
+	var transport = http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	return transport
+}()
+var checkClient = &http.Client{Transport: checkTransport}
+
+// Options configures alerting of PerformCheck
+type Options struct {
+	AlertThreshold  int
+	EscalationAfter time.Duration
+
+	// Notifier sends alerts to the chat, EscalationNotifier sends escalations and is nil if escalation is disabled
+	Notifier           notify.Notifier
+	EscalationNotifier notify.Notifier
+
+	// Notifiers are additional channels, they get down and up events in background
+	Notifiers []notify.Notifier
+
+	// HistoryRetention and IncidentRetention limit stored check results and closed incidents of each server
+	HistoryRetention  Retention
+	IncidentRetention Retention
+
+	// ProbeQuorum is the number of locations which must report a server with agents down,
+	// results of agents not reporting for AgentHeartbeat are ignored and the agent is reported offline
+	ProbeQuorum    int
+	AgentHeartbeat time.Duration
+
+	// IncidentSummary sends the summary of the incident to the chat when the server recovers
+	IncidentSummary bool
+
+	// PromTextfile is the path metrics are written to after each check cycle, disabled if empty
+	PromTextfile string
+
+	// Schedule is the named schedule of the servers checked by the cycle, the default schedule if empty.
+	// Cycles of named schedules only check their servers, the rest of the cycle runs on the default schedule.
+	Schedule string
+	// Interval is the cron period of the schedule, checks of long down servers are stretched from it.
+	// The backoff is disabled if it is zero.
+	Interval time.Duration
+	// CycleDeadline limits the total time of the checks of the cycle, servers not checked by then are skipped.
+	// Time of sending alerts is not counted. The checks are not limited if it is zero.
+	CycleDeadline time.Duration
+
+	// SubscriberNotifier returns the notifier of the subscribed private chat, subscriptions are ignored if it is nil
+	SubscriberNotifier func(chatId int64) notify.Notifier
+
+	// ChecksSkipped is called when cycles of a schedule reached their deadline before checking all servers
+	// several times in a row, skipped are the servers not checked by the last one. It can be nil.
+	ChecksSkipped func(skipped []string, cycles int)
+
+	// maintenanceUntil is the end of the global maintenance at the start of the check cycle
+	maintenanceUntil time.Time
 }
 
 var serverFailureCount = map[string]int{}
-var serverSendFaultMessage = map[string]bool{}
+var failureCountMutex sync.Mutex
+
+var lastCycleMutex sync.Mutex
+var lastCycleAt time.Time
 
-func PerformCheck(bot *tgbotapi.BotAPI, chatId int64, alertThreshold int) {
+// PerformCheck checks the servers of the schedule of options and sends alerts. Servers not checked when ctx is done
+// or the checks took CycleDeadline of options are skipped for the cycle, they are not marked failed.
+func PerformCheck(ctx context.Context, options Options) {
 	log.Printf("[DEBUG] Cron job started")
+	failureCountMutex.Lock()
 	log.Printf("[DEBUG] serverFailureCount: %v", serverFailureCount)
-	log.Printf("[DEBUG] serverSendFaultMessage: %v", serverSendFaultMessage)
+	failureCountMutex.Unlock()
 
 	var checksData = ReadChecksData()
 
+	// threshold set by /setthresholdglobal overrides the flag value
+	var alertThreshold = options.AlertThreshold
+	if checksData.Settings.AlertThreshold > 0 {
+		alertThreshold = checksData.Settings.AlertThreshold
+	}
+
+	var location = checksData.Settings.Location(Location)
+	options.maintenanceUntil = checksData.Settings.MaintenanceUntil
+
+	var schedule = options.Schedule
+	if schedule == "" {
+		schedule = DefaultSchedule
+	}
+
+	var budget = newCheckBudget(ctx, options.CycleDeadline)
+	var skipped []string
 	for _, serverCheck := range checksData.HealthChecks {
-		var serverAvailable = serverStatusIsOk(serverCheck.Url)
-		var checkTime = time.Now()
+		if serverCheck.ScheduleName() != schedule {
+			continue
+		}
+		if serverCheck.Paused {
+			log.Printf("[DEBUG] Server %s is paused, check skipped", serverCheck.Url)
+			continue
+		}
+		if serverCheck.SimulatedCycles == 0 && !backoffDue(serverCheck, time.Now(), options.Interval) {
+			log.Printf("[DEBUG] Server %s is checked every %v due to prolonged outage, check skipped",
+				serverCheck.Url, serverCheck.BackoffInterval)
+			continue
+		}
+		if budget.spent() {
+			skipped = append(skipped, serverCheck.Name)
+			continue
+		}
 
-		if serverAvailable {
-			serverCheck.LastSuccess = checkTime
+		var result CheckResult
+		if serverCheck.SimulatedCycles > 0 {
+			log.Printf("[INFO] Server %s fails in simulation, %d cycles left", serverCheck.Url, serverCheck.SimulatedCycles-1)
+			result = simulatedResult()
 		} else {
-			serverCheck.LastFailure = checkTime
+			var checked bool
+			result, checked = budget.run(func(ctx context.Context) CheckResult {
+				return combineProbeResults(serverCheck, checkServer(ctx, serverCheck), options.ProbeQuorum, options.AgentHeartbeat)
+			})
+			if !checked {
+				skipped = append(skipped, serverCheck.Name)
+				continue
+			}
+			metrics.ChecksPerformed.Add(1)
 		}
-		serverCheck.IsOk = serverAvailable
+		setCheckResult(&serverCheck, result, location)
 
-		// append new check to server checks
-		checksData.HealthChecks[serverCheck.Name] = serverCheck
+		// save check result, incident is closed when server is up
+		var incident, closedIncident *Incident
+		var protoWarning, compressionWarning bool
+		var missingHeaders []string
+		var pinMismatch, unexpectedIp bool
+		err := UpdateServerCheck(serverCheck.Name, func(storedCheck *ServerCheck) {
+			setCheckResult(storedCheck, result, location)
+			if result.Simulated {
+				countSimulatedCycle(storedCheck)
+			} else {
+				protoWarning = updateProtoWarning(storedCheck, result)
+				compressionWarning = updateCompressionWarning(storedCheck, result)
+				missingHeaders = updateSecurityAudit(storedCheck, result)
+				pinMismatch = updatePin(storedCheck, result)
+				unexpectedIp = updateExpectedIpWarning(storedCheck, result)
+				updateBackoff(storedCheck, result, options.Interval)
+			}
+			if !storedCheck.IsOk && storedCheck.Incident != nil {
+				storedCheck.Incident.recordFailure(result)
+			}
+			if storedCheck.IsOk && storedCheck.Incident != nil {
+				closedIncident = storedCheck.Incident
+				closeIncident(storedCheck, result.Time)
+			}
+			incident = storedCheck.Incident
+		})
+		if err != nil {
+			log.Printf("[ERROR] Error while saving checks data: %v", err)
+			continue
+		}
 
-		if !serverAvailable {
-			serverFailureCount[serverCheck.Name]++
+		if protoWarning {
+			sendProtoWarning(options, serverCheck, result)
+		}
+		if compressionWarning {
+			sendCompressionWarning(options, serverCheck, result)
+		}
+		if len(missingHeaders) > 0 {
+			sendSecurityWarning(options, serverCheck, result, missingHeaders)
+		}
+		if pinMismatch {
+			sendPinWarning(options, serverCheck, result)
+		}
+		if unexpectedIp {
+			sendExpectedIpWarning(options, serverCheck, result)
+		}
 
-			log.Printf("[INFO] Server %s is down %v times", serverCheck.Url, serverFailureCount[serverCheck.Url])
-			if serverFailureCount[serverCheck.Name] >= alertThreshold {
-				msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("❗❗❗ Server %s is down ❗❗❗", serverCheck.Url))
-				_, err := bot.Send(msg)
-				if err != nil {
-					log.Printf("[ERROR] Failed to send message: %v", err)
-				}
+		if !serverCheck.IsOk && serverCheck.InGrace(result.Time) {
+			log.Printf("[INFO] Server %s is down in grace period until %v, alert skipped", serverCheck.Url, serverCheck.GraceUntil)
+		} else if !serverCheck.IsOk {
+			var failureCount = increaseFailureCount(serverCheck.Name)
+
+			log.Printf("[INFO] Server %s is down %v times", serverCheck.Url, failureCount)
+			var serverAlertThreshold = alertThreshold
+			if serverCheck.AlertThreshold > 0 {
+				serverAlertThreshold = serverCheck.AlertThreshold
+			}
+
+			if failureCount >= serverAlertThreshold {
+				sendDownAlert(options, serverCheck, result)
+				resetFailureCount(serverCheck.Name)
+			}
 
-				serverSendFaultMessage[serverCheck.Name] = true
-				serverFailureCount[serverCheck.Name] = 0
+			if incident != nil && options.EscalationNotifier != nil && !options.inMaintenance() {
+				escalateIncident(options, serverCheck, *incident)
 			}
 		} else {
-			if serverSendFaultMessage[serverCheck.Name] {
-				msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Server %s is up 🎉", serverCheck.Url))
-				_, err := bot.Send(msg)
-				if err != nil {
-					log.Printf("[ERROR] Failed to send message: %v", err)
+			if closedIncident != nil {
+				var event = notify.Event{
+					Type:         notify.EventUp,
+					Server:       serverCheck.Name,
+					Url:          redact.Url(serverCheck.Url),
+					StatusCode:   result.StatusCode,
+					ResponseTime: result.ResponseTime,
+					Time:         result.Time,
+					Since:        closedIncident.Start,
+					Duration:     result.Time.Sub(closedIncident.Start),
+					Simulated:    closedIncident.Simulated,
+					EndedUptime:  closedIncident.EndedUptime,
+					EndedRecord:  closedIncident.EndedRecord,
+				}
+				if closedIncident.AckBy != "" {
+					event.AckBy = closedIncident.AckBy
+					event.AckDelay = closedIncident.AckAt.Sub(closedIncident.AlertedAt)
 				}
+				sendEvent(options, event)
 
-				serverSendFaultMessage[serverCheck.Name] = false
+				if options.IncidentSummary {
+					sendIncidentSummary(options, serverCheck, *closedIncident, result.Time, location)
+				}
+
+				if !closedIncident.EscalatedAt.IsZero() && options.EscalationNotifier != nil && !options.inMaintenance() {
+					sendEscalationResolved(options.EscalationNotifier, serverCheck, *closedIncident)
+				}
 			}
 
-			serverFailureCount[serverCheck.Name] = 0
+			resetFailureCount(serverCheck.Name)
 		}
+	}
 
-		// save checks data
-		err := SaveChecksData(checksData)
-		if err != nil {
-			log.Printf("[ERROR] Error while saving checks data: %v", err)
+	recordSkipped(options, schedule, skipped)
+
+	if schedule != DefaultSchedule {
+		log.Printf("[DEBUG] Check cycle of schedule %s completed", schedule)
+		return
+	}
+
+	checkSlas(options, location)
+	checkAgents(options)
+	endMaintenance(options)
+	pruneChecksData(options)
+
+	var completedAt = time.Now()
+	updateScores(completedAt)
+	if options.PromTextfile != "" {
+		if err := WriteTextfile(options.PromTextfile, completedAt); err != nil {
+			log.Printf("[ERROR] Failed to write metrics to %s: %v", options.PromTextfile, err)
+		}
+	}
+
+	lastCycleMutex.Lock()
+	lastCycleAt = completedAt
+	lastCycleMutex.Unlock()
+	metrics.CheckCycles.Add(1)
+}
+
+// LastCycleTime returns the time the last PerformCheck cycle completed, zero if none completed yet
+func LastCycleTime() time.Time {
+	lastCycleMutex.Lock()
+	defer lastCycleMutex.Unlock()
+
+	return lastCycleAt
+}
+
+// OldestCheck returns the server of the default schedule checked longest ago and the time of its last check,
+// servers not checked yet count from the time they were added and servers in backoff from the end
+// of their stretched interval. Paused servers are skipped, false if there are none.
+func OldestCheck(checksData Data) (name string, checked time.Time, ok bool) {
+	for _, serverCheck := range checksData.HealthChecks {
+		if serverCheck.Paused || serverCheck.ScheduleName() != DefaultSchedule {
 			continue
 		}
+
+		var last = serverCheck.LastChecked
+		if last.IsZero() {
+			last = serverCheck.AddedAt
+		} else {
+			last = last.Add(serverCheck.BackoffInterval)
+		}
+		if !ok || last.Before(checked) {
+			name, checked, ok = serverCheck.Name, last, true
+		}
 	}
+	return name, checked, ok
 }
 
-func serverStatusIsOk(serverUrl string) bool {
-	resp, err := http.Get(serverUrl)
+func increaseFailureCount(name string) int {
+	failureCountMutex.Lock()
+	defer failureCountMutex.Unlock()
+
+	serverFailureCount[name]++
+	return serverFailureCount[name]
+}
+
+func resetFailureCount(name string) {
+	failureCountMutex.Lock()
+	defer failureCountMutex.Unlock()
+
+	delete(serverFailureCount, name)
+}
+
+// sendEvent sends the event to the chat and to additional notifiers in background, nothing is sent during maintenance
+func sendEvent(options Options, event notify.Event) {
+	if options.inMaintenance() {
+		log.Printf("[INFO] Maintenance until %v, %s event of %s is not sent", options.maintenanceUntil, event.Type, event.Server)
+		return
+	}
+
+	if err := options.Notifier.Send(event); err != nil {
+		log.Printf("[ERROR] Failed to send message: %v", err)
+	}
+
+	notify.SendAll(options.Notifiers, event)
+	if event.Server != "" && EventCategory(event.Type) != "" {
+		sendToSubscribers(options, event)
+	}
+}
+
+// sendDownAlert opens incident and sends the down alert, repeated alerts are skipped
+// when the incident is acknowledged or the server is snoozed
+func sendDownAlert(options Options, serverCheck ServerCheck, result CheckResult) {
+	var now = time.Now()
+	if serverCheck.SnoozedUntil.After(now) {
+		log.Printf("[INFO] Server %s is snoozed until %v, alert skipped", serverCheck.Url, serverCheck.SnoozedUntil)
+		return
+	}
+	if options.inMaintenance() {
+		log.Printf("[INFO] Maintenance until %v, alert of server %s skipped", options.maintenanceUntil, serverCheck.Url)
+		return
+	}
+
+	var incident Incident
+	err := UpdateServerCheck(serverCheck.Name, func(storedCheck *ServerCheck) {
+		if storedCheck.Incident == nil {
+			storedCheck.Incident = &Incident{Start: storedCheck.FailingSince, AlertedAt: now, Simulated: result.Simulated}
+			if streak, record, ok := endedUptime(*storedCheck); ok {
+				storedCheck.Incident.EndedUptime, storedCheck.Incident.EndedRecord = streak.Duration(), record
+			}
+			// failures before the alert are in the history, the result of this check too
+			for _, failed := range HistorySince(storedCheck.History, storedCheck.FailingSince) {
+				storedCheck.Incident.recordFailure(failed)
+			}
+		}
+		incident = *storedCheck.Incident
+	})
+	if err != nil {
+		log.Printf("[ERROR] Error while saving checks data: %v", err)
+		return
+	}
+
+	if incident.AckBy != "" {
+		log.Printf("[INFO] Server %s is acknowledged by %s, alert skipped", serverCheck.Url, incident.AckBy)
+		return
+	}
+
+	sendEvent(options, notify.Event{
+		Type:          notify.EventDown,
+		Server:        serverCheck.Name,
+		Url:           redact.Url(serverCheck.Url),
+		Error:         result.Error,
+		ErrorCategory: result.Category,
+		BodyExcerpt:   result.Excerpt,
+		StatusCode:    result.StatusCode,
+		ResponseTime:  result.ResponseTime,
+		Time:          result.Time,
+		Since:         serverCheck.FailingSince,
+		Duration:      result.Time.Sub(serverCheck.FailingSince),
+		Simulated:     result.Simulated,
+	})
+}
+
+// sendProtoWarning sends the warning about the server served over a protocol lower than its minimum
+func sendProtoWarning(options Options, serverCheck ServerCheck, result CheckResult) {
+	log.Printf("[INFO] Server %s is served over %s, expected %s", serverCheck.Url, result.Proto, serverCheck.MinProto)
+
+	sendEvent(options, notify.Event{
+		Type:          notify.EventProtocol,
+		Server:        serverCheck.Name,
+		Url:           redact.Url(serverCheck.Url),
+		Error:         fmt.Sprintf("protocol %s, expected %s", result.Proto, serverCheck.MinProto),
+		StatusCode:    result.StatusCode,
+		ResponseTime:  result.ResponseTime,
+		Time:          result.Time,
+		Proto:         result.Proto,
+		ExpectedProto: serverCheck.MinProto,
+	})
+}
+
+// sendCompressionWarning sends the warning about the server responding without compression
+func sendCompressionWarning(options Options, serverCheck ServerCheck, result CheckResult) {
+	log.Printf("[INFO] Server %s responds without compression", serverCheck.Url)
+
+	sendEvent(options, notify.Event{
+		Type:         notify.EventCompression,
+		Server:       serverCheck.Name,
+		Url:          redact.Url(serverCheck.Url),
+		Error:        "response is not compressed",
+		StatusCode:   result.StatusCode,
+		ResponseTime: result.ResponseTime,
+		Time:         result.Time,
+	})
+}
+
+// sendSecurityWarning sends the warning about security headers which the server stopped sending
+func sendSecurityWarning(options Options, serverCheck ServerCheck, result CheckResult, headers []string) {
+	log.Printf("[INFO] Server %s stopped sending %s", serverCheck.Url, strings.Join(headers, ", "))
+
+	sendEvent(options, notify.Event{
+		Type:         notify.EventSecurityHeaders,
+		Server:       serverCheck.Name,
+		Url:          redact.Url(serverCheck.Url),
+		Error:        "missing " + strings.Join(headers, ", "),
+		StatusCode:   result.StatusCode,
+		ResponseTime: result.ResponseTime,
+		Time:         result.Time,
+		Headers:      headers,
+	})
+}
+
+// sendPinWarning sends the warning about the public key of the server not matching the pin
+func sendPinWarning(options Options, serverCheck ServerCheck, result CheckResult) {
+	log.Printf("[WARN] Server %s presented public key %s, pinned %s", serverCheck.Url, result.Spki, serverCheck.PinnedSpki)
+
+	sendEvent(options, notify.Event{
+		Type:              notify.EventPinMismatch,
+		Server:            serverCheck.Name,
+		Url:               redact.Url(serverCheck.Url),
+		Error:             "public key fingerprint " + result.Spki + " doesn't match the pin",
+		StatusCode:        result.StatusCode,
+		ResponseTime:      result.ResponseTime,
+		Time:              result.Time,
+		Fingerprint:       result.Spki,
+		PinnedFingerprint: serverCheck.PinnedSpki,
+	})
+}
+
+// sendExpectedIpWarning sends the warning about the server connected on an address outside its expected ones
+func sendExpectedIpWarning(options Options, serverCheck ServerCheck, result CheckResult) {
+	log.Printf("[WARN] Server %s connected on %s, expected %v", serverCheck.Url, result.RemoteIp, serverCheck.ExpectedIps)
+
+	sendEvent(options, notify.Event{
+		Type:         notify.EventUnexpectedIp,
+		Server:       serverCheck.Name,
+		Url:          redact.Url(serverCheck.Url),
+		Error:        fmt.Sprintf("connected to %s, expected %s", result.RemoteIp, strings.Join(serverCheck.ExpectedIps, ", ")),
+		StatusCode:   result.StatusCode,
+		ResponseTime: result.ResponseTime,
+		Time:         result.Time,
+		Ip:           result.RemoteIp,
+		ExpectedIps:  serverCheck.ExpectedIps,
+	})
+}
+
+// RecheckServer checks the server immediately and saves the result, alert counters and incident are not changed
+func RecheckServer(name string) (ServerCheck, CheckResult, error) {
+	var checksData = ReadChecksData()
+	serverCheck, ok := checksData.HealthChecks[name]
+	if !ok {
+		return serverCheck, CheckResult{}, ErrServerNotExists
+	}
+
+	// the simulated failure continues until the cycles run out, the manual check doesn't count as a cycle
+	var result CheckResult
+	if serverCheck.SimulatedCycles > 0 {
+		result = simulatedResult()
+	} else {
+		result = checkServer(context.Background(), serverCheck)
+	}
+	var location = checksData.Settings.Location(Location)
+
+	err := UpdateServerCheck(name, func(storedCheck *ServerCheck) {
+		setCheckResult(storedCheck, result, location)
+		if !storedCheck.IsOk && storedCheck.Incident != nil {
+			storedCheck.Incident.recordFailure(result)
+		}
+		serverCheck = *storedCheck
+	})
+
+	return serverCheck, result, err
+}
+
+// AckIncident acknowledges the active incident of the server
+func AckIncident(name string, userName string, comment string) (Incident, error) {
+	var incident Incident
+	err := UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		if serverCheck.Incident == nil {
+			return
+		}
+
+		serverCheck.Incident.AckBy = userName
+		serverCheck.Incident.AckAt = time.Now()
+		serverCheck.Incident.AckComment = comment
+		incident = *serverCheck.Incident
+	})
+
+	return incident, err
+}
+
+func checkServer(ctx context.Context, serverCheck ServerCheck) CheckResult {
+	if serverCheck.IsSsl() {
+		return checkSsl(ctx, serverCheck)
+	}
+	if serverCheck.IsSsh() {
+		return checkSsh(ctx, serverCheck)
+	}
+	if len(serverCheck.Endpoints) > 0 {
+		return checkEndpoints(ctx, serverCheck)
+	}
+	return checkUrl(ctx, serverCheck)
+}
+
+// checkUrl checks the url of the server, each resolved address if the server is set so
+func checkUrl(ctx context.Context, serverCheck ServerCheck) CheckResult {
+	if serverCheck.CheckAllIps {
+		return checkAllIps(ctx, serverCheck)
+	}
+
+	var start = time.Now()
+	response, responseTime, err := requestWithRetries(ctx, checkClient, serverCheck)
+	var result = CheckResult{Time: start, ResponseTime: responseTime, StatusCode: response.statusCode,
+		Proto: response.proto, SslExpiry: response.sslExpiry, Uncompressed: response.uncompressed,
+		SecurityHeaders: response.securityHeaders, Spki: response.spki, RemoteIp: response.remoteIp,
+		Attempts: response.attempts, Category: errorCategory(err)}
+	result.Status, result.Error = checkStatus(response, err, serverCheck)
+	if BodyExcerpt && result.Status == StatusFailed && len(response.body) > 0 {
+		result.Excerpt = bodyExcerpt(response.body)
+	}
+
+	return result
+}
+
+// checkStatus returns status of the check by the response status code or the request error,
+// successful responses fail on a protocol lower than the minimum, an unexpected address and on error page bodies
+// if the server is set so
+func checkStatus(response serverResponse, err error, serverCheck ServerCheck) (CheckStatus, string) {
+	switch {
+	case err != nil:
+		// errors of requests include the url
+		return StatusFailed, redact.Text(err.Error())
+	case response.statusCode != http.StatusOK:
+		return StatusFailed, fmt.Sprintf("status code %d", response.statusCode)
+	}
+
+	if protoError := protoError(serverCheck, response.proto); protoError != "" {
+		return StatusFailed, protoError
+	}
+	if ipError := expectedIpError(serverCheck, response.remoteIp); ipError != "" {
+		return StatusFailed, ipError
+	}
+	if serverCheck.Soft404 {
+		if signature := matchSoft404(response.body); signature != "" {
+			return StatusFailed, fmt.Sprintf("error page, body matches %q", signature)
+		}
+	}
+	if jsonError := jsonChecksError(response.body, serverCheck.JsonChecks); jsonError != "" {
+		return StatusFailed, jsonError
+	}
+	return StatusOk, ""
+}
+
+// setCheckResult sets status fields of the server check and appends result to its history,
+// downtime is recorded in the monthly buckets of the location
+func setCheckResult(serverCheck *ServerCheck, result CheckResult, location *time.Location) {
+	serverCheck.LastChecked = result.Time
+	if result.Simulated {
+		setSimulatedResult(serverCheck, result)
+		return
+	}
+	// a failure started by simulation is not a state change, the server was up before it
+	var checkedBefore = !serverCheck.LastSuccess.IsZero() || !serverCheck.LastFailure.IsZero()
+	var wasOk = serverCheck.IsOk || serverCheck.SimulatedFailure
+	endSimulatedFailure(serverCheck, result)
+	recordStreaks(serverCheck, result)
+
+	serverCheck.IsOk = result.Status != StatusFailed
+	if serverCheck.IsOk {
+		recordDowntime(serverCheck, result.Time, location)
+		serverCheck.LastSuccess = result.Time
+		serverCheck.FailingSince = time.Time{}
+		serverCheck.GraceUntil = time.Time{}
+		serverCheck.BackoffInterval = 0
+	} else {
+		serverCheck.LastFailure = result.Time
+		if serverCheck.FailingSince.IsZero() {
+			serverCheck.FailingSince = result.Time
+		}
+	}
+
+	if !result.SslExpiry.IsZero() {
+		serverCheck.SslExpiry = result.SslExpiry
+	}
+	if result.SslIssuer != "" {
+		serverCheck.SslIssuer = result.SslIssuer
+	}
+	if result.SshBanner != "" {
+		serverCheck.SshBanner = result.SshBanner
+	}
+	if result.RemoteIp != "" {
+		serverCheck.RemoteIp = result.RemoteIp
+	}
+
+	serverCheck.IpResults = result.Ips
+	recordEndpointResults(serverCheck, result.Endpoints)
+	serverCheck.History = appendHistory(serverCheck.History, result)
+	recordDayUptime(serverCheck, result, location, checkedBefore && wasOk != serverCheck.IsOk)
+	recordRecentError(serverCheck, result)
+}
+
+// AddServer adds a new server check, returns ErrServerExists if there is a server with the same name
+// and ErrServerLimit if there are MaxServers servers already. The limit is checked under the storage lock.
+func AddServer(serverCheck ServerCheck) error {
+	var exists, limited bool
+	err := UpdateChecksData(func(data *Data) {
+		if _, exists = data.HealthChecks[serverCheck.Name]; exists {
+			return
+		}
+		if limited = MaxServers > 0 && len(data.HealthChecks) >= MaxServers; limited {
+			return
+		}
+
+		if data.HealthChecks == nil {
+			data.HealthChecks = make(map[string]ServerCheck)
+		}
+		if serverCheck.AddedAt.IsZero() {
+			serverCheck.AddedAt = time.Now()
+		}
+		if serverCheck.GraceUntil.IsZero() && NewServerGrace > 0 {
+			serverCheck.GraceUntil = serverCheck.AddedAt.Add(NewServerGrace)
+		}
+		data.HealthChecks[serverCheck.Name] = serverCheck
+	})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrServerExists
+	}
+	if limited {
+		return ErrServerLimit
+	}
+
+	return nil
+}
+
+// RemoveServer removes the server check, returns ErrServerNotExists if there is no such server
+func RemoveServer(name string) error {
+	var found bool
+	err := UpdateChecksData(func(data *Data) {
+		if _, found = data.HealthChecks[name]; found {
+			delete(data.HealthChecks, name)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrServerNotExists
+	}
+
+	resetFailureCount(name)
+	return nil
+}
+
+// RemoveAllServers removes all server checks, settings are kept
+func RemoveAllServers() error {
+	err := UpdateChecksData(func(data *Data) {
+		data.HealthChecks = make(map[string]ServerCheck)
+	})
+	if err != nil {
+		return err
+	}
+
+	failureCountMutex.Lock()
+	serverFailureCount = map[string]int{}
+	failureCountMutex.Unlock()
+	return nil
+}
+
+// FullServerUrl adds https scheme to the server url if it has no scheme and brackets the host if it is an IPv6 literal,
+// internationalized hosts are converted to the Unicode form
+func FullServerUrl(serverUrl string) string {
+	var scheme = "https://"
+	for _, prefix := range []string{"https://", "http://"} {
+		if strings.HasPrefix(serverUrl, prefix) {
+			scheme, serverUrl = prefix, strings.TrimPrefix(serverUrl, prefix)
+			break
+		}
+	}
+
+	// a port can be told apart from the IPv6 address only in brackets, so unbracketed literals have no port
+	host, path, hasPath := strings.Cut(serverUrl, "/")
+	if strings.Contains(host, ":") && net.ParseIP(host) != nil {
+		serverUrl = "[" + host + "]"
+		if hasPath {
+			serverUrl += "/" + path
+		}
+	}
+
+	return unicodeHost(scheme + serverUrl)
+}
+
+// UrlName returns the default name of the server with the url, it is the url without scheme, credentials,
+// IPv6 brackets and the trailing slash
+func UrlName(serverUrl string) string {
+	parsedUrl, err := url.Parse(FullServerUrl(serverUrl))
+	if err != nil || parsedUrl.Host == "" {
+		return serverUrl
+	}
+
+	var name = parsedUrl.Hostname()
+	if port := parsedUrl.Port(); port != "" {
+		name += ":" + port
+	}
+	name += strings.TrimSuffix(parsedUrl.EscapedPath(), "/")
+	if parsedUrl.RawQuery != "" {
+		name += "?" + parsedUrl.RawQuery
+	}
+
+	return name
+}
+
+// UpdateServerCheck applies update to the stored server check, returns ErrServerNotExists if there is no such server
+func UpdateServerCheck(name string, update func(serverCheck *ServerCheck)) error {
+	var found bool
+	err := UpdateChecksData(func(data *Data) {
+		storedCheck, ok := data.HealthChecks[name]
+		if !ok {
+			return
+		}
+
+		found = true
+		update(&storedCheck)
+		data.HealthChecks[name] = storedCheck
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrServerNotExists
+	}
+
+	return nil
+}
+
+// serverResponse is the part of the response used by checks, body is read only for soft 404 detection,
+// JSON checks and excerpts of error responses
+type serverResponse struct {
+	statusCode   int
+	proto        string
+	sslExpiry    time.Time
+	spki         string
+	body         []byte
+	uncompressed bool
+	// remoteIp is the address of the first connection of the request
+	remoteIp string
+	// attempts is the number of requests of the check if it was retried
+	attempts int
+
+	securityHeaders map[string]bool
+}
+
+// requestServer performs GET request to the server, returns response status code
+// and expiry of the server certificate for https
+func requestServer(ctx context.Context, client *http.Client, serverCheck ServerCheck) (serverResponse, error) {
+	var response serverResponse
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, asciiUrl(serverCheck.Url), nil)
+	if err != nil {
+		return response, err
+	}
+	// connections of redirects are not recorded
+	request = request.WithContext(httptrace.WithClientTrace(request.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if response.remoteIp == "" {
+				response.remoteIp = remoteIp(info.Conn)
+			}
+		},
+	}))
+	request.Header.Set("User-Agent", UserAgent)
+	if user, password, ok := serverCheck.basicAuth(); ok {
+		request.SetBasicAuth(user, password)
+	}
+	if serverCheck.CompressionCheck {
+		// the transport doesn't decompress responses of requests with Accept-Encoding set
+		request.Header.Set("Accept-Encoding", compressionEncodings)
+	}
+
+	resp, err := client.Do(request)
 	if err != nil {
 		log.Printf("[DEBUG] Failed to get server status: %v", err)
-		return false
+		return response, err
 	}
 	defer resp.Body.Close()
 
-	var code = resp.StatusCode
+	response.statusCode, response.proto = resp.StatusCode, resp.Proto
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		response.sslExpiry = resp.TLS.PeerCertificates[0].NotAfter
+		response.spki = spkiFingerprint(resp.TLS.PeerCertificates[0])
+	}
+	if serverCheck.SecurityCheck {
+		response.securityHeaders = auditSecurityHeaders(resp.Header)
+	}
+
+	var body io.Reader = resp.Body
+	if serverCheck.CompressionCheck {
+		response.uncompressed, body = checkCompression(resp)
+	}
+	// a failed read leaves the body partial, the status code is still valid
+	if serverCheck.Soft404 || len(serverCheck.JsonChecks) > 0 {
+		response.body, _ = io.ReadAll(io.LimitReader(body, maxBodySize))
+	} else if BodyExcerpt && resp.StatusCode != http.StatusOK {
+		response.body, _ = io.ReadAll(io.LimitReader(body, excerptBodySize))
+	}
 
-	log.Printf("[DEBUG] server %v, code: %v", serverUrl, code)
+	log.Printf("[DEBUG] server %v, code: %v", serverCheck.Url, resp.StatusCode)
 
-	return code == http.StatusOK
+	return response, nil
 }
@@ -0,0 +1,80 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckStatus(t *testing.T) {
+	var tests = []struct {
+		name       string
+		response   serverResponse
+		err        error
+		wantStatus CheckStatus
+		wantError  string
+	}{
+		{"ok", serverResponse{statusCode: http.StatusOK}, nil, StatusOk, ""},
+		{"not found", serverResponse{statusCode: http.StatusNotFound}, nil, StatusFailed, "status code 404"},
+		{"server error", serverResponse{statusCode: http.StatusBadGateway}, nil, StatusFailed, "status code 502"},
+		{"redirect", serverResponse{statusCode: http.StatusMovedPermanently}, nil, StatusFailed, "status code 301"},
+		{"request error", serverResponse{}, errors.New("dial tcp: connection refused"), StatusFailed, "dial tcp: connection refused"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, errorText := checkStatus(test.response, test.err, ServerCheck{})
+			if status != test.wantStatus || errorText != test.wantError {
+				t.Errorf("got %v, %q, want %v, %q", status, errorText, test.wantStatus, test.wantError)
+			}
+		})
+	}
+}
+
+func TestPerformCheck(t *testing.T) {
+	var tests = []struct {
+		name           string
+		status         int
+		wasOk          bool
+		failures       int
+		threshold      int
+		wantOk         bool
+		wantDownAlerts int
+	}{
+		{"up server stays up", http.StatusOK, true, 1, 1, true, 0},
+		{"down server alerts at threshold", http.StatusInternalServerError, true, 2, 2, false, 1},
+		{"down server below threshold", http.StatusInternalServerError, true, 1, 2, false, 0},
+		{"alert is repeated each threshold failures", http.StatusInternalServerError, true, 4, 2, false, 2},
+		{"down server recovers", http.StatusOK, false, 1, 1, true, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+			useStorage(t, ServerCheck{Name: "web", Url: server.URL, IsOk: test.wasOk})
+			t.Cleanup(func() { resetFailureCount("web") })
+			var notifier = &testNotifier{}
+
+			for i := 0; i < test.failures; i++ {
+				PerformCheck(context.Background(), Options{AlertThreshold: test.threshold, Notifier: notifier})
+			}
+
+			var serverCheck = ReadChecksData().HealthChecks["web"]
+			if serverCheck.IsOk != test.wantOk {
+				t.Errorf("server is ok: %v, want %v", serverCheck.IsOk, test.wantOk)
+			}
+			if len(serverCheck.History) != test.failures {
+				t.Errorf("got %d results in the history, want one per check", len(serverCheck.History))
+			}
+			if sent := notifier.sent(notify.EventDown); sent != test.wantDownAlerts {
+				t.Errorf("sent %d down alerts, want %d", sent, test.wantDownAlerts)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// Package checkstest provides the storage of servers for tests of packages using checks
+package checkstest
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"path/filepath"
+	"testing"
+)
+
+// UseStorage stores the servers in a new storage in the temporary directory of the test,
+// the previous storage is used again when the test ends
+func UseStorage(t testing.TB, servers ...checks.ServerCheck) {
+	t.Helper()
+	var location = checks.StorageLocation()
+	checks.SetStorageLocation(filepath.Join(t.TempDir(), "data", "checks.json"))
+	t.Cleanup(func() { checks.SetStorageLocation(location) })
+
+	checks.InitStorage()
+	var checksData = checks.Data{HealthChecks: map[string]checks.ServerCheck{}}
+	for _, serverCheck := range servers {
+		checksData.HealthChecks[serverCheck.Name] = serverCheck
+	}
+	if err := checks.SaveChecksData(checksData); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+package checks
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// compressionEncodings are sent in Accept-Encoding by servers with the compression check
+const compressionEncodings = "gzip, br"
+
+// compressionMinSize is the size of compressible responses which are expected to be compressed
+const compressionMinSize = 1024
+
+// checkCompression returns true if the response is compressible, not smaller than compressionMinSize and
+// has no Content-Encoding. The returned body is the decoded body, responses encoded other than gzip are returned as is.
+func checkCompression(resp *http.Response) (uncompressed bool, body io.Reader) {
+	var encoding = strings.ToLower(resp.Header.Get("Content-Encoding"))
+	switch {
+	case encoding == "gzip":
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return false, resp.Body
+		}
+		return false, reader
+	case encoding != "" && encoding != "identity":
+		return false, resp.Body
+	case !compressible(resp.Header.Get("Content-Type")):
+		return false, resp.Body
+	case resp.ContentLength >= 0:
+		return resp.ContentLength >= compressionMinSize, resp.Body
+	}
+
+	// size of chunked responses is counted by reading the body
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, compressionMinSize))
+	return len(head) >= compressionMinSize, io.MultiReader(bytes.NewReader(head), resp.Body)
+}
+
+// compressible returns true for text content types
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "application/xml", "application/wasm":
+		return true
+	}
+	return false
+}
+
+// updateCompressionWarning returns true if the warning about missing compression should be sent for the result,
+// the warning is sent once until the compression is restored
+func updateCompressionWarning(serverCheck *ServerCheck, result CheckResult) bool {
+	if !serverCheck.CompressionCheck || result.Status == StatusFailed {
+		return false
+	}
+
+	var warn = result.Uncompressed && !serverCheck.Uncompressed
+	serverCheck.Uncompressed = result.Uncompressed
+
+	return warn
+}
+
+// SetCompressionCheck switches the compression check of the server
+func SetCompressionCheck(name string, enabled bool) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.CompressionCheck = enabled
+		serverCheck.Uncompressed = false
+	})
+}
@@ -0,0 +1,46 @@
+package checks
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"net/url"
+)
+
+// ExtractCredentials returns the url without user info and the user info escaped as in urls,
+// the url is returned as is if it has no user info
+func ExtractCredentials(serverUrl string) (string, string) {
+	parsedUrl, err := url.Parse(serverUrl)
+	if err != nil || parsedUrl.User == nil {
+		return serverUrl, ""
+	}
+
+	return redact.Url(serverUrl), parsedUrl.User.String()
+}
+
+// basicAuth returns the user and the password of the basic auth credentials of the server
+func (s ServerCheck) basicAuth() (string, string, bool) {
+	if s.BasicAuth == "" {
+		return "", "", false
+	}
+
+	// the user info is parsed as a part of url, so it is unescaped the same way
+	parsedUrl, err := url.Parse("http://" + s.BasicAuth + "@host")
+	if err != nil || parsedUrl.User == nil {
+		return "", "", false
+	}
+
+	password, _ := parsedUrl.User.Password()
+	return parsedUrl.User.Username(), password, true
+}
+
+// moveUrlCredentials moves user info of the url saved before credentials were stored apart to basic auth
+func moveUrlCredentials(serverCheck *ServerCheck) {
+	cleanUrl, userInfo := ExtractCredentials(serverCheck.Url)
+	if userInfo == "" {
+		return
+	}
+
+	serverCheck.Url = cleanUrl
+	if serverCheck.BasicAuth == "" {
+		serverCheck.BasicAuth = userInfo
+	}
+}
@@ -0,0 +1,158 @@
+package checks
+
+import (
+	"strings"
+	"time"
+)
+
+// dailyUptimeDays is the number of days daily uptime is kept for
+const dailyUptimeDays = 90
+
+const dayLayout = "2006-01-02"
+
+// DayUptime is the number of checks of the server in a day of the configured timezone.
+// Partial days were not monitored from the start, e.g. the server was added or resumed during the day.
+// StateChanges is the number of changes from up to down and from down to up.
+type DayUptime struct {
+	Date         string `json:"date"`
+	Checks       int    `json:"checks"`
+	Successes    int    `json:"successes"`
+	StateChanges int    `json:"stateChanges,omitempty"`
+	Partial      bool   `json:"partial,omitempty"`
+}
+
+// Availability returns percentage of not failed checks of the day
+func (d DayUptime) Availability() float64 {
+	if d.Checks == 0 {
+		return 0
+	}
+	return float64(d.Successes) * 100 / float64(d.Checks)
+}
+
+// recordDayUptime counts the check result and the state change in its day, a new day is started lazily on its first check
+func recordDayUptime(serverCheck *ServerCheck, result CheckResult, location *time.Location, stateChanged bool) {
+	var date = result.Time.In(location).Format(dayLayout)
+
+	var days = serverCheck.DailyUptime
+	if len(days) == 0 || days[len(days)-1].Date != date {
+		// a day is monitored from the start only if the previous day has checks
+		var previousDate = result.Time.In(location).AddDate(0, 0, -1).Format(dayLayout)
+		var partial = len(days) == 0 || days[len(days)-1].Date != previousDate
+
+		days = append(days, DayUptime{Date: date, Partial: partial})
+		if len(days) > dailyUptimeDays {
+			days = days[len(days)-dailyUptimeDays:]
+		}
+	}
+
+	var day = &days[len(days)-1]
+	day.Checks++
+	if result.Status != StatusFailed {
+		day.Successes++
+	}
+	if stateChanged {
+		day.StateChanges++
+	}
+	serverCheck.DailyUptime = days
+}
+
+// StateChanges returns the number of changes from up to down and back over count days up to the day of now
+func (s ServerCheck) StateChanges(now time.Time, location *time.Location, count int) int {
+	var changes int
+	for _, day := range s.UptimeDays(now, location, count) {
+		changes += day.StateChanges
+	}
+	return changes
+}
+
+// UptimeDays returns uptime of count days up to the day of now, oldest first. Days without checks have zero checks.
+func (s ServerCheck) UptimeDays(now time.Time, location *time.Location, count int) []DayUptime {
+	var byDate = make(map[string]DayUptime, len(s.DailyUptime))
+	for _, day := range s.DailyUptime {
+		byDate[day.Date] = day
+	}
+
+	var days = make([]DayUptime, count)
+	var today = now.In(location)
+	for i := range days {
+		var date = today.AddDate(0, 0, i-count+1).Format(dayLayout)
+		if day, ok := byDate[date]; ok {
+			days[i] = day
+		} else {
+			days[i] = DayUptime{Date: date}
+		}
+	}
+
+	return days
+}
+
+// DailyAvailability returns percentage of not failed checks of the fully monitored days,
+// ok is false if there are none
+func DailyAvailability(days []DayUptime) (availability float64, ok bool) {
+	var checks, successes int
+	for _, day := range days {
+		if day.Partial {
+			continue
+		}
+		checks += day.Checks
+		successes += day.Successes
+	}
+
+	if checks == 0 {
+		return 0, false
+	}
+	return float64(successes) * 100 / float64(checks), true
+}
+
+// DayUptimeBar renders each day as a colored block: no checks, partial day, all checks successful,
+// at least 99% successful or below
+func DayUptimeBar(days []DayUptime) string {
+	var bar strings.Builder
+	for _, day := range days {
+		bar.WriteString(DayUptimeLevel(day).Block())
+	}
+	return bar.String()
+}
+
+// UptimeLevel groups days by availability for rendering
+type UptimeLevel string
+
+const (
+	UptimeNoData   UptimeLevel = "nodata"
+	UptimePartial  UptimeLevel = "partial"
+	UptimeFull     UptimeLevel = "full"
+	UptimeDegraded UptimeLevel = "degraded"
+	UptimeLow      UptimeLevel = "low"
+)
+
+// DayUptimeLevel returns the level of the day
+func DayUptimeLevel(day DayUptime) UptimeLevel {
+	switch availability := day.Availability(); {
+	case day.Checks == 0:
+		return UptimeNoData
+	case day.Partial:
+		return UptimePartial
+	case day.Successes == day.Checks:
+		return UptimeFull
+	case availability >= 99:
+		return UptimeDegraded
+	default:
+		return UptimeLow
+	}
+}
+
+// Block returns the colored block of the level
+func (l UptimeLevel) Block() string {
+	switch l {
+	case UptimePartial:
+		return "🟦"
+	case UptimeFull:
+		return "🟩"
+	case UptimeDegraded:
+		return "🟨"
+	case UptimeLow:
+		return "🟥"
+	default:
+		return "⬜"
+	}
+}
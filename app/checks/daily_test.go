@@ -0,0 +1,93 @@
+package checks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSetCheckResultCountsStateChanges(t *testing.T) {
+	var ok = CheckResult{Status: StatusOk}
+	var failed = CheckResult{Status: StatusFailed}
+	var simulated = CheckResult{Status: StatusFailed, Simulated: true}
+
+	var tests = []struct {
+		name        string
+		results     []CheckResult
+		wantChanges int
+	}{
+		{"first check is not a change", []CheckResult{failed}, 0},
+		{"same state", []CheckResult{ok, ok, ok}, 0},
+		{"down", []CheckResult{ok, failed, failed}, 1},
+		{"down and up", []CheckResult{ok, failed, ok}, 2},
+		{"flapping", []CheckResult{ok, failed, ok, failed, ok, failed}, 5},
+		{"simulated failure", []CheckResult{ok, simulated, simulated, ok}, 0},
+		{"real failure after simulation", []CheckResult{ok, simulated, failed, ok}, 2},
+	}
+
+	var now = time.Now().UTC()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var serverCheck ServerCheck
+			for i, result := range test.results {
+				result.Time = now.Add(time.Duration(i-len(test.results)) * time.Second)
+				setCheckResult(&serverCheck, result, time.UTC)
+			}
+			if changes := serverCheck.StateChanges(now, time.UTC, 1); changes != test.wantChanges {
+				t.Errorf("got %d state changes, want %d", changes, test.wantChanges)
+			}
+		})
+	}
+}
+
+func TestStateChangesOverDays(t *testing.T) {
+	var now = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	var serverCheck = ServerCheck{DailyUptime: []DayUptime{
+		{Date: "2026-02-01", Checks: 10, StateChanges: 4},
+		{Date: "2026-03-01", Checks: 10, StateChanges: 3},
+		{Date: "2026-03-05", Checks: 10, StateChanges: 2},
+		{Date: "2026-03-10", Checks: 10, StateChanges: 1},
+	}}
+
+	var tests = []struct {
+		days int
+		want int
+	}{
+		{1, 1},
+		{7, 3},
+		{30, 6},
+		{90, 10},
+	}
+	for _, test := range tests {
+		if got := serverCheck.StateChanges(now, time.UTC, test.days); got != test.want {
+			t.Errorf("got %d state changes over %d days, want %d", got, test.days, test.want)
+		}
+	}
+}
+
+func TestStateChangesPersistAcrossRestarts(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+	useStorage(t, ServerCheck{Name: "web", Url: server.URL})
+	t.Cleanup(func() { resetFailureCount("web") })
+
+	var statuses = []int{http.StatusOK, http.StatusInternalServerError, http.StatusOK, http.StatusInternalServerError}
+	for _, code := range statuses {
+		status.Store(int32(code))
+		PerformCheck(context.Background(), Options{AlertThreshold: 1, Notifier: &testNotifier{}})
+		// the next cycle reads the servers from the storage like after a restart
+		cache.loaded = false
+	}
+
+	var serverCheck = ReadChecksData().HealthChecks["web"]
+	if changes := serverCheck.StateChanges(time.Now(), Location, 1); changes != 3 {
+		t.Errorf("got %d state changes after restarts, want 3", changes)
+	}
+}
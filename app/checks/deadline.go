@@ -0,0 +1,73 @@
+package checks
+
+import (
+	"context"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// skipWarningCycles is the number of consecutive cycles of a schedule with skipped servers after which
+// Options.ChecksSkipped is called, it is called again after a cycle checks all servers
+const skipWarningCycles = 3
+
+// checkBudget limits the total time of the checks of a cycle. Time spent between checks, like sending alerts
+// throttled by the rate limit of Telegram, is not counted, so a mass outage doesn't cause skipped checks.
+type checkBudget struct {
+	ctx     context.Context
+	limited bool
+	left    time.Duration
+}
+
+// newCheckBudget returns the budget of the checks of the cycle, checks are not limited if limit is zero
+func newCheckBudget(ctx context.Context, limit time.Duration) *checkBudget {
+	return &checkBudget{ctx: ctx, limited: limit > 0, left: limit}
+}
+
+// spent returns true if the checks of the cycle took the whole budget or ctx is done
+func (b *checkBudget) spent() bool {
+	return b.ctx.Err() != nil || b.limited && b.left <= 0
+}
+
+// run runs the check within the time left and subtracts its time, false if the check was interrupted
+// by the end of the budget or ctx, the result of such check says nothing about the server
+func (b *checkBudget) run(check func(ctx context.Context) CheckResult) (CheckResult, bool) {
+	var ctx, cancel = context.WithCancel(b.ctx)
+	if b.limited {
+		ctx, cancel = context.WithTimeout(b.ctx, b.left)
+	}
+	defer cancel()
+
+	var start = time.Now()
+	var result = check(ctx)
+	if b.limited {
+		b.left -= time.Since(start)
+	}
+
+	return result, result.Status != StatusFailed || ctx.Err() == nil
+}
+
+var skippedCycles = map[string]int{}
+var skippedCyclesMutex sync.Mutex
+
+// recordSkipped records servers of the schedule not checked by the cycle before its deadline
+func recordSkipped(options Options, schedule string, skipped []string) {
+	skippedCyclesMutex.Lock()
+	defer skippedCyclesMutex.Unlock()
+
+	if len(skipped) == 0 {
+		delete(skippedCycles, schedule)
+		return
+	}
+
+	metrics.ChecksSkipped.Add(int64(len(skipped)))
+	log.Printf("[WARN] Check cycle of schedule %s reached the deadline, %d servers skipped: %s",
+		schedule, len(skipped), strings.Join(skipped, ", "))
+
+	skippedCycles[schedule]++
+	if skippedCycles[schedule] == skipWarningCycles && options.ChecksSkipped != nil {
+		options.ChecksSkipped(skipped, skipWarningCycles)
+	}
+}
@@ -0,0 +1,70 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func serversOf(t *testing.T, count int, handler http.HandlerFunc) []ServerCheck {
+	var server = httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	var servers []ServerCheck
+	for i := 0; i < count; i++ {
+		servers = append(servers, ServerCheck{Name: fmt.Sprintf("server%d", i), Url: server.URL, IsOk: true})
+	}
+	return servers
+}
+
+func TestPerformCheckSkipsSlowTargets(t *testing.T) {
+	useStorage(t, serversOf(t, 4, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	})...)
+	var notifier = &testNotifier{}
+
+	var skippedBefore = metrics.ChecksSkipped.Value()
+	var start = time.Now()
+	PerformCheck(context.Background(), Options{AlertThreshold: 1, Notifier: notifier, CycleDeadline: 500 * time.Millisecond})
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cycle took %v, want it to end at the deadline", elapsed)
+	}
+	if skipped := metrics.ChecksSkipped.Value() - skippedBefore; skipped != 3 {
+		t.Errorf("skipped %d servers, want 3", skipped)
+	}
+	for name, serverCheck := range ReadChecksData().HealthChecks {
+		if !serverCheck.IsOk {
+			t.Errorf("server %s is marked down, skipped servers must keep their state", name)
+		}
+	}
+	if sent := notifier.sent(notify.EventDown); sent != 0 {
+		t.Errorf("sent %d down alerts, want none", sent)
+	}
+}
+
+func TestPerformCheckDeadlineExcludesAlerts(t *testing.T) {
+	useStorage(t, serversOf(t, 5, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})...)
+	// each alert takes longer than the whole deadline, like alerts queued by the rate limit
+	var notifier = &testNotifier{delay: 400 * time.Millisecond}
+
+	var skippedBefore = metrics.ChecksSkipped.Value()
+	PerformCheck(context.Background(), Options{AlertThreshold: 1, Notifier: notifier, CycleDeadline: 300 * time.Millisecond})
+
+	if skipped := metrics.ChecksSkipped.Value() - skippedBefore; skipped != 0 {
+		t.Errorf("skipped %d servers, time of alerts must not count toward the deadline", skipped)
+	}
+	if sent := notifier.sent(notify.EventDown); sent != 5 {
+		t.Errorf("sent %d down alerts, want 5", sent)
+	}
+}
@@ -0,0 +1,67 @@
+package checks
+
+import (
+	"time"
+)
+
+// downtimeMonths is the number of months downtime totals are kept for
+const downtimeMonths = 12
+
+// Location is the timezone of monthly downtime buckets, main sets it from the flag, /settimezone overrides it
+var Location = time.Local
+
+// Downtime returns downtime of the server in the month of now and in total, including the ongoing failure
+func (s ServerCheck) Downtime(now time.Time, location *time.Location) (month time.Duration, total time.Duration) {
+	var monthly = make(map[string]time.Duration, len(s.MonthlyDowntime))
+	for key, downtime := range s.MonthlyDowntime {
+		monthly[key] = downtime
+	}
+	total = s.TotalDowntime
+
+	if !s.IsOk && !s.FailingSince.IsZero() {
+		addDowntime(monthly, s.FailingSince, now, location)
+		total += now.Sub(s.FailingSince)
+	}
+
+	return monthly[monthKey(now, location)], total
+}
+
+// recordDowntime adds the span of the failure ended by the successful check to the server downtime.
+// It is called once when the server is up, so restarts during the failure don't count it twice.
+func recordDowntime(serverCheck *ServerCheck, upAt time.Time, location *time.Location) {
+	if serverCheck.FailingSince.IsZero() || !upAt.After(serverCheck.FailingSince) {
+		return
+	}
+
+	if serverCheck.MonthlyDowntime == nil {
+		serverCheck.MonthlyDowntime = make(map[string]time.Duration)
+	}
+	addDowntime(serverCheck.MonthlyDowntime, serverCheck.FailingSince, upAt, location)
+	serverCheck.TotalDowntime += upAt.Sub(serverCheck.FailingSince)
+
+	var oldest = monthKey(upAt.In(location).AddDate(0, -downtimeMonths+1, 0), location)
+	for key := range serverCheck.MonthlyDowntime {
+		if key < oldest {
+			delete(serverCheck.MonthlyDowntime, key)
+		}
+	}
+}
+
+// addDowntime adds the span from start to end to the buckets of calendar months in the location
+func addDowntime(monthly map[string]time.Duration, start time.Time, end time.Time, location *time.Location) {
+	start = start.In(location)
+	for start.Before(end) {
+		var nextMonth = time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, location)
+		var spanEnd = end
+		if nextMonth.Before(end) {
+			spanEnd = nextMonth
+		}
+
+		monthly[monthKey(start, location)] += spanEnd.Sub(start)
+		start = nextMonth
+	}
+}
+
+func monthKey(t time.Time, location *time.Location) string {
+	return t.In(location).Format("2006-01")
+}
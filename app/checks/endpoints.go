@@ -0,0 +1,249 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// MainEndpoint is the label of the server url in endpoint results
+const MainEndpoint = "main"
+
+// MaxEndpoints is the number of endpoints a server can have besides its url
+const MaxEndpoints = 10
+
+// maxLabelLength is the maximum number of characters in an endpoint label
+const maxLabelLength = 32
+
+var ErrEndpointExists = errors.New("endpoint already exists")
+var ErrEndpointNotExists = errors.New("endpoint not exists")
+var ErrEndpointLimit = errors.New("limit of endpoints reached")
+var ErrEndpointLabel = errors.New("invalid endpoint label")
+
+// Endpoint is an additional url checked with the server, like /readyz of a service checked by /healthz
+type Endpoint struct {
+	Label string `json:"label"`
+	Url   string `json:"url"`
+}
+
+// EndpointResult is the last result of the server url or one of its endpoints,
+// Checks and Failures count all checks of the endpoint
+type EndpointResult struct {
+	Label        string        `json:"label"`
+	Status       CheckStatus   `json:"status"`
+	ResponseTime time.Duration `json:"responseTime"`
+	StatusCode   int           `json:"statusCode,omitempty"`
+	Error        string        `json:"error,omitempty"`
+
+	Checks   int `json:"checks"`
+	Failures int `json:"failures,omitempty"`
+}
+
+// Availability returns the share of successful checks of the endpoint in percent
+func (r EndpointResult) Availability() float64 {
+	if r.Checks == 0 {
+		return 0
+	}
+	return float64(r.Checks-r.Failures) / float64(r.Checks) * 100
+}
+
+// EndpointLabel returns the default label of the endpoint url, the last element of its path or its host
+func EndpointLabel(endpointUrl string) string {
+	parsedUrl, err := url.Parse(endpointUrl)
+	if err != nil {
+		return ""
+	}
+	if label := path.Base(strings.TrimSuffix(parsedUrl.Path, "/")); label != "." && label != "/" {
+		return label
+	}
+	return parsedUrl.Hostname()
+}
+
+// validateLabel checks the label of a new endpoint, labels are like names of servers without spaces
+func validateLabel(label string) error {
+	if label == "" || label == MainEndpoint || len([]rune(label)) > maxLabelLength {
+		return fmt.Errorf("%w, it must be up to %d characters and not %q", ErrEndpointLabel, maxLabelLength, MainEndpoint)
+	}
+	for _, r := range label {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.", r) {
+			return fmt.Errorf("%w, %q is not a letter, digit, dash, underscore or dot", ErrEndpointLabel, r)
+		}
+	}
+	return nil
+}
+
+// AddEndpoint adds the endpoint to the server, returns ErrEndpointExists if the server has an endpoint with the label
+// and ErrEndpointLimit if it has MaxEndpoints endpoints
+func AddEndpoint(name string, endpoint Endpoint) error {
+	if err := validateLabel(endpoint.Label); err != nil {
+		return err
+	}
+
+	var exists, limited bool
+	err := UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		if exists = slices.ContainsFunc(serverCheck.Endpoints, func(e Endpoint) bool { return e.Label == endpoint.Label }); exists {
+			return
+		}
+		if limited = len(serverCheck.Endpoints) >= MaxEndpoints; limited {
+			return
+		}
+		serverCheck.Endpoints = append(serverCheck.Endpoints, endpoint)
+		if serverCheck.EndpointFailMode == "" {
+			serverCheck.EndpointFailMode = IpFailAny
+		}
+	})
+	switch {
+	case err != nil:
+		return err
+	case exists:
+		return ErrEndpointExists
+	case limited:
+		return ErrEndpointLimit
+	}
+	return nil
+}
+
+// RemoveEndpoint removes the endpoint of the server with its stats, all endpoints if label is empty
+func RemoveEndpoint(name string, label string) error {
+	var found bool
+	err := UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		var count = len(serverCheck.Endpoints)
+		serverCheck.Endpoints = slices.DeleteFunc(serverCheck.Endpoints, func(e Endpoint) bool {
+			return label == "" || e.Label == label
+		})
+		found = len(serverCheck.Endpoints) < count
+		serverCheck.EndpointResults = slices.DeleteFunc(serverCheck.EndpointResults, func(r EndpointResult) bool {
+			return label == "" || r.Label == label
+		})
+
+		if len(serverCheck.Endpoints) == 0 {
+			serverCheck.Endpoints = nil
+			serverCheck.EndpointFailMode = ""
+			serverCheck.EndpointResults = nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrEndpointNotExists
+	}
+	return nil
+}
+
+// SetEndpointFailMode sets whether the server is down if any of its endpoints fails or only if all of them fail
+func SetEndpointFailMode(name string, mode IpFailMode) error {
+	var found bool
+	err := UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		if found = len(serverCheck.Endpoints) > 0; found {
+			serverCheck.EndpointFailMode = mode
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrEndpointNotExists
+	}
+	return nil
+}
+
+// checkEndpoints checks the server url and its endpoints concurrently, the result is aggregated by the fail mode.
+// Protocol, certificate and header results are of the server url.
+func checkEndpoints(ctx context.Context, serverCheck ServerCheck) CheckResult {
+	var results = make([]CheckResult, len(serverCheck.Endpoints)+1)
+	var wg sync.WaitGroup
+	wg.Add(len(results))
+	go func() {
+		defer wg.Done()
+		results[0] = checkUrl(ctx, serverCheck)
+	}()
+	for i, endpoint := range serverCheck.Endpoints {
+		// JSON checks and address checks are set for the server url
+		var endpointCheck = serverCheck
+		endpointCheck.Url, endpointCheck.JsonChecks, endpointCheck.CheckAllIps = endpoint.Url, nil, false
+		endpointCheck.ExpectedIps, endpointCheck.ExpectedIpAction = nil, ""
+		go func(i int) {
+			defer wg.Done()
+			results[i+1] = checkUrl(ctx, endpointCheck)
+		}(i)
+	}
+	wg.Wait()
+
+	var labels = []string{MainEndpoint}
+	for _, endpoint := range serverCheck.Endpoints {
+		labels = append(labels, endpoint.Label)
+	}
+	return aggregateEndpointResults(results, labels, serverCheck.EndpointFailMode)
+}
+
+// aggregateEndpointResults returns the check result of the server from results of its url and endpoints,
+// response time is the slowest one. The error lists failed and successful endpoints if any of them failed.
+func aggregateEndpointResults(results []CheckResult, labels []string, mode IpFailMode) CheckResult {
+	var result = results[0]
+
+	// failed endpoints are listed first, like "readyz failed with 503, main OK"
+	var failures, successes []string
+	var failedCode int
+	for i, endpointResult := range results {
+		result.ResponseTime = max(result.ResponseTime, endpointResult.ResponseTime)
+		result.Endpoints = append(result.Endpoints, EndpointResult{Label: labels[i], Status: endpointResult.Status,
+			ResponseTime: endpointResult.ResponseTime, StatusCode: endpointResult.StatusCode, Error: endpointResult.Error})
+
+		switch {
+		case endpointResult.Status != StatusFailed:
+			successes = append(successes, labels[i]+" OK")
+			continue
+		case endpointResult.Error == fmt.Sprintf("status code %d", endpointResult.StatusCode):
+			failures = append(failures, fmt.Sprintf("%s failed with %d", labels[i], endpointResult.StatusCode))
+		default:
+			failures = append(failures, fmt.Sprintf("%s failed: %s", labels[i], endpointResult.Error))
+		}
+		if failedCode == 0 {
+			failedCode = endpointResult.StatusCode
+		}
+	}
+	if len(failures) == 0 {
+		return result
+	}
+
+	result.Error = strings.Join(append(failures, successes...), ", ")
+	result.Category = ""
+	result.StatusCode = failedCode
+	switch {
+	case mode == IpFailAll && len(failures) < len(results):
+		result.Status = StatusDegraded
+	default:
+		result.Status = StatusFailed
+	}
+
+	return result
+}
+
+// recordEndpointResults replaces last endpoint results of the server and counts their checks
+func recordEndpointResults(serverCheck *ServerCheck, results []EndpointResult) {
+	if results == nil {
+		serverCheck.EndpointResults = nil
+		return
+	}
+
+	var recorded = make([]EndpointResult, 0, len(results))
+	for _, result := range results {
+		if i := slices.IndexFunc(serverCheck.EndpointResults, func(r EndpointResult) bool { return r.Label == result.Label }); i != -1 {
+			result.Checks, result.Failures = serverCheck.EndpointResults[i].Checks, serverCheck.EndpointResults[i].Failures
+		}
+		result.Checks++
+		if result.Status == StatusFailed {
+			result.Failures++
+		}
+		recorded = append(recorded, result)
+	}
+	serverCheck.EndpointResults = recorded
+}
@@ -0,0 +1,41 @@
+package checks
+
+import (
+	"slices"
+	"time"
+)
+
+// maxRecentErrors is the number of distinct errors kept per server
+const maxRecentErrors = 5
+
+// RecentError is a distinct error of failed checks of the server with the number of checks failed with it
+type RecentError struct {
+	Message    string    `json:"message"`
+	Category   string    `json:"category,omitempty"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	FirstSeen  time.Time `json:"firstSeen"`
+	LastSeen   time.Time `json:"lastSeen"`
+	Count      int       `json:"count"`
+}
+
+// recordRecentError counts the error of the failed check, a new error replaces the least recently seen one
+// when there are maxRecentErrors of them. Errors are kept most recently seen first.
+func recordRecentError(serverCheck *ServerCheck, result CheckResult) {
+	if result.Status != StatusFailed || result.Error == "" {
+		return
+	}
+
+	var recent = RecentError{Message: result.Error, FirstSeen: result.Time}
+	var kept = serverCheck.RecentErrors
+	if i := slices.IndexFunc(kept, func(e RecentError) bool { return e.Message == result.Error }); i != -1 {
+		recent = kept[i]
+		kept = slices.Delete(slices.Clone(kept), i, i+1)
+	} else if len(kept) >= maxRecentErrors {
+		kept = kept[:maxRecentErrors-1]
+	}
+
+	recent.Category, recent.StatusCode = result.Category, result.StatusCode
+	recent.LastSeen = result.Time
+	recent.Count++
+	serverCheck.RecentErrors = append([]RecentError{recent}, kept...)
+}
@@ -0,0 +1,66 @@
+package checks
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"log"
+	"time"
+)
+
+// escalateIncident sends the incident to the escalation chat once,
+// if it is not acknowledged within options.EscalationAfter since the alert
+func escalateIncident(options Options, serverCheck ServerCheck, incident Incident) {
+	var now = time.Now()
+	if incident.AckBy != "" || !incident.EscalatedAt.IsZero() || now.Sub(incident.AlertedAt) < options.EscalationAfter {
+		return
+	}
+
+	var escalate bool
+	err := UpdateServerCheck(serverCheck.Name, func(storedCheck *ServerCheck) {
+		// incident could be acknowledged while the check was running
+		if storedCheck.Incident == nil || storedCheck.Incident.AckBy != "" || !storedCheck.Incident.EscalatedAt.IsZero() {
+			return
+		}
+
+		storedCheck.Incident.EscalatedAt = now
+		escalate = true
+	})
+	if err != nil {
+		log.Printf("[ERROR] Error while saving checks data: %v", err)
+		return
+	}
+	if !escalate {
+		return
+	}
+
+	log.Printf("[INFO] Incident of server %s escalated", serverCheck.Url)
+	err = options.EscalationNotifier.Send(notify.Event{
+		Type:      notify.EventEscalated,
+		Server:    serverCheck.Name,
+		Url:       redact.Url(serverCheck.Url),
+		Time:      now,
+		Since:     incident.Start,
+		Duration:  now.Sub(incident.Start),
+		Simulated: incident.Simulated,
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to send escalation message: %v", err)
+	}
+}
+
+// sendEscalationResolved posts closing note of the escalated incident to the escalation chat
+func sendEscalationResolved(notifier notify.Notifier, serverCheck ServerCheck, incident Incident) {
+	var now = time.Now()
+	err := notifier.Send(notify.Event{
+		Type:      notify.EventEscalationResolved,
+		Server:    serverCheck.Name,
+		Url:       redact.Url(serverCheck.Url),
+		Time:      now,
+		Since:     incident.Start,
+		Duration:  now.Sub(incident.Start),
+		Simulated: incident.Simulated,
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to send escalation message: %v", err)
+	}
+}
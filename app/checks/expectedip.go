@@ -0,0 +1,128 @@
+package checks
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// ExpectedIpAction is what happens when the server is connected on an address outside its expected ones
+type ExpectedIpAction string
+
+const (
+	// ExpectedIpFail fails the check
+	ExpectedIpFail ExpectedIpAction = "fail"
+	// ExpectedIpWarn sends a warning once for each unexpected address
+	ExpectedIpWarn ExpectedIpAction = "warn"
+)
+
+// ParseExpectedIps parses comma separated IPv4 and IPv6 addresses and CIDRs,
+// returns them as CIDRs with single addresses as /32 or /128
+func ParseExpectedIps(value string) ([]string, error) {
+	var prefixes []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		prefix, err := parseExpectedIp(part)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.String())
+	}
+	if len(prefixes) == 0 {
+		return nil, fmt.Errorf("no addresses")
+	}
+
+	return prefixes, nil
+}
+
+func parseExpectedIp(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR: %s", value)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid address: %s", value)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// ipExpected returns true if the address is in one of the CIDRs, IPv4-mapped IPv6 addresses match IPv4 CIDRs
+func ipExpected(ip string, expected []string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, value := range expected {
+		if prefix, err := netip.ParsePrefix(value); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteIpExpected returns true if the server was last connected on an expected address or no addresses are expected
+func (s ServerCheck) RemoteIpExpected() bool {
+	return len(s.ExpectedIps) == 0 || s.RemoteIp == "" || ipExpected(s.RemoteIp, s.ExpectedIps)
+}
+
+// remoteIp returns the address of the remote end of the connection without the port and zone
+func remoteIp(conn net.Conn) string {
+	addrPort, err := netip.ParseAddrPort(conn.RemoteAddr().String())
+	if err != nil {
+		return ""
+	}
+	return addrPort.Addr().Unmap().WithZone("").String()
+}
+
+// expectedIpError returns the error of the check if the server was connected on an unexpected address
+// and the server fails on it, empty otherwise
+func expectedIpError(serverCheck ServerCheck, ip string) string {
+	if serverCheck.ExpectedIpAction != ExpectedIpFail || ip == "" || ipExpected(ip, serverCheck.ExpectedIps) {
+		return ""
+	}
+	return fmt.Sprintf("connected to %s, expected %s", ip, strings.Join(serverCheck.ExpectedIps, ", "))
+}
+
+// updateExpectedIpWarning returns true if the warning about the address of the result should be sent,
+// the warning is sent once for each unexpected address until an expected one is connected again
+func updateExpectedIpWarning(serverCheck *ServerCheck, result CheckResult) bool {
+	if serverCheck.ExpectedIpAction != ExpectedIpWarn || result.RemoteIp == "" {
+		return false
+	}
+	if ipExpected(result.RemoteIp, serverCheck.ExpectedIps) {
+		serverCheck.UnexpectedIp = ""
+		return false
+	}
+	if result.RemoteIp == serverCheck.UnexpectedIp {
+		return false
+	}
+
+	serverCheck.UnexpectedIp = result.RemoteIp
+	return true
+}
+
+// SetExpectedIps sets the CIDRs the server must be connected on and whether the check fails or warns outside them,
+// empty expected disables the check
+func SetExpectedIps(name string, expected []string, action ExpectedIpAction) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.ExpectedIps = expected
+		serverCheck.ExpectedIpAction = action
+		serverCheck.UnexpectedIp = ""
+		if len(expected) == 0 {
+			serverCheck.ExpectedIpAction = ""
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package checks
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"html"
+	"net"
+	"regexp"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+)
+
+// Categories of request errors shown in down alerts
+const (
+	CategoryTimeout = "timeout"
+	CategoryDns     = "dns"
+	CategoryRefused = "refused"
+	CategoryTls     = "tls"
+	CategoryBanner  = "banner"
+)
+
+// BodyExcerpt adds excerpts of response bodies of failed checks to down alerts, main sets it from the flag
+var BodyExcerpt bool
+
+// excerptSize is the number of characters of the body excerpt, excerptBodySize is the number of body bytes read
+// of responses with an error status code
+const excerptSize = 200
+const excerptBodySize = 64 * 1024
+
+// errorCategory returns the category of the request error, empty if the error is not categorized
+func errorCategory(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &dnsErr):
+		return CategoryDns
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return CategoryTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return CategoryRefused
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &unknownAuthority),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return CategoryTls
+	case errors.Is(err, errMalformedBanner):
+		return CategoryBanner
+	}
+	return ""
+}
+
+// scriptPattern matches scripts and styles of html bodies, tagPattern matches tags
+var scriptPattern = regexp.MustCompile(`(?is)<(script|style)\b.*?</(script|style)>`)
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// bodyExcerpt returns the beginning of the body as one line of text without html tags and secrets
+func bodyExcerpt(body []byte) string {
+	var text = strings.ToValidUTF8(string(body), "")
+	text = scriptPattern.ReplaceAllString(text, " ")
+	text = tagPattern.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	text = strings.Join(strings.FieldsFunc(text, isExcerptSpace), " ")
+	text = redact.Text(text)
+
+	if utf8.RuneCountInString(text) <= excerptSize {
+		return text
+	}
+	return string([]rune(text)[:excerptSize]) + "…"
+}
+
+// isExcerptSpace reports whitespace and control characters, they are collapsed to a space in excerpts
+func isExcerptSpace(r rune) bool {
+	return r <= ' ' || r == 0x7f
+}
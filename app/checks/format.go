@@ -0,0 +1,47 @@
+package checks
+
+import (
+	"fmt"
+	"time"
+)
+
+// FormatTimeAgo formats time relative to now, like "1d 4h ago" or "3m 12s ago"
+func FormatTimeAgo(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	return FormatDuration(time.Since(t)) + " ago"
+}
+
+// FormatTime formats time in the location, the date is omitted for today, like "14:02 CET"
+func FormatTime(t time.Time, location *time.Location) string {
+	t = t.In(location)
+
+	var now = time.Now().In(location)
+	if t.Year() == now.Year() && t.YearDay() == now.YearDay() {
+		return t.Format("15:04 MST")
+	}
+	return t.Format("2006-01-02 15:04 MST")
+}
+
+// FormatDuration formats duration with two most significant units, like "1d 4h" or "3m 12s"
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+
+	var days = int(d.Hours()) / 24
+	var hours = int(d.Hours()) % 24
+	var minutes = int(d.Minutes()) % 60
+	var seconds = int(d.Seconds()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
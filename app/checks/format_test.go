@@ -0,0 +1,70 @@
+package checks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTimeAgo(t *testing.T) {
+	var tests = []struct {
+		name string
+		ago  time.Duration
+		want string
+	}{
+		{"seconds", 59 * time.Second, "59s ago"},
+		{"just over a minute", 61 * time.Second, "1m 1s ago"},
+		{"minutes", 3*time.Minute + 12*time.Second, "3m 12s ago"},
+		{"just under a day", 23*time.Hour + 59*time.Minute, "23h 59m ago"},
+		{"just over a day", 25 * time.Hour, "1d 1h ago"},
+		{"multiple days", 3*24*time.Hour + 4*time.Hour + 30*time.Minute, "3d 4h ago"},
+		{"weeks", 15 * 24 * time.Hour, "15d 0h ago"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := FormatTimeAgo(time.Now().Add(-test.ago)); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatTimeAgoNever(t *testing.T) {
+	if got := FormatTimeAgo(time.Time{}); got != "never" {
+		t.Errorf("got %q, want never", got)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	var tests = []struct {
+		duration time.Duration
+		want     string
+	}{
+		{0, "0s"},
+		{1499 * time.Millisecond, "1s"},
+		{59*time.Second + 600*time.Millisecond, "1m 0s"},
+		{time.Hour, "1h 0m"},
+		{24 * time.Hour, "1d 0h"},
+	}
+
+	for _, test := range tests {
+		if got := FormatDuration(test.duration); got != test.want {
+			t.Errorf("%v: got %q, want %q", test.duration, got, test.want)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	var location = time.FixedZone("CET", 3600)
+
+	var today = time.Now().In(location)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, location)
+	if got := FormatTime(today, location); got != "00:00 CET" {
+		t.Errorf("got %q for today, want the time only", got)
+	}
+
+	var past = time.Date(2024, 3, 1, 14, 2, 0, 0, time.UTC)
+	if got := FormatTime(past, location); got != "2024-03-01 15:02 CET" {
+		t.Errorf("got %q, want the date in the location", got)
+	}
+}
@@ -0,0 +1,14 @@
+package checks
+
+import (
+	"time"
+)
+
+// NewServerGrace is the grace period of added servers, main sets it from the flag
+var NewServerGrace time.Duration
+
+// InGrace returns true if the server was added recently and has not succeeded yet, down alerts are not sent
+// and failures are not counted toward the alert threshold until the grace period ends
+func (s ServerCheck) InGrace(now time.Time) bool {
+	return s.GraceUntil.After(now)
+}
@@ -0,0 +1,58 @@
+package checks
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// useStorage stores the servers in a new storage in the temporary directory of the test,
+// the previous storage is used again when the test ends
+func useStorage(t testing.TB, servers ...ServerCheck) {
+	t.Helper()
+	var location = StorageLocation()
+	SetStorageLocation(filepath.Join(t.TempDir(), "data", "checks.json"))
+	t.Cleanup(func() { SetStorageLocation(location) })
+
+	InitStorage()
+	var checksData = Data{HealthChecks: map[string]ServerCheck{}}
+	for _, serverCheck := range servers {
+		checksData.HealthChecks[serverCheck.Name] = serverCheck
+	}
+	if err := SaveChecksData(checksData); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testNotifier records sent events, each send takes delay like a send throttled by the rate limit
+type testNotifier struct {
+	delay  time.Duration
+	mutex  sync.Mutex
+	events []notify.Event
+}
+
+func (n *testNotifier) Send(event notify.Event) error {
+	time.Sleep(n.delay)
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.events = append(n.events, event)
+	return nil
+}
+
+func (n *testNotifier) Name() string {
+	return "test"
+}
+
+func (n *testNotifier) sent(eventType notify.EventType) int {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	var count int
+	for _, event := range n.events {
+		if event.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
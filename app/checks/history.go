@@ -0,0 +1,128 @@
+package checks
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+type CheckStatus string
+
+const (
+	StatusOk       CheckStatus = "ok"
+	StatusFailed   CheckStatus = "failed"
+	StatusDegraded CheckStatus = "degraded"
+)
+
+// CheckResult is a result of a single server check
+type CheckResult struct {
+	Time         time.Time     `json:"time"`
+	Status       CheckStatus   `json:"status"`
+	ResponseTime time.Duration `json:"responseTime"`
+	StatusCode   int           `json:"statusCode,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	// Proto is the negotiated protocol like HTTP/2.0
+	Proto string `json:"proto,omitempty"`
+	// Attempts is the number of requests of the check if it was retried, the check is counted once
+	Attempts int `json:"attempts,omitempty"`
+	// Simulated results are not stored in the history
+	Simulated bool `json:"-"`
+	// Category of the request error and Excerpt of the response body are shown in the down alert
+	Category string `json:"-"`
+	Excerpt  string `json:"-"`
+
+	// SslExpiry, SslIssuer, SshBanner, Spki, RemoteIp, Uncompressed, SecurityHeaders, Ips and Endpoints
+	// are stored on the server check, not in the history
+	SslExpiry       time.Time        `json:"-"`
+	SslIssuer       string           `json:"-"`
+	SshBanner       string           `json:"-"`
+	Spki            string           `json:"-"`
+	RemoteIp        string           `json:"-"`
+	Uncompressed    bool             `json:"-"`
+	SecurityHeaders map[string]bool  `json:"-"`
+	Ips             []IpResult       `json:"-"`
+	Endpoints       []EndpointResult `json:"-"`
+}
+
+// appendHistory appends the result, history is trimmed to the retention at the end of the check cycle.
+// Maps and slices of the result are dropped, they are stored on the server check and would be shared with it.
+func appendHistory(history []CheckResult, result CheckResult) []CheckResult {
+	result.SecurityHeaders, result.Ips, result.Endpoints = nil, nil, nil
+	return append(history, result)
+}
+
+// UptimeBar renders the last width check results as colored blocks, oldest first.
+// Histories shorter than width are padded with empty blocks on the left.
+func UptimeBar(history []CheckResult, width int) string {
+	if len(history) > width {
+		history = history[len(history)-width:]
+	}
+
+	var bar strings.Builder
+	bar.WriteString(strings.Repeat("⬜", width-len(history)))
+	for _, result := range history {
+		switch result.Status {
+		case StatusOk:
+			bar.WriteString("🟩")
+		case StatusDegraded:
+			bar.WriteString("🟨")
+		default:
+			bar.WriteString("🟥")
+		}
+	}
+
+	return bar.String()
+}
+
+// ResponseTimeStats returns min, average and 95th percentile response time of successful checks,
+// count is the number of successful checks
+func ResponseTimeStats(history []CheckResult) (min, avg, p95 time.Duration, count int) {
+	var responseTimes []time.Duration
+	var total time.Duration
+	for _, result := range history {
+		if result.Status == StatusFailed {
+			continue
+		}
+		responseTimes = append(responseTimes, result.ResponseTime)
+		total += result.ResponseTime
+	}
+
+	count = len(responseTimes)
+	if count == 0 {
+		return 0, 0, 0, 0
+	}
+
+	sort.Slice(responseTimes, func(i, j int) bool { return responseTimes[i] < responseTimes[j] })
+
+	return responseTimes[0], total / time.Duration(count), responseTimes[(count*95-1)/100], count
+}
+
+// HistorySince returns check results made since the time
+func HistorySince(history []CheckResult, since time.Time) []CheckResult {
+	var i = sort.Search(len(history), func(i int) bool { return !history[i].Time.Before(since) })
+	return history[i:]
+}
+
+// Availability returns percentage of not failed checks in the history, ok is false for empty history
+func Availability(history []CheckResult) (availability float64, ok bool) {
+	if len(history) == 0 {
+		return 0, false
+	}
+
+	var successful int
+	for _, result := range history {
+		if result.Status != StatusFailed {
+			successful++
+		}
+	}
+
+	return float64(successful) * 100 / float64(len(history)), true
+}
+
+// LastResult returns the most recent check result of the server
+func (s ServerCheck) LastResult() (CheckResult, bool) {
+	if len(s.History) == 0 {
+		return CheckResult{}, false
+	}
+	return s.History[len(s.History)-1], true
+}
@@ -0,0 +1,38 @@
+package checks
+
+import (
+	"testing"
+)
+
+// historyOf returns check results with the statuses, oldest first
+func historyOf(statuses ...CheckStatus) []CheckResult {
+	var history []CheckResult
+	for _, status := range statuses {
+		history = append(history, CheckResult{Status: status})
+	}
+	return history
+}
+
+func TestUptimeBar(t *testing.T) {
+	var tests = []struct {
+		name    string
+		history []CheckResult
+		width   int
+		want    string
+	}{
+		{"empty history", nil, 5, "⬜⬜⬜⬜⬜"},
+		{"shorter than width", historyOf(StatusOk, StatusFailed), 5, "⬜⬜⬜🟩🟥"},
+		{"mix of states", historyOf(StatusOk, StatusFailed, StatusDegraded, StatusOk), 4, "🟩🟥🟨🟩"},
+		{"longer than width keeps the last checks",
+			historyOf(StatusFailed, StatusFailed, StatusOk, StatusDegraded, StatusOk), 3, "🟩🟨🟩"},
+		{"all up", historyOf(StatusOk, StatusOk, StatusOk), 3, "🟩🟩🟩"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := UptimeBar(test.history, test.width); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
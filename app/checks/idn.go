@@ -0,0 +1,45 @@
+package checks
+
+import (
+	"golang.org/x/net/idna"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// unicodeHost returns the url with the internationalized host in the Unicode form shown to users,
+// the url is returned as is if it can't be parsed
+func unicodeHost(serverUrl string) string {
+	return convertHost(serverUrl, idna.Display.ToUnicode)
+}
+
+// asciiUrl returns the url with the internationalized host in the punycode form used in requests, DNS lookups
+// and certificate verification
+func asciiUrl(serverUrl string) string {
+	return convertHost(serverUrl, idna.Lookup.ToASCII)
+}
+
+// convertHost replaces the host in the url as entered, url.URL.String would escape non-ASCII hosts
+func convertHost(serverUrl string, convert func(string) (string, error)) string {
+	parsedUrl, err := url.Parse(serverUrl)
+	if err != nil || parsedUrl.Host == "" || net.ParseIP(parsedUrl.Hostname()) != nil {
+		return serverUrl
+	}
+
+	host, err := convert(parsedUrl.Hostname())
+	if err != nil || host == parsedUrl.Hostname() {
+		return serverUrl
+	}
+
+	// the host follows the user info in the authority, which ends with the path, the query or the fragment
+	var start = strings.Index(serverUrl, "//") + len("//")
+	var end = len(serverUrl)
+	if i := strings.IndexAny(serverUrl[start:], "/?#"); i != -1 {
+		end = start + i
+	}
+	if i := strings.LastIndex(serverUrl[start:end], "@"); i != -1 {
+		start += i + 1
+	}
+
+	return serverUrl[:start] + strings.Replace(serverUrl[start:end], parsedUrl.Hostname(), host, 1) + serverUrl[end:]
+}
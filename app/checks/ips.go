@@ -0,0 +1,198 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IpFailMode is the state of a server checked on each resolved address when some of the addresses fail
+type IpFailMode string
+
+const (
+	// IpFailAny marks the server down if any address fails
+	IpFailAny IpFailMode = "any"
+	// IpFailAll marks the server down only if all addresses fail, otherwise it is degraded
+	IpFailAll IpFailMode = "all"
+)
+
+const resolveTimeout = 10 * time.Second
+
+// IpResult is the result of the check of a single resolved address of the server
+type IpResult struct {
+	Ip           string        `json:"ip"`
+	Status       CheckStatus   `json:"status"`
+	ResponseTime time.Duration `json:"responseTime"`
+	StatusCode   int           `json:"statusCode,omitempty"`
+	Error        string        `json:"error,omitempty"`
+
+	proto        string
+	sslExpiry    time.Time
+	spki         string
+	remoteIp     string
+	attempts     int
+	uncompressed bool
+
+	securityHeaders map[string]bool
+}
+
+// checkAllIps resolves the host of the server and checks each A and AAAA record, the result is aggregated
+// by the fail mode of the server. Addresses are resolved on each check, so changed DNS answers are picked up.
+func checkAllIps(ctx context.Context, serverCheck ServerCheck) CheckResult {
+	var start = time.Now()
+	var failed = func(err error) CheckResult {
+		return CheckResult{Time: start, ResponseTime: time.Since(start), Status: StatusFailed, Error: err.Error()}
+	}
+
+	parsedUrl, err := url.Parse(asciiUrl(serverCheck.Url))
+	if err != nil {
+		return failed(err)
+	}
+
+	resolveCtx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(resolveCtx, parsedUrl.Hostname())
+	if err != nil {
+		return failed(fmt.Errorf("resolve %s: %w", parsedUrl.Hostname(), err))
+	}
+	if len(addrs) == 0 {
+		return failed(fmt.Errorf("no addresses of %s", parsedUrl.Hostname()))
+	}
+
+	var ipResults = make([]IpResult, len(addrs))
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		wg.Add(1)
+		go func(i int, ip net.IP) {
+			defer wg.Done()
+			ipResults[i] = checkIp(ctx, serverCheck, parsedUrl, ip)
+		}(i, addr.IP)
+	}
+	wg.Wait()
+
+	sortIpResults(ipResults)
+
+	var result = aggregateIpResults(start, ipResults, serverCheck.IpFailMode)
+	// a public key not matching the pin on any address is reported
+	for _, ipResult := range ipResults {
+		if serverCheck.PinnedSpki != "" && ipResult.spki != "" && ipResult.spki != serverCheck.PinnedSpki {
+			result.Spki = ipResult.spki
+			break
+		}
+	}
+	// an unexpected address is reported, otherwise the first one
+	for _, ipResult := range ipResults {
+		if len(serverCheck.ExpectedIps) > 0 && ipResult.remoteIp != "" && !ipExpected(ipResult.remoteIp, serverCheck.ExpectedIps) {
+			result.RemoteIp = ipResult.remoteIp
+			break
+		}
+		if result.RemoteIp == "" {
+			result.RemoteIp = ipResult.remoteIp
+		}
+	}
+
+	return result
+}
+
+// checkIp requests the server url on the ip, Host header and TLS server name stay the host of the url
+func checkIp(ctx context.Context, serverCheck ServerCheck, parsedUrl *url.URL, ip net.IP) IpResult {
+	var port = parsedUrl.Port()
+	if port == "" {
+		port = "80"
+		if parsedUrl.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	var transport = checkTransport.Clone()
+	defer transport.CloseIdleConnections()
+
+	var dialer net.Dialer
+	var hostPort = net.JoinHostPort(parsedUrl.Hostname(), port)
+	transport.DialContext = func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		// redirects to other hosts are dialed as usual
+		if addr == hostPort {
+			addr = net.JoinHostPort(ip.String(), port)
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	response, responseTime, err := requestWithRetries(ctx, &http.Client{Transport: transport}, serverCheck)
+	var result = IpResult{Ip: ip.String(), ResponseTime: responseTime, StatusCode: response.statusCode,
+		proto: response.proto, sslExpiry: response.sslExpiry, uncompressed: response.uncompressed,
+		securityHeaders: response.securityHeaders, spki: response.spki, remoteIp: response.remoteIp,
+		attempts: response.attempts}
+	result.Status, result.Error = checkStatus(response, err, serverCheck)
+
+	return result
+}
+
+// sortIpResults orders results by address, so 10.0.0.9 comes before 10.0.0.10 and IPv4 before IPv6
+func sortIpResults(ipResults []IpResult) {
+	slices.SortFunc(ipResults, func(a, b IpResult) int {
+		return netip.MustParseAddr(a.Ip).Compare(netip.MustParseAddr(b.Ip))
+	})
+}
+
+// aggregateIpResults returns the check result of the server from results of its addresses,
+// response time and attempts are the largest ones and protocol is the lowest one, it is uncompressed if any address is
+// and has a security header if all addresses have it
+func aggregateIpResults(start time.Time, ipResults []IpResult, mode IpFailMode) CheckResult {
+	var result = CheckResult{Time: start, Status: StatusOk, Ips: ipResults}
+
+	var failures []string
+	for _, ipResult := range ipResults {
+		result.ResponseTime = max(result.ResponseTime, ipResult.ResponseTime)
+		if result.SslExpiry.IsZero() {
+			result.SslExpiry, result.Spki = ipResult.sslExpiry, ipResult.spki
+		}
+		result.Uncompressed = result.Uncompressed || ipResult.uncompressed
+		result.Attempts = max(result.Attempts, ipResult.attempts)
+		result.SecurityHeaders = mergeSecurityHeaders(result.SecurityHeaders, ipResult.securityHeaders)
+		if ipResult.proto != "" && (result.Proto == "" || protoLess(ipResult.proto, result.Proto)) {
+			result.Proto = ipResult.proto
+		}
+		if ipResult.Status == StatusFailed {
+			failures = append(failures, ipResult.Ip+": "+ipResult.Error)
+			continue
+		}
+		if result.StatusCode == 0 {
+			result.StatusCode = ipResult.StatusCode
+		}
+	}
+	if len(failures) == 0 {
+		return result
+	}
+
+	result.Error = fmt.Sprintf("%d of %d addresses failed: %s", len(failures), len(ipResults), strings.Join(failures, "; "))
+	switch {
+	case mode == IpFailAll && len(failures) < len(ipResults):
+		result.Status = StatusDegraded
+	default:
+		result.Status = StatusFailed
+		if result.StatusCode == 0 {
+			result.StatusCode = ipResults[0].StatusCode
+		}
+	}
+
+	return result
+}
+
+// SetCheckAllIps switches checking of each resolved address of the server, mode is ignored when disabled
+func SetCheckAllIps(name string, enabled bool, mode IpFailMode) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.CheckAllIps = enabled
+		serverCheck.IpFailMode = mode
+		if !enabled {
+			serverCheck.IpFailMode = ""
+			serverCheck.IpResults = nil
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package checks
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCheckIp(t *testing.T) {
+	var host, serverName string
+	var server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, serverName = r.Host, r.TLS.ServerName
+	}))
+	defer server.Close()
+
+	// the test certificate is issued for example.com, which is dialed on the address of the test server
+	var tlsConfig = checkTransport.TLSClientConfig
+	checkTransport.TLSClientConfig = &tls.Config{RootCAs: server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+	t.Cleanup(func() { checkTransport.TLSClientConfig = tlsConfig })
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	var serverCheck = ServerCheck{Name: "web", Url: "https://example.com:" + port + "/"}
+	parsedUrl, err := url.Parse(serverCheck.Url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result = checkIp(context.Background(), serverCheck, parsedUrl, net.ParseIP("127.0.0.1"))
+	if result.Status != StatusOk || result.Ip != "127.0.0.1" || result.remoteIp != "127.0.0.1" {
+		t.Fatalf("got result %+v, want ok on 127.0.0.1", result)
+	}
+	if host != "example.com:"+port || serverName != "example.com" {
+		t.Errorf("got Host %q and server name %q, want the host of the url", host, serverName)
+	}
+
+	// nothing listens on the other loopback address, so the dial goes there instead of the host
+	result = checkIp(context.Background(), serverCheck, parsedUrl, net.ParseIP("127.0.0.2"))
+	if result.Status != StatusFailed || result.Ip != "127.0.0.2" {
+		t.Errorf("got result %+v, want failed on 127.0.0.2", result)
+	}
+}
+
+func TestAggregateIpResults(t *testing.T) {
+	var ok = func(ip string, responseTime time.Duration) IpResult {
+		return IpResult{Ip: ip, Status: StatusOk, StatusCode: http.StatusOK, ResponseTime: responseTime}
+	}
+	var failed = func(ip string) IpResult {
+		return IpResult{Ip: ip, Status: StatusFailed, StatusCode: http.StatusBadGateway, Error: "status code 502",
+			ResponseTime: time.Millisecond}
+	}
+
+	var tests = []struct {
+		name             string
+		ipResults        []IpResult
+		mode             IpFailMode
+		wantStatus       CheckStatus
+		wantStatusCode   int
+		wantResponseTime time.Duration
+		wantError        string
+	}{
+		{"all ok", []IpResult{ok("10.0.0.1", time.Second), ok("10.0.0.2", 2*time.Second)}, IpFailAny,
+			StatusOk, http.StatusOK, 2 * time.Second, ""},
+		{"one failed in any mode", []IpResult{ok("10.0.0.1", time.Second), failed("10.0.0.2")}, IpFailAny,
+			StatusFailed, http.StatusOK, time.Second, "1 of 2 addresses failed: 10.0.0.2: status code 502"},
+		{"one failed in all mode", []IpResult{ok("10.0.0.1", time.Second), failed("10.0.0.2")}, IpFailAll,
+			StatusDegraded, http.StatusOK, time.Second, "1 of 2 addresses failed: 10.0.0.2: status code 502"},
+		{"all failed in all mode", []IpResult{failed("10.0.0.1"), failed("10.0.0.2")}, IpFailAll,
+			StatusFailed, http.StatusBadGateway, time.Millisecond,
+			"2 of 2 addresses failed: 10.0.0.1: status code 502; 10.0.0.2: status code 502"},
+		{"all failed in any mode", []IpResult{failed("10.0.0.1"), failed("10.0.0.2")}, IpFailAny,
+			StatusFailed, http.StatusBadGateway, time.Millisecond,
+			"2 of 2 addresses failed: 10.0.0.1: status code 502; 10.0.0.2: status code 502"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var result = aggregateIpResults(time.Now(), test.ipResults, test.mode)
+			if result.Status != test.wantStatus || result.StatusCode != test.wantStatusCode || result.Error != test.wantError {
+				t.Errorf("got status %s, status code %d and error %q, want %s, %d and %q",
+					result.Status, result.StatusCode, result.Error, test.wantStatus, test.wantStatusCode, test.wantError)
+			}
+			if result.ResponseTime != test.wantResponseTime {
+				t.Errorf("got response time %v, want the largest one %v", result.ResponseTime, test.wantResponseTime)
+			}
+		})
+	}
+}
+
+func TestSortIpResults(t *testing.T) {
+	var ipResults = []IpResult{{Ip: "2001:db8::1"}, {Ip: "10.0.0.10"}, {Ip: "10.0.0.9"}, {Ip: "9.0.0.1"}}
+	sortIpResults(ipResults)
+
+	var want = []string{"9.0.0.1", "10.0.0.9", "10.0.0.10", "2001:db8::1"}
+	for i, ipResult := range ipResults {
+		if ipResult.Ip != want[i] {
+			t.Fatalf("got order %v, want %v", ipResults, want)
+		}
+	}
+}
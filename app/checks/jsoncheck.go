@@ -0,0 +1,152 @@
+package checks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// JsonCheck asserts a number at the dotted path of the JSON response body, like queue_depth < 5000
+type JsonCheck struct {
+	Path  string  `json:"path"`
+	Op    string  `json:"op"`
+	Value float64 `json:"value"`
+}
+
+// jsonOps are comparators of JSON checks
+var jsonOps = []string{"<", "<=", ">", ">=", "=="}
+
+var ErrJsonCheck = errors.New("invalid JSON check")
+
+// ParseJsonCheck returns the check of the path, the comparator and the number
+func ParseJsonCheck(path string, op string, value string) (JsonCheck, error) {
+	if path == "" || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") || strings.Contains(path, "..") {
+		return JsonCheck{}, fmt.Errorf("%w: path must be like data.queue_depth", ErrJsonCheck)
+	}
+	if !slices.Contains(jsonOps, op) {
+		return JsonCheck{}, fmt.Errorf("%w: comparator must be one of %s", ErrJsonCheck, strings.Join(jsonOps, " "))
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return JsonCheck{}, fmt.Errorf("%w: %s is not a number", ErrJsonCheck, value)
+	}
+
+	return JsonCheck{Path: path, Op: op, Value: number}, nil
+}
+
+func (c JsonCheck) String() string {
+	return fmt.Sprintf("%s %s %g", c.Path, c.Op, c.Value)
+}
+
+// holds returns true if the actual value satisfies the check
+func (c JsonCheck) holds(actual float64) bool {
+	switch c.Op {
+	case "<":
+		return actual < c.Value
+	case "<=":
+		return actual <= c.Value
+	case ">":
+		return actual > c.Value
+	case ">=":
+		return actual >= c.Value
+	case "==":
+		return actual == c.Value
+	}
+	return false
+}
+
+// jsonChecksError returns the error of the first failed check of the body, empty if all checks hold
+func jsonChecksError(body []byte, jsonChecks []JsonCheck) string {
+	if len(jsonChecks) == 0 {
+		return ""
+	}
+
+	var data any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Sprintf("body is not JSON: %v", err)
+	}
+
+	for _, check := range jsonChecks {
+		value, ok := jsonValue(data, check.Path)
+		if !ok {
+			return fmt.Sprintf("JSON path %s not found", check.Path)
+		}
+		actual, ok := jsonNumber(value)
+		if !ok {
+			encoded, _ := json.Marshal(value)
+			return fmt.Sprintf("JSON path %s is not a number: %s", check.Path, encoded)
+		}
+		if !check.holds(actual) {
+			return fmt.Sprintf("%s is %g, expected %s %g", check.Path, actual, check.Op, check.Value)
+		}
+	}
+
+	return ""
+}
+
+// jsonValue returns the value at the dotted path, numeric parts of the path index arrays
+func jsonValue(data any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch node := data.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			data = value
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			data = node[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return data, true
+}
+
+// jsonNumber coerces numbers and numeric strings to float64
+func jsonNumber(value any) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// AddJsonCheck adds the check to the server, a check of the same path and comparator is replaced
+func AddJsonCheck(name string, check JsonCheck) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.JsonChecks = slices.DeleteFunc(serverCheck.JsonChecks, func(c JsonCheck) bool {
+			return c.Path == check.Path && c.Op == check.Op
+		})
+		serverCheck.JsonChecks = append(serverCheck.JsonChecks, check)
+	})
+}
+
+// ClearJsonChecks removes checks of the path from the server, all checks if path is empty,
+// and returns the number of removed checks
+func ClearJsonChecks(name string, path string) (int, error) {
+	var removed int
+	err := UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		var count = len(serverCheck.JsonChecks)
+		serverCheck.JsonChecks = slices.DeleteFunc(serverCheck.JsonChecks, func(c JsonCheck) bool {
+			return path == "" || c.Path == path
+		})
+		removed = count - len(serverCheck.JsonChecks)
+		if len(serverCheck.JsonChecks) == 0 {
+			serverCheck.JsonChecks = nil
+		}
+	})
+
+	return removed, err
+}
@@ -0,0 +1,49 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrStorageLocked is returned by LockStorage when another instance holds the lock
+var ErrStorageLocked = errors.New("another instance is running against this storage")
+
+// StorageLock is an exclusive advisory lock on the lockfile next to the storage
+type StorageLock struct {
+	file *os.File
+}
+
+// LockStorage acquires the storage lock, so two bot instances never share the storage.
+// If wait is false it fails with ErrStorageLocked when the lock is held, otherwise it waits for the lock.
+func LockStorage(wait bool) (*StorageLock, error) {
+	var lockLocation = StorageLocation() + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockLocation), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(lockLocation, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", lockLocation, err)
+	}
+
+	if err := lockFile(file, wait); err != nil {
+		file.Close()
+		if errors.Is(err, errLockHeld) {
+			return nil, fmt.Errorf("%w: %s is locked", ErrStorageLocked, lockLocation)
+		}
+		return nil, fmt.Errorf("lock %s: %w", lockLocation, err)
+	}
+
+	return &StorageLock{file: file}, nil
+}
+
+// Unlock releases the storage lock
+func (l *StorageLock) Unlock() error {
+	if err := unlockFile(l.file); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,53 @@
+//go:build !windows
+
+package checks
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockStorage(t *testing.T) {
+	var location = StorageLocation()
+	SetStorageLocation(filepath.Join(t.TempDir(), "data", "checks.json"))
+	t.Cleanup(func() { SetStorageLocation(location) })
+
+	first, err := LockStorage(false)
+	if err != nil {
+		t.Fatalf("first instance failed to lock: %v", err)
+	}
+
+	// the second instance opens the lockfile on its own, like another process
+	if _, err := LockStorage(false); !errors.Is(err, ErrStorageLocked) {
+		t.Fatalf("second instance: %v, want ErrStorageLocked", err)
+	}
+
+	var locked = make(chan *StorageLock)
+	go func() {
+		second, err := LockStorage(true)
+		if err != nil {
+			t.Errorf("waiting instance failed to lock: %v", err)
+		}
+		locked <- second
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("waiting instance locked the storage held by the first one")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case second := <-locked:
+		if second != nil {
+			second.Unlock()
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting instance didn't lock the released storage")
+	}
+}
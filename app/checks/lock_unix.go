@@ -0,0 +1,30 @@
+//go:build !windows
+
+package checks
+
+import (
+	"errors"
+	"golang.org/x/sys/unix"
+	"os"
+)
+
+var errLockHeld = unix.EWOULDBLOCK
+
+func lockFile(file *os.File, wait bool) error {
+	var how = unix.LOCK_EX
+	if !wait {
+		how |= unix.LOCK_NB
+	}
+
+	for {
+		err := unix.Flock(int(file.Fd()), how)
+		// flock is restarted if it was interrupted by a signal while waiting
+		if !errors.Is(err, unix.EINTR) {
+			return err
+		}
+	}
+}
+
+func unlockFile(file *os.File) error {
+	return unix.Flock(int(file.Fd()), unix.LOCK_UN)
+}
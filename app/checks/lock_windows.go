@@ -0,0 +1,26 @@
+//go:build windows
+
+package checks
+
+import (
+	"golang.org/x/sys/windows"
+	"os"
+)
+
+var errLockHeld = windows.ERROR_LOCK_VIOLATION
+
+// the whole file is locked, the range only has to be the same for lock and unlock
+const lockRange = ^uint32(0)
+
+func lockFile(file *os.File, wait bool) error {
+	var flags uint32 = windows.LOCKFILE_EXCLUSIVE_LOCK
+	if !wait {
+		flags |= windows.LOCKFILE_FAIL_IMMEDIATELY
+	}
+
+	return windows.LockFileEx(windows.Handle(file.Fd()), flags, 0, lockRange, lockRange, &windows.Overlapped{})
+}
+
+func unlockFile(file *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, lockRange, lockRange, &windows.Overlapped{})
+}
@@ -0,0 +1,55 @@
+package checks
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"log"
+	"sort"
+	"time"
+)
+
+// SetMaintenance suppresses all alerts until the time, zero time ends the maintenance on the next check cycle
+func SetMaintenance(until time.Time) error {
+	return UpdateChecksData(func(data *Data) {
+		if until.IsZero() && data.Settings.MaintenanceUntil.After(time.Now()) {
+			// the expired maintenance is cleared with the summary by the next check cycle
+			until = time.Now()
+		}
+		data.Settings.MaintenanceUntil = until
+	})
+}
+
+// inMaintenance returns true if alerts are suppressed by the global maintenance
+func (o Options) inMaintenance() bool {
+	return o.maintenanceUntil.After(time.Now())
+}
+
+// endMaintenance clears the expired maintenance and sends the summary of servers which are still down
+func endMaintenance(options Options) {
+	var until = ReadChecksData().Settings.MaintenanceUntil
+	if until.IsZero() || until.After(time.Now()) {
+		return
+	}
+
+	var down []string
+	err := UpdateChecksData(func(data *Data) {
+		data.Settings.MaintenanceUntil = time.Time{}
+		for name, serverCheck := range data.HealthChecks {
+			if !serverCheck.IsOk && !serverCheck.Paused {
+				down = append(down, name)
+			}
+		}
+	})
+	if err != nil {
+		log.Printf("[ERROR] Error while saving checks data: %v", err)
+		return
+	}
+	sort.Strings(down)
+
+	log.Printf("[INFO] Maintenance ended, %d servers are down", len(down))
+	options.maintenanceUntil = time.Time{}
+	sendEvent(options, notify.Event{
+		Type:    notify.EventMaintenanceEnded,
+		Time:    time.Now(),
+		Servers: down,
+	})
+}
@@ -0,0 +1,28 @@
+package checks
+
+import (
+	"context"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	"net/http"
+	"testing"
+)
+
+func TestPerformCheckCountsMetrics(t *testing.T) {
+	var servers = serversOf(t, 2, func(w http.ResponseWriter, r *http.Request) {})
+	servers[1].SimulatedCycles = 1
+	useStorage(t, servers...)
+
+	var performed, cycles, writes = metrics.ChecksPerformed.Value(), metrics.CheckCycles.Value(), metrics.StorageWrites.Value()
+	PerformCheck(context.Background(), Options{AlertThreshold: 1, Notifier: &testNotifier{}})
+
+	// the simulated check is not a performed one
+	if got := metrics.ChecksPerformed.Value() - performed; got != 1 {
+		t.Errorf("checks_performed increased by %d, want 1", got)
+	}
+	if got := metrics.CheckCycles.Value() - cycles; got != 1 {
+		t.Errorf("check_cycles increased by %d, want 1", got)
+	}
+	if got := metrics.StorageWrites.Value() - writes; got < 2 {
+		t.Errorf("storage_writes increased by %d, want a write of each result", got)
+	}
+}
@@ -0,0 +1,127 @@
+package checks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SchemaVersion is the version of the storage format written by this build, bump it with each migration
+const SchemaVersion = 2
+
+// migration upgrades decoded storage of the previous schema version in place
+type migration func(data map[string]any) error
+
+// migrations[i] upgrades schema version i to i+1, files written before versioning have version 0
+var migrations = []migration{
+	migrateUnversioned,
+	migrateLastChecked,
+}
+
+// decodeChecksData upgrades storage of an older schema step by step and decodes it,
+// version is the schema version of the stored data. Storage of a newer schema is not decoded.
+func decodeChecksData(raw []byte) (checksData Data, version int, err error) {
+	var header struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return Data{}, 0, err
+	}
+
+	version = header.SchemaVersion
+	switch {
+	case version > SchemaVersion:
+		return Data{}, version, fmt.Errorf("storage schema version %d is newer than %d supported by this build, "+
+			"upgrade the bot", version, SchemaVersion)
+	case version == SchemaVersion:
+		if err := json.Unmarshal(raw, &checksData); err != nil {
+			return Data{}, version, err
+		}
+		return checksData, version, decryptSecrets(&checksData)
+	}
+
+	// numbers are kept as is, so durations in nanoseconds don't lose precision as float64
+	var decoder = json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var data map[string]any
+	if err := decoder.Decode(&data); err != nil {
+		return Data{}, version, err
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	for v := version; v < SchemaVersion; v++ {
+		if err := migrations[v](data); err != nil {
+			return Data{}, version, fmt.Errorf("migrate storage from schema version %d: %w", v, err)
+		}
+		data["schemaVersion"] = v + 1
+	}
+
+	migrated, err := json.Marshal(data)
+	if err != nil {
+		return Data{}, version, err
+	}
+	if err := json.Unmarshal(migrated, &checksData); err != nil {
+		return Data{}, version, err
+	}
+
+	return checksData, version, decryptSecrets(&checksData)
+}
+
+// migrateUnversioned maps legacy spellings of the url key to "url" and fills names of servers from their keys
+func migrateUnversioned(data map[string]any) error {
+	healthChecks, ok := data["healthChecks"].(map[string]any)
+	if !ok {
+		data["healthChecks"] = map[string]any{}
+		return nil
+	}
+
+	for key, value := range healthChecks {
+		serverCheck, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("server %s is not an object", key)
+		}
+
+		for _, legacyKey := range []string{"URL", "Url"} {
+			if url, ok := serverCheck[legacyKey]; ok {
+				if _, exists := serverCheck["url"]; !exists {
+					serverCheck["url"] = url
+				}
+				delete(serverCheck, legacyKey)
+			}
+		}
+
+		if name, _ := serverCheck["name"].(string); name == "" {
+			serverCheck["name"] = key
+		}
+	}
+
+	return nil
+}
+
+// migrateLastChecked sets lastChecked of checked servers to the later of their last success and last failure
+func migrateLastChecked(data map[string]any) error {
+	healthChecks, _ := data["healthChecks"].(map[string]any)
+	for key, value := range healthChecks {
+		serverCheck, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("server %s is not an object", key)
+		}
+
+		var lastChecked time.Time
+		for _, field := range []string{"lastSuccess", "lastFailure"} {
+			value, _ := serverCheck[field].(string)
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil && t.After(lastChecked) {
+				lastChecked = t
+			}
+		}
+		if !lastChecked.IsZero() {
+			serverCheck["lastChecked"] = lastChecked
+		}
+	}
+
+	return nil
+}
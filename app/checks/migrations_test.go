@@ -0,0 +1,99 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeFixture decodes a storage file of an older schema from testdata
+func decodeFixture(t *testing.T, name string) (Data, int) {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checksData, version, err := decodeChecksData(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return checksData, version
+}
+
+func TestMigrateUnversioned(t *testing.T) {
+	checksData, version := decodeFixture(t, "schema-v0.json")
+
+	if version != 0 {
+		t.Errorf("got version %d, want 0", version)
+	}
+	if len(checksData.SuperUsers) != 1 || checksData.SuperUsers[0] != "admin" {
+		t.Errorf("super users were not kept: %v", checksData.SuperUsers)
+	}
+
+	var expected = map[string]string{
+		"api":  "https://api.example.com",
+		"site": "https://example.com",
+		// the current key wins over a legacy one
+		"both": "https://current.example.com",
+	}
+	for key, url := range expected {
+		var serverCheck = checksData.HealthChecks[key]
+		if serverCheck.Url != url {
+			t.Errorf("%s: got url %q, want %q", key, serverCheck.Url, url)
+		}
+		if serverCheck.Name != key {
+			t.Errorf("%s: got name %q", key, serverCheck.Name)
+		}
+	}
+	if !checksData.HealthChecks["api"].IsOk || checksData.HealthChecks["site"].IsOk {
+		t.Error("status of servers was not kept")
+	}
+}
+
+func TestMigrateLastChecked(t *testing.T) {
+	checksData, version := decodeFixture(t, "schema-v1.json")
+
+	if version != 1 {
+		t.Errorf("got version %d, want 1", version)
+	}
+
+	var expected = map[string]time.Time{
+		"failed-last":    time.Date(2024, 1, 2, 10, 0, 0, 500000000, time.UTC),
+		"succeeded-last": time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC),
+		"never-checked":  {},
+	}
+	for name, lastChecked := range expected {
+		if got := checksData.HealthChecks[name].LastChecked; !got.Equal(lastChecked) {
+			t.Errorf("%s: got last checked %v, want %v", name, got, lastChecked)
+		}
+	}
+	// durations must not lose precision on the way through the migration
+	if retryDelay := checksData.HealthChecks["failed-last"].RetryDelay; retryDelay != 1500000001 {
+		t.Errorf("got retry delay %d, want 1500000001", retryDelay)
+	}
+}
+
+func TestDecodeChecksDataNewerSchema(t *testing.T) {
+	_, version, err := decodeChecksData([]byte(`{"schemaVersion": 99, "healthChecks": {}}`))
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("got error %v, want a newer schema error", err)
+	}
+	if version != 99 {
+		t.Errorf("got version %d, want 99", version)
+	}
+}
+
+func TestDecodeChecksDataCurrentSchema(t *testing.T) {
+	checksData, version, err := decodeChecksData([]byte(fmt.Sprintf(
+		`{"schemaVersion": %d, "healthChecks": {"api": {"name": "api", "url": "https://api.example.com"}}}`, SchemaVersion)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != SchemaVersion || checksData.HealthChecks["api"].Url != "https://api.example.com" {
+		t.Errorf("got version %d and servers %v", version, checksData.HealthChecks)
+	}
+}
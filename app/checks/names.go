@@ -0,0 +1,49 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// MaxNameLength is the maximum number of characters in a server name
+const MaxNameLength = 64
+
+// reservedNames are keywords used as command arguments, a server with such name can't be told apart from them
+var reservedNames = []string{"all", "any", "clear", "current", "default", "fail", "none", "off", "on", "warn"}
+
+var ErrNameEmpty = errors.New("name is empty")
+var ErrNameTooLong = errors.New("name is too long")
+var ErrNameReserved = errors.New("name is reserved")
+var ErrNameChars = errors.New("name contains not allowed characters")
+
+// ValidateName checks the name of a new server, stored servers are not validated so they keep working.
+// Names may contain letters, digits, dash, underscore, dot and spaces between words.
+func ValidateName(name string) error {
+	if name == "" {
+		return ErrNameEmpty
+	}
+	if len([]rune(name)) > MaxNameLength {
+		return fmt.Errorf("%w, it must be at most %d characters", ErrNameTooLong, MaxNameLength)
+	}
+	if slices.Contains(reservedNames, strings.ToLower(name)) {
+		return fmt.Errorf("%w, %q is a command argument", ErrNameReserved, name)
+	}
+	if strings.TrimSpace(name) != name || strings.Contains(name, "  ") {
+		return fmt.Errorf("%w, spaces are allowed only between words", ErrNameChars)
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_. ", r) {
+			return fmt.Errorf("%w, %q is not a letter, digit, dash, underscore or dot", ErrNameChars, r)
+		}
+	}
+
+	return nil
+}
+
+// ReservedNames returns keywords which can't be used as server names
+func ReservedNames() []string {
+	return slices.Clone(reservedNames)
+}
@@ -0,0 +1,64 @@
+package checks
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// spkiFingerprint returns hex encoded SHA-256 of the subject public key info of the certificate
+func spkiFingerprint(certificate *x509.Certificate) string {
+	var sum = sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParsePin parses SHA-256 SPKI fingerprint in hex, optionally separated by colons,
+// or in base64 with optional "sha256/" prefix, returns it in lowercase hex
+func ParsePin(value string) (string, error) {
+	var hexValue = strings.ToLower(strings.ReplaceAll(value, ":", ""))
+	if sum, err := hex.DecodeString(hexValue); err == nil && len(sum) == sha256.Size {
+		return hexValue, nil
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, "sha256/"))
+	if err == nil && len(sum) == sha256.Size {
+		return hex.EncodeToString(sum), nil
+	}
+
+	return "", fmt.Errorf("invalid SHA-256 fingerprint: %s", value)
+}
+
+// updatePin captures the fingerprint of the result if it is requested and returns true if the fingerprint
+// doesn't match the pin, the warning is sent once for each presented fingerprint
+func updatePin(serverCheck *ServerCheck, result CheckResult) bool {
+	if result.Spki == "" {
+		return false
+	}
+	if serverCheck.PinPending {
+		serverCheck.PinnedSpki, serverCheck.PinPending = result.Spki, false
+		return false
+	}
+	if serverCheck.PinnedSpki == "" || result.Spki == serverCheck.PinnedSpki {
+		serverCheck.PinMismatch = ""
+		return false
+	}
+	if result.Spki == serverCheck.PinMismatch {
+		return false
+	}
+
+	serverCheck.PinMismatch = result.Spki
+	return true
+}
+
+// SetPin pins the SPKI fingerprint of the server, with pending the fingerprint is captured from the next check.
+// Empty fingerprint without pending removes the pin.
+func SetPin(name string, fingerprint string, pending bool) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.PinnedSpki = fingerprint
+		serverCheck.PinPending = pending
+		serverCheck.PinMismatch = ""
+	})
+}
@@ -0,0 +1,190 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"strings"
+	"time"
+)
+
+// categories of alerts sent to subscribers
+const (
+	CategoryDown     = "down"
+	CategoryWarnings = "warnings"
+	CategorySla      = "sla"
+)
+
+// Categories are alert categories in the order shown in preferences
+var Categories = []string{CategoryDown, CategoryWarnings, CategorySla}
+
+// AlertMode is whether alerts of the category are sent to the subscriber
+type AlertMode string
+
+const (
+	ModeOn AlertMode = "on"
+	// ModeAwake sends alerts outside of quiet hours only
+	ModeAwake AlertMode = "awake"
+	ModeOff   AlertMode = "off"
+)
+
+// quietHoursLayout is the format of the quiet hours bounds
+const quietHoursLayout = "15:04"
+
+var ErrQuietHours = errors.New("invalid quiet hours")
+
+// Preferences are alert preferences of the subscriber, they don't affect the alert chat.
+// Empty mode is the default: down alerts are always sent, other categories are not.
+type Preferences struct {
+	Down     AlertMode `json:"down,omitempty"`
+	Warnings AlertMode `json:"warnings,omitempty"`
+	Sla      AlertMode `json:"sla,omitempty"`
+
+	// QuietFrom and QuietTo are bounds of the daily quiet hours like "22:00", in the bot timezone
+	QuietFrom string `json:"quietFrom,omitempty"`
+	QuietTo   string `json:"quietTo,omitempty"`
+}
+
+// EventCategory returns the category of the event sent to subscribers, empty if it is not sent to them
+func EventCategory(eventType notify.EventType) string {
+	switch eventType {
+	case notify.EventDown, notify.EventUp:
+		return CategoryDown
+	case notify.EventProtocol, notify.EventCompression, notify.EventSecurityHeaders, notify.EventPinMismatch,
+		notify.EventUnexpectedIp:
+		return CategoryWarnings
+	case notify.EventSlaBreach, notify.EventSlaRecovered:
+		return CategorySla
+	}
+	return ""
+}
+
+// Mode returns the mode of the category with the default applied
+func (p Preferences) Mode(category string) AlertMode {
+	var mode AlertMode
+	switch category {
+	case CategoryDown:
+		mode = p.Down
+		if mode == "" {
+			mode = ModeOn
+		}
+	case CategoryWarnings:
+		mode = p.Warnings
+	case CategorySla:
+		mode = p.Sla
+	}
+
+	if mode == "" {
+		return ModeOff
+	}
+	return mode
+}
+
+// setMode sets the mode of the category
+func (p *Preferences) setMode(category string, mode AlertMode) {
+	switch category {
+	case CategoryDown:
+		p.Down = mode
+	case CategoryWarnings:
+		p.Warnings = mode
+	case CategorySla:
+		p.Sla = mode
+	}
+}
+
+// HasQuietHours returns true if quiet hours are set
+func (p Preferences) HasQuietHours() bool {
+	return p.QuietFrom != "" && p.QuietTo != ""
+}
+
+// IsQuiet returns true if now is within quiet hours, the window may span midnight
+func (p Preferences) IsQuiet(now time.Time) bool {
+	if !p.HasQuietHours() {
+		return false
+	}
+	from, errFrom := time.Parse(quietHoursLayout, p.QuietFrom)
+	to, errTo := time.Parse(quietHoursLayout, p.QuietTo)
+	if errFrom != nil || errTo != nil {
+		return false
+	}
+
+	var minute = now.Hour()*60 + now.Minute()
+	var fromMinute, toMinute = from.Hour()*60 + from.Minute(), to.Hour()*60 + to.Minute()
+	if fromMinute <= toMinute {
+		return minute >= fromMinute && minute < toMinute
+	}
+	return minute >= fromMinute || minute < toMinute
+}
+
+// Allows returns true if the event is sent to the subscriber at now
+func (p Preferences) Allows(eventType notify.EventType, now time.Time) bool {
+	var category = EventCategory(eventType)
+	if category == "" {
+		return false
+	}
+
+	switch p.Mode(category) {
+	case ModeOn:
+		return true
+	case ModeAwake:
+		return !p.IsQuiet(now)
+	}
+	return false
+}
+
+// ToggleCategory switches the category of the chat to the next mode: on, outside quiet hours, off
+func ToggleCategory(chatId int64, category string) (Preferences, error) {
+	var preferences Preferences
+	err := UpdateChecksData(func(checksData *Data) {
+		preferences = checksData.Preferences[chatId]
+		switch preferences.Mode(category) {
+		case ModeOn:
+			preferences.setMode(category, ModeAwake)
+		case ModeAwake:
+			preferences.setMode(category, ModeOff)
+		default:
+			preferences.setMode(category, ModeOn)
+		}
+		setPreferences(checksData, chatId, preferences)
+	})
+	return preferences, err
+}
+
+// SetQuietHours sets quiet hours of the chat like "22:00-08:00", empty window clears them
+func SetQuietHours(chatId int64, window string) (Preferences, error) {
+	var from, to string
+	if window != "" {
+		var found bool
+		from, to, found = strings.Cut(window, "-")
+		if !found || from == to {
+			return Preferences{}, fmt.Errorf("%w: %q", ErrQuietHours, window)
+		}
+		for _, bound := range []*string{&from, &to} {
+			parsed, err := time.Parse(quietHoursLayout, *bound)
+			if err != nil {
+				return Preferences{}, fmt.Errorf("%w: %q", ErrQuietHours, window)
+			}
+			*bound = parsed.Format(quietHoursLayout)
+		}
+	}
+
+	var preferences Preferences
+	err := UpdateChecksData(func(checksData *Data) {
+		preferences = checksData.Preferences[chatId]
+		preferences.QuietFrom, preferences.QuietTo = from, to
+		setPreferences(checksData, chatId, preferences)
+	})
+	return preferences, err
+}
+
+// setPreferences stores preferences of the chat, default preferences are not stored
+func setPreferences(checksData *Data, chatId int64, preferences Preferences) {
+	if preferences == (Preferences{}) {
+		delete(checksData.Preferences, chatId)
+		return
+	}
+	if checksData.Preferences == nil {
+		checksData.Preferences = make(map[int64]Preferences)
+	}
+	checksData.Preferences[chatId] = preferences
+}
@@ -0,0 +1,85 @@
+package checks
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ProtoAction is what happens when the server is served over a protocol lower than its minimum
+type ProtoAction string
+
+const (
+	// ProtoFail fails the check
+	ProtoFail ProtoAction = "fail"
+	// ProtoWarn sends a warning once, the next one is sent after the protocol was restored
+	ProtoWarn ProtoAction = "warn"
+)
+
+// minProtoVersions are major and minor versions of protocols accepted by /setminproto
+var minProtoVersions = map[string][2]int{
+	"h2":       {2, 0},
+	"http/1.1": {1, 1},
+}
+
+// ValidMinProto returns true if the protocol can be set as the minimum
+func ValidMinProto(minProto string) bool {
+	_, ok := minProtoVersions[minProto]
+	return ok
+}
+
+// protoBelow returns true if the negotiated protocol like HTTP/1.1 is lower than the minimum like h2,
+// unknown protocols are not compared
+func protoBelow(proto string, minProto string) bool {
+	minVersion, ok := minProtoVersions[minProto]
+	if !ok {
+		return false
+	}
+	major, minor, ok := http.ParseHTTPVersion(proto)
+	if !ok {
+		return false
+	}
+
+	return major < minVersion[0] || major == minVersion[0] && minor < minVersion[1]
+}
+
+// protoLess returns true if the negotiated protocol a is lower than b
+func protoLess(a string, b string) bool {
+	aMajor, aMinor, _ := http.ParseHTTPVersion(a)
+	bMajor, bMinor, _ := http.ParseHTTPVersion(b)
+	return aMajor < bMajor || aMajor == bMajor && aMinor < bMinor
+}
+
+// protoError returns the error of the check if the protocol is lower than the minimum of the server
+// and the server fails on it, empty otherwise
+func protoError(serverCheck ServerCheck, proto string) string {
+	if serverCheck.ProtoAction != ProtoFail || !protoBelow(proto, serverCheck.MinProto) {
+		return ""
+	}
+	return fmt.Sprintf("protocol %s, expected %s", proto, serverCheck.MinProto)
+}
+
+// updateProtoWarning returns true if the warning about the protocol should be sent for the result,
+// the warning is sent once until the protocol is restored
+func updateProtoWarning(serverCheck *ServerCheck, result CheckResult) bool {
+	if serverCheck.ProtoAction != ProtoWarn || result.Proto == "" {
+		return false
+	}
+
+	var below = protoBelow(result.Proto, serverCheck.MinProto)
+	var warn = below && !serverCheck.ProtoWarned
+	serverCheck.ProtoWarned = below
+
+	return warn
+}
+
+// SetMinProto sets the lowest accepted protocol of the server, empty minProto disables the check
+func SetMinProto(name string, minProto string, action ProtoAction) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.MinProto = minProto
+		serverCheck.ProtoAction = action
+		serverCheck.ProtoWarned = false
+		if minProto == "" {
+			serverCheck.ProtoAction = ""
+		}
+	})
+}
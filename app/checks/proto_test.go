@@ -0,0 +1,123 @@
+package checks
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// protoServers returns an HTTP/2 server over TLS and a plain HTTP/1.1 server, checks trust the certificate of the first
+func protoServers(t *testing.T) (h2 *httptest.Server, h1 *httptest.Server) {
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h2 = httptest.NewUnstartedServer(handler)
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	h1 = httptest.NewServer(handler)
+
+	// a clone of the shared transport trusting the test certificate negotiates protocols the same way
+	var transport = checkTransport.Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: h2.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+	var client = checkClient
+	checkClient = &http.Client{Transport: transport}
+	t.Cleanup(func() {
+		checkClient = client
+		transport.CloseIdleConnections()
+		h2.Close()
+		h1.Close()
+	})
+
+	return h2, h1
+}
+
+func TestCheckTransportEnablesHttp2(t *testing.T) {
+	if !checkTransport.ForceAttemptHTTP2 {
+		t.Error("HTTP/2 is not enabled in the check transport")
+	}
+}
+
+func TestMinProto(t *testing.T) {
+	h2, h1 := protoServers(t)
+
+	var tests = []struct {
+		name       string
+		server     *httptest.Server
+		minProto   string
+		action     ProtoAction
+		wantProto  string
+		wantStatus CheckStatus
+		wantError  string
+	}{
+		{"h2 without minimum", h2, "", "", "HTTP/2.0", StatusOk, ""},
+		{"h1 without minimum", h1, "", "", "HTTP/1.1", StatusOk, ""},
+		{"h2 with h2 minimum", h2, "h2", ProtoFail, "HTTP/2.0", StatusOk, ""},
+		{"h1 with h2 minimum", h1, "h2", ProtoFail, "HTTP/1.1", StatusFailed, "protocol HTTP/1.1, expected h2"},
+		{"h1 with h2 warning", h1, "h2", ProtoWarn, "HTTP/1.1", StatusOk, ""},
+		{"h1 with h1 minimum", h1, "http/1.1", ProtoFail, "HTTP/1.1", StatusOk, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var result = checkServer(context.Background(),
+				ServerCheck{Name: "web", Url: test.server.URL, MinProto: test.minProto, ProtoAction: test.action})
+
+			if result.Proto != test.wantProto || result.Status != test.wantStatus || result.Error != test.wantError {
+				t.Errorf("got %s %s %q, want %s %s %q", result.Proto, result.Status, result.Error,
+					test.wantProto, test.wantStatus, test.wantError)
+			}
+		})
+	}
+}
+
+func TestProtoBelow(t *testing.T) {
+	var tests = []struct {
+		proto    string
+		minProto string
+		want     bool
+	}{
+		{"HTTP/1.1", "h2", true},
+		{"HTTP/1.0", "h2", true},
+		{"HTTP/2.0", "h2", false},
+		{"HTTP/1.0", "http/1.1", true},
+		{"HTTP/1.1", "http/1.1", false},
+		{"HTTP/2.0", "http/1.1", false},
+		{"", "h2", false},
+		{"HTTP/1.1", "", false},
+	}
+
+	for _, test := range tests {
+		if got := protoBelow(test.proto, test.minProto); got != test.want {
+			t.Errorf("protoBelow(%q, %q) = %v, want %v", test.proto, test.minProto, got, test.want)
+		}
+	}
+}
+
+func TestProtoWarningIsSentOnce(t *testing.T) {
+	var serverCheck = ServerCheck{MinProto: "h2", ProtoAction: ProtoWarn}
+
+	var want = []bool{true, false, false, true}
+	for i, proto := range []string{"HTTP/1.1", "HTTP/1.1", "HTTP/2.0", "HTTP/1.1"} {
+		if warn := updateProtoWarning(&serverCheck, CheckResult{Proto: proto}); warn != want[i] {
+			t.Errorf("check %d over %s sent warning: %v, want %v", i+1, proto, warn, want[i])
+		}
+	}
+}
+
+func TestSetMinProto(t *testing.T) {
+	useStorage(t, ServerCheck{Name: "web", Url: "https://example.com", ProtoWarned: true})
+
+	if err := SetMinProto("web", "h2", ProtoWarn); err != nil {
+		t.Fatal(err)
+	}
+	if stored := ReadChecksData().HealthChecks["web"]; stored.MinProto != "h2" || stored.ProtoAction != ProtoWarn || stored.ProtoWarned {
+		t.Errorf("got %+v after setting the minimum", stored)
+	}
+
+	if err := SetMinProto("web", "", ProtoWarn); err != nil {
+		t.Fatal(err)
+	}
+	if stored := ReadChecksData().HealthChecks["web"]; stored.MinProto != "" || stored.ProtoAction != "" {
+		t.Errorf("got minimum %q with action %q after clearing", stored.MinProto, stored.ProtoAction)
+	}
+}
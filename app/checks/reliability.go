@@ -0,0 +1,62 @@
+package checks
+
+import (
+	"time"
+)
+
+// minReliabilityIncidents is the number of incidents MTTR and MTBF need to be meaningful
+const minReliabilityIncidents = 2
+
+// DefaultReliabilityWindow is the window of MTTR and MTBF if it is not selected
+const DefaultReliabilityWindow = 30 * 24 * time.Hour
+
+// MTTR returns mean time to recovery of incidents closed since the time, false if there are fewer than two of them.
+// The open incident is not counted until it is closed.
+func (s ServerCheck) MTTR(since time.Time) (time.Duration, bool) {
+	var total time.Duration
+	var count int
+	for _, incident := range s.PastIncidents {
+		if incident.Start.Before(since) || incident.End.IsZero() {
+			continue
+		}
+		total += incident.End.Sub(incident.Start)
+		count++
+	}
+
+	if count < minReliabilityIncidents {
+		return 0, false
+	}
+	return total / time.Duration(count), true
+}
+
+// MTBF returns mean time between starts of incidents since the time including the open one,
+// false if there are fewer than two incidents
+func (s ServerCheck) MTBF(since time.Time) (time.Duration, bool) {
+	var starts []time.Time
+	for _, incident := range s.PastIncidents {
+		if !incident.Start.Before(since) {
+			starts = append(starts, incident.Start)
+		}
+	}
+	if s.Incident != nil && !s.Incident.Start.Before(since) {
+		starts = append(starts, s.Incident.Start)
+	}
+
+	if len(starts) < minReliabilityIncidents {
+		return 0, false
+	}
+	// incidents are stored in order of start, so the span is between the first and the last one
+	return starts[len(starts)-1].Sub(starts[0]) / time.Duration(len(starts)-1), true
+}
+
+// closeIncident moves the open incident to past incidents, they are trimmed to the retention at the end of the check cycle
+func closeIncident(serverCheck *ServerCheck, end time.Time) {
+	var incident = *serverCheck.Incident
+	incident.End = end
+	serverCheck.Incident = nil
+
+	if incident.Simulated {
+		return
+	}
+	serverCheck.PastIncidents = append(serverCheck.PastIncidents, incident)
+}
@@ -0,0 +1,126 @@
+package checks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+)
+
+// MaxRestoreSize is the maximal size of a restored storage file
+const MaxRestoreSize = 10 << 20
+
+var ErrNoPrerestore = errors.New("no storage saved before restore")
+
+// prerestoreLocation returns the file keeping the storage replaced by the last restore, so the restore can be undone.
+// Must be called with the mutex held.
+func prerestoreLocation() string {
+	return storageLocation + ".prerestore"
+}
+
+// ParseRestore decodes the storage file to restore, migrating older schema versions, and validates its servers
+func ParseRestore(raw []byte) (Data, error) {
+	if len(raw) > MaxRestoreSize {
+		return Data{}, fmt.Errorf("file is larger than %d bytes", MaxRestoreSize)
+	}
+
+	checksData, _, err := decodeChecksData(raw)
+	if err != nil {
+		return Data{}, err
+	}
+
+	if MaxServers > 0 && len(checksData.HealthChecks) > MaxServers {
+		return Data{}, fmt.Errorf("%w: %d servers, the limit is %d", ErrServerLimit, len(checksData.HealthChecks), MaxServers)
+	}
+	for name, serverCheck := range checksData.HealthChecks {
+		if serverCheck.Name != name || serverCheck.Url == "" {
+			return Data{}, fmt.Errorf("server %q has no url or a different name %q", name, serverCheck.Name)
+		}
+	}
+
+	return checksData, nil
+}
+
+// RestoreDiff returns the number of servers the restore adds, changes and removes
+func RestoreDiff(current Data, restored Data) (added int, updated int, removed int) {
+	for name, serverCheck := range restored.HealthChecks {
+		currentCheck, ok := current.HealthChecks[name]
+		if !ok {
+			added++
+			continue
+		}
+
+		// compared encoded, so times loaded from the file and set at runtime are equal
+		currentJson, _ := json.Marshal(currentCheck)
+		restoredJson, _ := json.Marshal(serverCheck)
+		if string(currentJson) != string(restoredJson) {
+			updated++
+		}
+	}
+	for name := range current.HealthChecks {
+		if _, ok := restored.HealthChecks[name]; !ok {
+			removed++
+		}
+	}
+
+	return added, updated, removed
+}
+
+// Restore replaces the storage with checksData, the replaced storage is kept for UndoRestore.
+// Failure counts of servers are reset, so alerts start over with the restored state.
+func Restore(checksData Data) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	current, err := os.ReadFile(storageLocation)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(prerestoreLocation(), current, 0o644); err != nil {
+		return err
+	}
+
+	if err := saveChecksData(checksData.clone()); err != nil {
+		return err
+	}
+	resetFailureCounts()
+
+	log.Printf("[INFO] Storage restored with %d servers, the previous storage is kept in %s",
+		len(checksData.HealthChecks), prerestoreLocation())
+	return nil
+}
+
+// UndoRestore puts back the storage replaced by the last restore, returns ErrNoPrerestore if there is none
+func UndoRestore() error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	raw, err := os.ReadFile(prerestoreLocation())
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNoPrerestore
+	}
+	if err != nil {
+		return err
+	}
+
+	checksData, _, err := decodeChecksData(raw)
+	if err != nil {
+		return err
+	}
+	if err := saveChecksData(checksData); err != nil {
+		return err
+	}
+	resetFailureCounts()
+
+	log.Printf("[INFO] Restore undone, storage with %d servers is back", len(checksData.HealthChecks))
+	return os.Remove(prerestoreLocation())
+}
+
+// resetFailureCounts forgets failures of all servers counted toward the alert threshold
+func resetFailureCounts() {
+	failureCountMutex.Lock()
+	defer failureCountMutex.Unlock()
+
+	clear(serverFailureCount)
+}
@@ -0,0 +1,128 @@
+package checks
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention limits stored entries by count like "1000" or by age like "720h" or "30d"
+type Retention struct {
+	Count int
+	Age   time.Duration
+}
+
+// UnmarshalFlag parses the retention from the flag value
+func (r *Retention) UnmarshalFlag(value string) error {
+	if count, err := strconv.Atoi(value); err == nil {
+		if count < 1 {
+			return fmt.Errorf("retention count must be positive: %s", value)
+		}
+		*r = Retention{Count: count}
+		return nil
+	}
+
+	age, err := parseAge(value)
+	if err != nil {
+		return fmt.Errorf("retention must be a positive count or duration like 720h or 30d: %s", value)
+	}
+
+	*r = Retention{Age: age}
+	return nil
+}
+
+// parseAge parses a positive duration like "720h" or a number of days like "30d"
+func parseAge(value string) (time.Duration, error) {
+	var age time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		age, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("duration must be positive: %s", value)
+	}
+
+	return age, nil
+}
+
+func (r Retention) String() string {
+	if r.Age > 0 {
+		return r.Age.String()
+	}
+	return strconv.Itoa(r.Count)
+}
+
+// keep returns the index of the first entry to keep of count entries, entryTime returns time of the entry
+func (r Retention) keep(count int, now time.Time, entryTime func(i int) time.Time) int {
+	var start = 0
+	if r.Count > 0 && count > r.Count {
+		start = count - r.Count
+	}
+	if r.Age > 0 {
+		var since = now.Add(-r.Age)
+		for start < count && entryTime(start).Before(since) {
+			start++
+		}
+	}
+	return start
+}
+
+// pruneServerCheck trims check history and closed incidents of the server to the retention,
+// returns the number of pruned entries
+func pruneServerCheck(serverCheck *ServerCheck, options Options, now time.Time) (history int, incidents int) {
+	history = options.HistoryRetention.keep(len(serverCheck.History), now, func(i int) time.Time {
+		return serverCheck.History[i].Time
+	})
+	if history > 0 && history < len(serverCheck.History) {
+		serverCheck.HistoryRetainedSince = serverCheck.History[history].Time
+		serverCheck.History = append([]CheckResult(nil), serverCheck.History[history:]...)
+	} else if history > 0 {
+		// all results are older than the retention, like of a paused server
+		serverCheck.HistoryRetainedSince = serverCheck.History[history-1].Time
+		serverCheck.History = nil
+	}
+
+	incidents = options.IncidentRetention.keep(len(serverCheck.PastIncidents), now, func(i int) time.Time {
+		return serverCheck.PastIncidents[i].Start
+	})
+	if incidents > 0 {
+		// the window starts after the end of the last pruned incident
+		serverCheck.IncidentsRetainedSince = serverCheck.PastIncidents[incidents-1].End
+		serverCheck.PastIncidents = append([]Incident(nil), serverCheck.PastIncidents[incidents:]...)
+	}
+
+	return history, incidents
+}
+
+// pruneChecksData trims history and incidents of all servers to the retention of options
+func pruneChecksData(options Options) {
+	var history, incidents int
+	err := UpdateChecksData(func(checksData *Data) {
+		var now = time.Now()
+		for name, serverCheck := range checksData.HealthChecks {
+			prunedHistory, prunedIncidents := pruneServerCheck(&serverCheck, options, now)
+			if prunedHistory > 0 || prunedIncidents > 0 {
+				checksData.HealthChecks[name] = serverCheck
+			}
+			history += prunedHistory
+			incidents += prunedIncidents
+		}
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to save pruned checks data: %v", err)
+		return
+	}
+
+	if history > 0 || incidents > 0 {
+		log.Printf("[INFO] Pruned %d check results and %d incidents", history, incidents)
+	}
+}
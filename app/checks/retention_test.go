@@ -0,0 +1,98 @@
+package checks
+
+import (
+	"testing"
+	"time"
+)
+
+func historyAt(now time.Time, ages ...time.Duration) []CheckResult {
+	var history []CheckResult
+	for _, age := range ages {
+		history = append(history, CheckResult{Time: now.Add(-age), Status: StatusOk})
+	}
+	return history
+}
+
+func incidentsAt(now time.Time, ages ...time.Duration) []Incident {
+	var incidents []Incident
+	for _, age := range ages {
+		incidents = append(incidents, Incident{Start: now.Add(-age), End: now.Add(-age + time.Minute)})
+	}
+	return incidents
+}
+
+func TestPruneServerCheckByCount(t *testing.T) {
+	var now = time.Now()
+	var serverCheck = ServerCheck{
+		History:       historyAt(now, 4*time.Hour, 3*time.Hour, 2*time.Hour, time.Hour),
+		PastIncidents: incidentsAt(now, 3*time.Hour, 2*time.Hour, time.Hour),
+	}
+	var options = Options{HistoryRetention: Retention{Count: 2}, IncidentRetention: Retention{Count: 1}}
+
+	history, incidents := pruneServerCheck(&serverCheck, options, now)
+	if history != 2 || incidents != 2 {
+		t.Fatalf("pruned %d results and %d incidents, want 2 and 2", history, incidents)
+	}
+	if len(serverCheck.History) != 2 || !serverCheck.History[0].Time.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("history %v, want the last 2 results", serverCheck.History)
+	}
+	if !serverCheck.HistoryRetainedSince.Equal(now.Add(-2 * time.Hour)) {
+		t.Errorf("history retained since %v, want the first kept result", serverCheck.HistoryRetainedSince)
+	}
+	if len(serverCheck.PastIncidents) != 1 || !serverCheck.PastIncidents[0].Start.Equal(now.Add(-time.Hour)) {
+		t.Errorf("incidents %v, want the last one", serverCheck.PastIncidents)
+	}
+	if !serverCheck.IncidentsRetainedSince.Equal(now.Add(-2*time.Hour + time.Minute)) {
+		t.Errorf("incidents retained since %v, want the end of the last pruned one", serverCheck.IncidentsRetainedSince)
+	}
+}
+
+func TestPruneServerCheckByAge(t *testing.T) {
+	var now = time.Now()
+	var serverCheck = ServerCheck{
+		History:       historyAt(now, 3*time.Hour, 2*time.Hour, 30*time.Minute),
+		PastIncidents: incidentsAt(now, 3*time.Hour, 30*time.Minute),
+	}
+	var options = Options{HistoryRetention: Retention{Age: time.Hour}, IncidentRetention: Retention{Age: time.Hour}}
+
+	history, incidents := pruneServerCheck(&serverCheck, options, now)
+	if history != 2 || incidents != 1 {
+		t.Fatalf("pruned %d results and %d incidents, want 2 and 1", history, incidents)
+	}
+	if len(serverCheck.History) != 1 || len(serverCheck.PastIncidents) != 1 {
+		t.Errorf("kept %d results and %d incidents, want 1 and 1", len(serverCheck.History), len(serverCheck.PastIncidents))
+	}
+}
+
+func TestPruneServerCheckAllPruned(t *testing.T) {
+	var now = time.Now()
+	var serverCheck = ServerCheck{
+		History:       historyAt(now, 3*time.Hour, 2*time.Hour),
+		PastIncidents: incidentsAt(now, 3*time.Hour, 2*time.Hour),
+	}
+	var options = Options{HistoryRetention: Retention{Age: time.Hour}, IncidentRetention: Retention{Age: time.Hour}}
+
+	history, incidents := pruneServerCheck(&serverCheck, options, now)
+	if history != 2 || incidents != 2 {
+		t.Fatalf("pruned %d results and %d incidents, want 2 and 2", history, incidents)
+	}
+	if serverCheck.History != nil || len(serverCheck.PastIncidents) != 0 {
+		t.Errorf("kept %d results and %d incidents, want none", len(serverCheck.History), len(serverCheck.PastIncidents))
+	}
+	if !serverCheck.HistoryRetainedSince.Equal(now.Add(-2 * time.Hour)) {
+		t.Errorf("history retained since %v, want the last pruned result", serverCheck.HistoryRetainedSince)
+	}
+}
+
+func TestPruneServerCheckNothingToPrune(t *testing.T) {
+	var now = time.Now()
+	var serverCheck = ServerCheck{History: historyAt(now, time.Minute)}
+	var options = Options{HistoryRetention: Retention{Count: 10}, IncidentRetention: Retention{Count: 10}}
+
+	if history, incidents := pruneServerCheck(&serverCheck, options, now); history != 0 || incidents != 0 {
+		t.Errorf("pruned %d results and %d incidents, want none", history, incidents)
+	}
+	if len(serverCheck.History) != 1 || !serverCheck.HistoryRetainedSince.IsZero() {
+		t.Errorf("history changed: %v since %v", serverCheck.History, serverCheck.HistoryRetainedSince)
+	}
+}
@@ -0,0 +1,77 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// MaxRetries is the maximum number of retries of a check, MaxRetryDelay is the longest delay between them
+const MaxRetries = 5
+const MaxRetryDelay = 30 * time.Second
+
+// DefaultRetryDelay is the delay between retries if it is not set
+const DefaultRetryDelay = time.Second
+
+// CheckDeadline limits all attempts of a check of a server with retries, main sets it from the flag
+var CheckDeadline = 30 * time.Second
+
+// transientError returns true if the request failed on a timeout or a dropped connection,
+// responses with error status codes are not transient
+func transientError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout() ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// requestWithRetries requests the server and retries transient failures up to the retries of the server,
+// all attempts are made within CheckDeadline. Response time is the time of the last attempt.
+func requestWithRetries(ctx context.Context, client *http.Client, serverCheck ServerCheck) (serverResponse, time.Duration, error) {
+	if serverCheck.Retries == 0 {
+		var start = time.Now()
+		response, err := requestServer(ctx, client, serverCheck)
+		return response, time.Since(start), err
+	}
+
+	var delay = serverCheck.RetryDelay
+	if delay == 0 {
+		delay = DefaultRetryDelay
+	}
+	ctx, cancel := context.WithTimeout(ctx, CheckDeadline)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		var start = time.Now()
+		response, err := requestServer(ctx, client, serverCheck)
+		if attempt > 1 {
+			response.attempts = attempt
+		}
+		if err == nil || attempt > serverCheck.Retries || !transientError(err) || ctx.Err() != nil {
+			return response, time.Since(start), err
+		}
+
+		var timer = time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return response, time.Since(start), err
+		case <-timer.C:
+		}
+	}
+}
+
+// SetRetries sets the number of retries of transient failures within a check of the server and the delay
+// between them, zero retries disable them
+func SetRetries(name string, retries int, delay time.Duration) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.Retries = retries
+		serverCheck.RetryDelay = delay
+		if retries == 0 {
+			serverCheck.RetryDelay = 0
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flakyServer drops the connection of the first drops requests and responds with the status to the next ones,
+// it returns the url and the number of requests made
+func flakyServer(t *testing.T, drops int32, status int) (string, *atomic.Int32) {
+	var requests atomic.Int32
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= drops {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, &requests
+}
+
+func TestRetries(t *testing.T) {
+	var tests = []struct {
+		name         string
+		drops        int32
+		status       int
+		retries      int
+		wantOk       bool
+		wantRequests int32
+		wantAttempts int
+	}{
+		{"dropped connection without retries", 1, http.StatusOK, 0, false, 1, 0},
+		{"dropped connection retried", 2, http.StatusOK, 2, true, 3, 3},
+		{"no drops", 0, http.StatusOK, 2, true, 1, 0},
+		{"retries exhausted", 10, http.StatusOK, 2, false, 3, 3},
+		{"5xx not retried", 0, http.StatusServiceUnavailable, 3, false, 1, 0},
+		{"4xx not retried", 0, http.StatusNotFound, 3, false, 1, 0},
+		{"5xx after a retry", 1, http.StatusInternalServerError, 3, false, 2, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serverUrl, requests := flakyServer(t, test.drops, test.status)
+			useStorage(t, ServerCheck{Name: "web", Url: serverUrl, IsOk: true, Retries: test.retries, RetryDelay: time.Millisecond})
+
+			checked, result, err := RecheckServer("web")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok := result.Status != StatusFailed; ok != test.wantOk {
+				t.Errorf("got status %v, error %q, want ok %v", result.Status, result.Error, test.wantOk)
+			}
+			if got := requests.Load(); got != test.wantRequests {
+				t.Errorf("got %d requests, want %d", got, test.wantRequests)
+			}
+			if result.Attempts != test.wantAttempts {
+				t.Errorf("got %d attempts on the result, want %d", result.Attempts, test.wantAttempts)
+			}
+			// the check is counted once in the history regardless of attempts
+			if len(checked.History) != 1 || checked.History[0].Attempts != test.wantAttempts {
+				t.Errorf("got history %+v, want one result", checked.History)
+			}
+		})
+	}
+}
+
+func TestRetriesWithinCheckDeadline(t *testing.T) {
+	var tests = []struct {
+		name  string
+		delay time.Duration
+		hang  bool
+	}{
+		// every attempt hangs until the deadline ends the check
+		{"hanging server", time.Millisecond, true},
+		// the delay before the next attempt is cut by the deadline
+		{"long retry delay", 10 * time.Second, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests atomic.Int32
+			var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if test.hang {
+					select {
+					case <-time.After(5 * time.Second):
+					case <-r.Context().Done():
+					}
+					return
+				}
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+			}))
+			defer server.Close()
+			var checkDeadline = CheckDeadline
+			CheckDeadline = 200 * time.Millisecond
+			t.Cleanup(func() { CheckDeadline = checkDeadline })
+			useStorage(t, ServerCheck{Name: "web", Url: server.URL, IsOk: true, Retries: MaxRetries, RetryDelay: test.delay})
+
+			var start = time.Now()
+			_, result, err := RecheckServer("web")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("check took %v, want it to end at CheckDeadline", elapsed)
+			}
+			if result.Status != StatusFailed {
+				t.Errorf("got status %v, want failed", result.Status)
+			}
+			if got := requests.Load(); got != 1 {
+				t.Errorf("got %d requests, want only one within the deadline", got)
+			}
+		})
+	}
+}
+
+func TestTransientError(t *testing.T) {
+	var tests = []struct {
+		err  error
+		want bool
+	}{
+		{io.EOF, true},
+		{io.ErrUnexpectedEOF, true},
+		{fmt.Errorf("read tcp: %w", syscall.ECONNRESET), true},
+		{context.DeadlineExceeded, true},
+		{fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED), false},
+		{errors.New("unexpected status code 503"), false},
+	}
+
+	for _, test := range tests {
+		if got := transientError(test.err); got != test.want {
+			t.Errorf("transientError(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultSchedule is the schedule of servers not assigned to a named one, it runs on the checks cron
+const DefaultSchedule = "default"
+
+var ErrScheduleNotExists = errors.New("schedule not exists")
+
+// Schedules are named cron specs servers can be assigned to, main sets them from flags
+var Schedules = map[string]string{}
+
+// ParseSchedule parses a named schedule like "fast=*/15 * * * * *", the spec is not validated
+func ParseSchedule(value string) (name string, spec string, err error) {
+	name, spec, ok := strings.Cut(value, "=")
+	name, spec = strings.TrimSpace(name), strings.TrimSpace(spec)
+	if !ok || spec == "" {
+		return "", "", fmt.Errorf("schedule %q must be like name=spec", value)
+	}
+	if err := ValidateName(name); err != nil {
+		return "", "", fmt.Errorf("invalid schedule name %q: %w", name, err)
+	}
+	return name, spec, nil
+}
+
+// ScheduleNames returns names of the schedules sorted, the default schedule first
+func ScheduleNames() []string {
+	var names []string
+	for name := range Schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return slices.Insert(names, 0, DefaultSchedule)
+}
+
+// ScheduleName returns the schedule the server is checked on,
+// servers assigned to a schedule which is not configured anymore are checked on the default one
+func (s ServerCheck) ScheduleName() string {
+	if _, ok := Schedules[s.Schedule]; ok {
+		return s.Schedule
+	}
+	return DefaultSchedule
+}
+
+// SetSchedule assigns the server to the schedule, the default schedule removes the assignment.
+// ErrScheduleNotExists is returned if the schedule is not configured.
+func SetSchedule(name string, schedule string) error {
+	if _, ok := Schedules[schedule]; !ok && schedule != DefaultSchedule {
+		return ErrScheduleNotExists
+	}
+	if schedule == DefaultSchedule {
+		schedule = ""
+	}
+
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.Schedule = schedule
+	})
+}
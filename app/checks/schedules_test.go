@@ -0,0 +1,113 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// useSchedules sets the configured schedules for the test
+func useSchedules(t *testing.T, schedules map[string]string) {
+	var configured = Schedules
+	Schedules = schedules
+	t.Cleanup(func() { Schedules = configured })
+}
+
+func TestParseSchedule(t *testing.T) {
+	var tests = []struct {
+		value    string
+		wantName string
+		wantSpec string
+		wantErr  bool
+	}{
+		{"fast=*/15 * * * * *", "fast", "*/15 * * * * *", false},
+		{" fast = @every 1m ", "fast", "@every 1m", false},
+		{"fast", "", "", true},
+		{"fast=", "", "", true},
+		{"=@every 1m", "", "", true},
+		{"fa/st=@every 1m", "", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			name, spec, err := ParseSchedule(test.value)
+			if name != test.wantName || spec != test.wantSpec || (err != nil) != test.wantErr {
+				t.Errorf("got %q, %q, %v, want %q, %q and error %v", name, spec, err, test.wantName, test.wantSpec, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetSchedule(t *testing.T) {
+	useSchedules(t, map[string]string{"fast": "@every 15s"})
+
+	var tests = []struct {
+		name         string
+		schedule     string
+		wantErr      error
+		wantSchedule string
+	}{
+		{"configured", "fast", nil, "fast"},
+		{"default removes the assignment", DefaultSchedule, nil, ""},
+		{"not configured", "slow", ErrScheduleNotExists, "fast"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useStorage(t, ServerCheck{Name: "web", Url: "https://example.com", Schedule: "fast"})
+
+			if err := SetSchedule("web", test.schedule); !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v, want %v", err, test.wantErr)
+			}
+			if schedule := ReadChecksData().HealthChecks["web"].Schedule; schedule != test.wantSchedule {
+				t.Errorf("got schedule %q, want %q", schedule, test.wantSchedule)
+			}
+		})
+	}
+}
+
+func TestPerformCheckOfSchedule(t *testing.T) {
+	useSchedules(t, map[string]string{"fast": "@every 15s"})
+
+	var mutex sync.Mutex
+	var checked []string
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		checked = append(checked, r.URL.Path[1:])
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		schedule    string
+		wantChecked []string
+	}{
+		{"fast", []string{"fast"}},
+		// servers of a schedule which is not configured anymore fall back to the default one
+		{"", []string{"default", "removed"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.schedule, func(t *testing.T) {
+			useStorage(t,
+				ServerCheck{Name: "fast", Url: server.URL + "/fast", IsOk: true, Schedule: "fast"},
+				ServerCheck{Name: "default", Url: server.URL + "/default", IsOk: true},
+				ServerCheck{Name: "removed", Url: server.URL + "/removed", IsOk: true, Schedule: "removed"},
+			)
+			checked = nil
+
+			PerformCheck(context.Background(), Options{AlertThreshold: 1, Notifier: &testNotifier{}, Schedule: test.schedule})
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			slices.Sort(checked)
+			if !slices.Equal(checked, test.wantChecked) {
+				t.Errorf("got checked %v, want %v", checked, test.wantChecked)
+			}
+		})
+	}
+}
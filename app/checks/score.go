@@ -0,0 +1,158 @@
+package checks
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+)
+
+// ScoreWeights are weights of the health score components and the latency threshold, main sets them from flags
+type ScoreWeights struct {
+	Availability float64
+	Latency      float64
+	Ssl          float64
+	Incidents    float64
+
+	LatencyThreshold time.Duration
+}
+
+var Scoring = ScoreWeights{Availability: 40, Latency: 20, Ssl: 20, Incidents: 20, LatencyThreshold: time.Second}
+
+// Validate returns an error if a weight is negative, all weights are zero or the latency threshold is not positive
+func (w ScoreWeights) Validate() error {
+	if w.Availability < 0 || w.Latency < 0 || w.Ssl < 0 || w.Incidents < 0 {
+		return errors.New("weights must not be negative")
+	}
+	if w.Availability+w.Latency+w.Ssl+w.Incidents == 0 {
+		return errors.New("at least one weight must be positive")
+	}
+	if w.LatencyThreshold <= 0 {
+		return errors.New("latency threshold must be positive")
+	}
+	return nil
+}
+
+// bounds of the score components, each component is linear between its bounds
+const (
+	// availability over the last 24 hours scores 0 at 95% and below
+	scoreMinAvailability = 95.0
+	// certificates expiring in 30 days or later score full
+	scoreSslDays = 30.0
+	// 4 or more incidents started in the last 7 days score 0
+	scoreIncidents       = 4.0
+	scoreIncidentsWindow = 7 * 24 * time.Hour
+)
+
+// scoreSampleInterval is the interval of score samples kept to compare the score with a day ago
+const scoreSampleInterval = time.Hour
+
+// ScoreSample is the health score of the server at the time
+type ScoreSample struct {
+	Time  time.Time `json:"time"`
+	Score int       `json:"score"`
+}
+
+// HealthScore returns a score from 0 to 100 of the server at the time, lower is more worrying. It is the weighted mean of:
+//   - availability over the last 24 hours, 100% scores full and 95% or less scores 0
+//   - average response time over the last 24 hours, up to LatencyThreshold scores full and twice of it or more scores 0
+//   - days until the certificate expires, 30 or more scores full and an expired certificate scores 0
+//   - incidents started over the last 7 days including the open one, none scores full and 4 or more score 0
+//
+// Components without data, like the certificate of a plain HTTP server, are left out of the mean.
+// False is returned if the server has no checks in the last 24 hours.
+func HealthScore(serverCheck ServerCheck, weights ScoreWeights, now time.Time) (int, bool) {
+	var history = HistorySince(serverCheck.History, now.Add(-24*time.Hour))
+	availability, ok := Availability(history)
+	if !ok {
+		return 0, false
+	}
+
+	var total, weightSum float64
+	var add = func(weight float64, value float64) {
+		total += weight * math.Max(0, math.Min(1, value))
+		weightSum += weight
+	}
+
+	add(weights.Availability, (availability-scoreMinAvailability)/(100-scoreMinAvailability))
+
+	if _, avg, _, count := ResponseTimeStats(history); count > 0 {
+		add(weights.Latency, 2-avg.Seconds()/weights.LatencyThreshold.Seconds())
+	}
+
+	if !serverCheck.SslExpiry.IsZero() {
+		add(weights.Ssl, serverCheck.SslExpiry.Sub(now).Hours()/24/scoreSslDays)
+	}
+
+	add(weights.Incidents, 1-float64(incidentsSince(serverCheck, now.Add(-scoreIncidentsWindow)))/scoreIncidents)
+
+	if weightSum == 0 {
+		return 0, false
+	}
+	return int(math.Round(total * 100 / weightSum)), true
+}
+
+// incidentsSince returns the number of incidents started since the time including the open one
+func incidentsSince(serverCheck ServerCheck, since time.Time) int {
+	var count int
+	for _, incident := range serverCheck.PastIncidents {
+		if !incident.Start.Before(since) {
+			count++
+		}
+	}
+	if serverCheck.Incident != nil && !serverCheck.Incident.Simulated && !serverCheck.Incident.Start.Before(since) {
+		count++
+	}
+	return count
+}
+
+// ScoreChange returns the difference of the current score and the score a day ago,
+// false if the server was not scored a day ago
+func (s ServerCheck) ScoreChange(now time.Time) (int, bool) {
+	if len(s.ScoreSamples) == 0 || s.ScoredAt.IsZero() {
+		return 0, false
+	}
+
+	// the oldest sample is kept up to a sample interval longer than a day
+	var oldest = s.ScoreSamples[0]
+	if oldest.Time.After(now.Add(-24*time.Hour + scoreSampleInterval)) {
+		return 0, false
+	}
+	return s.Score - oldest.Score, true
+}
+
+// setScore sets the score of the server and records a sample each hour, samples older than a day are removed
+func setScore(serverCheck *ServerCheck, score int, now time.Time) {
+	serverCheck.Score, serverCheck.ScoredAt = score, now
+
+	var samples = serverCheck.ScoreSamples
+	if len(samples) == 0 || now.Sub(samples[len(samples)-1].Time) >= scoreSampleInterval {
+		samples = append(samples, ScoreSample{Time: now, Score: score})
+	}
+	// one sample older than a day is kept to compare with
+	for len(samples) > 1 && !samples[1].Time.After(now.Add(-24*time.Hour)) {
+		samples = samples[1:]
+	}
+	serverCheck.ScoreSamples = samples
+}
+
+// updateScores recomputes health scores of the servers after the check cycle, paused servers keep their score
+func updateScores(now time.Time) {
+	err := UpdateChecksData(func(checksData *Data) {
+		for name, serverCheck := range checksData.HealthChecks {
+			if serverCheck.Paused {
+				continue
+			}
+
+			score, ok := HealthScore(serverCheck, Scoring, now)
+			if !ok {
+				continue
+			}
+			setScore(&serverCheck, score, now)
+			checksData.HealthChecks[name] = serverCheck
+		}
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to save health scores: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package checks
+
+import (
+	"testing"
+	"time"
+)
+
+// scoreHistory returns a day of checks every 30 minutes before the time, every failEvery-th check fails
+func scoreHistory(now time.Time, responseTime time.Duration, failEvery int) []CheckResult {
+	var history []CheckResult
+	for i := 48; i > 0; i-- {
+		var result = CheckResult{Time: now.Add(-time.Duration(i) * 30 * time.Minute), Status: StatusOk, ResponseTime: responseTime}
+		if failEvery > 0 && i%failEvery == 0 {
+			result = CheckResult{Time: result.Time, Status: StatusFailed, Error: "connection refused"}
+		}
+		history = append(history, result)
+	}
+	return history
+}
+
+func TestHealthScore(t *testing.T) {
+	var now = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	var days = func(n float64) time.Time { return now.Add(time.Duration(n * 24 * float64(time.Hour))) }
+
+	var tests = []struct {
+		name   string
+		server ServerCheck
+		want   int
+	}{
+		{"perfect server", ServerCheck{
+			History:   scoreHistory(now, 200*time.Millisecond, 0),
+			SslExpiry: days(90),
+			// incidents older than a week and simulated ones are not counted
+			PastIncidents: []Incident{{Start: days(-10), End: days(-10)}},
+			Incident:      &Incident{Start: now.Add(-time.Hour), Simulated: true},
+		}, 100},
+		// latency of 1.5s scores half: 40 + 20*0.5 + 20 + 20
+		{"slow server", ServerCheck{
+			History: scoreHistory(now, 1500*time.Millisecond, 0), SslExpiry: days(90),
+		}, 90},
+		{"server slower than twice the threshold", ServerCheck{
+			History: scoreHistory(now, 3*time.Second, 0), SslExpiry: days(90),
+		}, 80},
+		// 75% availability and 4 incidents in a week score 0: 0 + 20 + 20 + 0
+		{"flapping server", ServerCheck{
+			History:   scoreHistory(now, 200*time.Millisecond, 4),
+			SslExpiry: days(90),
+			PastIncidents: []Incident{
+				{Start: days(-6), End: days(-6)}, {Start: days(-3), End: days(-3)}, {Start: days(-1), End: days(-1)},
+			},
+			Incident: &Incident{Start: now.Add(-30 * time.Minute)},
+		}, 40},
+		// one incident of 4 in a week: 40 + 20 + 20 + 20*0.75
+		{"server with one incident", ServerCheck{
+			History:       scoreHistory(now, 200*time.Millisecond, 0),
+			SslExpiry:     days(90),
+			PastIncidents: []Incident{{Start: days(-2), End: days(-2)}},
+		}, 95},
+		// one failed check of 48 is 97.9% availability, 58% of the component: 40*0.58 + 20 + 20 + 20
+		{"server with a failed check", ServerCheck{
+			History: scoreHistory(now, 200*time.Millisecond, 48), SslExpiry: days(90),
+		}, 83},
+		// 6 days of 30 score 0.2: 40 + 20 + 20*0.2 + 20
+		{"expiring certificate", ServerCheck{
+			History: scoreHistory(now, 200*time.Millisecond, 0), SslExpiry: days(6),
+		}, 84},
+		{"expired certificate", ServerCheck{
+			History: scoreHistory(now, 200*time.Millisecond, 0), SslExpiry: days(-1),
+		}, 80},
+		// the certificate is left out of the mean: (40 + 10 + 20) / 80
+		{"slow server without certificate", ServerCheck{
+			History: scoreHistory(now, 1500*time.Millisecond, 0),
+		}, 88},
+		// all checks failed, the latency is left out: (0 + 20 + 20*0.75) / 80
+		{"down server", ServerCheck{
+			History: scoreHistory(now, 0, 1), SslExpiry: days(90), Incident: &Incident{Start: days(-1)},
+		}, 44},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			score, ok := HealthScore(test.server, Scoring, now)
+			if !ok || score != test.want {
+				t.Errorf("got score %d, %v, want %d", score, ok, test.want)
+			}
+		})
+	}
+}
+
+func TestHealthScoreOrder(t *testing.T) {
+	var now = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	var servers = []ServerCheck{
+		{History: scoreHistory(now, 200*time.Millisecond, 4), Incident: &Incident{Start: now.Add(-time.Hour)}},
+		{History: scoreHistory(now, 200*time.Millisecond, 0), SslExpiry: now.Add(3 * 24 * time.Hour)},
+		{History: scoreHistory(now, 1500*time.Millisecond, 0), SslExpiry: now.Add(90 * 24 * time.Hour)},
+		{History: scoreHistory(now, 200*time.Millisecond, 0), SslExpiry: now.Add(90 * 24 * time.Hour)},
+	}
+
+	var previous = -1
+	for i, server := range servers {
+		score, _ := HealthScore(server, Scoring, now)
+		if score <= previous {
+			t.Errorf("server %d scored %d, want more than %d of the more worrying one", i, score, previous)
+		}
+		previous = score
+	}
+}
+
+func TestHealthScoreWithoutRecentChecks(t *testing.T) {
+	var now = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	if _, ok := HealthScore(ServerCheck{}, Scoring, now); ok {
+		t.Error("server without checks was scored")
+	}
+	var old = ServerCheck{History: scoreHistory(now.Add(-48*time.Hour), 200*time.Millisecond, 0)}
+	if _, ok := HealthScore(old, Scoring, now); ok {
+		t.Error("server without checks in the last day was scored")
+	}
+}
+
+func TestHealthScoreWeights(t *testing.T) {
+	var now = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	var server = ServerCheck{History: scoreHistory(now, 200*time.Millisecond, 0), SslExpiry: now.Add(6 * 24 * time.Hour)}
+
+	var sslOnly = ScoreWeights{Ssl: 1, LatencyThreshold: time.Second}
+	if score, _ := HealthScore(server, sslOnly, now); score != 20 {
+		t.Errorf("got score %d with only the certificate weighted, want 20", score)
+	}
+
+	var tests = []struct {
+		weights ScoreWeights
+		wantErr bool
+	}{
+		{Scoring, false},
+		{sslOnly, false},
+		{ScoreWeights{LatencyThreshold: time.Second}, true},
+		{ScoreWeights{Availability: -1, Ssl: 1, LatencyThreshold: time.Second}, true},
+		{ScoreWeights{Availability: 1}, true},
+	}
+	for _, test := range tests {
+		if err := test.weights.Validate(); (err != nil) != test.wantErr {
+			t.Errorf("Validate of %+v returned %v", test.weights, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package checks
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// secretKey encrypts credentials of server urls, id is stored with the ciphertext to find the key on rotation
+type secretKey struct {
+	id       string
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+// secretKeys are set by SetSecretKeys, credentials are stored in plaintext if current is nil
+var secretKeys struct {
+	current *secretKey
+	byId    map[string]*secretKey
+}
+
+// SetSecretKeys sets the key credentials are encrypted with, old keys are only used to decrypt stored credentials.
+// Credentials are stored in plaintext if current is empty.
+func SetSecretKeys(current string, old ...string) error {
+	secretKeys.current = nil
+	secretKeys.byId = map[string]*secretKey{}
+
+	for i, secret := range append([]string{current}, old...) {
+		if secret == "" {
+			continue
+		}
+
+		key, err := newSecretKey(secret)
+		if err != nil {
+			return err
+		}
+		secretKeys.byId[key.id] = key
+		if i == 0 {
+			secretKeys.current = key
+		}
+	}
+
+	return nil
+}
+
+func newSecretKey(secret string) (*secretKey, error) {
+	var derive = func(purpose string) []byte {
+		var sum = sha256.Sum256([]byte(purpose + ":" + secret))
+		return sum[:]
+	}
+
+	block, err := aes.NewCipher(derive("aes"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &secretKey{id: hex.EncodeToString(derive("id"))[:8], aead: aead, nonceKey: derive("nonce")}, nil
+}
+
+// encrypt returns "<key id>:<base64 nonce and ciphertext>". The nonce is derived from the plaintext,
+// so the same credentials are encrypted to the same value and saves of the same data stay byte-identical.
+func (k *secretKey) encrypt(plaintext string) string {
+	var mac = hmac.New(sha256.New, k.nonceKey)
+	mac.Write([]byte(plaintext))
+	var nonce = mac.Sum(nil)[:k.aead.NonceSize()]
+
+	var sealed = k.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return k.id + ":" + base64.StdEncoding.EncodeToString(sealed)
+}
+
+func decryptSecret(value string) (string, error) {
+	id, encoded, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", errors.New("invalid encrypted value")
+	}
+	key, ok := secretKeys.byId[id]
+	if !ok {
+		return "", fmt.Errorf("no secret key with id %s", id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < key.aead.NonceSize() {
+		return "", errors.New("invalid encrypted value")
+	}
+
+	plaintext, err := key.aead.Open(nil, sealed[:key.aead.NonceSize()], sealed[key.aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("wrong secret key with id %s", id)
+	}
+	return string(plaintext), nil
+}
+
+// ReencryptSecrets saves the storage, so credentials decrypted with old keys are encrypted with the current one
+func ReencryptSecrets() error {
+	return UpdateChecksData(func(checksData *Data) {})
+}
+
+// encryptSecrets returns copy of checks data with basic auth of servers moved to encrypted UrlCredentials,
+// checks data is returned as is if there is no secret key
+func encryptSecrets(checksData Data) Data {
+	if secretKeys.current == nil {
+		return checksData
+	}
+
+	var healthChecks = make(map[string]ServerCheck, len(checksData.HealthChecks))
+	for name, serverCheck := range checksData.HealthChecks {
+		if serverCheck.BasicAuth != "" {
+			serverCheck.UrlCredentials = secretKeys.current.encrypt(serverCheck.BasicAuth)
+			serverCheck.BasicAuth = ""
+		}
+		healthChecks[name] = serverCheck
+	}
+	checksData.HealthChecks = healthChecks
+
+	return checksData
+}
+
+// decryptSecrets puts decrypted UrlCredentials to basic auth of servers, user info of urls saved before credentials
+// were stored apart is moved there too. The error lists servers with credentials which can't be decrypted.
+func decryptSecrets(checksData *Data) error {
+	var failed []string
+	for name, serverCheck := range checksData.HealthChecks {
+		if serverCheck.UrlCredentials == "" {
+			moveUrlCredentials(&serverCheck)
+			checksData.HealthChecks[name] = serverCheck
+			continue
+		}
+
+		userInfo, err := decryptSecret(serverCheck.UrlCredentials)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", name, err))
+			continue
+		}
+
+		serverCheck.BasicAuth = userInfo
+		serverCheck.UrlCredentials = ""
+		checksData.HealthChecks[name] = serverCheck
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("can't decrypt credentials of servers, set --secret-key or --old-secret-key "+
+			"to the key they were saved with: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
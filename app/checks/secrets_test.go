@@ -0,0 +1,143 @@
+package checks
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func useSecretKeys(t *testing.T, current string, old ...string) {
+	t.Helper()
+	if err := SetSecretKeys(current, old...); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetSecretKeys("") })
+}
+
+// storedFile returns the storage file as saved
+func storedFile(t *testing.T) string {
+	t.Helper()
+	raw, err := os.ReadFile(storageLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
+
+// reloadStorage makes the next read decode the storage file
+func reloadStorage() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	cache.loaded = false
+}
+
+func TestSecretRoundTrip(t *testing.T) {
+	useSecretKeys(t, "first-key")
+
+	for _, plaintext := range []string{"user:pass", "user", "пользователь:пароль", ""} {
+		var encrypted = secretKeys.current.encrypt(plaintext)
+		if encrypted != secretKeys.current.encrypt(plaintext) {
+			t.Errorf("%q is encrypted to different values", plaintext)
+		}
+		if plaintext != "" && strings.Contains(encrypted, plaintext) {
+			t.Errorf("%q is not encrypted: %s", plaintext, encrypted)
+		}
+
+		decrypted, err := decryptSecret(encrypted)
+		if err != nil || decrypted != plaintext {
+			t.Errorf("decryptSecret(encrypt(%q)) = %q, %v", plaintext, decrypted, err)
+		}
+	}
+}
+
+func TestDecryptSecretErrors(t *testing.T) {
+	useSecretKeys(t, "first-key")
+	var encrypted = secretKeys.current.encrypt("user:pass")
+
+	useSecretKeys(t, "second-key")
+	if _, err := decryptSecret(encrypted); err == nil || !strings.Contains(err.Error(), "no secret key") {
+		t.Errorf("decrypt with another key: %v, want no secret key error", err)
+	}
+
+	// a key with the same id but different material can't open the value
+	var id, _, _ = strings.Cut(encrypted, ":")
+	secretKeys.byId[id] = secretKeys.current
+	if _, err := decryptSecret(encrypted); err == nil || !strings.Contains(err.Error(), "wrong secret key") {
+		t.Errorf("decrypt with a wrong key: %v, want wrong secret key error", err)
+	}
+
+	for _, value := range []string{"no-separator", id + ":not base64!", id + ":AAAA"} {
+		if _, err := decryptSecret(value); err == nil {
+			t.Errorf("decryptSecret(%q) succeeded", value)
+		}
+	}
+}
+
+func TestSaveEncryptsPlaintextCredentials(t *testing.T) {
+	// servers saved without a key keep credentials in plaintext
+	useStorage(t, ServerCheck{Name: "api", Url: "https://example.com", BasicAuth: "user:secretpass"})
+	if !strings.Contains(storedFile(t), "secretpass") {
+		t.Fatal("credentials saved without a key are not in plaintext")
+	}
+
+	useSecretKeys(t, "first-key")
+	reloadStorage()
+	if err := ReencryptSecrets(); err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedFile(t); strings.Contains(stored, "secretpass") {
+		t.Errorf("credentials are saved in plaintext with a key:\n%s", stored)
+	}
+
+	reloadStorage()
+	if basicAuth := ReadChecksData().HealthChecks["api"].BasicAuth; basicAuth != "user:secretpass" {
+		t.Errorf("decrypted credentials %q, want user:secretpass", basicAuth)
+	}
+}
+
+func TestMissingKeyListsServers(t *testing.T) {
+	useSecretKeys(t, "first-key")
+	useStorage(t,
+		ServerCheck{Name: "api", Url: "https://example.com", BasicAuth: "user:pass"},
+		ServerCheck{Name: "web", Url: "https://example.org", BasicAuth: "user:pass"},
+		ServerCheck{Name: "public", Url: "https://example.net"},
+	)
+
+	for _, keys := range [][]string{{""}, {"second-key"}} {
+		useSecretKeys(t, keys[0])
+		_, _, err := decodeChecksData([]byte(storedFile(t)))
+		if err == nil {
+			t.Fatalf("decoded credentials with key %q", keys[0])
+		}
+		if !strings.Contains(err.Error(), "api (") || !strings.Contains(err.Error(), "web (") ||
+			strings.Contains(err.Error(), "public") {
+			t.Errorf("error doesn't list the servers with credentials: %v", err)
+		}
+	}
+}
+
+func TestRotateSecrets(t *testing.T) {
+	useSecretKeys(t, "first-key")
+	useStorage(t, ServerCheck{Name: "api", Url: "https://example.com", BasicAuth: "user:pass"})
+	var firstId = secretKeys.current.id
+
+	// the new key encrypts, the old one decrypts the stored credentials
+	useSecretKeys(t, "second-key", "first-key")
+	reloadStorage()
+	if err := ReencryptSecrets(); err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedFile(t); strings.Contains(stored, firstId+":") || !strings.Contains(stored, secretKeys.current.id+":") {
+		t.Errorf("credentials are not encrypted with the new key:\n%s", stored)
+	}
+
+	// the old key is not needed anymore
+	useSecretKeys(t, "second-key")
+	checksData, _, err := decodeChecksData([]byte(storedFile(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if basicAuth := checksData.HealthChecks["api"].BasicAuth; basicAuth != "user:pass" {
+		t.Errorf("decrypted credentials %q, want user:pass", basicAuth)
+	}
+}
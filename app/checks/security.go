@@ -0,0 +1,67 @@
+package checks
+
+import (
+	"net/http"
+	"strings"
+)
+
+// securityHeaders are the audited headers in order of display,
+// X-Frame-Options passes with frame-ancestors of Content-Security-Policy too
+var securityHeaders = []string{"Strict-Transport-Security", "X-Content-Type-Options", "X-Frame-Options", "Referrer-Policy"}
+
+// SecurityHeaders returns the audited headers in order of display
+func SecurityHeaders() []string {
+	return securityHeaders
+}
+
+// auditSecurityHeaders returns which of the audited headers the response has
+func auditSecurityHeaders(header http.Header) map[string]bool {
+	var csp = strings.ToLower(header.Get("Content-Security-Policy"))
+	return map[string]bool{
+		"Strict-Transport-Security": header.Get("Strict-Transport-Security") != "",
+		"X-Content-Type-Options":    strings.EqualFold(strings.TrimSpace(header.Get("X-Content-Type-Options")), "nosniff"),
+		"X-Frame-Options":           header.Get("X-Frame-Options") != "" || strings.Contains(csp, "frame-ancestors"),
+		"Referrer-Policy":           header.Get("Referrer-Policy") != "",
+	}
+}
+
+// updateSecurityAudit stores the audit of the result and returns headers which were present in the previous audit
+// and are missing now
+func updateSecurityAudit(serverCheck *ServerCheck, result CheckResult) (disappeared []string) {
+	if !serverCheck.SecurityCheck || result.SecurityHeaders == nil {
+		return nil
+	}
+
+	for _, header := range securityHeaders {
+		if serverCheck.SecurityHeaders[header] && !result.SecurityHeaders[header] {
+			disappeared = append(disappeared, header)
+		}
+	}
+	serverCheck.SecurityHeaders = result.SecurityHeaders
+
+	return disappeared
+}
+
+// mergeSecurityHeaders returns headers present in both audits, a nil audit is ignored
+func mergeSecurityHeaders(a map[string]bool, b map[string]bool) map[string]bool {
+	if a == nil || b == nil {
+		if a == nil {
+			return b
+		}
+		return a
+	}
+
+	var merged = make(map[string]bool, len(a))
+	for header, present := range a {
+		merged[header] = present && b[header]
+	}
+	return merged
+}
+
+// SetSecurityCheck switches the security header audit of the server
+func SetSecurityCheck(name string, enabled bool) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.SecurityCheck = enabled
+		serverCheck.SecurityHeaders = nil
+	})
+}
@@ -0,0 +1,63 @@
+package checks
+
+import (
+	"log"
+	"time"
+)
+
+// simulatedError is the error of simulated failed checks
+const simulatedError = "simulated failure"
+
+// Simulate makes the next cycles checks of the server fail without requesting it, 0 cancels the simulation.
+// Alerts, reminders, escalation and recovery run as for a real failure, availability statistics are not changed.
+func Simulate(name string, cycles int) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.SimulatedCycles = cycles
+	})
+}
+
+// simulatedResult returns the failed result of the simulated check
+func simulatedResult() CheckResult {
+	return CheckResult{Time: time.Now(), Status: StatusFailed, Error: simulatedError, Simulated: true}
+}
+
+// setSimulatedResult sets status of the server failing in simulation, history, uptime and downtime are not recorded
+func setSimulatedResult(serverCheck *ServerCheck, result CheckResult) {
+	serverCheck.IsOk = false
+	if serverCheck.FailingSince.IsZero() {
+		serverCheck.FailingSince = result.Time
+		serverCheck.SimulatedFailure = true
+	}
+}
+
+// countSimulatedCycle counts the check cycle of the simulation, manual checks are not counted
+func countSimulatedCycle(serverCheck *ServerCheck) {
+	if serverCheck.SimulatedCycles == 0 {
+		return
+	}
+
+	serverCheck.SimulatedCycles--
+	if serverCheck.SimulatedCycles == 0 {
+		log.Printf("[INFO] Simulated failure of server %s ended", serverCheck.Name)
+	}
+}
+
+// endSimulatedFailure forgets the failure started by simulation on the first real check result,
+// so its time is not recorded as downtime. If the server really fails, the open incident becomes real.
+func endSimulatedFailure(serverCheck *ServerCheck, result CheckResult) {
+	if !serverCheck.SimulatedFailure {
+		return
+	}
+
+	serverCheck.FailingSince = time.Time{}
+	serverCheck.SimulatedFailure = false
+	if serverCheck.Incident != nil && result.Status == StatusFailed {
+		serverCheck.Incident.Simulated = false
+		serverCheck.Incident.Start = result.Time
+	}
+}
+
+// IsSimulated returns true if the server fails in simulation or the failure was started by simulation
+func (s ServerCheck) IsSimulated() bool {
+	return s.SimulatedCycles > 0 || s.SimulatedFailure
+}
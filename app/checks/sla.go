@@ -0,0 +1,109 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"log"
+	"strconv"
+	"time"
+)
+
+// DefaultSlaWindow is the SLA window of servers set without a window
+const DefaultSlaWindow = 30 * 24 * time.Hour
+
+// maxSlaWindow is the longest SLA window, daily uptime is not kept longer
+const maxSlaWindow = dailyUptimeDays * 24 * time.Hour
+
+// ParseSlaWindow parses the SLA window like "30d" or "12h"
+func ParseSlaWindow(value string) (time.Duration, error) {
+	window, err := parseAge(value)
+	if err != nil || window > maxSlaWindow {
+		return 0, fmt.Errorf("SLA window must be a duration like 12h or 30d up to %dd: %s", dailyUptimeDays, value)
+	}
+	return window, nil
+}
+
+// SlaAvailability returns availability of the server over its SLA window, ok is false if there are no checks.
+// Windows of whole days are counted by daily uptime, so they are not limited by the history retention.
+func (s ServerCheck) SlaAvailability(now time.Time, location *time.Location) (availability float64, ok bool) {
+	const day = 24 * time.Hour
+	if s.SlaWindow%day != 0 {
+		return Availability(HistorySince(s.History, now.Add(-s.SlaWindow)))
+	}
+
+	var checks, successes int
+	for _, uptime := range s.UptimeDays(now, location, int(s.SlaWindow/day)) {
+		checks += uptime.Checks
+		successes += uptime.Successes
+	}
+	if checks == 0 {
+		return 0, false
+	}
+	return float64(successes) * 100 / float64(checks), true
+}
+
+// ErrorBudgetLeft returns percentage of the error budget of the target left at the availability,
+// it is negative when the budget is overspent
+func ErrorBudgetLeft(availability float64, target float64) float64 {
+	return 100 - (100-availability)*100/(100-target)
+}
+
+// checkSlas sends a warning when availability of a server drops below its SLA target
+// and a recovery note when it is back, the breach state is stored so restarts don't repeat them
+func checkSlas(options Options, location *time.Location) {
+	var now = time.Now()
+	for _, serverCheck := range ReadChecksData().HealthChecks {
+		if serverCheck.SlaTarget == 0 || serverCheck.Paused {
+			continue
+		}
+
+		availability, ok := serverCheck.SlaAvailability(now, location)
+		if !ok || availability < serverCheck.SlaTarget == serverCheck.SlaBreached {
+			continue
+		}
+
+		var breached = !serverCheck.SlaBreached
+		err := UpdateServerCheck(serverCheck.Name, func(storedCheck *ServerCheck) {
+			storedCheck.SlaBreached = breached
+		})
+		if err != nil {
+			log.Printf("[ERROR] Error while saving checks data: %v", err)
+			continue
+		}
+
+		var eventType = notify.EventSlaRecovered
+		if breached {
+			eventType = notify.EventSlaBreach
+		}
+		log.Printf("[INFO] Server %s availability %.3f%%, SLA target %v%%", serverCheck.Url, availability, serverCheck.SlaTarget)
+
+		sendEvent(options, notify.Event{
+			Type:         eventType,
+			Server:       serverCheck.Name,
+			Url:          redact.Url(serverCheck.Url),
+			Time:         now,
+			Availability: availability,
+			SlaTarget:    serverCheck.SlaTarget,
+			SlaWindow:    serverCheck.SlaWindow,
+			ErrorBudget:  ErrorBudgetLeft(availability, serverCheck.SlaTarget),
+		})
+	}
+}
+
+// SetSla sets the availability target of the server in percent over the window, zero target removes it
+func SetSla(name string, target float64, window time.Duration) error {
+	if target < 0 || target >= 100 {
+		return errors.New("SLA target must be between 0 and 100: " + strconv.FormatFloat(target, 'f', -1, 64))
+	}
+
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.SlaTarget = target
+		serverCheck.SlaWindow = window
+		serverCheck.SlaBreached = false
+		if target == 0 {
+			serverCheck.SlaWindow = 0
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package checks
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// maxBodySize is the number of body bytes read for soft 404 detection
+const maxBodySize = 256 * 1024
+
+// soft404Signatures are case-insensitive patterns of error pages served with status 200
+var soft404Signatures = compileSignatures(
+	`404 not found`,
+	`page not found`,
+	`application error`,
+	`something went wrong`,
+	`internal server error`,
+	`default backend - 404`,
+	`service unavailable`,
+	`traceback \(most recent call last\)`,
+	`exception in thread "`,
+	`at [\w$.]+\([\w$]+\.java:\d+\)`,
+	`stack trace:`,
+)
+
+func compileSignatures(patterns ...string) []*regexp.Regexp {
+	var signatures []*regexp.Regexp
+	for _, pattern := range patterns {
+		signatures = append(signatures, regexp.MustCompile(`(?i)`+pattern))
+	}
+	return signatures
+}
+
+// LoadSoft404Signatures adds signatures from the file to the built-in ones, each line is a regular expression
+// matched case-insensitively, empty lines and lines starting with # are skipped
+func LoadSoft404Signatures(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var scanner = bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var pattern = strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		signature, err := regexp.Compile(`(?i)` + pattern)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		soft404Signatures = append(soft404Signatures, signature)
+	}
+
+	return scanner.Err()
+}
+
+// matchSoft404 returns the text of the body matched by the first signature, empty if the body doesn't match
+func matchSoft404(body []byte) string {
+	for _, signature := range soft404Signatures {
+		if match := signature.Find(body); match != nil {
+			return string(match)
+		}
+	}
+	return ""
+}
+
+// SetSoft404 switches soft 404 detection of the server
+func SetSoft404(name string, enabled bool) error {
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.Soft404 = enabled
+	})
+}
@@ -0,0 +1,105 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sshScheme is the scheme of urls of SSH checks, like ssh://example.com:22
+const sshScheme = "ssh://"
+
+// maxBannerLines is the number of lines the SSH server may send before its identification, maxBannerSize limits
+// each line as RFC 4253 does
+const maxBannerLines = 20
+const maxBannerSize = 255
+
+// errMalformedBanner is returned when the server doesn't send the SSH identification
+var errMalformedBanner = errors.New("malformed SSH banner")
+
+// SshUrl returns the url of the SSH check of host:port, an error if the port is missing or invalid
+func SshUrl(hostPort string) (string, error) {
+	return hostPortUrl(sshScheme, hostPort)
+}
+
+// IsSsh returns true if the server is checked by its SSH banner
+func (s ServerCheck) IsSsh() bool {
+	return s.Type == CheckSsh
+}
+
+// checkSsh connects to host:port of the server and reads its SSH identification banner, like SSH-2.0-OpenSSH_9.3.
+// No authentication is attempted. The check fails if the banner is not received in time, is malformed
+// or doesn't contain the expected banner of the server. Response time is the time until the banner is received.
+func checkSsh(ctx context.Context, serverCheck ServerCheck) CheckResult {
+	var start = time.Now()
+	var failed = func(err error) CheckResult {
+		return CheckResult{Time: start, ResponseTime: time.Since(start), Status: StatusFailed, Error: err.Error(),
+			Category: errorCategory(err)}
+	}
+
+	parsedUrl, err := url.Parse(asciiUrl(serverCheck.Url))
+	if err != nil {
+		return failed(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", parsedUrl.Host)
+	if err != nil {
+		return failed(err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	banner, err := readSshBanner(conn)
+	if err != nil {
+		return failed(err)
+	}
+
+	var result = CheckResult{Time: start, ResponseTime: time.Since(start), Status: StatusOk, RemoteIp: remoteIp(conn),
+		SshBanner: banner}
+	if serverCheck.ExpectedBanner != "" && !strings.Contains(banner, serverCheck.ExpectedBanner) {
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf("banner %q doesn't contain %q", banner, serverCheck.ExpectedBanner)
+	}
+
+	return result
+}
+
+// readSshBanner returns the identification line of the SSH server, lines sent before it are skipped
+func readSshBanner(conn net.Conn) (string, error) {
+	var reader = bufio.NewReaderSize(conn, maxBannerSize+1)
+	for i := 0; i < maxBannerLines; i++ {
+		line, err := reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return "", fmt.Errorf("%w: line is longer than %d characters", errMalformedBanner, maxBannerSize)
+		}
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("%w: connection closed without identification", errMalformedBanner)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		var banner = strings.TrimRight(string(line), "\r\n")
+		if !strings.HasPrefix(banner, "SSH-") {
+			continue
+		}
+		if !strings.HasPrefix(banner, "SSH-2.0-") && !strings.HasPrefix(banner, "SSH-1.99-") {
+			return "", fmt.Errorf("%w: unsupported version %q", errMalformedBanner, banner)
+		}
+		return banner, nil
+	}
+
+	return "", fmt.Errorf("%w: no identification in the first %d lines", errMalformedBanner, maxBannerLines)
+}
@@ -0,0 +1,172 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckType is the kind of the check of the server
+type CheckType string
+
+const (
+	// CheckHttp requests the url of the server, it is the type of servers without type
+	CheckHttp CheckType = ""
+	// CheckSsl only performs the TLS handshake with host:port of the server, like of SMTP or LDAPS
+	CheckSsl CheckType = "ssl"
+	// CheckSsh reads the SSH identification banner of host:port of the server without authentication
+	CheckSsh CheckType = "ssh"
+)
+
+// STARTTLS protocols of SSL checks, the TLS handshake is performed on the connection upgraded by the protocol
+const (
+	StartTlsSmtp = "smtp"
+	StartTlsImap = "imap"
+)
+
+// StartTlsProtocols are protocols accepted by /addssl starttls=
+var StartTlsProtocols = []string{StartTlsSmtp, StartTlsImap}
+
+// sslScheme is the scheme of urls of SSL checks, like ssl://mail.example.com:465
+const sslScheme = "ssl://"
+
+// dialTimeout limits the connection and the exchange of SSL and SSH checks
+const dialTimeout = 30 * time.Second
+
+// SslUrl returns the url of the SSL check of host:port, an error if the port is missing or invalid
+func SslUrl(hostPort string) (string, error) {
+	return hostPortUrl(sslScheme, hostPort)
+}
+
+// hostPortUrl returns the url of host:port with the scheme, an error if the port is missing or invalid
+func hostPortUrl(scheme string, hostPort string) (string, error) {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return "", err
+	}
+	if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+		return "", fmt.Errorf("invalid port %s", port)
+	}
+	if host == "" {
+		return "", fmt.Errorf("missing host")
+	}
+
+	return scheme + net.JoinHostPort(host, port), nil
+}
+
+// IsHttp returns true if the server is checked by requesting its url
+func (s ServerCheck) IsHttp() bool {
+	return s.Type == CheckHttp
+}
+
+// IsSsl returns true if the server is checked only by the TLS handshake
+func (s ServerCheck) IsSsl() bool {
+	return s.Type == CheckSsl
+}
+
+// checkSsl connects to host:port of the server, upgrades the connection by STARTTLS if the server is set so
+// and performs the TLS handshake. Response time is the time of the handshake, the check fails if the handshake fails,
+// including certificates which are expired or not trusted.
+func checkSsl(ctx context.Context, serverCheck ServerCheck) CheckResult {
+	var start = time.Now()
+	var failed = func(err error) CheckResult {
+		return CheckResult{Time: start, ResponseTime: time.Since(start), Status: StatusFailed, Error: err.Error(),
+			Category: errorCategory(err)}
+	}
+
+	parsedUrl, err := url.Parse(asciiUrl(serverCheck.Url))
+	if err != nil {
+		return failed(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", parsedUrl.Host)
+	if err != nil {
+		return failed(err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var config = &tls.Config{ServerName: parsedUrl.Hostname()}
+	var handshakeStart time.Time
+	var state tls.ConnectionState
+	switch serverCheck.StartTls {
+	case StartTlsSmtp:
+		client, err := smtp.NewClient(conn, parsedUrl.Hostname())
+		if err != nil {
+			return failed(fmt.Errorf("smtp: %w", err))
+		}
+		if err := client.Hello("localhost"); err != nil {
+			return failed(fmt.Errorf("smtp: %w", err))
+		}
+		handshakeStart = time.Now()
+		if err := client.StartTLS(config); err != nil {
+			return failed(fmt.Errorf("smtp starttls: %w", err))
+		}
+		state, _ = client.TLSConnectionState()
+	default:
+		if serverCheck.StartTls == StartTlsImap {
+			if err := imapStartTls(conn); err != nil {
+				return failed(fmt.Errorf("imap starttls: %w", err))
+			}
+		}
+		handshakeStart = time.Now()
+		var tlsConn = tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return failed(err)
+		}
+		state = tlsConn.ConnectionState()
+	}
+
+	var result = CheckResult{Time: start, ResponseTime: time.Since(handshakeStart), Status: StatusOk,
+		RemoteIp: remoteIp(conn)}
+	if len(state.PeerCertificates) > 0 {
+		var certificate = state.PeerCertificates[0]
+		result.SslExpiry, result.Spki = certificate.NotAfter, spkiFingerprint(certificate)
+		result.SslIssuer = certificate.Issuer.String()
+	}
+
+	return result
+}
+
+// imapStartTls reads the greeting of the IMAP server and requests STARTTLS, the connection is ready for the handshake
+func imapStartTls(conn net.Conn) error {
+	var reader = bufio.NewReader(conn)
+	greeting, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(greeting))
+	}
+
+	if _, err := conn.Write([]byte("a1 STARTTLS\r\n")); err != nil {
+		return err
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		// untagged responses like capabilities may come before the tagged one
+		if strings.HasPrefix(line, "* ") {
+			continue
+		}
+		if !strings.HasPrefix(line, "a1 OK") {
+			return fmt.Errorf("unexpected response %q", strings.TrimSpace(line))
+		}
+		return nil
+	}
+}
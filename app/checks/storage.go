@@ -2,59 +2,206 @@ package checks
 
 import (
 	"encoding/json"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	"io"
 	"log"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 )
 
-var mutex sync.Mutex
+var mutex sync.RWMutex
 var storageLocation = "data/checks.json"
 
+// cache is the storage loaded in memory, written through on each save
+// and reloaded when the file is modified outside of the bot
+var cache struct {
+	data    Data
+	loaded  bool
+	modTime time.Time
+	size    int64
+}
+
+// StorageLocation returns the path of the storage file
+func StorageLocation() string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	return storageLocation
+}
+
+// SetStorageLocation sets the path of the storage file and drops the cache, so the file is loaded on the next read.
+// InitStorage must be called after it if the file may not exist.
+func SetStorageLocation(location string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	storageLocation = location
+	cache.loaded = false
+}
+
 func SaveChecksData(checksData Data) error {
 	mutex.Lock()
 	defer mutex.Unlock()
 
-	file, err := os.Create("data/checks.json")
+	return saveChecksData(checksData.clone())
+}
+
+// ReadChecksData returns a copy of the cached storage, changes of the copy are not saved
+func ReadChecksData() Data {
+	mutex.RLock()
+	if cacheFresh() {
+		defer mutex.RUnlock()
+		return cache.data.clone()
+	}
+	mutex.RUnlock()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return readChecksData().clone()
+}
+
+// UpdateChecksData reads checks data, applies update and saves the result while holding the storage lock,
+// so concurrent updates don't overwrite each other
+func UpdateChecksData(update func(checksData *Data)) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var checksData = readChecksData().clone()
+	update(&checksData)
+
+	return saveChecksData(checksData)
+}
+
+// saveChecksData writes checks data and replaces the cache, the cache is kept if the write fails
+func saveChecksData(checksData Data) error {
+	checksData.SchemaVersion = SchemaVersion
+
+	err := writeChecksData(checksData)
+	if err != nil {
+		metrics.StorageWriteErrors.Add(1)
+	}
+	metrics.RecentStorageWrites.Add(err != nil)
+	if err != nil {
+		return err
+	}
+
+	cache.data = checksData
+	cache.loaded = true
+	if info, err := os.Stat(storageLocation); err == nil {
+		cache.modTime, cache.size = info.ModTime(), info.Size()
+	} else {
+		// the file is reloaded on the next read
+		cache.loaded = false
+	}
+
+	return nil
+}
+
+// writeChecksData writes checks data to a temporary file and renames it to the storage,
+// so the storage is never left partially written. The file is indented and encoding/json writes map keys sorted,
+// so saves of the same data are byte-identical.
+func writeChecksData(checksData Data) error {
+	checksData = encryptSecrets(checksData)
+
+	var tmpLocation = storageLocation + ".tmp"
+	file, err := os.Create(tmpLocation)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
 	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
 
 	if err := encoder.Encode(checksData); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpLocation, storageLocation); err != nil {
 		return err
 	}
 
+	metrics.StorageWrites.Add(1)
 	return nil
 }
 
-func ReadChecksData() Data {
-	mutex.Lock()
-	defer mutex.Unlock()
+// readChecksData returns the cached storage, the file is loaded if it was not loaded yet or was modified.
+// It must be called with the write lock held, the result must not be changed.
+func readChecksData() Data {
+	if cacheFresh() {
+		return cache.data
+	}
 
-	file, err := os.Open(storageLocation)
+	info, err := os.Stat(storageLocation)
+	if err != nil {
+		log.Fatalf("[ERROR] failed open checks.json: %v", err)
+	}
+	raw, err := os.ReadFile(storageLocation)
 	if err != nil {
 		log.Fatalf("[ERROR] failed open checks.json: %v", err)
 	}
-	defer file.Close()
 
-	decoder := json.NewDecoder(file)
+	checksData, _, err := decodeChecksData(raw)
+	if err != nil {
+		log.Fatalf("[ERROR] failed decode checks.json: %v", err)
+	}
 
-	var checksData Data
+	if cache.loaded {
+		log.Printf("[INFO] %s was modified outside of the bot, reloaded", storageLocation)
+	}
+	cache.data = checksData
+	cache.loaded = true
+	cache.modTime, cache.size = info.ModTime(), info.Size()
 
-	if err := decoder.Decode(&checksData); err != nil {
-		log.Fatalf("[ERROR] failed decode checks.json: %v", err)
+	return cache.data
+}
+
+// cacheFresh returns true if the cache is loaded and the file was not modified since
+func cacheFresh() bool {
+	if !cache.loaded {
+		return false
 	}
 
-	return checksData
+	info, err := os.Stat(storageLocation)
+	return err == nil && info.ModTime().Equal(cache.modTime) && info.Size() == cache.size
+}
+
+// CheckStorage verifies the storage file is readable and writable without changing it
+func CheckStorage() error {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	file, err := os.OpenFile(storageLocation, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	raw, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	if _, _, err := decodeChecksData(raw); err != nil {
+		return fmt.Errorf("decode %s: %w", storageLocation, err)
+	}
+
+	return nil
 }
 
 func InitStorage() {
-	if _, err := os.Stat(storageLocation); os.IsNotExist(err) {
-		err = os.MkdirAll("data", os.ModePerm)
+	var location = StorageLocation()
+	if _, err := os.Stat(location); os.IsNotExist(err) {
+		err = os.MkdirAll(filepath.Dir(location), os.ModePerm)
 
-		file, err := os.Create(storageLocation)
+		file, err := os.Create(location)
 		if err != nil {
 			log.Fatalf("[ERROR] failed create checks.json: %v", err)
 		}
@@ -66,4 +213,88 @@ func InitStorage() {
 
 		defer file.Close()
 	}
+
+	migrateStorage()
+}
+
+// migrateStorage saves storage of an older schema in the current one, the bot exits if the schema is newer
+func migrateStorage() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	raw, err := os.ReadFile(storageLocation)
+	if err != nil {
+		log.Fatalf("[ERROR] failed open checks.json: %v", err)
+	}
+
+	checksData, version, err := decodeChecksData(raw)
+	if err != nil {
+		log.Fatalf("[ERROR] failed load checks.json: %v", err)
+	}
+	if version == SchemaVersion {
+		return
+	}
+
+	if err := saveChecksData(checksData); err != nil {
+		log.Fatalf("[ERROR] failed save migrated checks.json: %v", err)
+	}
+	log.Printf("[INFO] Storage migrated from schema version %d to %d", version, SchemaVersion)
+}
+
+// clone returns a deep copy of checks data, so copies returned to callers don't share maps and slices with the cache
+func (d Data) clone() Data {
+	var clone = d
+	clone.SuperUsers = slices.Clone(d.SuperUsers)
+	clone.Subscribers = slices.Clone(d.Subscribers)
+	// values of Preferences, Agents and ProbeResults hold no maps or slices, so cloning the maps copies them fully
+	clone.Preferences = maps.Clone(d.Preferences)
+	clone.Agents = maps.Clone(d.Agents)
+	clone.Settings.ChatMigrations = maps.Clone(d.Settings.ChatMigrations)
+	clone.Settings.ChatLanguages = maps.Clone(d.Settings.ChatLanguages)
+	clone.Settings.ChatListModes = maps.Clone(d.Settings.ChatListModes)
+	if d.TagSubscribers != nil {
+		clone.TagSubscribers = make(map[string][]int64, len(d.TagSubscribers))
+		for tag, subscribers := range d.TagSubscribers {
+			clone.TagSubscribers[tag] = slices.Clone(subscribers)
+		}
+	}
+
+	if d.HealthChecks != nil {
+		clone.HealthChecks = make(map[string]ServerCheck, len(d.HealthChecks))
+		for name, serverCheck := range d.HealthChecks {
+			clone.HealthChecks[name] = serverCheck.clone()
+		}
+	}
+
+	return clone
+}
+
+func (s ServerCheck) clone() ServerCheck {
+	var clone = s
+	if s.Incident != nil {
+		var incident = *s.Incident
+		incident.Errors = slices.Clone(s.Incident.Errors)
+		clone.Incident = &incident
+	}
+	clone.PastIncidents = slices.Clone(s.PastIncidents)
+	for i := range clone.PastIncidents {
+		clone.PastIncidents[i].Errors = slices.Clone(s.PastIncidents[i].Errors)
+	}
+	clone.History = slices.Clone(s.History)
+	clone.DailyUptime = slices.Clone(s.DailyUptime)
+	clone.MonthlyDowntime = maps.Clone(s.MonthlyDowntime)
+	clone.IpResults = slices.Clone(s.IpResults)
+	clone.SecurityHeaders = maps.Clone(s.SecurityHeaders)
+	clone.ProbeLocations = slices.Clone(s.ProbeLocations)
+	clone.ProbeResults = maps.Clone(s.ProbeResults)
+	clone.Subscribers = slices.Clone(s.Subscribers)
+	clone.Tags = slices.Clone(s.Tags)
+	clone.JsonChecks = slices.Clone(s.JsonChecks)
+	clone.ExpectedIps = slices.Clone(s.ExpectedIps)
+	clone.Endpoints = slices.Clone(s.Endpoints)
+	clone.EndpointResults = slices.Clone(s.EndpointResults)
+	clone.ScoreSamples = slices.Clone(s.ScoreSamples)
+	clone.RecentErrors = slices.Clone(s.RecentErrors)
+
+	return clone
 }
@@ -0,0 +1,184 @@
+package checks
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// storedServer is a server with every kind of nested data the storage copies
+func storedServer(name string) ServerCheck {
+	return ServerCheck{
+		Name: name, Url: "https://" + name, IsOk: true,
+		Incident:        &Incident{Start: time.Now(), Errors: []string{"timeout"}},
+		PastIncidents:   []Incident{{Start: time.Now().Add(-time.Hour), Errors: []string{"refused"}}},
+		History:         []CheckResult{{Time: time.Now(), Status: StatusOk}},
+		SecurityHeaders: map[string]bool{"Strict-Transport-Security": true},
+		ProbeResults:    map[string]ProbeResult{"eu": {}},
+		Subscribers:     []int64{1},
+		Tags:            []string{"prod"},
+	}
+}
+
+func TestReadChecksDataCopiesNestedData(t *testing.T) {
+	useStorage(t, storedServer("server"))
+
+	var copied = ReadChecksData().HealthChecks["server"]
+	copied.Incident.Errors[0] = "changed"
+	copied.PastIncidents[0].Errors[0] = "changed"
+	copied.History[0].Status = StatusFailed
+	copied.SecurityHeaders["Strict-Transport-Security"] = false
+	copied.ProbeResults["eu"] = ProbeResult{Error: "changed"}
+	copied.Subscribers[0] = 2
+	copied.Tags[0] = "changed"
+
+	var stored = ReadChecksData().HealthChecks["server"]
+	if stored.Incident.Errors[0] != "timeout" || stored.PastIncidents[0].Errors[0] != "refused" {
+		t.Errorf("incident errors changed through a copy: %v, %v", stored.Incident.Errors, stored.PastIncidents[0].Errors)
+	}
+	if stored.History[0].Status != StatusOk || !stored.SecurityHeaders["Strict-Transport-Security"] {
+		t.Errorf("history or headers changed through a copy: %v, %v", stored.History, stored.SecurityHeaders)
+	}
+	if stored.ProbeResults["eu"].Error != "" || stored.Subscribers[0] != 1 {
+		t.Errorf("probe results or subscribers changed through a copy: %v, %v", stored.ProbeResults, stored.Subscribers)
+	}
+	if stored.Tags[0] != "prod" {
+		t.Errorf("tags changed through a copy: %v", stored.Tags)
+	}
+}
+
+// TestStorageConcurrentAccess is meant to be run with -race, readers mutate their copies while updates run
+func TestStorageConcurrentAccess(t *testing.T) {
+	useStorage(t, storedServer("server"))
+
+	var wait sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wait.Add(2)
+		go func(i int) {
+			defer wait.Done()
+			for j := 0; j < 20; j++ {
+				err := UpdateChecksData(func(checksData *Data) {
+					var serverCheck = checksData.HealthChecks["server"]
+					serverCheck.PastIncidents = append(serverCheck.PastIncidents, Incident{Errors: []string{fmt.Sprint(i, j)}})
+					serverCheck.Incident.Errors = append(serverCheck.Incident.Errors, "error")
+					serverCheck.SecurityHeaders[fmt.Sprint(i, j)] = true
+					checksData.HealthChecks["server"] = serverCheck
+				})
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+		go func() {
+			defer wait.Done()
+			for j := 0; j < 50; j++ {
+				var serverCheck = ReadChecksData().HealthChecks["server"]
+				for k := range serverCheck.PastIncidents {
+					serverCheck.PastIncidents[k].Errors[0] = "changed"
+				}
+				serverCheck.Incident.Errors[0] = "changed"
+				serverCheck.SecurityHeaders["Strict-Transport-Security"] = false
+			}
+		}()
+	}
+	wait.Wait()
+
+	var stored = ReadChecksData().HealthChecks["server"]
+	if len(stored.PastIncidents) != 81 {
+		t.Errorf("got %d past incidents, want 81, concurrent updates were lost", len(stored.PastIncidents))
+	}
+	for _, incident := range stored.PastIncidents {
+		if incident.Errors[0] == "changed" {
+			t.Fatal("past incident changed through a copy")
+		}
+	}
+	if stored.Incident.Errors[0] != "timeout" || !stored.SecurityHeaders["Strict-Transport-Security"] {
+		t.Error("incident or headers changed through a copy")
+	}
+}
+
+func BenchmarkReadChecksData(b *testing.B) {
+	var servers []ServerCheck
+	for i := 0; i < 50; i++ {
+		var serverCheck = storedServer(fmt.Sprint("server", i))
+		for j := 0; j < 1000; j++ {
+			serverCheck.History = append(serverCheck.History, CheckResult{Time: time.Now(), Status: StatusOk})
+		}
+		servers = append(servers, serverCheck)
+	}
+	useStorage(b, servers...)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ReadChecksData()
+	}
+}
+
+// sampleData is storage with several servers, inserted into the maps in the given order
+func sampleData(names ...string) Data {
+	var checkedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var checksData = Data{
+		SchemaVersion: SchemaVersion,
+		HealthChecks:  map[string]ServerCheck{},
+		SuperUsers:    []string{"admin"},
+		Agents:        map[string]AgentState{},
+	}
+	for _, name := range names {
+		checksData.HealthChecks[name] = ServerCheck{
+			Name: name, Url: "https://" + name + ".example.com", IsOk: name != "site", LastChecked: checkedAt,
+			History:         []CheckResult{{Time: checkedAt, Status: StatusOk, ResponseTime: time.Second}},
+			SecurityHeaders: map[string]bool{"X-Frame-Options": true, "Content-Security-Policy": false},
+		}
+		checksData.Agents[name] = AgentState{LastSeen: checkedAt}
+	}
+	return checksData
+}
+
+func TestSaveChecksDataIsDeterministic(t *testing.T) {
+	useStorage(t)
+
+	if err := SaveChecksData(sampleData("api", "site", "db")); err != nil {
+		t.Fatal(err)
+	}
+	var first = storedFile(t)
+	if err := SaveChecksData(sampleData("api", "site", "db")); err != nil {
+		t.Fatal(err)
+	}
+	if second := storedFile(t); second != first {
+		t.Errorf("two saves of the same data differ:\n%s\n%s", first, second)
+	}
+
+	for _, names := range [][]string{{"db", "site", "api"}, {"site", "api", "db"}} {
+		if err := SaveChecksData(sampleData(names...)); err != nil {
+			t.Fatal(err)
+		}
+		if saved := storedFile(t); saved != first {
+			t.Errorf("saves with insertion order %v differ:\n%s\n%s", names, first, saved)
+		}
+	}
+
+	if !strings.Contains(first, "\n  \"healthChecks\": {\n") {
+		t.Errorf("storage is not indented:\n%s", first)
+	}
+	if strings.Index(first, `"api": {`) > strings.Index(first, `"db": {`) ||
+		strings.Index(first, `"db": {`) > strings.Index(first, `"site": {`) {
+		t.Errorf("servers are not sorted by name:\n%s", first)
+	}
+}
+
+func TestSaveChecksDataKeepsDecodedData(t *testing.T) {
+	useStorage(t)
+
+	var saved = sampleData("api", "site", "db")
+	if err := SaveChecksData(saved); err != nil {
+		t.Fatal(err)
+	}
+	reloadStorage()
+
+	if loaded := ReadChecksData(); !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("decoded storage differs from saved:\n%+v\n%+v", loaded, saved)
+	}
+}
@@ -0,0 +1,84 @@
+package checks
+
+import (
+	"time"
+)
+
+// MinEndedStreak is the shortest uptime streak mentioned in the recovery alert when a failure ended it
+const MinEndedStreak = 24 * time.Hour
+
+// Streak is a period the server was up or down without a change
+type Streak struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+func (s Streak) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// CurrentStreak returns the current uptime or downtime streak of the server up to now, false if it was not checked yet.
+// A pause doesn't end the streak, the streak continues when the server is resumed.
+func (s ServerCheck) CurrentStreak(now time.Time) (streak Streak, up bool, ok bool) {
+	switch {
+	case s.IsOk && !s.UpSince.IsZero():
+		return Streak{Start: s.UpSince, End: now}, true, true
+	case !s.IsOk && !s.FailingSince.IsZero():
+		return Streak{Start: s.FailingSince, End: now}, false, true
+	default:
+		return Streak{}, false, false
+	}
+}
+
+// recordStreaks ends the current streak if the result changes the state of the server and updates the records.
+// It is called before the result is set, so the state and FailingSince are those of the previous check.
+func recordStreaks(serverCheck *ServerCheck, result CheckResult) {
+	if result.Status == StatusFailed {
+		if !serverCheck.UpSince.IsZero() {
+			var streak = Streak{Start: serverCheck.UpSince, End: result.Time}
+			serverCheck.LastUptime = streak
+			if streak.Duration() > serverCheck.LongestUptime.Duration() {
+				serverCheck.LongestUptime = streak
+			}
+			serverCheck.UpSince = time.Time{}
+		}
+		return
+	}
+
+	if !serverCheck.FailingSince.IsZero() {
+		var streak = Streak{Start: serverCheck.FailingSince, End: result.Time}
+		if streak.Duration() > serverCheck.LongestDowntime.Duration() {
+			serverCheck.LongestDowntime = streak
+		}
+	}
+	if serverCheck.UpSince.IsZero() {
+		serverCheck.UpSince = upStreakStart(*serverCheck, result.Time)
+	}
+}
+
+// upStreakStart returns the start of the uptime streak continued or started by a successful check at the time.
+// Servers up before streaks were tracked continue the streak since the last failure or the oldest kept check.
+func upStreakStart(serverCheck ServerCheck, now time.Time) time.Time {
+	if !serverCheck.IsOk || !serverCheck.FailingSince.IsZero() {
+		return now
+	}
+
+	switch {
+	case !serverCheck.LastFailure.IsZero():
+		return serverCheck.LastFailure
+	case len(serverCheck.History) > 0:
+		return serverCheck.History[0].Time
+	default:
+		return now
+	}
+}
+
+// endedUptime returns the uptime streak ended by the failure starting the current downtime,
+// ok is false if the failure didn't end an uptime streak of at least MinEndedStreak
+func endedUptime(serverCheck ServerCheck) (streak Streak, record bool, ok bool) {
+	var last = serverCheck.LastUptime
+	if last.End.IsZero() || !last.End.Equal(serverCheck.FailingSince) || last.Duration() < MinEndedStreak {
+		return Streak{}, false, false
+	}
+	return last, last == serverCheck.LongestUptime, true
+}
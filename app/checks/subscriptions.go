@@ -0,0 +1,198 @@
+package checks
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AllServers subscribes the chat to alerts of all servers
+const AllServers = "all"
+
+// TagPrefix starts a subscription to alerts of servers with the tag, like "tag:prod"
+const TagPrefix = "tag:"
+
+// subscriptionTag returns the lowercased tag of the "tag:" subscription, false if the name is not one
+func subscriptionTag(name string) (string, bool, error) {
+	if len(name) < len(TagPrefix) || !strings.EqualFold(name[:len(TagPrefix)], TagPrefix) {
+		return "", false, nil
+	}
+
+	var tag = strings.ToLower(name[len(TagPrefix):])
+	if !serverTagPattern.MatchString(tag) {
+		return "", true, ErrInvalidTag
+	}
+	return tag, true, nil
+}
+
+// Subscribe subscribes the private chat to alerts of the server, of servers with the "tag:" tag or of all servers.
+// Servers don't need to have the tag yet.
+func Subscribe(chatId int64, name string) error {
+	tag, isTag, err := subscriptionTag(name)
+	if err != nil {
+		return err
+	}
+	if isTag {
+		return UpdateChecksData(func(checksData *Data) {
+			if slices.Contains(checksData.TagSubscribers[tag], chatId) {
+				return
+			}
+			if checksData.TagSubscribers == nil {
+				checksData.TagSubscribers = make(map[string][]int64)
+			}
+			checksData.TagSubscribers[tag] = append(checksData.TagSubscribers[tag], chatId)
+		})
+	}
+
+	if name == AllServers {
+		return UpdateChecksData(func(checksData *Data) {
+			if !slices.Contains(checksData.Subscribers, chatId) {
+				checksData.Subscribers = append(checksData.Subscribers, chatId)
+			}
+		})
+	}
+
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		if !slices.Contains(serverCheck.Subscribers, chatId) {
+			serverCheck.Subscribers = append(serverCheck.Subscribers, chatId)
+		}
+	})
+}
+
+// Unsubscribe removes the subscription of the chat to the server, to the "tag:" tag or to all servers,
+// returns false if there was none
+func Unsubscribe(chatId int64, name string) (bool, error) {
+	var found bool
+	var remove = func(subscribers []int64) []int64 {
+		var i = slices.Index(subscribers, chatId)
+		if i == -1 {
+			return subscribers
+		}
+		found = true
+		return slices.Delete(subscribers, i, i+1)
+	}
+
+	tag, isTag, err := subscriptionTag(name)
+	if err != nil {
+		return false, err
+	}
+	if isTag {
+		err := UpdateChecksData(func(checksData *Data) {
+			var subscribers = remove(checksData.TagSubscribers[tag])
+			if len(subscribers) == 0 {
+				delete(checksData.TagSubscribers, tag)
+				return
+			}
+			checksData.TagSubscribers[tag] = subscribers
+		})
+		return found, err
+	}
+
+	if name == AllServers {
+		err := UpdateChecksData(func(checksData *Data) {
+			checksData.Subscribers = remove(checksData.Subscribers)
+		})
+		return found, err
+	}
+
+	err = UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.Subscribers = remove(serverCheck.Subscribers)
+	})
+	return found, err
+}
+
+// RemoveSubscriber removes all subscriptions and preferences of the chat
+func RemoveSubscriber(chatId int64) error {
+	return UpdateChecksData(func(checksData *Data) {
+		delete(checksData.Preferences, chatId)
+		checksData.Subscribers = slices.DeleteFunc(checksData.Subscribers, func(id int64) bool { return id == chatId })
+		for tag, subscribers := range checksData.TagSubscribers {
+			subscribers = slices.DeleteFunc(subscribers, func(id int64) bool { return id == chatId })
+			if len(subscribers) == 0 {
+				delete(checksData.TagSubscribers, tag)
+				continue
+			}
+			checksData.TagSubscribers[tag] = subscribers
+		}
+		for name, serverCheck := range checksData.HealthChecks {
+			if slices.Contains(serverCheck.Subscribers, chatId) {
+				serverCheck.Subscribers = slices.DeleteFunc(serverCheck.Subscribers, func(id int64) bool { return id == chatId })
+				checksData.HealthChecks[name] = serverCheck
+			}
+		}
+	})
+}
+
+// Subscriptions returns whether the chat is subscribed to all servers, names of servers and tags it is subscribed to.
+// Subscriptions to removed servers are removed with the server.
+func (d Data) Subscriptions(chatId int64) (all bool, names []string, tags []string) {
+	for name, serverCheck := range d.HealthChecks {
+		if slices.Contains(serverCheck.Subscribers, chatId) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for tag, subscribers := range d.TagSubscribers {
+		if slices.Contains(subscribers, chatId) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+
+	return slices.Contains(d.Subscribers, chatId), names, tags
+}
+
+// subscribers returns chats subscribed to the server, to one of its tags or to all servers
+func (d Data) subscribers(name string) []int64 {
+	var subscribers = slices.Clone(d.Subscribers)
+	var add = func(chatIds []int64) {
+		for _, chatId := range chatIds {
+			if !slices.Contains(subscribers, chatId) {
+				subscribers = append(subscribers, chatId)
+			}
+		}
+	}
+
+	var serverCheck = d.HealthChecks[name]
+	add(serverCheck.Subscribers)
+	for tag, chatIds := range d.TagSubscribers {
+		if serverCheck.HasTag(tag) {
+			add(chatIds)
+		}
+	}
+	return subscribers
+}
+
+// sendToSubscribers sends the event to chats subscribed to the server in background if their preferences allow it,
+// a chat which blocked the bot is unsubscribed
+func sendToSubscribers(options Options, event notify.Event) {
+	if options.SubscriberNotifier == nil {
+		return
+	}
+
+	var checksData = ReadChecksData()
+	var now = time.Now().In(checksData.Settings.Location(Location))
+	for _, chatId := range checksData.subscribers(event.Server) {
+		if !checksData.Preferences[chatId].Allows(event.Type, now) {
+			continue
+		}
+
+		go func(chatId int64) {
+			err := options.SubscriberNotifier(chatId).Send(event)
+			if errors.Is(err, notify.ErrChatBlocked) {
+				log.Printf("[INFO] Subscriber %d blocked the bot, subscriptions removed", chatId)
+				if err := RemoveSubscriber(chatId); err != nil {
+					log.Printf("[ERROR] Failed to remove subscriber %d: %v", chatId, err)
+				}
+				return
+			}
+			if err != nil {
+				log.Printf("[ERROR] Failed to send %s event of %s to subscriber %d: %v", event.Type, event.Server, chatId, err)
+			}
+		}(chatId)
+	}
+}
@@ -0,0 +1,86 @@
+package checks
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSubscribeTag(t *testing.T) {
+	useStorage(t, ServerCheck{Name: "db", Url: "https://db.example.com", Tags: []string{"prod"}})
+
+	for _, name := range []string{"tag:prod", "TAG:Prod", "tag:staging"} {
+		if err := Subscribe(1, name); err != nil {
+			t.Fatalf("subscribe to %s: %v", name, err)
+		}
+	}
+	if err := Subscribe(1, "tag:no spaces"); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("got error %v for an invalid tag, want ErrInvalidTag", err)
+	}
+
+	all, names, tags := ReadChecksData().Subscriptions(1)
+	if all || len(names) != 0 || !slices.Equal(tags, []string{"prod", "staging"}) {
+		t.Errorf("got subscriptions %v, %v, %v, want tags prod and staging", all, names, tags)
+	}
+}
+
+func TestTagSubscribersGetAlerts(t *testing.T) {
+	useStorage(t,
+		ServerCheck{Name: "db", Url: "https://db.example.com", Tags: []string{"prod", "db"}, Subscribers: []int64{2}},
+		ServerCheck{Name: "web", Url: "https://example.com", Tags: []string{"staging"}},
+	)
+	for _, subscription := range []struct {
+		chatId int64
+		name   string
+	}{{1, "tag:prod"}, {2, "tag:db"}, {3, "tag:db"}, {3, "tag:prod"}, {4, AllServers}} {
+		if err := Subscribe(subscription.chatId, subscription.name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var tests = []struct {
+		server string
+		want   []int64
+	}{
+		// each chat gets the alert once however many of its subscriptions match
+		{"db", []int64{1, 2, 3, 4}},
+		{"web", []int64{4}},
+	}
+	for _, test := range tests {
+		var got = ReadChecksData().subscribers(test.server)
+		slices.Sort(got)
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: alert sent to %v, want %v", test.server, got, test.want)
+		}
+	}
+}
+
+func TestUnsubscribeTag(t *testing.T) {
+	useStorage(t, ServerCheck{Name: "db", Url: "https://db.example.com", Tags: []string{"prod"}})
+	if found, err := Unsubscribe(1, "tag:prod"); err != nil || found {
+		t.Errorf("got %v, %v without tag subscriptions, want not found", found, err)
+	}
+	for _, chatId := range []int64{1, 2} {
+		if err := Subscribe(chatId, "tag:prod"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := Unsubscribe(1, "tag:Prod")
+	if err != nil || !found {
+		t.Fatalf("got %v, %v, want the subscription removed", found, err)
+	}
+	if found, err = Unsubscribe(1, "tag:prod"); err != nil || found {
+		t.Errorf("got %v, %v for a removed subscription, want not found", found, err)
+	}
+	if subscribers := ReadChecksData().subscribers("db"); !slices.Equal(subscribers, []int64{2}) {
+		t.Errorf("got subscribers %v, want the other chat", subscribers)
+	}
+
+	if err := RemoveSubscriber(2); err != nil {
+		t.Fatal(err)
+	}
+	if tagSubscribers := ReadChecksData().TagSubscribers; len(tagSubscribers) != 0 {
+		t.Errorf("got tag subscribers %v of removed chats", tagSubscribers)
+	}
+}
@@ -0,0 +1,53 @@
+package checks
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"log"
+	"slices"
+	"time"
+)
+
+// maxIncidentErrors is the number of distinct errors kept on the incident
+const maxIncidentErrors = 5
+
+// recordFailure counts the failed check of the incident and keeps its error if it is a new one
+func (i *Incident) recordFailure(result CheckResult) {
+	if result.Status != StatusFailed {
+		return
+	}
+
+	i.FailedChecks++
+	if result.Error != "" && len(i.Errors) < maxIncidentErrors && !slices.Contains(i.Errors, result.Error) {
+		i.Errors = append(i.Errors, result.Error)
+	}
+}
+
+// sendIncidentSummary sends the summary of the incident closed at end to the chat, nothing is sent during maintenance
+func sendIncidentSummary(options Options, serverCheck ServerCheck, incident Incident, end time.Time, location *time.Location) {
+	if options.inMaintenance() {
+		return
+	}
+
+	var event = notify.Event{
+		Type:         notify.EventIncidentSummary,
+		Server:       serverCheck.Name,
+		Url:          redact.Url(serverCheck.Url),
+		Time:         end.In(location),
+		Since:        incident.Start.In(location),
+		Duration:     end.Sub(incident.Start),
+		AckBy:        incident.AckBy,
+		FailedChecks: incident.FailedChecks,
+		Errors:       incident.Errors,
+		Simulated:    incident.Simulated,
+		EndedUptime:  incident.EndedUptime,
+		EndedRecord:  incident.EndedRecord,
+	}
+	if incident.AckBy != "" {
+		event.AckDelay = incident.AckAt.Sub(incident.AlertedAt)
+	}
+
+	if err := options.Notifier.Send(event); err != nil {
+		log.Printf("[ERROR] Failed to send incident summary of %s: %v", serverCheck.Name, err)
+	}
+}
@@ -0,0 +1,38 @@
+package checks
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidTag = errors.New("invalid tag")
+
+// serverTagPattern matches tags of letters, digits, dashes and underscores
+var serverTagPattern = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+
+// SetTags sets tags of the server, they are lowercased and deduplicated, nil tags clear them
+func SetTags(name string, tags []string) error {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(tag)
+		if !serverTagPattern.MatchString(tag) {
+			return ErrInvalidTag
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return UpdateServerCheck(name, func(serverCheck *ServerCheck) {
+		serverCheck.Tags = normalized
+	})
+}
+
+// HasTag returns true if the server has the tag, case insensitive
+func (s ServerCheck) HasTag(tag string) bool {
+	return slices.ContainsFunc(s.Tags, func(serverTag string) bool {
+		return strings.EqualFold(serverTag, tag)
+	})
+}
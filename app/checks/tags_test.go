@@ -0,0 +1,53 @@
+package checks
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSetTags(t *testing.T) {
+	var tests = []struct {
+		name    string
+		tags    []string
+		want    []string
+		wantErr error
+	}{
+		{"set", []string{"prod", "eu-west"}, []string{"prod", "eu-west"}, nil},
+		{"normalized", []string{"Prod", "prod", "db_1"}, []string{"prod", "db_1"}, nil},
+		{"cyrillic", []string{"прод"}, []string{"прод"}, nil},
+		{"clear", nil, nil, nil},
+		{"empty", []string{"prod", ""}, []string{"old"}, ErrInvalidTag},
+		{"space", []string{"my tag"}, []string{"old"}, ErrInvalidTag},
+		{"colon", []string{"tag:prod"}, []string{"old"}, ErrInvalidTag},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useStorage(t, ServerCheck{Name: "web", Url: "https://example.com", Tags: []string{"old"}})
+
+			if err := SetTags("web", test.tags); !errors.Is(err, test.wantErr) {
+				t.Fatalf("got error %v, want %v", err, test.wantErr)
+			}
+			if tags := ReadChecksData().HealthChecks["web"].Tags; !slices.Equal(tags, test.want) {
+				t.Errorf("got tags %q, want %q", tags, test.want)
+			}
+		})
+	}
+}
+
+func TestSetTagsOfMissingServer(t *testing.T) {
+	useStorage(t)
+
+	if err := SetTags("web", []string{"prod"}); !errors.Is(err, ErrServerNotExists) {
+		t.Errorf("got error %v, want %v", err, ErrServerNotExists)
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	var serverCheck = ServerCheck{Tags: []string{"prod", "db"}}
+
+	if !serverCheck.HasTag("prod") || !serverCheck.HasTag("DB") || serverCheck.HasTag("staging") {
+		t.Errorf("got wrong tags match of %q", serverCheck.Tags)
+	}
+}
@@ -0,0 +1,88 @@
+package checks
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"sync"
+	"time"
+)
+
+// TestServer is the name of the synthetic server of test alerts
+const TestServer = "test-server"
+
+// TestAlertTypes are event types which can be sent as test alerts by argument of /testalert
+var TestAlertTypes = map[string]notify.EventType{
+	"down": notify.EventDown,
+	"up":   notify.EventUp,
+	"sla":  notify.EventSlaBreach,
+	"pin":  notify.EventPinMismatch,
+}
+
+// Delivery is the result of sending the test alert to a channel
+type Delivery struct {
+	Channel string
+	Err     error
+}
+
+// TestEvent returns the event of the type with fake data for the server, or for a synthetic server if name is empty
+func TestEvent(eventType notify.EventType, name string) (notify.Event, error) {
+	var url = "https://example.com"
+	if name == "" {
+		name = TestServer
+	} else {
+		serverCheck, ok := ReadChecksData().HealthChecks[name]
+		if !ok {
+			return notify.Event{}, ErrServerNotExists
+		}
+		url = redact.Url(serverCheck.Url)
+	}
+
+	var now = time.Now()
+	var event = notify.Event{
+		Type:         eventType,
+		Server:       name,
+		Url:          url,
+		StatusCode:   200,
+		ResponseTime: 120 * time.Millisecond,
+		Time:         now,
+		Since:        now.Add(-5 * time.Minute),
+		Duration:     5 * time.Minute,
+		Test:         true,
+	}
+	switch eventType {
+	case notify.EventDown:
+		event.StatusCode, event.Error = 503, "test alert, the server is not checked"
+	case notify.EventSlaBreach:
+		event.Availability, event.SlaTarget, event.SlaWindow, event.ErrorBudget = 99.85, 99.9, 30*24*time.Hour, 0
+		event.Error = "test alert, availability is not measured"
+	case notify.EventPinMismatch:
+		event.Fingerprint, event.PinnedFingerprint = "TEST", "TEST-PINNED"
+		event.Error = "test alert, the certificate is not checked"
+	}
+
+	return event, nil
+}
+
+// SendTestAlert sends the test event to the chat, additional notifiers and subscribers of the server ignoring
+// maintenance and preferences, and returns the result of each channel. No server state is changed.
+func SendTestAlert(options Options, event notify.Event) []Delivery {
+	var notifiers = append([]notify.Notifier{options.Notifier}, options.Notifiers...)
+	if options.SubscriberNotifier != nil {
+		for _, chatId := range ReadChecksData().subscribers(event.Server) {
+			notifiers = append(notifiers, options.SubscriberNotifier(chatId))
+		}
+	}
+
+	var deliveries = make([]Delivery, len(notifiers))
+	var wg sync.WaitGroup
+	for i, notifier := range notifiers {
+		wg.Add(1)
+		go func(i int, notifier notify.Notifier) {
+			defer wg.Done()
+			deliveries[i] = Delivery{Channel: notifier.Name(), Err: notifier.Send(event)}
+		}(i, notifier)
+	}
+	wg.Wait()
+
+	return deliveries
+}
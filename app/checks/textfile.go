@@ -0,0 +1,114 @@
+package checks
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// textfileMetric is a gauge of the Prometheus textfile with a value per server
+type textfileMetric struct {
+	name  string
+	help  string
+	value func(serverCheck ServerCheck, now time.Time) (float64, bool)
+}
+
+var textfileMetrics = []textfileMetric{
+	{
+		name: "healthcheck_up",
+		help: "Whether the server is up, 1 up and 0 down, paused servers are not exported",
+		value: func(serverCheck ServerCheck, now time.Time) (float64, bool) {
+			if serverCheck.IsOk {
+				return 1, true
+			}
+			return 0, true
+		},
+	},
+	{
+		name: "healthcheck_response_time_seconds",
+		help: "Response time of the last check of the server",
+		value: func(serverCheck ServerCheck, now time.Time) (float64, bool) {
+			result, ok := serverCheck.LastResult()
+			return result.ResponseTime.Seconds(), ok
+		},
+	},
+	{
+		name: "healthcheck_availability_ratio",
+		help: "Share of successful checks of the server over the last 24 hours",
+		value: func(serverCheck ServerCheck, now time.Time) (float64, bool) {
+			availability, ok := Availability(HistorySince(serverCheck.History, now.Add(-24*time.Hour)))
+			return availability / 100, ok
+		},
+	},
+	{
+		name: "healthcheck_ssl_expiry_days",
+		help: "Days until the certificate of the server expires",
+		value: func(serverCheck ServerCheck, now time.Time) (float64, bool) {
+			if serverCheck.SslExpiry.IsZero() {
+				return 0, false
+			}
+			return math.Floor(serverCheck.SslExpiry.Sub(now).Hours() / 24), true
+		},
+	},
+	{
+		name: "healthcheck_score",
+		help: "Health score of the server from 0 to 100, lower is more worrying",
+		value: func(serverCheck ServerCheck, now time.Time) (float64, bool) {
+			return float64(serverCheck.Score), !serverCheck.ScoredAt.IsZero()
+		},
+	},
+}
+
+// WriteTextfile writes metrics of the servers in the Prometheus text format for the node_exporter textfile collector.
+// The file is written to a temporary file and renamed, so it is never read partially, and servers which were
+// removed disappear from it.
+func WriteTextfile(path string, now time.Time) error {
+	var checksData = ReadChecksData()
+
+	var names []string
+	for name, serverCheck := range checksData.HealthChecks {
+		if !serverCheck.Paused {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var text strings.Builder
+	for _, metric := range textfileMetrics {
+		fmt.Fprintf(&text, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name)
+		for _, name := range names {
+			if value, ok := metric.value(checksData.HealthChecks[name], now); ok {
+				fmt.Fprintf(&text, "%s{server=\"%s\"} %g\n", metric.name, labelEscaper.Replace(name), value)
+			}
+		}
+	}
+	fmt.Fprintf(&text, "# HELP healthcheck_last_cycle_timestamp_seconds Time the last check cycle completed\n"+
+		"# TYPE healthcheck_last_cycle_timestamp_seconds gauge\nhealthcheck_last_cycle_timestamp_seconds %d\n", now.Unix())
+
+	// the temporary file has no .prom extension, so the collector ignores it
+	file, err := os.CreateTemp(filepath.Dir(path), ".textfile-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(text.String()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(0o644); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// labelEscaper escapes label values of the Prometheus text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,149 @@
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exposition lines of the Prometheus text format, label values are quoted with \\, \" and \n escaped
+var helpLine = regexp.MustCompile(`^# HELP ([a-zA-Z_:][a-zA-Z0-9_:]*) [^\n]+$`)
+var typeLine = regexp.MustCompile(`^# TYPE ([a-zA-Z_:][a-zA-Z0-9_:]*) (counter|gauge|histogram|summary|untyped)$`)
+var sampleLine = regexp.MustCompile(
+	`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\[\\"n])*"(?:,[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\[\\"n])*")*\})? (\S+)$`)
+
+// parseTextfile validates the exposition format strictly and returns sample values by metric and labels
+func parseTextfile(t *testing.T, text string) map[string]float64 {
+	t.Helper()
+	if !strings.HasSuffix(text, "\n") {
+		t.Fatal("textfile doesn't end with a newline")
+	}
+
+	var samples = make(map[string]float64)
+	var described = make(map[string]bool)
+	var current string
+	for i, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		if match := helpLine.FindStringSubmatch(line); match != nil {
+			if described[match[1]] {
+				t.Errorf("line %d: metric %s is described twice", i+1, match[1])
+			}
+			described[match[1]], current = true, match[1]
+			continue
+		}
+		if match := typeLine.FindStringSubmatch(line); match != nil {
+			if match[1] != current {
+				t.Errorf("line %d: TYPE of %s follows HELP of %s", i+1, match[1], current)
+			}
+			continue
+		}
+
+		var match = sampleLine.FindStringSubmatch(line)
+		if match == nil {
+			t.Errorf("line %d is not valid: %q", i+1, line)
+			continue
+		}
+		if match[1] != current {
+			t.Errorf("line %d: sample of %s is not in its block", i+1, match[1])
+		}
+		value, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			t.Errorf("line %d: value %q is not a number", i+1, match[3])
+		}
+		if _, ok := samples[match[1]+match[2]]; ok {
+			t.Errorf("line %d: duplicate series %s%s", i+1, match[1], match[2])
+		}
+		samples[match[1]+match[2]] = value
+	}
+	return samples
+}
+
+func TestWriteTextfile(t *testing.T) {
+	var now = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	useStorage(t,
+		ServerCheck{Name: "web", Url: "https://example.com", IsOk: true, SslExpiry: now.Add(30*24*time.Hour + time.Hour),
+			Score: 87, ScoredAt: now,
+			History: []CheckResult{
+				{Time: now.Add(-2 * time.Minute), Status: StatusFailed, ResponseTime: time.Second},
+				{Time: now.Add(-time.Minute), Status: StatusOk, ResponseTime: 250 * time.Millisecond},
+			}},
+		ServerCheck{Name: `odd "name" \ here`, Url: "https://odd.example.com"},
+		ServerCheck{Name: "paused", Url: "https://paused.example.com", IsOk: true, Paused: true},
+	)
+	var path = filepath.Join(t.TempDir(), "healthcheck.prom")
+
+	if err := WriteTextfile(path, now); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samples = parseTextfile(t, string(raw))
+
+	var want = map[string]float64{
+		`healthcheck_up{server="web"}`:                    1,
+		`healthcheck_up{server="odd \"name\" \\ here"}`:   0,
+		`healthcheck_response_time_seconds{server="web"}`: 0.25,
+		`healthcheck_availability_ratio{server="web"}`:    0.5,
+		`healthcheck_ssl_expiry_days{server="web"}`:       30,
+		`healthcheck_score{server="web"}`:                 87,
+		`healthcheck_last_cycle_timestamp_seconds`:        float64(now.Unix()),
+	}
+	for series, value := range want {
+		if got, ok := samples[series]; !ok || got != value {
+			t.Errorf("%s: got %v (present %v), want %v", series, got, ok, value)
+		}
+	}
+	if len(samples) != len(want) {
+		t.Errorf("got %d series, want %d:\n%s", len(samples), len(want), raw)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("got file mode %v, want 0644 so node_exporter can read it", info.Mode().Perm())
+	}
+}
+
+func TestWriteTextfileDropsRemovedServers(t *testing.T) {
+	useStorage(t, ServerCheck{Name: "web", Url: "https://example.com", IsOk: true},
+		ServerCheck{Name: "api", Url: "https://api.example.com", IsOk: true})
+	var dir = t.TempDir()
+	var path = filepath.Join(dir, "healthcheck.prom")
+
+	if err := WriteTextfile(path, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveServer("api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteTextfile(path, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samples = parseTextfile(t, string(raw))
+	if _, ok := samples[`healthcheck_up{server="api"}`]; ok || strings.Contains(string(raw), `"api"`) {
+		t.Errorf("removed server is exported:\n%s", raw)
+	}
+	if _, ok := samples[`healthcheck_up{server="web"}`]; !ok {
+		t.Errorf("server is not exported:\n%s", raw)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got files %v, want only the textfile without temporary files", entries)
+	}
+}
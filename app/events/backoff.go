@@ -0,0 +1,78 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"log"
+	"strings"
+	"time"
+)
+
+// setBackoff sets how long the server must be down before its checks are stretched and the longest interval,
+// "off" turns the backoff off for the server and "default" uses the global settings
+func (l *TelegramListener) setBackoff(ctx *commandContext) {
+	var name, value = ctx.fields[0], strings.ToLower(ctx.fields[1])
+
+	var after, maxInterval time.Duration
+	var disabled = value == "off"
+	if !disabled && value != "default" {
+		var err error
+		after, err = time.ParseDuration(value)
+		if err != nil || after <= 0 {
+			l.reply(ctx.chatId, "backoff.invalid")
+			return
+		}
+		if len(ctx.fields) > 2 {
+			maxInterval, err = time.ParseDuration(ctx.fields[2])
+			if err != nil || maxInterval <= 0 {
+				l.reply(ctx.chatId, "backoff.invalid")
+				return
+			}
+		}
+	} else if len(ctx.fields) > 2 {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	err := checks.SetBackoff(name, after, maxInterval, disabled)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	var serverCheck = checks.ServerCheck{BackoffAfter: after, BackoffMax: maxInterval}
+	var effectiveAfter, effectiveMax = serverCheck.BackoffSettings()
+	switch {
+	case disabled:
+		l.reply(ctx.chatId, "backoff.off", name)
+	case after == 0 && effectiveAfter == 0:
+		l.reply(ctx.chatId, "backoff.default_off", name)
+	case after == 0:
+		l.reply(ctx.chatId, "backoff.default", name, effectiveAfter, effectiveMax)
+	default:
+		l.reply(ctx.chatId, "backoff.set", name, effectiveAfter, effectiveMax)
+	}
+}
+
+// formatBackoff formats the stretched interval of checks of the long down server and its backoff settings
+// if they are set for the server
+func formatBackoff(lang i18n.Lang, serverCheck checks.ServerCheck) string {
+	var text string
+	if serverCheck.BackoffInterval > 0 {
+		text += i18n.T(lang, "details.backoff", i18n.Duration(lang, serverCheck.BackoffInterval))
+	}
+
+	switch after, maxInterval := serverCheck.BackoffSettings(); {
+	case serverCheck.BackoffDisabled:
+		text += i18n.T(lang, "details.backoff_off")
+	case serverCheck.BackoffAfter > 0 || serverCheck.BackoffMax > 0:
+		text += i18n.T(lang, "details.backoff_set", i18n.Duration(lang, after), i18n.Duration(lang, maxInterval))
+	}
+	return text
+}
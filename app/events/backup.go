@@ -0,0 +1,41 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"time"
+)
+
+// SendBackup sends the storage file as a document to the backup chat, a failed backup is retried on the next schedule
+func (l *TelegramListener) SendBackup() {
+	if l.BackupChat == 0 {
+		return
+	}
+
+	data, servers, err := checks.Backup()
+	if err != nil {
+		log.Printf("[ERROR] Failed to encode backup: %v", err)
+		return
+	}
+
+	var now = time.Now()
+	var settings = checks.ReadChecksData().Settings
+	var chatId = settings.MigratedChat(l.BackupChat)
+	var lang = settings.Language(chatId, l.Language)
+
+	doc := tgbotapi.NewDocument(chatId, tgbotapi.FileBytes{Name: "checks-" + now.Format("2006-01-02-1504") + ".json", Bytes: data})
+	doc.Caption = i18n.T(lang, "backup.caption", i18n.Plural(lang, "unit.server", servers),
+		checks.FormatTime(now, settings.Location(l.Location)))
+	doc.DisableNotification = true
+	if _, err := l.Sender.Send(doc); err != nil {
+		log.Printf("[ERROR] Failed to send backup to chat %d, retrying on the next schedule: %v", chatId, err)
+		return
+	}
+
+	log.Printf("[INFO] Backup of %d servers sent to chat %d", servers, chatId)
+	if err := checks.SetLastBackup(now); err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"runtime"
+	"time"
+)
+
+// botStats shows internal metrics of the bot: cycle duration, scheduler lag and error counts
+func (l *TelegramListener) botStats(ctx *commandContext) {
+	var lang = ctx.lang
+
+	var text = i18n.T(lang, "botstats.last_cycle", formatTimeAgo(lang, l.location(), checks.LastCycleTime()))
+	text += i18n.T(lang, "botstats.cycle_duration",
+		time.Duration(metrics.CycleDuration.Value())*time.Millisecond, l.Scheduler.Interval())
+	text += i18n.T(lang, "botstats.scheduler_lag", time.Duration(metrics.SchedulerLag.Value())*time.Millisecond)
+	text += i18n.T(lang, "botstats.checks", metrics.ChecksPerformed.Value(), metrics.CheckCycles.Value())
+	text += i18n.T(lang, "botstats.telegram", metrics.TelegramSends.Value(), metrics.TelegramSendErrors.Value(),
+		metrics.QueueDepth.Value())
+	text += i18n.T(lang, "botstats.storage", metrics.StorageWrites.Value(), metrics.StorageWriteErrors.Value())
+	text += i18n.T(lang, "botstats.goroutines", runtime.NumGoroutine())
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, text))
+}
@@ -0,0 +1,208 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"strings"
+	"time"
+)
+
+// processCallback handles inline keyboard buttons, data format is "action:ref[:arg]" with the reference of the server
+func (l *TelegramListener) processCallback(query *tgbotapi.CallbackQuery) {
+	// the callback is always answered, otherwise Telegram shows the button loading
+	if query.Message == nil {
+		l.answerCallbackAlert(query, i18n.T(l.Language, "callback.stale"))
+		return
+	}
+	action, arg, _ := strings.Cut(query.Data, ":")
+
+	// preferences are personal, they are changed in a private chat which is not an allowed chat
+	if action == "pref" {
+		l.preferencesCallback(query, arg)
+		return
+	}
+
+	// viewers can open details, other buttons change state
+	var allowed = l.IsSuper(query.From) || action == "details" && l.isViewer(query.From, query.Message.Chat.ID)
+	if !allowed || !l.isAllowedChat(query.Message.Chat.ID) {
+		l.answerCallbackAlert(query, i18n.T(l.lang(query.Message.Chat.ID), "callback.unauthorized"))
+		return
+	}
+
+	switch action {
+	case "ack":
+		l.ackCallback(query, serverName(arg))
+	case "recheck":
+		l.recheckCallback(query, serverName(arg))
+	case "snooze":
+		l.snoozeCallback(query, arg)
+	case "details":
+		l.detailsCallback(query, serverName(arg))
+	case "confirm":
+		l.confirmCallback(query, arg, true)
+	case "cancel":
+		l.confirmCallback(query, arg, false)
+	case "addflow":
+		l.addFlowCallback(query, arg)
+	case "undoadd":
+		l.undoAddCallback(query, serverName(arg))
+	case "dpause", "dresume", "dcheck", "dthreshold", "dremove":
+		l.detailsActionCallback(query, action, serverName(arg))
+	default:
+		l.answerCallbackAlert(query, i18n.T(l.lang(query.Message.Chat.ID), "callback.stale"))
+	}
+}
+
+// serverName returns the name of the server of the reference in callback data, buttons sent before references
+// were used carry the name itself. The reference is returned if there is no such server.
+func serverName(ref string) string {
+	var healthChecks = checks.ReadChecksData().HealthChecks
+	if _, ok := healthChecks[ref]; ok {
+		return ref
+	}
+	for name := range healthChecks {
+		if notify.ServerRef(name) == ref {
+			return name
+		}
+	}
+	return ref
+}
+
+func (l *TelegramListener) ackCallback(query *tgbotapi.CallbackQuery, name string) {
+	var lang = l.lang(query.Message.Chat.ID)
+
+	incident, err := checks.AckIncident(name, userIdentity(query.From), "")
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.answerCallbackAlert(query, i18n.T(lang, "server.not_exists", name))
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.answerCallbackAlert(query, i18n.T(lang, "ack.failed"))
+		return
+	}
+	if incident.AckBy == "" {
+		l.answerCallback(query, i18n.T(lang, "ack.no_incident", name))
+		return
+	}
+
+	l.answerCallback(query, i18n.T(lang, "ack.done"))
+	l.editAlert(query, fmt.Sprintf("%s\n\n%s", query.Message.Text, i18n.T(lang, "ack.by", incident.AckBy)))
+}
+
+func (l *TelegramListener) recheckCallback(query *tgbotapi.CallbackQuery, name string) {
+	var chatId = query.Message.Chat.ID
+	var lang = l.lang(chatId)
+	l.answerCallback(query, i18n.T(lang, "recheck.checking"))
+
+	serverCheck, _, err := checks.RecheckServer(name)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to recheck server %s: %v", name, err)
+		l.reply(chatId, "server.check_failed", name)
+		return
+	}
+
+	var result = i18n.T(lang, "recheck.still_down")
+	if serverCheck.IsOk {
+		result = i18n.T(lang, "recheck.up")
+	}
+
+	var text = fmt.Sprintf("%s\n\n%s", notify.AlertText(lang, alertEvent(serverCheck)),
+		i18n.T(lang, "recheck.checked", time.Now().In(l.location()).Format("15:04:05 MST"), result))
+	if serverCheck.Incident != nil && serverCheck.Incident.AckBy != "" {
+		text += "\n" + i18n.T(lang, "ack.by", serverCheck.Incident.AckBy)
+	}
+	l.editAlert(query, text)
+}
+
+// alertEvent returns the down event of the alert of the server, with the last error of its incident
+func alertEvent(serverCheck checks.ServerCheck) notify.Event {
+	var event = notify.Event{
+		Type:   notify.EventDown,
+		Server: serverCheck.Name,
+		Url:    redact.Url(serverCheck.Url),
+		Since:  serverCheck.FailingSince,
+	}
+	if serverCheck.Incident != nil && len(serverCheck.Incident.Errors) > 0 {
+		event.Error = serverCheck.Incident.Errors[len(serverCheck.Incident.Errors)-1]
+	}
+	return event
+}
+
+func (l *TelegramListener) snoozeCallback(query *tgbotapi.CallbackQuery, arg string) {
+	var name, durationArg = arg, ""
+	if i := strings.LastIndex(arg, ":"); i != -1 {
+		name, durationArg = arg[:i], arg[i+1:]
+	}
+	name = serverName(name)
+
+	var lang = l.lang(query.Message.Chat.ID)
+	duration, err := time.ParseDuration(durationArg)
+	if err != nil {
+		l.answerCallbackAlert(query, i18n.T(lang, "snooze.invalid"))
+		return
+	}
+
+	var snoozedUntil = time.Now().Add(duration)
+	err = checks.UpdateServerCheck(name, func(serverCheck *checks.ServerCheck) {
+		serverCheck.SnoozedUntil = snoozedUntil
+	})
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.answerCallbackAlert(query, i18n.T(lang, "server.not_exists", name))
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.answerCallbackAlert(query, i18n.T(lang, "snooze.failed"))
+		return
+	}
+
+	l.answerCallback(query, i18n.T(lang, "snooze.done", i18n.Duration(lang, duration)))
+	l.editAlert(query, fmt.Sprintf("%s\n\n%s", query.Message.Text,
+		i18n.T(lang, "snooze.by", userIdentity(query.From), checks.FormatTime(snoozedUntil, l.location()))))
+}
+
+func (l *TelegramListener) detailsCallback(query *tgbotapi.CallbackQuery, name string) {
+	var lang = l.lang(query.Message.Chat.ID)
+	serverCheck, ok := checks.ReadChecksData().HealthChecks[name]
+	if !ok {
+		l.answerCallbackAlert(query, i18n.T(lang, "server.not_exists", name))
+		return
+	}
+
+	l.answerCallback(query, "")
+	l.send(tgbotapi.NewMessage(query.Message.Chat.ID, formatServerDetails(lang, l.location(), serverCheck, checks.DefaultReliabilityWindow)))
+}
+
+func (l *TelegramListener) answerCallback(query *tgbotapi.CallbackQuery, text string) {
+	if _, err := l.Bot.Request(tgbotapi.NewCallback(query.ID, text)); err != nil {
+		log.Printf("[ERROR] Failed to answer callback: %v", err)
+	}
+}
+
+// answerCallbackAlert answers the callback with an error shown in a dialog instead of a toast
+func (l *TelegramListener) answerCallbackAlert(query *tgbotapi.CallbackQuery, text string) {
+	if _, err := l.Bot.Request(tgbotapi.NewCallbackWithAlert(query.ID, text)); err != nil {
+		log.Printf("[ERROR] Failed to answer callback: %v", err)
+	}
+}
+
+// editAlert replaces text of the alert message keeping its buttons
+func (l *TelegramListener) editAlert(query *tgbotapi.CallbackQuery, text string) {
+	var edit = tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ReplyMarkup = query.Message.ReplyMarkup
+
+	if _, err := l.Sender.Send(edit); err != nil {
+		log.Printf("[ERROR] Failed to edit message: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerName(t *testing.T) {
+	var long = strings.Repeat("сервер", 11)
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "api", Url: "https://api.example.com"},
+		checks.ServerCheck{Name: long, Url: "https://example.com"})
+
+	var tests = []struct {
+		ref  string
+		want string
+	}{
+		{notify.ServerRef("api"), "api"},
+		{notify.ServerRef(long), long},
+		// buttons sent before references were used carry the name
+		{"api", "api"},
+		{"unknown", "unknown"},
+	}
+	for _, test := range tests {
+		if got := serverName(test.ref); got != test.want {
+			t.Errorf("serverName(%q) = %q, want %q", test.ref, got, test.want)
+		}
+	}
+}
+
+func TestDetailsKeyboardCallbackDataLimit(t *testing.T) {
+	for _, paused := range []bool{false, true} {
+		var keyboard = detailsKeyboard(i18n.En, checks.ServerCheck{Name: strings.Repeat("сервер", 11), Paused: paused})
+		for _, row := range keyboard.InlineKeyboard {
+			for _, button := range row {
+				if len(*button.CallbackData) > 64 {
+					t.Errorf("callback data %q is %d bytes, Telegram allows 64", *button.CallbackData, len(*button.CallbackData))
+				}
+			}
+		}
+	}
+}
+
+func TestUndoAddKeyboardCallbackDataLimit(t *testing.T) {
+	var name = strings.Repeat("сервер", 11)
+	var data = *undoAddKeyboard(i18n.En, name).InlineKeyboard[0][0].CallbackData
+	if len(data) > 64 {
+		t.Errorf("callback data %q is %d bytes, Telegram allows 64", data, len(data))
+	}
+
+	checkstest.UseStorage(t, checks.ServerCheck{Name: name, Url: "https://example.com"})
+	if _, ref, _ := strings.Cut(data, ":"); serverName(ref) != name {
+		t.Errorf("reference %q resolved to %q, want %q", ref, serverName(ref), name)
+	}
+}
+
+func TestCallbackIsAlwaysAnswered(t *testing.T) {
+	var message = &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: testChat, Type: "supergroup"}}
+	var otherChat = &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 42, Type: "group"}}
+	var super = &tgbotapi.User{ID: 10, UserName: testSuper}
+
+	var tests = []struct {
+		name      string
+		from      *tgbotapi.User
+		message   *tgbotapi.Message
+		data      string
+		wantText  string
+		wantAlert bool
+	}{
+		{"success", super, message, "dpause:" + notify.ServerRef("web"), "Paused", false},
+		{"stranger", &tgbotapi.User{ID: 20, UserName: "stranger"}, message, "dpause:" + notify.ServerRef("web"), "Not authorized", true},
+		{"viewer changing state", &tgbotapi.User{ID: 30, UserName: "viewer"}, message, "dremove:" + notify.ServerRef("web"), "Not authorized", true},
+		{"other chat", super, otherChat, "dpause:" + notify.ServerRef("web"), "Not authorized", true},
+		{"unknown data", super, message, "obsolete:web", "Already handled", true},
+		{"inline message", super, nil, "dpause:" + notify.ServerRef("web"), "Already handled", true},
+		{"removed server", super, message, "ack:missing", "Server missing not exists", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com", IsOk: true})
+			l, telegram := newTestListener(t)
+			l.Viewers = SuperUser{"viewer"}
+
+			l.processUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+				ID: "7", From: test.from, Message: test.message, Data: test.data,
+			}})
+
+			var answers = telegram.requested("answerCallbackQuery")
+			if len(answers) != 1 {
+				t.Fatalf("got answers %v, want one", answers)
+			}
+			var answer = answers[0]
+			if answer.Get("callback_query_id") != "7" || answer.Get("text") != test.wantText ||
+				(answer.Get("show_alert") == "true") != test.wantAlert {
+				t.Errorf("got answer %v, want %q with alert %v", answer, test.wantText, test.wantAlert)
+			}
+			if paused := checks.ReadChecksData().HealthChecks["web"].Paused; paused != (test.name == "success") {
+				t.Errorf("server paused: %v", paused)
+			}
+		})
+	}
+}
+
+func TestSnoozeButtonOfUserWithoutUsername(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"})
+	l, telegram := newTestListener(t)
+	l.SuperUsers = SuperUser{"42"}
+
+	l.processUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID: "1", From: &tgbotapi.User{ID: 42, FirstName: "Bob"}, Message: commandMessage(100, "❌ Server web is down"),
+		Data: "snooze:" + notify.ServerRef("web") + ":1h",
+	}})
+
+	if snoozed := checks.ReadChecksData().HealthChecks["web"].SnoozedUntil; snoozed.IsZero() {
+		t.Error("server was not snoozed")
+	}
+	if sent := telegram.sent(); len(sent) != 1 || !strings.HasPrefix(sent[0], "❌ Server web is down\n\nsnoozed by Bob until ") {
+		t.Errorf("got replies %q, want the alert snoozed by Bob", sent)
+	}
+}
+
+func TestRecheckButtonKeepsAlertError(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: server.URL, FailingSince: time.Now().Add(-time.Hour),
+		Incident: &checks.Incident{Start: time.Now().Add(-time.Hour), AlertedAt: time.Now(), Errors: []string{"timeout"}}})
+	l, telegram := newTestListener(t)
+
+	l.processUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID: "1", From: &tgbotapi.User{ID: 10, UserName: testSuper}, Message: commandMessage(100, "❌ Server web is down"),
+		Data: "recheck:" + notify.ServerRef("web"),
+	}})
+
+	var incidentErrors = checks.ReadChecksData().HealthChecks["web"].Incident.Errors
+	var want = fmt.Sprintf("❗❗❗ Server %s is down ❗❗❗\nError: %s\n\nChecked at ", server.URL, incidentErrors[len(incidentErrors)-1])
+	if sent := telegram.sent(); len(sent) != 1 || !strings.HasPrefix(sent[0], want) || !strings.HasSuffix(sent[0], "❌ still down") {
+		t.Errorf("got replies %q, want the alert with the last error starting with %q", sent, want)
+	}
+}
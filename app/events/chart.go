@@ -0,0 +1,61 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/chart"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"strconv"
+	"time"
+)
+
+const defaultChartHours = 24
+
+func (l *TelegramListener) chart(ctx *commandContext) {
+	var name, hours = ctx.fields[0], defaultChartHours
+	if len(ctx.fields) > 1 {
+		var err error
+		if hours, err = strconv.Atoi(ctx.fields[1]); err != nil || hours < 1 {
+			l.reply(ctx.chatId, "chart.hours_invalid")
+			return
+		}
+	}
+
+	serverCheck, ok := checks.ReadChecksData().HealthChecks[name]
+	if !ok {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+
+	var lang = ctx.lang
+	var since = time.Now().Add(-time.Duration(hours) * time.Hour)
+	var history = checks.HistorySince(serverCheck.History, since)
+	if len(history) == 0 {
+		l.reply(ctx.chatId, "chart.no_checks", name, i18n.Plural(lang, "unit.hour", hours))
+		return
+	}
+
+	image, err := chart.ResponseTimePNG(history)
+	if err != nil {
+		log.Printf("[ERROR] Failed to render chart: %v", err)
+		l.reply(ctx.chatId, "chart.failed", name)
+		return
+	}
+
+	var caption = i18n.T(lang, "chart.caption", name, checks.FormatTime(history[0].Time, l.location()),
+		i18n.Plural(lang, "unit.check", len(history)))
+	if since.Before(serverCheck.HistoryRetainedSince) {
+		caption += "\n" + i18n.T(lang, "chart.pruned", i18n.Plural(lang, "unit.hour", hours))
+	}
+	if minTime, avgTime, p95Time, count := checks.ResponseTimeStats(history); count > 0 {
+		caption += "\n" + i18n.T(lang, "chart.stats", minTime.Round(time.Millisecond),
+			avgTime.Round(time.Millisecond), p95Time.Round(time.Millisecond))
+	}
+
+	photo := tgbotapi.NewPhoto(ctx.chatId, tgbotapi.FileBytes{Name: name + ".png", Bytes: image})
+	photo.Caption = caption
+	if _, err := l.send(photo); err != nil {
+		log.Printf("[ERROR] Failed to send chart: %v", err)
+	}
+}
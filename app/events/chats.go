@@ -0,0 +1,61 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+)
+
+func (l *TelegramListener) isAllowedChat(chatId int64) bool {
+	var settings = checks.ReadChecksData().Settings
+	for _, allowedChat := range l.allowedChats() {
+		if chatId == allowedChat || chatId == settings.MigratedChat(allowedChat) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedChats returns chats to accept commands from, the alert chat by default
+func (l *TelegramListener) allowedChats() []int64 {
+	if len(l.AllowedChats) == 0 {
+		return []int64{l.Chat}
+	}
+	return l.AllowedChats
+}
+
+// rejectChat replies once to a chat the bot doesn't accept commands from
+func (l *TelegramListener) rejectChat(chatId int64) {
+	if l.rejectedChats == nil {
+		l.rejectedChats = make(map[int64]bool)
+	}
+
+	if l.rejectedChats[chatId] {
+		return
+	}
+	l.rejectedChats[chatId] = true
+
+	log.Printf("[INFO] Ignored command from not allowed chat %d", chatId)
+	l.reply(chatId, "chat.rejected")
+}
+
+// migrateChat stores new chat id when a group is migrated to a supergroup
+func (l *TelegramListener) migrateChat(fromChatId int64, toChatId int64) {
+	if !l.isAllowedChat(fromChatId) {
+		return
+	}
+
+	saveError := checks.UpdateChecksData(func(checksData *checks.Data) {
+		if checksData.Settings.ChatMigrations == nil {
+			checksData.Settings.ChatMigrations = make(map[int64]int64)
+		}
+		checksData.Settings.ChatMigrations[fromChatId] = toChatId
+	})
+	if saveError != nil {
+		log.Printf("[ERROR] Failed to save chat migration %d -> %d: %v", fromChatId, toChatId, saveError)
+		return
+	}
+
+	log.Printf("[INFO] Chat %d migrated to %d", fromChatId, toChatId)
+	l.reply(toChatId, "chat.migrated", fromChatId, toChatId)
+}
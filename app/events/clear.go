@@ -0,0 +1,53 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"strings"
+)
+
+// clearArg returns true if the argument of a set command clears the setting, restoring the default behavior
+func clearArg(arg string) bool {
+	switch strings.ToLower(arg) {
+	case "clear", "off", "0":
+		return true
+	}
+	return false
+}
+
+// formatDefaults lists settings of the server which are not set and use the default behavior
+func formatDefaults(lang i18n.Lang, serverCheck checks.ServerCheck) string {
+	var defaults []string
+	if !serverCheck.Soft404 {
+		defaults = append(defaults, i18n.T(lang, "default.soft404"))
+	}
+	if len(serverCheck.JsonChecks) == 0 {
+		defaults = append(defaults, i18n.T(lang, "default.json"))
+	}
+	if serverCheck.MinProto == "" {
+		defaults = append(defaults, i18n.T(lang, "default.proto"))
+	}
+	if !serverCheck.CompressionCheck {
+		defaults = append(defaults, i18n.T(lang, "default.compression"))
+	}
+	if !serverCheck.CheckAllIps {
+		defaults = append(defaults, i18n.T(lang, "default.ips"))
+	}
+	if len(serverCheck.Endpoints) == 0 {
+		defaults = append(defaults, i18n.T(lang, "default.endpoints"))
+	}
+	if serverCheck.SlaTarget == 0 {
+		defaults = append(defaults, i18n.T(lang, "default.sla"))
+	}
+	if serverCheck.PinnedSpki == "" && !serverCheck.PinPending {
+		defaults = append(defaults, i18n.T(lang, "default.pin"))
+	}
+	if len(serverCheck.ProbeLocations) == 0 {
+		defaults = append(defaults, i18n.T(lang, "default.probes"))
+	}
+	if len(defaults) == 0 {
+		return ""
+	}
+
+	return i18n.T(lang, "details.defaults", strings.Join(defaults, ", "))
+}
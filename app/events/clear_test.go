@@ -0,0 +1,127 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClearArg(t *testing.T) {
+	for arg, want := range map[string]bool{"clear": true, "CLEAR": true, "off": true, "0": true, "on": false, "": false, "h2": false} {
+		if got := clearArg(arg); got != want {
+			t.Errorf("clearArg(%q) = %v, want %v", arg, got, want)
+		}
+	}
+}
+
+func TestSetAndClear(t *testing.T) {
+	var tests = []struct {
+		set         string
+		clear       string
+		isSet       func(serverCheck checks.ServerCheck) bool
+		wantCleared string
+		wantDefault string
+	}{
+		{"/setsoft404 web on", "/setsoft404 web clear",
+			func(s checks.ServerCheck) bool { return s.Soft404 },
+			"Error page detection of web cleared, using default (off)", "error page detection"},
+		{"/setminproto web h2 warn", "/setminproto web clear",
+			func(s checks.ServerCheck) bool { return s.MinProto != "" || s.ProtoAction != "" },
+			"Minimum protocol of web cleared, using default (any protocol)", "minimum protocol"},
+		{"/setcompressioncheck web on", "/setcompressioncheck web off",
+			func(s checks.ServerCheck) bool { return s.CompressionCheck },
+			"Compression check of web cleared, using default (off)", "compression check"},
+		{"/checkallips web all", "/checkallips web 0",
+			func(s checks.ServerCheck) bool { return s.CheckAllIps },
+			"Address checks of web cleared, using default (any address)", "address checks"},
+		{"/setsla web 99.9", "/setsla web clear",
+			func(s checks.ServerCheck) bool { return s.SlaTarget != 0 },
+			"SLA target of web cleared, using default (no target)", "SLA"},
+		{"/setpin web current", "/setpin web clear",
+			func(s checks.ServerCheck) bool { return s.PinPending || s.PinnedSpki != "" },
+			"Pin of web cleared, using default (no pin)", "pinned key"},
+		{"/setprobes web eu-west", "/setprobes web off",
+			func(s checks.ServerCheck) bool { return len(s.ProbeLocations) > 0 },
+			"Locations of web cleared, using default (checked only by the bot)", "locations"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.set, func(t *testing.T) {
+			checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"})
+			l, telegram := newTestListener(t)
+
+			l.handleCommand(commandMessage(1, test.set))
+			var serverCheck = checks.ReadChecksData().HealthChecks["web"]
+			if !test.isSet(serverCheck) {
+				t.Fatalf("setting is not set by %s, replies %q", test.set, telegram.sent())
+			}
+			if strings.Contains(formatDefaults(i18n.En, serverCheck), test.wantDefault) {
+				t.Errorf("details list the set %s as default", test.wantDefault)
+			}
+
+			l.handleCommand(commandMessage(2, test.clear))
+			serverCheck = checks.ReadChecksData().HealthChecks["web"]
+			if test.isSet(serverCheck) {
+				t.Errorf("setting is not cleared by %s", test.clear)
+			}
+			if sent := telegram.sent(); sent[len(sent)-1] != test.wantCleared {
+				t.Errorf("got reply %q, want %q", sent[len(sent)-1], test.wantCleared)
+			}
+			if !strings.Contains(formatDefaults(i18n.En, serverCheck), test.wantDefault) {
+				t.Errorf("details don't list the cleared %s as default", test.wantDefault)
+			}
+		})
+	}
+}
+
+func TestClearThresholdGlobal(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+	l.AlertThreshold = 3
+
+	l.handleCommand(commandMessage(1, "/setthresholdglobal 5"))
+	if threshold := checks.ReadChecksData().Settings.AlertThreshold; threshold != 5 {
+		t.Fatalf("got threshold %d, want 5", threshold)
+	}
+	l.handleCommand(commandMessage(2, "/setthresholdglobal clear"))
+
+	if threshold := checks.ReadChecksData().Settings.AlertThreshold; threshold != 0 {
+		t.Errorf("got threshold %d after clearing, want the flag value", threshold)
+	}
+	if sent := telegram.sent(); sent[len(sent)-1] != "Alert threshold cleared, using default (3)" {
+		t.Errorf("got replies %q", sent)
+	}
+}
+
+func TestClearedSettingRevertsCheck(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><title>Page not found</title></html>")
+	}))
+	defer server.Close()
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: server.URL, IsOk: true, LastChecked: time.Now()})
+	l, _ := newTestListener(t)
+
+	for _, step := range []struct {
+		command string
+		wantOk  bool
+	}{
+		{"/setsoft404 web on", false},
+		{"/setsoft404 web clear", true},
+	} {
+		l.handleCommand(commandMessage(1, step.command))
+
+		_, result, err := checks.RecheckServer("web")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok := result.Status == checks.StatusOk; ok != step.wantOk {
+			t.Errorf("check after %s is ok: %v, want %v, error %q", step.command, ok, step.wantOk, result.Error)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"strings"
+	"unicode"
+)
+
+// command describes a bot command, the registry is used for dispatching, /help and the telegram commands menu
+type command struct {
+	name           string
+	usage          string
+	descriptionKey string
+	category       string
+	permission     permission
+	handler        handlerFunc
+
+	// minArgs and maxArgs limit the number of arguments, maxArgs 0 means no limit
+	minArgs int
+	maxArgs int
+	// httpOnly commands configure the HTTP request, they are rejected for SSL checks named by the first argument
+	httpOnly bool
+}
+
+// commandContext is the message of the command with the parsed arguments, passed through middleware to the handler
+type commandContext struct {
+	message *tgbotapi.Message
+	chatId  int64
+	user    *tgbotapi.User
+	lang    i18n.Lang
+
+	// args is the text after the command, fields are args split by whitespace
+	args   string
+	fields []string
+	// usage of the command to reply with when arguments are invalid
+	usage string
+}
+
+type handlerFunc func(ctx *commandContext)
+
+func (l *TelegramListener) newCommandContext(message *tgbotapi.Message, args string) *commandContext {
+	return &commandContext{
+		message: message,
+		chatId:  message.Chat.ID,
+		user:    message.From,
+		lang:    l.lang(message.Chat.ID),
+		args:    args,
+		fields:  splitArgs(args),
+	}
+}
+
+type permission int
+
+const (
+	// permissionSuper commands are available to superusers in the allowed chats
+	permissionSuper permission = iota
+	// permissionPublic commands are available to everyone in any chat
+	permissionPublic
+	// permissionRead commands don't change anything, they are available to viewers and superusers in the allowed chats
+	permissionRead
+	// permissionPrivate commands are personal, they are available to viewers and superusers in a private chat with the bot
+	permissionPrivate
+)
+
+// categories of commands in the order they are shown in /help
+const (
+	categoryServers   = "servers"
+	categoryIncidents = "incidents"
+	categorySettings  = "settings"
+	categoryBot       = "bot"
+	categorySupers    = "supers"
+)
+
+var categories = []string{categoryServers, categoryIncidents, categorySettings, categoryBot, categorySupers}
+
+func (l *TelegramListener) commands() []command {
+	return []command{
+		{name: "add", usage: "/add [url] [name] [grace=duration]", descriptionKey: "cmd.add", category: categoryServers, handler: l.addServer},
+		{name: "addssl", usage: "/addssl <host:port> [name] [starttls=smtp|imap]", descriptionKey: "cmd.addssl", category: categoryServers, handler: l.addSslServer, minArgs: 1, maxArgs: 3},
+		{name: "addssh", usage: "/addssh <host:port> [name] [expect=text]", descriptionKey: "cmd.addssh", category: categoryServers, handler: l.addSshServer, minArgs: 1, maxArgs: 3},
+		{name: "remove", usage: "/remove <name>", descriptionKey: "cmd.remove", category: categoryServers, handler: l.removeServer, minArgs: 1},
+		{name: "removeall", usage: "/removeall", descriptionKey: "cmd.removeall", category: categoryServers, handler: l.removeAllServers},
+		{name: "list", usage: "/list [sort:status|name|added|availability|latency|score|changes] [limit:N] [tag:name] [normal|compact|verbose]", descriptionKey: "cmd.list", category: categoryServers, permission: permissionRead, handler: l.listServers, maxArgs: 4},
+		{name: "stats", usage: "/stats [sort:name|status|added|availability|latency|score|changes] [limit:N] [tag:name]", descriptionKey: "cmd.stats", category: categoryServers, permission: permissionRead, handler: l.stats, maxArgs: 3},
+		{name: "details", usage: "/details <name> [days]", descriptionKey: "cmd.details", category: categoryServers, permission: permissionRead, handler: l.details, minArgs: 1, maxArgs: 2},
+		{name: "settags", usage: "/settags <name> <tag,...|clear>", descriptionKey: "cmd.settags", category: categoryServers, handler: l.setTags, minArgs: 2, maxArgs: 2},
+		{name: "checkallips", usage: "/checkallips <name> any|all|clear", descriptionKey: "cmd.checkallips", category: categoryServers, handler: l.checkAllIps, minArgs: 2, maxArgs: 2, httpOnly: true},
+		{name: "addendpoint", usage: "/addendpoint <name> <url> [label]", descriptionKey: "cmd.addendpoint", category: categoryServers, handler: l.addEndpoint, minArgs: 2, maxArgs: 3, httpOnly: true},
+		{name: "removeendpoint", usage: "/removeendpoint <name> <label>|all", descriptionKey: "cmd.removeendpoint", category: categoryServers, handler: l.removeEndpoint, minArgs: 2, maxArgs: 2},
+		{name: "endpointmode", usage: "/endpointmode <name> any|all", descriptionKey: "cmd.endpointmode", category: categoryServers, handler: l.endpointMode, minArgs: 2, maxArgs: 2, httpOnly: true},
+		{name: "setschedule", usage: "/setschedule <name> <schedule>|default", descriptionKey: "cmd.setschedule", category: categoryServers, handler: l.setSchedule, minArgs: 2, maxArgs: 2},
+		{name: "setsoft404", usage: "/setsoft404 <name> on|off", descriptionKey: "cmd.setsoft404", category: categoryServers, handler: l.setSoft404, minArgs: 2, maxArgs: 2, httpOnly: true},
+		{name: "setjsoncheck", usage: "/setjsoncheck <name> <path> <op> <number>|[path] clear", descriptionKey: "cmd.setjsoncheck", category: categoryServers, handler: l.setJsonCheck, minArgs: 2, maxArgs: 4, httpOnly: true},
+		{name: "setretries", usage: "/setretries <name> <count>|clear [delay]", descriptionKey: "cmd.setretries", category: categoryServers, handler: l.setRetries, minArgs: 2, maxArgs: 3, httpOnly: true},
+		{name: "setbackoff", usage: "/setbackoff <name> <after> [max]|off|default", descriptionKey: "cmd.setbackoff", category: categoryServers, handler: l.setBackoff, minArgs: 2, maxArgs: 3},
+		{name: "setminproto", usage: "/setminproto <name> h2|http/1.1|clear [fail|warn]", descriptionKey: "cmd.setminproto", category: categoryServers, handler: l.setMinProto, minArgs: 2, maxArgs: 3, httpOnly: true},
+		{name: "setcompressioncheck", usage: "/setcompressioncheck <name> on|off", descriptionKey: "cmd.setcompression", category: categoryServers, handler: l.setCompressionCheck, minArgs: 2, maxArgs: 2, httpOnly: true},
+		{name: "securitycheck", usage: "/securitycheck <name> on|off", descriptionKey: "cmd.securitycheck", category: categoryServers, handler: l.securityCheck, minArgs: 2, maxArgs: 2, httpOnly: true},
+		{name: "setsla", usage: "/setsla <name> <percent|clear> [window]", descriptionKey: "cmd.setsla", category: categoryServers, handler: l.setSla, minArgs: 2, maxArgs: 3},
+		{name: "sla", usage: "/sla", descriptionKey: "cmd.sla", category: categoryServers, permission: permissionRead, handler: l.sla},
+		{name: "setexpectedip", usage: "/setexpectedip <name> <ip|cidr>[,...]|clear [fail|warn]", descriptionKey: "cmd.setexpectedip", category: categoryServers, handler: l.setExpectedIps, minArgs: 2, maxArgs: 3, httpOnly: true},
+		{name: "setpin", usage: "/setpin <name> <sha256-fingerprint|current|clear>", descriptionKey: "cmd.setpin", category: categoryServers, handler: l.setPin, minArgs: 2, maxArgs: 2},
+		{name: "setprobes", usage: "/setprobes <name> <location,...|clear>", descriptionKey: "cmd.setprobes", category: categoryServers, handler: l.setProbes, minArgs: 2, maxArgs: 2, httpOnly: true},
+		{name: "uptimehistory", usage: "/uptimehistory <name>", descriptionKey: "cmd.uptimehistory", category: categoryServers, permission: permissionRead, handler: l.uptimeHistory, minArgs: 1, maxArgs: 1},
+		{name: "chart", usage: "/chart <name> [hours]", descriptionKey: "cmd.chart", category: categoryServers, permission: permissionRead, handler: l.chart, minArgs: 1, maxArgs: 2},
+		{name: "down", usage: "/down", descriptionKey: "cmd.down", category: categoryIncidents, permission: permissionRead, handler: l.down},
+		{name: "ack", usage: "/ack <name> [comment]", descriptionKey: "cmd.ack", category: categoryIncidents, handler: l.ack, minArgs: 1},
+		{name: "notifications", usage: "/notifications [name] [count]", descriptionKey: "cmd.notifications", category: categoryIncidents, permission: permissionRead, handler: l.notifications, maxArgs: 2},
+		{name: "maintenanceall", usage: "/maintenanceall <duration>|off", descriptionKey: "cmd.maintenanceall", category: categoryIncidents, handler: l.maintenanceAll, minArgs: 1, maxArgs: 1},
+		{name: "setcron", usage: "/setcron <spec>|default", descriptionKey: "cmd.setcron", category: categorySettings, handler: l.setCron},
+		{name: "setthresholdglobal", usage: "/setthresholdglobal <n>|clear", descriptionKey: "cmd.setthresholdglobal", category: categorySettings, handler: l.setThresholdGlobal, minArgs: 1, maxArgs: 1},
+		{name: "settings", usage: "/settings", descriptionKey: "cmd.settings", category: categorySettings, permission: permissionRead, handler: l.settings},
+		{name: "setlanguage", usage: "/setlanguage en|ru|default", descriptionKey: "cmd.setlanguage", category: categorySettings, handler: l.setLanguage},
+		{name: "settimezone", usage: "/settimezone <name>|default", descriptionKey: "cmd.settimezone", category: categorySettings, handler: l.setTimezone},
+		{name: "restore", usage: "/restore [undo]", descriptionKey: "cmd.restore", category: categorySettings, handler: l.restore, maxArgs: 1},
+		{name: "simulate", usage: "/simulate <name> down <cycles>|off", descriptionKey: "cmd.simulate", category: categoryIncidents, handler: l.simulate, minArgs: 2, maxArgs: 3},
+		{name: "testalert", usage: "/testalert [name] [down|up|sla|pin]", descriptionKey: "cmd.testalert", category: categoryIncidents, handler: l.testAlert, maxArgs: 2},
+		{name: "subscribe", usage: "/subscribe <name>|tag:<tag>|all", descriptionKey: "cmd.subscribe", category: categoryIncidents, permission: permissionPrivate, handler: l.subscribe, minArgs: 1, maxArgs: 1},
+		{name: "unsubscribe", usage: "/unsubscribe <name>|tag:<tag>|all", descriptionKey: "cmd.unsubscribe", category: categoryIncidents, permission: permissionPrivate, handler: l.unsubscribe, minArgs: 1, maxArgs: 1},
+		{name: "subscriptions", usage: "/subscriptions", descriptionKey: "cmd.subscriptions", category: categoryIncidents, permission: permissionPrivate, handler: l.subscriptions},
+		{name: "preferences", usage: "/preferences [quiet HH:MM-HH:MM|off]", descriptionKey: "cmd.preferences", category: categoryIncidents, permission: permissionPrivate, handler: l.preferences, maxArgs: 2},
+		{name: "debug", usage: "/debug on|off|status", descriptionKey: "cmd.debug", category: categoryBot, handler: l.debug},
+		{name: "keep", usage: "/keep", descriptionKey: "cmd.keep", category: categoryBot, handler: l.keep},
+		{name: "heartbeat", usage: "/heartbeat on|off", descriptionKey: "cmd.heartbeat", category: categoryBot, handler: l.heartbeat, minArgs: 1, maxArgs: 1},
+		{name: "botstats", usage: "/botstats", descriptionKey: "cmd.botstats", category: categoryBot, permission: permissionRead, handler: l.botStats},
+		{name: "version", usage: "/version", descriptionKey: "cmd.version", category: categoryBot, permission: permissionRead, handler: l.version},
+		{name: "ping", usage: "/ping", descriptionKey: "cmd.ping", category: categoryBot, permission: permissionRead, handler: l.ping},
+		{name: "help", usage: "/help [command]", descriptionKey: "cmd.help", category: categoryBot, permission: permissionRead, handler: l.help},
+		{name: "whoami", usage: "/whoami", descriptionKey: "cmd.whoami", category: categorySupers, permission: permissionPublic, handler: l.whoami},
+		{name: "addsuper", usage: "/addsuper <username>", descriptionKey: "cmd.addsuper", category: categorySupers, handler: l.addSuper, minArgs: 1, maxArgs: 1},
+		{name: "removesuper", usage: "/removesuper <username>", descriptionKey: "cmd.removesuper", category: categorySupers, handler: l.removeSuper, minArgs: 1, maxArgs: 1},
+		{name: "listsupers", usage: "/listsupers", descriptionKey: "cmd.listsupers", category: categorySupers, handler: l.listSupers},
+	}
+}
+
+// menuDescription returns description of the command for the telegram commands menu, with arguments if there are any
+func (c command) menuDescription(lang i18n.Lang) string {
+	var description = i18n.T(lang, c.descriptionKey)
+	if c.usage != "/"+c.name {
+		description += ": " + c.usage
+	}
+	return description
+}
+
+// handleCommand runs the command handler wrapped in the middleware, unknown commands are handled as superuser commands
+func (l *TelegramListener) handleCommand(message *tgbotapi.Message) {
+	name, botName, args := parseCommand(message.Text)
+
+	// ignore commands addressed to other bots in groups
+	if botName != "" && !strings.EqualFold(botName, l.Bot.Self.UserName) {
+		return
+	}
+
+	cmd, found := l.findCommand(name)
+	if !found {
+		cmd = command{name: name, permission: permissionRead, handler: func(ctx *commandContext) {
+			l.unknownCommand(ctx, name)
+		}}
+	} else {
+		// unknown commands can be fixed by editing the message
+		l.markExecuted(message)
+	}
+
+	var ctx = l.newCommandContext(message, args)
+	ctx.usage = cmd.usage
+	l.withMiddleware(cmd)(ctx)
+}
+
+// findCommand returns the registered command with the name, case insensitive
+func (l *TelegramListener) findCommand(name string) (command, bool) {
+	for _, cmd := range l.commands() {
+		if strings.EqualFold(cmd.name, name) {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// RegisterCommands sets the telegram commands menu for the allowed chats, failures are not fatal
+func (l *TelegramListener) RegisterCommands() {
+	var settings = checks.ReadChecksData().Settings
+	for _, chatId := range l.allowedChats() {
+		l.registerChatCommands(settings.MigratedChat(chatId))
+	}
+}
+
+// registerChatCommands sets the telegram commands menu of the chat in the language of the chat
+func (l *TelegramListener) registerChatCommands(chatId int64) {
+	var lang = l.lang(chatId)
+
+	var botCommands []tgbotapi.BotCommand
+	for _, cmd := range l.commands() {
+		botCommands = append(botCommands, tgbotapi.BotCommand{Command: cmd.name, Description: cmd.menuDescription(lang)})
+	}
+
+	scope := tgbotapi.NewBotCommandScopeChat(chatId)
+	if _, err := l.Bot.Request(tgbotapi.NewSetMyCommandsWithScope(scope, botCommands...)); err != nil {
+		log.Printf("[WARN] Failed to set bot commands for chat %d: %v", chatId, err)
+	}
+}
+
+// parseCommand splits command text like "/add@my_bot example.com name" into command name, addressed bot name
+// and arguments, without relying on the command entity length
+func parseCommand(text string) (name string, botName string, args string) {
+	var command = text
+	if i := strings.IndexFunc(text, unicode.IsSpace); i != -1 {
+		command = text[:i]
+		args = strings.TrimLeftFunc(text[i:], unicode.IsSpace)
+	}
+
+	name = strings.TrimPrefix(command, "/")
+	if i := strings.Index(name, "@"); i != -1 {
+		botName = name[i+1:]
+		name = name[:i]
+	}
+
+	return name, botName, args
+}
+
+// splitArgs splits arguments by whitespace, text in double quotes is a single argument, e.g. a name with spaces
+func splitArgs(args string) []string {
+	var fields []string
+	var field strings.Builder
+	var inField, quoted bool
+	for _, r := range args {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inField = true
+		case unicode.IsSpace(r) && !quoted:
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+
+	return fields
+}
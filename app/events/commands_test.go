@@ -0,0 +1,142 @@
+package events
+
+import (
+	"encoding/json"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestRegisterCommands(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	l.RegisterCommands()
+
+	var requests = telegram.requested("setMyCommands")
+	if len(requests) != 1 {
+		t.Fatalf("got %d setMyCommands requests, want one for the chat", len(requests))
+	}
+
+	var scope struct {
+		Type   string `json:"type"`
+		ChatId int64  `json:"chat_id"`
+	}
+	if err := json.Unmarshal([]byte(requests[0].Get("scope")), &scope); err != nil {
+		t.Fatal(err)
+	}
+	if scope.Type != "chat" || scope.ChatId != testChat {
+		t.Errorf("got scope %+v, want the chat %d", scope, testChat)
+	}
+
+	var botCommands []struct {
+		Command     string `json:"command"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal([]byte(requests[0].Get("commands")), &botCommands); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, botCommand := range botCommands {
+		if botCommand.Description == "" {
+			t.Errorf("command /%s has no description", botCommand.Command)
+		}
+		names = append(names, botCommand.Command)
+	}
+	for _, name := range []string{"add", "remove", "list", "details", "help"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("menu %v has no /%s", names, name)
+		}
+	}
+
+	// the menu is the registry, so every command of the menu is handled
+	var registered []string
+	for _, cmd := range l.commands() {
+		registered = append(registered, cmd.name)
+	}
+	if !slices.Equal(names, registered) {
+		t.Errorf("got menu %v, want the registered commands %v", names, registered)
+	}
+}
+
+func TestRegisterCommandsFailureIsNotFatal(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+	telegram.breakMethod("setMyCommands")
+
+	l.RegisterCommands()
+
+	if requests := telegram.requested("setMyCommands"); len(requests) != 1 {
+		t.Errorf("got %d setMyCommands requests, want one", len(requests))
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	var tests = []struct {
+		text                string
+		name, botName, args string
+	}{
+		{"/list", "list", "", ""},
+		{"/list@test_bot", "list", "test_bot", ""},
+		{"/add example.com web", "add", "", "example.com web"},
+		{"/add@test_bot example.com web", "add", "test_bot", "example.com web"},
+		{"/add@other_bot   example.com\tweb", "add", "other_bot", "example.com\tweb"},
+		{"/details@test_bot \"my server\"", "details", "test_bot", "\"my server\""},
+	}
+
+	for _, test := range tests {
+		name, botName, args := parseCommand(test.text)
+		if name != test.name || botName != test.botName || args != test.args {
+			t.Errorf("parseCommand(%q) = %q, %q, %q, want %q, %q, %q",
+				test.text, name, botName, args, test.name, test.botName, test.args)
+		}
+	}
+}
+
+func TestCommandAddressedToBot(t *testing.T) {
+	var tests = []struct {
+		name      string
+		text      string
+		wantReply string
+	}{
+		{"unaddressed", "/remove web", "Server web removed"},
+		{"this bot", "/remove@test_bot web", "Server web removed"},
+		{"this bot in other case", "/remove@Test_Bot web", "Server web removed"},
+		{"other bot", "/remove@other_bot web", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"})
+			l, telegram := newTestListener(t)
+
+			l.handleCommand(commandMessage(1, test.text))
+
+			assertReplies(t, telegram, test.wantReply)
+			if _, ok := checks.ReadChecksData().HealthChecks["web"]; ok == (test.wantReply != "") {
+				t.Errorf("server stored: %v after %s", ok, test.text)
+			}
+		})
+	}
+}
+
+func TestAddressedAddParsesArguments(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/add "+server.URL+" plain"))
+	l.handleCommand(commandMessage(2, "/add@test_bot "+server.URL+" addressed"))
+	l.handleCommand(commandMessage(3, "/add@other_bot "+server.URL+" other"))
+	// the first checks edit the replies
+	waitFor(t, func() bool { return len(telegram.requested("editMessageText")) == 2 })
+
+	var healthChecks = checks.ReadChecksData().HealthChecks
+	if len(healthChecks) != 2 || healthChecks["plain"].Url != server.URL || healthChecks["addressed"].Url != server.URL {
+		t.Errorf("got servers %v, want plain and addressed with the same url", storedServers())
+	}
+}
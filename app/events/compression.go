@@ -0,0 +1,32 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+)
+
+// setCompressionCheck switches the warning about text responses of the server sent without compression
+func (l *TelegramListener) setCompressionCheck(ctx *commandContext) {
+	var name, mode = ctx.fields[0], ctx.fields[1]
+	if clearArg(mode) {
+		mode = "off"
+	}
+	if mode != "on" && mode != "off" {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	err := checks.SetCompressionCheck(name, mode == "on")
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	l.reply(ctx.chatId, "compression."+mode, name)
+}
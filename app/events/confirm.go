@@ -0,0 +1,139 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var confirmationTimeout = 60 * time.Second
+
+// confirmation is a destructive action waiting for the Confirm button
+type confirmation struct {
+	userId    int64
+	chatId    int64
+	messageId int
+	action    func()
+	timer     *time.Timer
+}
+
+type confirmations struct {
+	mutex   sync.Mutex
+	lastId  int
+	pending map[string]*confirmation
+}
+
+// requestConfirmation asks the user to confirm action with inline buttons,
+// the action runs only when the same user presses Confirm within the timeout
+func (l *TelegramListener) requestConfirmation(ctx *commandContext, text string, action func()) {
+	l.confirmInMessage(ctx.chatId, ctx.user.ID, 0, text, action)
+}
+
+// confirmInMessage asks for confirmation editing the message with messageId, or in a new message if it is 0
+func (l *TelegramListener) confirmInMessage(chatId int64, userId int64, messageId int, text string, action func()) {
+	l.confirmations.mutex.Lock()
+	l.confirmations.lastId++
+	var id = strconv.Itoa(l.confirmations.lastId)
+	l.confirmations.mutex.Unlock()
+
+	var lang = l.lang(chatId)
+	var keyboard = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.confirm"), "confirm:"+id),
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.cancel"), "cancel:"+id),
+	))
+
+	var msg tgbotapi.Chattable
+	if messageId == 0 {
+		newMessage := tgbotapi.NewMessage(chatId, text)
+		newMessage.ReplyMarkup = keyboard
+		msg = newMessage
+	} else {
+		msg = tgbotapi.NewEditMessageTextAndMarkup(chatId, messageId, text, keyboard)
+	}
+
+	sent, err := l.send(msg)
+	if err != nil {
+		log.Printf("[ERROR] Failed to send confirmation: %v", err)
+		return
+	}
+
+	var pending = &confirmation{
+		userId:    userId,
+		chatId:    chatId,
+		messageId: sent.MessageID,
+		action:    action,
+	}
+	pending.timer = time.AfterFunc(confirmationTimeout, func() {
+		if l.takeConfirmation(id) == nil {
+			return
+		}
+		l.editConfirmation(pending, fmt.Sprintf("%s\n\n%s", text, i18n.T(lang, "confirm.timeout")))
+	})
+
+	l.confirmations.mutex.Lock()
+	if l.confirmations.pending == nil {
+		l.confirmations.pending = make(map[string]*confirmation)
+	}
+	l.confirmations.pending[id] = pending
+	l.confirmations.mutex.Unlock()
+}
+
+func (l *TelegramListener) confirmCallback(query *tgbotapi.CallbackQuery, id string, confirmed bool) {
+	l.confirmations.mutex.Lock()
+	pending, ok := l.confirmations.pending[id]
+	l.confirmations.mutex.Unlock()
+
+	// ids start over after a restart, so buttons of other messages may carry the id of the pending confirmation
+	var lang = l.lang(query.Message.Chat.ID)
+	if !ok || query.Message.MessageID != pending.messageId || query.Message.Chat.ID != pending.chatId {
+		l.answerCallbackAlert(query, i18n.T(lang, "confirm.expired"))
+		return
+	}
+
+	if pending.userId != query.From.ID {
+		l.answerCallbackAlert(query, i18n.T(lang, "confirm.other_user"))
+		return
+	}
+
+	if l.takeConfirmation(id) == nil {
+		l.answerCallbackAlert(query, i18n.T(lang, "confirm.expired"))
+		return
+	}
+	pending.timer.Stop()
+
+	if !confirmed {
+		l.answerCallback(query, i18n.T(lang, "confirm.cancelled"))
+		l.editConfirmation(pending, fmt.Sprintf("%s\n\n%s", query.Message.Text, i18n.T(lang, "confirm.cancelled")))
+		return
+	}
+
+	l.answerCallback(query, i18n.T(lang, "confirm.confirmed"))
+	l.editConfirmation(pending, fmt.Sprintf("%s\n\n%s", query.Message.Text,
+		i18n.T(lang, "confirm.confirmed_by", userIdentity(query.From))))
+	pending.action()
+}
+
+// takeConfirmation removes pending confirmation, nil is returned if it was already handled
+func (l *TelegramListener) takeConfirmation(id string) *confirmation {
+	l.confirmations.mutex.Lock()
+	defer l.confirmations.mutex.Unlock()
+
+	pending, ok := l.confirmations.pending[id]
+	if !ok {
+		return nil
+	}
+	delete(l.confirmations.pending, id)
+
+	return pending
+}
+
+// editConfirmation replaces confirmation text and removes its buttons
+func (l *TelegramListener) editConfirmation(pending *confirmation, text string) {
+	if _, err := l.Sender.Send(tgbotapi.NewEditMessageText(pending.chatId, pending.messageId, text)); err != nil {
+		log.Printf("[ERROR] Failed to edit confirmation: %v", err)
+	}
+}
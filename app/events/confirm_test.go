@@ -0,0 +1,170 @@
+package events
+
+import (
+	"encoding/json"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"slices"
+	"testing"
+	"time"
+)
+
+const removeAllConfirm = "⚠️ This will delete 2 servers"
+
+// requestRemoveAll sends /removeall and returns the confirmation message with its buttons
+func requestRemoveAll(t *testing.T, l *TelegramListener, telegram *fakeTelegram) *tgbotapi.Message {
+	t.Helper()
+	l.handleCommand(commandMessage(1, "/removeall"))
+
+	var requests = telegram.requested("sendMessage")
+	if len(requests) != 1 || requests[0].Get("text") != removeAllConfirm {
+		t.Fatalf("got requests %v, want the confirmation", requests)
+	}
+	var markup tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(requests[0].Get("reply_markup")), &markup); err != nil {
+		t.Fatal(err)
+	}
+	return &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: testChat}, Text: removeAllConfirm, ReplyMarkup: &markup}
+}
+
+// pressButton presses the button of the message with the index as the user, testSuper has the id of commandMessage
+func pressButton(l *TelegramListener, message *tgbotapi.Message, index int, userName string) {
+	var userId = int64(20)
+	if userName == testSuper {
+		userId = 10
+	}
+	l.processUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "1",
+		From:    &tgbotapi.User{ID: userId, UserName: userName},
+		Message: message,
+		Data:    *message.ReplyMarkup.InlineKeyboard[0][index].CallbackData,
+	}})
+}
+
+// callbackAnswers returns texts of the answers to button presses
+func callbackAnswers(telegram *fakeTelegram) []string {
+	telegram.mutex.Lock()
+	defer telegram.mutex.Unlock()
+	return slices.Clone(telegram.answers)
+}
+
+func useConfirmationStorage(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"},
+		checks.ServerCheck{Name: "api", Url: "https://api.example.com"})
+}
+
+func TestConfirm(t *testing.T) {
+	useConfirmationStorage(t)
+	l, telegram := newTestListener(t)
+
+	var confirmation = requestRemoveAll(t, l, telegram)
+	if servers := storedServers(); len(servers) != 2 {
+		t.Fatalf("servers were removed before confirmation: %v", servers)
+	}
+	pressButton(l, confirmation, 0, testSuper)
+
+	if servers := storedServers(); len(servers) != 0 {
+		t.Errorf("got servers %v after confirmation, want none", servers)
+	}
+	var want = []string{removeAllConfirm, removeAllConfirm + "\n\nConfirmed by @admin", "All servers removed"}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got messages %q, want %q", sent, want)
+	}
+	if answers := callbackAnswers(telegram); !slices.Equal(answers, []string{"Confirmed"}) {
+		t.Errorf("got answers %q", answers)
+	}
+
+	// the action runs once
+	pressButton(l, confirmation, 0, testSuper)
+	if answers := callbackAnswers(telegram); answers[len(answers)-1] != "Confirmation expired" {
+		t.Errorf("got answers %q, want the second press expired", answers)
+	}
+}
+
+func TestConfirmCancel(t *testing.T) {
+	useConfirmationStorage(t)
+	l, telegram := newTestListener(t)
+
+	var confirmation = requestRemoveAll(t, l, telegram)
+	pressButton(l, confirmation, 1, testSuper)
+	pressButton(l, confirmation, 0, testSuper)
+
+	if servers := storedServers(); len(servers) != 2 {
+		t.Errorf("got servers %v after cancel, want both kept", servers)
+	}
+	var want = []string{removeAllConfirm, removeAllConfirm + "\n\nCancelled"}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got messages %q, want %q", sent, want)
+	}
+	if answers := callbackAnswers(telegram); !slices.Equal(answers, []string{"Cancelled", "Confirmation expired"}) {
+		t.Errorf("got answers %q, want the confirm after cancel expired", answers)
+	}
+}
+
+func TestConfirmTimeout(t *testing.T) {
+	var timeout = confirmationTimeout
+	confirmationTimeout = 20 * time.Millisecond
+	t.Cleanup(func() { confirmationTimeout = timeout })
+	useConfirmationStorage(t)
+	l, telegram := newTestListener(t)
+
+	var confirmation = requestRemoveAll(t, l, telegram)
+	waitFor(t, func() bool { return len(telegram.sent()) == 2 })
+	pressButton(l, confirmation, 0, testSuper)
+
+	if servers := storedServers(); len(servers) != 2 {
+		t.Errorf("got servers %v after timeout, want both kept", servers)
+	}
+	var edits = telegram.requested("editMessageText")
+	if len(edits) != 1 || edits[0].Get("text") != removeAllConfirm+"\n\n⌛ Not confirmed in time" || edits[0].Get("reply_markup") != "" {
+		t.Errorf("got edits %v, want the buttons removed", edits)
+	}
+	if answers := callbackAnswers(telegram); !slices.Equal(answers, []string{"Confirmation expired"}) {
+		t.Errorf("got answers %q", answers)
+	}
+}
+
+func TestConfirmOtherUser(t *testing.T) {
+	useConfirmationStorage(t)
+	l, telegram := newTestListener(t)
+	l.SuperUsers = SuperUser{testSuper, "bob"}
+
+	var confirmation = requestRemoveAll(t, l, telegram)
+	pressButton(l, confirmation, 0, "bob")
+	if servers := storedServers(); len(servers) != 2 {
+		t.Fatalf("got servers %v after another user confirmed, want both kept", servers)
+	}
+
+	// the user who issued the command still can confirm
+	pressButton(l, confirmation, 0, testSuper)
+	if servers := storedServers(); len(servers) != 0 {
+		t.Errorf("got servers %v, want none", servers)
+	}
+	var want = []string{"Only the user who issued the command can confirm it", "Confirmed"}
+	if answers := callbackAnswers(telegram); !slices.Equal(answers, want) {
+		t.Errorf("got answers %q, want %q", answers, want)
+	}
+}
+
+func TestConfirmButtonOfOtherMessage(t *testing.T) {
+	useConfirmationStorage(t)
+	l, telegram := newTestListener(t)
+
+	var confirmation = requestRemoveAll(t, l, telegram)
+	// a button with the same id on an older message, like one sent before a restart
+	var stale = *confirmation
+	stale.MessageID = 100
+	pressButton(l, &stale, 0, testSuper)
+	var otherChat = *confirmation
+	otherChat.Chat = &tgbotapi.Chat{ID: 42}
+	pressButton(l, &otherChat, 0, testSuper)
+
+	if servers := storedServers(); len(servers) != 2 {
+		t.Errorf("got servers %v after a press on another message, want both kept", servers)
+	}
+	var want = []string{"Confirmation expired", "Not authorized"}
+	if answers := callbackAnswers(telegram); !slices.Equal(answers, want) {
+		t.Errorf("got answers %q, want %q", answers, want)
+	}
+}
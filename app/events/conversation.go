@@ -0,0 +1,208 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var conversationTimeout = 5 * time.Minute
+
+type conversationStep int
+
+const (
+	stepAskUrl conversationStep = iota
+	stepAskName
+	stepAskThreshold
+)
+
+// conversationKey identifies a guided conversation of a user in a chat
+type conversationKey struct {
+	chatId int64
+	userId int64
+}
+
+// conversation is a state of the guided flow, like /add or editing the alert threshold from /details
+type conversation struct {
+	step      conversationStep
+	url       string
+	basicAuth string
+	expires   time.Time
+
+	// server and details message edited at the end of the flow
+	name             string
+	detailsMessageId int
+}
+
+func (l *TelegramListener) startAddConversation(chatId int64, userId int64) {
+	l.startConversation(conversationKey{chatId, userId}, &conversation{step: stepAskUrl})
+
+	var lang = l.lang(chatId)
+	l.sendConversationPrompt(chatId, i18n.T(lang, "add.ask_url"), cancelKeyboard(lang))
+}
+
+// processConversation handles a non-command message of a user with an active conversation
+func (l *TelegramListener) processConversation(message *tgbotapi.Message) {
+	var key = conversationKey{message.Chat.ID, message.From.ID}
+	current := l.activeConversation(key)
+	if current == nil {
+		return
+	}
+
+	var text = strings.TrimSpace(message.Text)
+	var lang = l.lang(message.Chat.ID)
+
+	switch current.step {
+	case stepAskUrl:
+		var serverUrl = checks.FullServerUrl(text)
+		if parsedUrl, err := url.Parse(serverUrl); err != nil || parsedUrl.Host == "" || strings.Contains(text, " ") {
+			l.sendConversationPrompt(message.Chat.ID, i18n.T(lang, "add.invalid_url"), cancelKeyboard(lang))
+			return
+		}
+
+		current.url, current.basicAuth = checks.ExtractCredentials(serverUrl)
+		if current.basicAuth != "" {
+			l.warnCredentials(message)
+		}
+		current.step = stepAskName
+		current.expires = time.Now().Add(conversationTimeout)
+
+		l.sendConversationPrompt(message.Chat.ID, i18n.T(lang, "add.ask_name"), tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.url_as_name"), "addflow:urlname"),
+				tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.cancel"), "addflow:cancel"),
+			),
+		))
+
+	case stepAskName:
+		var fields = splitArgs(text)
+		if len(fields) != 1 {
+			l.sendConversationPrompt(message.Chat.ID, i18n.T(lang, "add.invalid_name"), cancelKeyboard(lang))
+			return
+		}
+
+		l.finishAddConversation(key, message.Chat.ID, fields[0])
+
+	case stepAskThreshold:
+		threshold, err := strconv.Atoi(text)
+		if err != nil || threshold < 0 {
+			l.sendConversationPrompt(message.Chat.ID, i18n.T(lang, "add.threshold_invalid"), cancelKeyboard(lang))
+			return
+		}
+
+		delete(l.conversations, key)
+		l.setServerThreshold(message.Chat.ID, current.name, threshold, current.detailsMessageId)
+	}
+}
+
+func (l *TelegramListener) addFlowCallback(query *tgbotapi.CallbackQuery, action string) {
+	var key = conversationKey{query.Message.Chat.ID, query.From.ID}
+	var lang = l.lang(query.Message.Chat.ID)
+	current := l.activeConversation(key)
+	if current == nil {
+		l.answerCallbackAlert(query, i18n.T(lang, "add.expired"))
+		return
+	}
+
+	switch action {
+	case "cancel":
+		delete(l.conversations, key)
+		l.answerCallback(query, i18n.T(lang, "confirm.cancelled"))
+		l.reply(query.Message.Chat.ID, "add.cancelled")
+	case "urlname":
+		if current.step != stepAskName {
+			l.answerCallback(query, i18n.T(lang, "add.url_first"))
+			return
+		}
+
+		l.answerCallback(query, "")
+		l.finishAddConversation(key, query.Message.Chat.ID, checks.UrlName(current.url))
+	}
+}
+
+func (l *TelegramListener) undoAddCallback(query *tgbotapi.CallbackQuery, name string) {
+	var lang = l.lang(query.Message.Chat.ID)
+	err := checks.RemoveServer(name)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.answerCallbackAlert(query, i18n.T(lang, "server.not_exists", name))
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.answerCallbackAlert(query, i18n.T(lang, "server.remove_failed", name))
+		return
+	}
+
+	l.answerCallback(query, i18n.T(lang, "add.undo_removed"))
+	if _, err := l.Sender.Send(tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID,
+		i18n.T(lang, "add.undone", name))); err != nil {
+		log.Printf("[ERROR] Failed to edit message: %v", err)
+	}
+}
+
+func (l *TelegramListener) finishAddConversation(key conversationKey, chatId int64, name string) {
+	var current = l.conversations[key]
+	delete(l.conversations, key)
+
+	var server = Server{Url: current.url, Name: name, BasicAuth: current.basicAuth}
+	if !l.createServer(chatId, server) {
+		return
+	}
+
+	var markup = undoAddKeyboard(l.lang(chatId), server.Name)
+	l.sendAdded(chatId, server, &markup)
+}
+
+// undoAddKeyboard returns the button removing the server added by the guided flow
+func undoAddKeyboard(lang i18n.Lang, name string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.undo"), "undoadd:"+notify.ServerRef(name)),
+	))
+}
+
+func (l *TelegramListener) startConversation(key conversationKey, current *conversation) {
+	if l.conversations == nil {
+		l.conversations = make(map[conversationKey]*conversation)
+	}
+
+	current.expires = time.Now().Add(conversationTimeout)
+	l.conversations[key] = current
+}
+
+// activeConversation returns not expired conversation, expired conversations are removed
+func (l *TelegramListener) activeConversation(key conversationKey) *conversation {
+	current, ok := l.conversations[key]
+	if !ok {
+		return nil
+	}
+
+	if time.Now().After(current.expires) {
+		delete(l.conversations, key)
+		return nil
+	}
+
+	return current
+}
+
+// sendConversationPrompt sends the prompt with buttons, in groups with privacy mode enabled
+// the user has to reply to the prompt for the answer to reach the bot
+func (l *TelegramListener) sendConversationPrompt(chatId int64, text string, keyboard tgbotapi.InlineKeyboardMarkup) {
+	msg := tgbotapi.NewMessage(chatId, text)
+	msg.ReplyMarkup = keyboard
+	if _, err := l.send(msg); err != nil {
+		log.Printf("[ERROR] Failed to send message: %v", err)
+	}
+}
+
+func cancelKeyboard(lang i18n.Lang) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.cancel"), "addflow:cancel"),
+	))
+}
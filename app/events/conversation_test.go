@@ -0,0 +1,187 @@
+package events
+
+import (
+	"encoding/json"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// say sends the text as a message of testSuper
+func say(l *TelegramListener, id int, text string) {
+	l.processUpdate(tgbotapi.Update{Message: commandMessage(id, text)})
+}
+
+// lastButton returns the message with buttons last sent and the callback data of its button with the index
+func lastButton(t *testing.T, telegram *fakeTelegram, index int) (*tgbotapi.Message, string) {
+	t.Helper()
+	telegram.mutex.Lock()
+	defer telegram.mutex.Unlock()
+	var requests = telegram.requests["sendMessage"]
+	for i := len(requests) - 1; i >= 0; i-- {
+		var markup tgbotapi.InlineKeyboardMarkup
+		if json.Unmarshal([]byte(requests[i].Get("reply_markup")), &markup) != nil || len(markup.InlineKeyboard) == 0 {
+			continue
+		}
+		var message = &tgbotapi.Message{MessageID: 100 + i, Chat: &tgbotapi.Chat{ID: testChat}, Text: requests[i].Get("text")}
+		return message, *markup.InlineKeyboard[0][index].CallbackData
+	}
+	t.Fatal("no message with buttons was sent")
+	return nil, ""
+}
+
+// press presses the button with the data on the message as testSuper
+func press(l *TelegramListener, message *tgbotapi.Message, data string) {
+	l.processUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID: "1", From: &tgbotapi.User{ID: 10, UserName: testSuper}, Message: message, Data: data,
+	}})
+}
+
+func okServer(t *testing.T) string {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestAddConversation(t *testing.T) {
+	var serverUrl = okServer(t)
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	say(l, 1, "/add")
+	say(l, 2, "not a url")
+	say(l, 3, serverUrl)
+	say(l, 4, "two words")
+	say(l, 5, "web")
+	// the first check edits the reply
+	waitFor(t, func() bool { return len(telegram.requested("editMessageText")) == 1 })
+
+	var sent = telegram.sent()
+	var want = []string{
+		"Send the server URL, for example: github.com",
+		"Invalid URL, send the server URL again",
+		"Send the server name",
+		"Name must be a single word or quoted, send the server name again",
+		"Server web [" + serverUrl + "] added\n⏳ Checking...",
+	}
+	if len(sent) != len(want)+1 || !slices.Equal(sent[:len(want)], want) {
+		t.Fatalf("got messages %q, want %q and the first check", sent, want)
+	}
+	if !strings.HasPrefix(sent[len(want)], "Server web ["+serverUrl+"] added\n✅ 200 OK") {
+		t.Errorf("got %q, want the result of the first check", sent[len(want)])
+	}
+	if stored := checks.ReadChecksData().HealthChecks["web"]; stored.Url != serverUrl {
+		t.Errorf("got stored server %+v", stored)
+	}
+
+	// the conversation is over
+	say(l, 6, "api")
+	if len(telegram.sent()) != len(want)+1 {
+		t.Errorf("message after the conversation got a reply: %q", telegram.sent())
+	}
+
+	var added, undo = lastButton(t, telegram, 0)
+	press(l, added, undo)
+	if servers := storedServers(); len(servers) != 0 {
+		t.Errorf("got servers %v after undo, want none", servers)
+	}
+}
+
+func TestAddConversationUrlAsName(t *testing.T) {
+	var serverUrl = okServer(t)
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	say(l, 1, "/add")
+	// the url prompt has no such button, like a press of the button of an older prompt
+	var prompt, _ = lastButton(t, telegram, 0)
+	press(l, prompt, "addflow:urlname")
+	say(l, 2, serverUrl+"/health")
+	prompt, urlName := lastButton(t, telegram, 0)
+	press(l, prompt, urlName)
+	waitFor(t, func() bool { return len(telegram.requested("editMessageText")) == 1 })
+
+	var name = strings.TrimPrefix(serverUrl, "http://") + "/health"
+	if servers := storedServers(); !slices.Equal(servers, []string{name}) {
+		t.Errorf("got servers %v, want %s", servers, name)
+	}
+	telegram.mutex.Lock()
+	var answers = slices.Clone(telegram.answers)
+	telegram.mutex.Unlock()
+	if len(answers) != 2 || answers[0] != "Send the server URL first" {
+		t.Errorf("got answers %q, want the url asked first", answers)
+	}
+}
+
+func TestAddConversationCancel(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	say(l, 1, "/add")
+	say(l, 2, "example.com")
+	var prompt, cancel = lastButton(t, telegram, 1)
+	press(l, prompt, cancel)
+	say(l, 3, "web")
+
+	if servers := storedServers(); len(servers) != 0 {
+		t.Errorf("got servers %v after cancel, want none", servers)
+	}
+	var want = []string{"Send the server URL, for example: github.com", "Send the server name", "Adding server cancelled"}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got messages %q, want %q", sent, want)
+	}
+}
+
+func TestAddConversationTimeout(t *testing.T) {
+	var timeout = conversationTimeout
+	conversationTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { conversationTimeout = timeout })
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	say(l, 1, "/add")
+	time.Sleep(20 * time.Millisecond)
+	say(l, 2, "example.com")
+	var prompt, cancel = lastButton(t, telegram, 0)
+	press(l, prompt, cancel)
+
+	assertReplies(t, telegram, "Send the server URL, for example: github.com")
+	telegram.mutex.Lock()
+	var answers = slices.Clone(telegram.answers)
+	telegram.mutex.Unlock()
+	if !slices.Equal(answers, []string{"Conversation expired, send /add again"}) {
+		t.Errorf("got answers %q, want the conversation expired", answers)
+	}
+}
+
+func TestAddConversationWithOtherCommands(t *testing.T) {
+	var serverUrl = okServer(t)
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "old", Url: "https://old.example.com"})
+	l, telegram := newTestListener(t)
+	l.SuperUsers = SuperUser{testSuper, "bob"}
+
+	say(l, 1, "/add")
+	// commands and messages of other users don't change the conversation
+	say(l, 2, "/remove old")
+	var other = commandMessage(3, "https://other.example.com")
+	other.From = &tgbotapi.User{ID: 20, UserName: "bob"}
+	l.processUpdate(tgbotapi.Update{Message: other})
+	say(l, 4, serverUrl)
+	say(l, 5, "web")
+	waitFor(t, func() bool { return len(telegram.requested("editMessageText")) == 1 })
+
+	if servers := storedServers(); !slices.Equal(servers, []string{"web"}) {
+		t.Errorf("got servers %v, want only web", servers)
+	}
+	var sent = telegram.sent()
+	var want = []string{"Send the server URL, for example: github.com", "Server old removed", "Send the server name"}
+	if len(sent) < 3 || !slices.Equal(sent[:3], want) {
+		t.Errorf("got messages %q, want %q first", sent, want)
+	}
+}
@@ -0,0 +1,17 @@
+package events
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+)
+
+// warnCredentials tells the user credentials of the url are used as basic auth and deletes the message with them,
+// the message is kept if the bot has no rights to delete it
+func (l *TelegramListener) warnCredentials(message *tgbotapi.Message) {
+	l.reply(message.Chat.ID, "add.credentials")
+
+	if _, err := l.Bot.Request(tgbotapi.NewDeleteMessage(message.Chat.ID, message.MessageID)); err != nil {
+		log.Printf("[WARN] Failed to delete message %d with credentials in chat %d: %v", message.MessageID,
+			message.Chat.ID, err)
+	}
+}
@@ -0,0 +1,385 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const detailsBarWidth = 30
+
+// maxRecentErrorSize is the number of characters of each recent error shown in details
+const maxRecentErrorSize = 120
+
+func (l *TelegramListener) details(ctx *commandContext) {
+	var name, window = ctx.fields[0], checks.DefaultReliabilityWindow
+	if len(ctx.fields) > 1 {
+		days, err := strconv.Atoi(ctx.fields[1])
+		if err != nil || days < 1 {
+			l.reply(ctx.chatId, "details.days_invalid")
+			return
+		}
+		window = time.Duration(days) * 24 * time.Hour
+	}
+
+	serverCheck, ok := checks.ReadChecksData().HealthChecks[name]
+	if !ok {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+
+	var lang = ctx.lang
+	msg := tgbotapi.NewMessage(ctx.chatId, formatServerDetails(lang, l.location(), serverCheck, window))
+	msg.ReplyMarkup = detailsKeyboard(lang, serverCheck)
+	l.send(msg)
+}
+
+// detailsKeyboard returns buttons to manage the server from the details message
+func detailsKeyboard(lang i18n.Lang, serverCheck checks.ServerCheck) tgbotapi.InlineKeyboardMarkup {
+	var ref = notify.ServerRef(serverCheck.Name)
+	var pauseButton = tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.pause"), "dpause:"+ref)
+	if serverCheck.Paused {
+		pauseButton = tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.resume"), "dresume:"+ref)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			pauseButton,
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.check_now"), "dcheck:"+ref),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.edit_threshold"), "dthreshold:"+ref),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.remove"), "dremove:"+ref),
+		),
+	)
+}
+
+func (l *TelegramListener) detailsActionCallback(query *tgbotapi.CallbackQuery, action string, name string) {
+	var lang = l.lang(query.Message.Chat.ID)
+
+	switch action {
+	case "dpause", "dresume":
+		var paused = action == "dpause"
+		err := checks.UpdateServerCheck(name, func(serverCheck *checks.ServerCheck) {
+			serverCheck.Paused = paused
+		})
+		if !l.handleDetailsError(query, name, err) {
+			return
+		}
+
+		if paused {
+			l.answerCallback(query, i18n.T(lang, "details.paused_answer"))
+		} else {
+			l.answerCallback(query, i18n.T(lang, "details.resumed_answer"))
+		}
+		l.editDetails(query.Message.Chat.ID, query.Message.MessageID, name)
+
+	case "dcheck":
+		l.answerCallback(query, i18n.T(lang, "recheck.checking"))
+		_, _, err := checks.RecheckServer(name)
+		if !l.handleDetailsError(query, name, err) {
+			return
+		}
+
+		l.editDetails(query.Message.Chat.ID, query.Message.MessageID, name)
+
+	case "dthreshold":
+		l.answerCallback(query, "")
+		l.startConversation(conversationKey{query.Message.Chat.ID, query.From.ID}, &conversation{
+			step:             stepAskThreshold,
+			name:             name,
+			detailsMessageId: query.Message.MessageID,
+		})
+		l.sendConversationPrompt(query.Message.Chat.ID, i18n.T(lang, "details.ask_threshold", name),
+			cancelKeyboard(lang))
+
+	case "dremove":
+		l.answerCallback(query, "")
+		var chatId, messageId = query.Message.Chat.ID, query.Message.MessageID
+		l.confirmInMessage(chatId, query.From.ID, messageId, i18n.T(lang, "details.remove_confirm", name), func() {
+			err := checks.RemoveServer(name)
+			if err != nil && !errors.Is(err, checks.ErrServerNotExists) {
+				log.Printf("[ERROR] Failed to save checks data: %v", err)
+				l.reply(chatId, "server.remove_failed", name)
+				return
+			}
+
+			l.Sender.Send(tgbotapi.NewEditMessageText(chatId, messageId, i18n.T(lang, "server.removed", name)))
+		})
+	}
+}
+
+// setServerThreshold sets alert threshold of the server and updates the details message
+func (l *TelegramListener) setServerThreshold(chatId int64, name string, threshold int, detailsMessageId int) {
+	err := checks.UpdateServerCheck(name, func(serverCheck *checks.ServerCheck) {
+		serverCheck.AlertThreshold = threshold
+	})
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(chatId, "details.threshold_failed", name)
+		return
+	}
+
+	l.editDetails(chatId, detailsMessageId, name)
+}
+
+// editDetails replaces the details message with the current server state
+func (l *TelegramListener) editDetails(chatId int64, messageId int, name string) {
+	serverCheck, ok := checks.ReadChecksData().HealthChecks[name]
+	if !ok {
+		return
+	}
+
+	var lang = l.lang(chatId)
+	var edit = tgbotapi.NewEditMessageTextAndMarkup(chatId, messageId, formatServerDetails(lang, l.location(), serverCheck,
+		checks.DefaultReliabilityWindow), detailsKeyboard(lang, serverCheck))
+	if _, err := l.Sender.Send(edit); err != nil {
+		log.Printf("[ERROR] Failed to edit message: %v", err)
+	}
+}
+
+// handleDetailsError answers the callback if err is not nil, returns true if there is no error
+func (l *TelegramListener) handleDetailsError(query *tgbotapi.CallbackQuery, name string, err error) bool {
+	var lang = l.lang(query.Message.Chat.ID)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.answerCallbackAlert(query, i18n.T(lang, "server.not_exists", name))
+		return false
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to update server %s: %v", name, err)
+		l.answerCallbackAlert(query, i18n.T(lang, "server.update_failed", name))
+		return false
+	}
+
+	return true
+}
+
+// formatServerDetails formats state of the server, MTTR and MTBF are computed over the window
+func formatServerDetails(lang i18n.Lang, location *time.Location, serverCheck checks.ServerCheck,
+	window time.Duration) string {
+	var text = fmt.Sprintf("%s %s\n", serverStatusIcon(serverCheck), serverCheck.Name)
+	text += i18n.T(lang, "details.url", redact.Url(serverCheck.Url))
+	if len(serverCheck.Tags) > 0 {
+		text += i18n.T(lang, "details.tags", strings.Join(serverCheck.Tags, ", "))
+	}
+	if serverCheck.BasicAuth != "" {
+		text += i18n.T(lang, "details.auth", "basic")
+	}
+	text += formatGrace(lang, serverCheck)
+	text += formatSslCheck(lang, location, serverCheck)
+	text += formatSshCheck(lang, serverCheck)
+	if len(checks.Schedules) > 0 {
+		if schedule := serverCheck.ScheduleName(); schedule == checks.DefaultSchedule {
+			text += i18n.T(lang, "details.schedule_default")
+		} else {
+			text += i18n.T(lang, "details.schedule", schedule, checks.Schedules[schedule])
+		}
+	}
+	if serverCheck.Soft404 {
+		text += i18n.T(lang, "details.soft404")
+	}
+	for _, check := range serverCheck.JsonChecks {
+		text += i18n.T(lang, "details.json", check)
+	}
+	if lastResult, ok := serverCheck.LastResult(); ok && lastResult.Proto != "" {
+		text += i18n.T(lang, "details.proto", lastResult.Proto)
+	}
+	if serverCheck.MinProto != "" {
+		text += i18n.T(lang, "details.proto_"+string(serverCheck.ProtoAction), serverCheck.MinProto)
+	}
+	if serverCheck.RemoteIp != "" {
+		var remoteIp = serverCheck.RemoteIp
+		if !serverCheck.RemoteIpExpected() {
+			remoteIp = "⚠️ " + remoteIp
+		}
+		text += i18n.T(lang, "details.remote_ip", remoteIp)
+	}
+	if len(serverCheck.ExpectedIps) > 0 {
+		text += i18n.T(lang, "details.expect_"+string(serverCheck.ExpectedIpAction), strings.Join(serverCheck.ExpectedIps, ", "))
+	}
+	if serverCheck.Uncompressed {
+		text += i18n.T(lang, "details.uncompressed")
+	} else if serverCheck.CompressionCheck {
+		text += i18n.T(lang, "details.compression")
+	}
+	text += checks.UptimeBar(serverCheck.History, detailsBarWidth) + "\n"
+	text += i18n.T(lang, "details.last_checked", formatTimeAgo(lang, location, serverCheck.LastChecked))
+	if lastResult, ok := serverCheck.LastResult(); ok && lastResult.Attempts > 1 {
+		if lastResult.Status == checks.StatusFailed {
+			text += i18n.T(lang, "details.attempt_failed", lastResult.Attempts)
+		} else {
+			text += i18n.T(lang, "details.attempt_ok", lastResult.Attempts, max(lastResult.Attempts, serverCheck.Retries+1))
+		}
+	}
+	text += i18n.T(lang, "details.last_success", formatTimeAgo(lang, location, serverCheck.LastSuccess))
+	text += i18n.T(lang, "details.last_failure", formatTimeAgo(lang, location, serverCheck.LastFailure))
+	if serverCheck.AlertThreshold > 0 {
+		text += i18n.T(lang, "details.threshold", serverCheck.AlertThreshold)
+	} else {
+		text += i18n.T(lang, "details.threshold_global")
+	}
+	if serverCheck.Retries > 0 {
+		var delay = serverCheck.RetryDelay
+		if delay == 0 {
+			delay = checks.DefaultRetryDelay
+		}
+		text += i18n.T(lang, "details.retries", serverCheck.Retries, delay)
+	}
+	text += formatBackoff(lang, serverCheck)
+	if len(serverCheck.DailyUptime) > 0 {
+		var days = serverCheck.UptimeDays(time.Now(), location, uptimeHistoryDays)
+		text += i18n.T(lang, "details.daily_uptime", i18n.Plural(lang, "unit.day", uptimeHistoryDays),
+			formatDailyAvailability(lang, days), checks.DayUptimeBar(days))
+	}
+	text += formatStreaks(lang, location, serverCheck)
+	text += formatRecentErrors(lang, location, serverCheck)
+	if changes := serverCheck.StateChanges(time.Now(), location, 30); changes > 0 {
+		text += i18n.T(lang, "details.state_changes", serverCheck.StateChanges(time.Now(), location, 1),
+			serverCheck.StateChanges(time.Now(), location, 7), changes)
+	}
+	if month, total := serverCheck.Downtime(time.Now(), location); total > 0 {
+		text += i18n.T(lang, "details.downtime", i18n.ShortDuration(lang, month), i18n.ShortDuration(lang, total))
+	}
+	if !serverCheck.ScoredAt.IsZero() {
+		if change, ok := serverCheck.ScoreChange(time.Now()); ok {
+			text += i18n.T(lang, "details.score_change", serverCheck.Score, change)
+		} else {
+			text += i18n.T(lang, "details.score", serverCheck.Score)
+		}
+	}
+	text += formatReliability(lang, location, serverCheck, window)
+	text += formatIpResults(lang, serverCheck)
+	text += formatEndpointResults(lang, serverCheck)
+	text += formatSecurityHeaders(lang, serverCheck)
+	text += formatProbeResults(lang, serverCheck)
+	if serverCheck.SlaTarget > 0 {
+		var sla = formatSla(lang, time.Now(), location, serverCheck)
+		if serverCheck.SlaBreached {
+			sla = "⚠️ " + sla
+		}
+		text += i18n.T(lang, "details.sla", sla)
+	}
+	switch {
+	case serverCheck.PinPending:
+		text += i18n.T(lang, "details.pin_pending")
+	case serverCheck.PinnedSpki != "":
+		text += i18n.T(lang, "details.pin", serverCheck.PinnedSpki)
+		if serverCheck.PinMismatch != "" {
+			text += i18n.T(lang, "details.pin_mismatch", serverCheck.PinMismatch)
+		}
+	}
+	text += formatDefaults(lang, serverCheck)
+	if serverCheck.Paused {
+		text += i18n.T(lang, "details.paused")
+	}
+	if serverCheck.SimulatedCycles > 0 {
+		text += i18n.T(lang, "details.simulated", serverCheck.SimulatedCycles)
+	} else if serverCheck.SimulatedFailure {
+		text += i18n.T(lang, "details.simulated_ended")
+	}
+	if serverCheck.Incident != nil {
+		text += formatIncident(lang, location, serverCheck.Incident)
+	}
+	if serverCheck.SnoozedUntil.After(time.Now()) {
+		text += i18n.T(lang, "details.snoozed", checks.FormatTime(serverCheck.SnoozedUntil, location))
+	}
+
+	return text
+}
+
+// formatReliability formats MTTR and MTBF of the server, n/a if there are too few incidents in the window
+func formatReliability(lang i18n.Lang, location *time.Location, serverCheck checks.ServerCheck, window time.Duration) string {
+	var since = time.Now().Add(-window)
+	var mttr, mtbf = formatMttrMtbf(lang, serverCheck, since)
+
+	var text = i18n.T(lang, "details.reliability", mttr, mtbf, i18n.ShortDuration(lang, window))
+	if since.Before(serverCheck.IncidentsRetainedSince) {
+		text += i18n.T(lang, "details.pruned", checks.FormatTime(serverCheck.IncidentsRetainedSince, location))
+	}
+	return text
+}
+
+// formatMttrMtbf formats MTTR and MTBF of incidents since the time, n/a if there are too few of them
+func formatMttrMtbf(lang i18n.Lang, serverCheck checks.ServerCheck, since time.Time) (mttr string, mtbf string) {
+	mttr, mtbf = i18n.T(lang, "details.na"), i18n.T(lang, "details.na")
+	if duration, ok := serverCheck.MTTR(since); ok {
+		mttr = i18n.ShortDuration(lang, duration)
+	}
+	if duration, ok := serverCheck.MTBF(since); ok {
+		mtbf = i18n.ShortDuration(lang, duration)
+	}
+	return mttr, mtbf
+}
+
+func formatIncident(lang i18n.Lang, location *time.Location, incident *checks.Incident) string {
+	var text = i18n.T(lang, "incident.since", formatTimeAgo(lang, location, incident.Start))
+	if incident.AckBy == "" {
+		return text + i18n.T(lang, "incident.not_acked")
+	}
+
+	text += i18n.T(lang, "incident.acked", incident.AckBy, formatTimeAgo(lang, location, incident.AckAt))
+	if incident.AckComment != "" {
+		text += i18n.T(lang, "incident.comment", incident.AckComment)
+	}
+
+	return text
+}
+
+func serverStatusIcon(serverCheck checks.ServerCheck) string {
+	if serverCheck.Paused {
+		return "⏸"
+	}
+	if serverCheck.IsOk {
+		return "✅"
+	}
+	return "❌"
+}
+
+// formatStreaks formats the current uptime or downtime streak and the records of the server
+func formatStreaks(lang i18n.Lang, location *time.Location, serverCheck checks.ServerCheck) string {
+	var text string
+	if streak, up, ok := serverCheck.CurrentStreak(time.Now()); ok && up {
+		text += i18n.T(lang, "details.streak_up", i18n.Duration(lang, streak.Duration()))
+	} else if ok {
+		text += i18n.T(lang, "details.streak_down", i18n.Duration(lang, streak.Duration()))
+	}
+
+	if record := serverCheck.LongestUptime; !record.End.IsZero() {
+		text += i18n.T(lang, "details.record_up", i18n.Duration(lang, record.Duration()),
+			checks.FormatTime(record.Start, location), checks.FormatTime(record.End, location))
+	}
+	if record := serverCheck.LongestDowntime; !record.End.IsZero() {
+		text += i18n.T(lang, "details.record_down", i18n.Duration(lang, record.Duration()),
+			checks.FormatTime(record.Start, location), checks.FormatTime(record.End, location))
+	}
+	return text
+}
+
+// formatRecentErrors formats distinct errors of the last failed checks with their count and the time last seen
+func formatRecentErrors(lang i18n.Lang, location *time.Location, serverCheck checks.ServerCheck) string {
+	if len(serverCheck.RecentErrors) == 0 {
+		return ""
+	}
+
+	var text = i18n.T(lang, "details.recent_errors")
+	for _, recent := range serverCheck.RecentErrors {
+		var message = notify.Truncate(recent.Message, maxRecentErrorSize)
+		if recent.Category != "" {
+			message = i18n.T(lang, "alert.category_"+recent.Category) + ": " + message
+		}
+		text += i18n.T(lang, "details.recent_error", message, recent.Count, checks.FormatTime(recent.LastSeen, location))
+	}
+	return text
+}
@@ -0,0 +1,45 @@
+package events
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"time"
+)
+
+// executedTtl is how long executed command messages are remembered, edits of them are ignored
+const executedTtl = 48 * time.Hour
+
+type messageKey struct {
+	chatId    int64
+	messageId int
+}
+
+// processEditedMessage handles the edited message as a new command, e.g. to fix a typo in the command name.
+// Edits of commands which already ran are ignored, so editing /remove doesn't remove a server twice.
+func (l *TelegramListener) processEditedMessage(message *tgbotapi.Message) {
+	if message.From == nil || !message.IsCommand() {
+		return
+	}
+
+	if _, ok := l.executed[messageKey{message.Chat.ID, message.MessageID}]; ok {
+		log.Printf("[DEBUG] Edited command %q already ran, ignored", message.Text)
+		return
+	}
+
+	l.handleCommand(message)
+}
+
+// markExecuted remembers the command message, messages older than executedTtl are forgotten
+func (l *TelegramListener) markExecuted(message *tgbotapi.Message) {
+	if l.executed == nil {
+		l.executed = make(map[messageKey]time.Time)
+	}
+
+	var now = time.Now()
+	for key, executedAt := range l.executed {
+		if now.Sub(executedAt) > executedTtl {
+			delete(l.executed, key)
+		}
+	}
+	l.executed[messageKey{message.Chat.ID, message.MessageID}] = now
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"slices"
+	"testing"
+)
+
+// editedUpdate is an update with the message edited to the text
+func editedUpdate(message *tgbotapi.Message, text string) tgbotapi.Update {
+	var edited = commandMessage(message.MessageID, text)
+	edited.EditDate = edited.Date
+	return tgbotapi.Update{EditedMessage: edited}
+}
+
+// storedServers returns sorted names of the stored servers
+func storedServers() []string {
+	var names []string
+	for name := range checks.ReadChecksData().HealthChecks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestEditedCommandRunsOnce(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"},
+		checks.ServerCheck{Name: "api", Url: "https://api.example.com"})
+	l, telegram := newTestListener(t)
+
+	var typo = commandMessage(1, "/remov web")
+	l.processUpdate(tgbotapi.Update{Message: typo})
+	if servers := storedServers(); len(servers) != 2 {
+		t.Fatalf("command with a typo removed a server: %v", servers)
+	}
+
+	l.processUpdate(editedUpdate(typo, "/remove web"))
+	l.processUpdate(editedUpdate(typo, "/remove api"))
+
+	if servers := storedServers(); !slices.Equal(servers, []string{"api"}) {
+		t.Errorf("got servers %v, want only api", servers)
+	}
+	var want = []string{"Unknown command /remov — did you mean /remove?", "Server web removed"}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got replies %q, want %q", sent, want)
+	}
+}
+
+func TestEditOfExecutedCommandIgnored(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"},
+		checks.ServerCheck{Name: "api", Url: "https://api.example.com"})
+	l, telegram := newTestListener(t)
+
+	var remove = commandMessage(1, "/remove web")
+	l.processUpdate(tgbotapi.Update{Message: remove})
+	if _, ok := l.executed[messageKey{testChat, 1}]; !ok {
+		t.Fatal("executed command was not remembered")
+	}
+
+	l.processUpdate(editedUpdate(remove, "/remove api"))
+
+	if servers := storedServers(); !slices.Equal(servers, []string{"api"}) {
+		t.Errorf("got servers %v, want only api", servers)
+	}
+	assertReplies(t, telegram, "Server web removed")
+}
+
+func TestEditedCommandOfStrangerIgnored(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"})
+	l, telegram := newTestListener(t)
+
+	var update = editedUpdate(commandMessage(1, "/ad web"), "/remove web")
+	update.EditedMessage.From.UserName = "stranger"
+	l.processUpdate(update)
+
+	if servers := storedServers(); len(servers) != 1 {
+		t.Errorf("edited command of a stranger removed a server: %v", servers)
+	}
+	assertReplies(t, telegram, "")
+}
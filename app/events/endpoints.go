@@ -0,0 +1,134 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"log"
+	"net/url"
+	"time"
+)
+
+// addEndpoint adds an url checked with the server, the label is taken from the url if it is not set
+func (l *TelegramListener) addEndpoint(ctx *commandContext) {
+	var name = ctx.fields[0]
+	var endpointUrl = checks.FullServerUrl(ctx.fields[1])
+	if parsedUrl, err := url.Parse(endpointUrl); err != nil || parsedUrl.Host == "" {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+	if _, userInfo := checks.ExtractCredentials(endpointUrl); userInfo != "" {
+		l.reply(ctx.chatId, "endpoint.credentials")
+		return
+	}
+
+	var label = checks.EndpointLabel(endpointUrl)
+	if len(ctx.fields) > 2 {
+		label = ctx.fields[2]
+	}
+
+	err := checks.AddEndpoint(name, checks.Endpoint{Label: label, Url: endpointUrl})
+	switch {
+	case errors.Is(err, checks.ErrServerNotExists):
+		l.reply(ctx.chatId, "server.not_exists", name)
+	case errors.Is(err, checks.ErrEndpointLabel):
+		l.reply(ctx.chatId, "endpoint.invalid_label", label, checks.MainEndpoint)
+	case errors.Is(err, checks.ErrEndpointExists):
+		l.reply(ctx.chatId, "endpoint.exists", name, label)
+	case errors.Is(err, checks.ErrEndpointLimit):
+		l.reply(ctx.chatId, "endpoint.limit", checks.MaxEndpoints)
+	case err != nil:
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+	default:
+		l.reply(ctx.chatId, "endpoint.added", label, redact.Url(endpointUrl), name)
+	}
+}
+
+// removeEndpoint removes the endpoint of the server or all of its endpoints
+func (l *TelegramListener) removeEndpoint(ctx *commandContext) {
+	var name, label = ctx.fields[0], ctx.fields[1]
+	var all = label == checks.AllServers
+	if all {
+		label = ""
+	}
+
+	err := checks.RemoveEndpoint(name, label)
+	switch {
+	case errors.Is(err, checks.ErrServerNotExists):
+		l.reply(ctx.chatId, "server.not_exists", name)
+	case errors.Is(err, checks.ErrEndpointNotExists):
+		l.reply(ctx.chatId, "endpoint.not_exists", name, ctx.fields[1])
+	case err != nil:
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+	case all:
+		l.reply(ctx.chatId, "endpoint.removed_all", name)
+	default:
+		l.reply(ctx.chatId, "endpoint.removed", label, name)
+	}
+}
+
+// endpointMode sets whether the server is down if any of its endpoints fails or only if all of them fail
+func (l *TelegramListener) endpointMode(ctx *commandContext) {
+	var name, mode = ctx.fields[0], checks.IpFailMode(ctx.fields[1])
+	if mode != checks.IpFailAny && mode != checks.IpFailAll {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	err := checks.SetEndpointFailMode(name, mode)
+	switch {
+	case errors.Is(err, checks.ErrServerNotExists):
+		l.reply(ctx.chatId, "server.not_exists", name)
+	case errors.Is(err, checks.ErrEndpointNotExists):
+		l.reply(ctx.chatId, "endpoint.none", name)
+	case err != nil:
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+	default:
+		l.reply(ctx.chatId, "endpoint.mode_"+string(mode), name)
+	}
+}
+
+// formatEndpointResults formats status, latency and availability of the server url and each endpoint
+func formatEndpointResults(lang i18n.Lang, serverCheck checks.ServerCheck) string {
+	if len(serverCheck.Endpoints) == 0 {
+		return ""
+	}
+
+	var text = i18n.T(lang, "details.endpoints_any")
+	if serverCheck.EndpointFailMode == checks.IpFailAll {
+		text = i18n.T(lang, "details.endpoints_all")
+	}
+
+	var urls = []checks.Endpoint{{Label: checks.MainEndpoint, Url: serverCheck.Url}}
+	for _, endpoint := range append(urls, serverCheck.Endpoints...) {
+		var result = checks.EndpointResult{Label: endpoint.Label}
+		for _, r := range serverCheck.EndpointResults {
+			if r.Label == endpoint.Label {
+				result = r
+			}
+		}
+
+		if result.Checks == 0 {
+			text += i18n.T(lang, "details.endpoint_no_data", endpoint.Label, redact.Url(endpoint.Url))
+			continue
+		}
+
+		var icon = "✅"
+		if result.Status == checks.StatusFailed {
+			icon = "❌"
+		}
+		text += i18n.T(lang, "details.endpoint", icon, endpoint.Label, redact.Url(endpoint.Url),
+			result.ResponseTime.Round(time.Millisecond), fmt.Sprintf("%.2f%%", result.Availability()))
+		if result.Error != "" {
+			text += " " + result.Error
+		}
+		text += "\n"
+	}
+
+	return text
+}
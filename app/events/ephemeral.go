@@ -0,0 +1,67 @@
+package events
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"sync"
+	"time"
+)
+
+// ephemeralReplies tracks replies scheduled for deletion
+type ephemeralReplies struct {
+	mutex  sync.Mutex
+	timers map[messageKey]*time.Timer
+}
+
+// send sends the reply to a command, the reply is deleted after EphemeralReplies if it is set.
+// Alerts, recoveries and digests are not sent by send, so they are never deleted.
+func (l *TelegramListener) send(chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	sent, err := l.Sender.Send(chattable)
+	if err == nil && l.EphemeralReplies > 0 {
+		l.scheduleDeletion(messageKey{sent.Chat.ID, sent.MessageID})
+	}
+	return sent, err
+}
+
+func (l *TelegramListener) scheduleDeletion(key messageKey) {
+	l.ephemeral.mutex.Lock()
+	defer l.ephemeral.mutex.Unlock()
+
+	if l.ephemeral.timers == nil {
+		l.ephemeral.timers = make(map[messageKey]*time.Timer)
+	}
+	l.ephemeral.timers[key] = time.AfterFunc(l.EphemeralReplies, func() {
+		l.ephemeral.mutex.Lock()
+		delete(l.ephemeral.timers, key)
+		l.ephemeral.mutex.Unlock()
+
+		// the message is not retried, e.g. it is too old or the bot has no rights to delete it
+		if _, err := l.Bot.Request(tgbotapi.NewDeleteMessage(key.chatId, key.messageId)); err != nil {
+			log.Printf("[WARN] Failed to delete reply %d in chat %d: %v", key.messageId, key.chatId, err)
+		}
+	})
+}
+
+// keep exempts the bot message replied to from deletion
+func (l *TelegramListener) keep(ctx *commandContext) {
+	var replyTo = ctx.message.ReplyToMessage
+	if replyTo == nil {
+		l.reply(ctx.chatId, "keep.usage")
+		return
+	}
+
+	var key = messageKey{replyTo.Chat.ID, replyTo.MessageID}
+	l.ephemeral.mutex.Lock()
+	timer, ok := l.ephemeral.timers[key]
+	if ok {
+		timer.Stop()
+		delete(l.ephemeral.timers, key)
+	}
+	l.ephemeral.mutex.Unlock()
+
+	if !ok {
+		l.reply(ctx.chatId, "keep.not_scheduled")
+		return
+	}
+	l.reply(ctx.chatId, "keep.done")
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+	"strings"
+)
+
+// setExpectedIps sets the addresses the server must be connected on and whether the check fails or warns outside them
+func (l *TelegramListener) setExpectedIps(ctx *commandContext) {
+	var name, value = ctx.fields[0], ctx.fields[1]
+	var action = checks.ExpectedIpFail
+	if len(ctx.fields) > 2 {
+		action = checks.ExpectedIpAction(ctx.fields[2])
+	}
+	if action != checks.ExpectedIpFail && action != checks.ExpectedIpWarn {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	var expected []string
+	if !clearArg(value) {
+		var err error
+		if expected, err = checks.ParseExpectedIps(value); err != nil {
+			l.reply(ctx.chatId, "expectedip.invalid", err)
+			return
+		}
+	}
+
+	err := checks.SetExpectedIps(name, expected, action)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	if len(expected) == 0 {
+		l.reply(ctx.chatId, "expectedip.off", name)
+		return
+	}
+	l.reply(ctx.chatId, "expectedip."+string(action), name, strings.Join(expected, ", "))
+}
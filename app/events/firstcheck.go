@@ -0,0 +1,71 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"net/http"
+	"time"
+)
+
+// sendAdded confirms the added server and edits the confirmation with the result of the first check when it completes,
+// the first check doesn't count toward the alert threshold
+func (l *TelegramListener) sendAdded(chatId int64, server Server, markup *tgbotapi.InlineKeyboardMarkup) {
+	var lang = l.lang(chatId)
+	var text = i18n.T(lang, "server.added", server.Name, redact.Url(server.Url))
+
+	msg := tgbotapi.NewMessage(chatId, text+"\n"+i18n.T(lang, "add.checking"))
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	sent, err := l.send(msg)
+	if err != nil {
+		log.Printf("[ERROR] Failed to send message: %v", err)
+		return
+	}
+
+	go l.firstCheck(sent, server.Name, text, markup)
+}
+
+func (l *TelegramListener) firstCheck(sent tgbotapi.Message, name string, text string, markup *tgbotapi.InlineKeyboardMarkup) {
+	var chatId = sent.Chat.ID
+	var lang = l.lang(chatId)
+
+	serverCheck, result, err := checks.RecheckServer(name)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		// the server was removed by undo before the check completed
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed first check of server %s: %v", name, err)
+		text += "\n" + i18n.T(lang, "server.check_failed", name)
+	} else if serverCheck.IsOk && serverCheck.IsSsl() {
+		text += "\n" + i18n.T(lang, "add.first_ssl", result.ResponseTime.Round(time.Millisecond),
+			i18n.Duration(lang, time.Until(serverCheck.SslExpiry)))
+	} else if serverCheck.IsOk && serverCheck.IsSsh() {
+		text += "\n" + i18n.T(lang, "add.first_ssh", serverCheck.SshBanner, result.ResponseTime.Round(time.Millisecond))
+	} else if serverCheck.IsOk {
+		text += "\n" + i18n.T(lang, "add.first_ok", result.StatusCode, http.StatusText(result.StatusCode),
+			result.ResponseTime.Round(time.Millisecond))
+	} else {
+		var reason = result.Error
+		if reason == "" {
+			reason = http.StatusText(result.StatusCode)
+		}
+		var threshold = l.alertThreshold(checks.ReadChecksData().Settings)
+		if now := time.Now(); serverCheck.InGrace(now) {
+			text += "\n" + i18n.T(lang, "add.first_grace", reason, threshold, i18n.Duration(lang, serverCheck.GraceUntil.Sub(now)))
+		} else {
+			text += "\n" + i18n.T(lang, "add.first_failed", reason, threshold)
+		}
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatId, sent.MessageID, text)
+	edit.ReplyMarkup = markup
+	if _, err := l.Sender.Send(edit); err != nil {
+		log.Printf("[ERROR] Failed to edit message: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"strings"
+	"time"
+)
+
+// cutGraceArg removes the "grace=30m" argument of /add, grace is nil if it is not set.
+// Returns false if the duration is invalid.
+func cutGraceArg(fields []string) ([]string, *time.Duration, bool) {
+	var rest []string
+	var grace *time.Duration
+	for _, field := range fields {
+		value, found := strings.CutPrefix(field, "grace=")
+		if !found {
+			rest = append(rest, field)
+			continue
+		}
+
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return nil, nil, false
+		}
+		grace = &duration
+	}
+	return rest, grace, true
+}
+
+// formatGrace formats the time left of the grace period of the server, empty if it is not in grace period
+func formatGrace(lang i18n.Lang, serverCheck checks.ServerCheck) string {
+	var now = time.Now()
+	if !serverCheck.InGrace(now) {
+		return ""
+	}
+	return i18n.T(lang, "details.grace", i18n.Duration(lang, serverCheck.GraceUntil.Sub(now)))
+}
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+)
+
+// DefaultHeartbeatCron is the schedule of the heartbeat enabled by /heartbeat without the heartbeat cron flag
+const DefaultHeartbeatCron = "0 0 9 * * *"
+
+// heartbeatEnabled returns true if the heartbeat is enabled by /heartbeat or by the heartbeat cron flag
+func (l *TelegramListener) heartbeatEnabled(settings checks.Settings) bool {
+	if settings.Heartbeat != "" {
+		return settings.Heartbeat == "on"
+	}
+	return l.HeartbeatCron != ""
+}
+
+// heartbeatCron returns the schedule of the heartbeat
+func (l *TelegramListener) heartbeatCron() string {
+	if l.HeartbeatCron != "" {
+		return l.HeartbeatCron
+	}
+	return DefaultHeartbeatCron
+}
+
+// SendHeartbeat silently posts that all servers are up with the time of the last check cycle,
+// nothing is sent if the heartbeat is disabled or any server is down
+func (l *TelegramListener) SendHeartbeat() {
+	var checksData = checks.ReadChecksData()
+	if !l.heartbeatEnabled(checksData.Settings) {
+		return
+	}
+
+	var total, up int
+	for _, serverCheck := range checksData.HealthChecks {
+		if serverCheck.Paused {
+			continue
+		}
+		total++
+		if serverCheck.IsOk {
+			up++
+		}
+	}
+	if up < total {
+		log.Printf("[DEBUG] %d of %d servers are down, heartbeat skipped", total-up, total)
+		return
+	}
+
+	var chatId = checksData.Settings.MigratedChat(l.Chat)
+	var lang = checksData.Settings.Language(chatId, l.Language)
+	var location = checksData.Settings.Location(l.Location)
+
+	msg := tgbotapi.NewMessage(chatId, i18n.T(lang, "heartbeat.message", up, total,
+		formatTimeAgo(lang, location, checks.LastCycleTime())))
+	msg.DisableNotification = true
+	if _, err := l.Sender.Send(msg); err != nil {
+		log.Printf("[ERROR] Failed to send heartbeat: %v", err)
+	}
+}
+
+// heartbeat switches the heartbeat message at runtime, the setting overrides the heartbeat cron flag
+func (l *TelegramListener) heartbeat(ctx *commandContext) {
+	var mode = ctx.fields[0]
+	if mode != "on" && mode != "off" {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	err := checks.UpdateChecksData(func(checksData *checks.Data) {
+		checksData.Settings.Heartbeat = mode
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "heartbeat.failed")
+		return
+	}
+
+	if mode == "off" {
+		l.reply(ctx.chatId, "heartbeat.off")
+		return
+	}
+	l.reply(ctx.chatId, "heartbeat.on", l.heartbeatCron())
+}
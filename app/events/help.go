@@ -0,0 +1,102 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strings"
+)
+
+// maxSuggestDistance is the maximal edit distance of a command name suggested for an unknown command
+const maxSuggestDistance = 2
+
+// help lists commands available to the user grouped by category, /help <command> shows usage of the command
+func (l *TelegramListener) help(ctx *commandContext) {
+	var lang = ctx.lang
+
+	if name := strings.TrimPrefix(strings.TrimSpace(ctx.args), "/"); name != "" {
+		cmd, found := l.findCommand(name)
+		if !found || !l.canRun(ctx, cmd) {
+			l.reply(ctx.chatId, "help.not_found", name)
+			return
+		}
+
+		l.reply(ctx.chatId, "help.command", cmd.usage, i18n.T(lang, cmd.descriptionKey))
+		return
+	}
+
+	var text string
+	for _, category := range categories {
+		var lines string
+		for _, cmd := range l.commands() {
+			if cmd.category != category || !l.canRun(ctx, cmd) {
+				continue
+			}
+			lines += cmd.usage + " - " + i18n.T(lang, cmd.descriptionKey) + "\n"
+		}
+
+		if lines != "" {
+			text += i18n.T(lang, "category."+category) + "\n" + lines + "\n"
+		}
+	}
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, strings.TrimSpace(text)))
+}
+
+// canRun returns true if the user of the context may run the command
+func (l *TelegramListener) canRun(ctx *commandContext, cmd command) bool {
+	return cmd.permission == permissionPublic || l.IsSuper(ctx.user) ||
+		cmd.permission == permissionRead && l.isViewer(ctx.user, ctx.chatId) ||
+		cmd.permission == permissionPrivate && l.Viewers.Match(ctx.user) != ""
+}
+
+// unknownCommand suggests the closest registered command the user may run or points to /help,
+// it runs for superusers and viewers
+func (l *TelegramListener) unknownCommand(ctx *commandContext, name string) {
+	var allowed = func(cmd command) bool { return l.canRun(ctx, cmd) }
+	if suggestion := l.closestCommand(name, allowed); suggestion != "" {
+		l.reply(ctx.chatId, "help.suggest", name, suggestion)
+		return
+	}
+
+	l.reply(ctx.chatId, "help.unknown", name)
+}
+
+// closestCommand returns name of the allowed registered command within maxSuggestDistance of the name,
+// empty if there is none
+func (l *TelegramListener) closestCommand(name string, allowed func(cmd command) bool) string {
+	var closest string
+	var bestDistance = maxSuggestDistance + 1
+	for _, cmd := range l.commands() {
+		if !allowed(cmd) {
+			continue
+		}
+		if distance := editDistance(strings.ToLower(name), cmd.name); distance < bestDistance {
+			closest, bestDistance = cmd.name, distance
+		}
+	}
+
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a string, b string) int {
+	var previous = make([]int, len(b)+1)
+	var current = make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			var cost = 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
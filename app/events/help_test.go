@@ -0,0 +1,145 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"details", "details", 0},
+		{"", "list", 4},
+		{"list", "", 4},
+		{"detials", "details", 2},
+		{"lst", "list", 1},
+		{"lists", "list", 1},
+		{"pasue", "pause", 2},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b); got != test.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestClosestCommand(t *testing.T) {
+	var l = &TelegramListener{}
+
+	var tests = []struct {
+		name string
+		want string
+	}{
+		{"detials", "details"},
+		{"DETIALS", "details"},
+		{"lst", "list"},
+		{"remov", "remove"},
+		// farther than maxSuggestDistance
+		{"dtlsx", ""},
+		{"something", ""},
+	}
+
+	for _, test := range tests {
+		if got := l.closestCommand(test.name, func(command) bool { return true }); got != test.want {
+			t.Errorf("closestCommand(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestUnknownCommandReply(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/detials web"))
+	l.handleCommand(commandMessage(2, "/something"))
+
+	var want = []string{
+		"Unknown command /detials — did you mean /details?",
+		"Unknown command /something, send /help to see the commands",
+	}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got replies %q, want %q", sent, want)
+	}
+}
+
+func TestUnknownCommandSuggestsAllowedCommands(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+	l.Viewers = SuperUser{"viewer"}
+
+	for i, text := range []string{"/lst", "/remov web", "/detials web"} {
+		var message = commandMessage(i+1, text)
+		message.From.UserName = "viewer"
+		l.handleCommand(message)
+	}
+
+	var want = []string{
+		"Unknown command /lst — did you mean /list?",
+		"Unknown command /remov, send /help to see the commands",
+		"Unknown command /detials — did you mean /details?",
+	}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got replies %q, want %q", sent, want)
+	}
+}
+
+func TestUnknownCommandIgnoredForOthers(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	var message = commandMessage(1, "/detials web")
+	message.From.UserName = "stranger"
+	l.handleCommand(message)
+
+	if sent := telegram.sent(); len(sent) != 0 {
+		t.Errorf("replied %q to a user who is not superuser", sent)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	var tests = []struct {
+		name      string
+		user      string
+		chatId    int64
+		wantReply bool
+	}{
+		{"superuser", testSuper, testChat, true},
+		{"viewer", "viewer", testChat, true},
+		{"stranger", "stranger", testChat, false},
+		{"stranger in other chat", "stranger", 42, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkstest.UseStorage(t)
+			l, telegram := newTestListener(t)
+			l.Viewers = SuperUser{"viewer"}
+
+			var message = commandMessage(1, "/help")
+			message.From.UserName = test.user
+			message.Chat.ID = test.chatId
+			l.handleCommand(message)
+
+			var sent = telegram.sent()
+			if !test.wantReply {
+				if len(sent) != 0 {
+					t.Errorf("replied %q to a user who is not superuser or viewer", sent)
+				}
+				return
+			}
+			if len(sent) != 1 || !strings.Contains(sent[0], "/list") || !strings.Contains(sent[0], "/whoami") {
+				t.Fatalf("got replies %q, want the commands", sent)
+			}
+			if isSuper := strings.Contains(sent[0], "/remove <name>"); isSuper != (test.user == testSuper) {
+				t.Errorf("superuser commands are shown: %t, want %t", isSuper, test.user == testSuper)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package events
+
+import (
+	"encoding/json"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testChat is the alert chat of listeners of the tests, testSuper is its superuser
+const testChat = int64(-100)
+const testSuper = "admin"
+
+// fakeTelegram is a Telegram Bot API server, it records sent messages and serves queued updates
+type fakeTelegram struct {
+	server *httptest.Server
+
+	mutex    sync.Mutex
+	updates  []tgbotapi.Update
+	failures int
+	polls    []time.Time
+	messages []tgbotapi.MessageConfig
+	answers  []string
+	// requests are forms of the requests by method other than getUpdates, broken methods fail
+	requests map[string][]url.Values
+	broken   map[string]bool
+}
+
+// newTestListener returns a listener of testChat with testSuper, talking to a fake Telegram server
+func newTestListener(t *testing.T) (*TelegramListener, *fakeTelegram) {
+	t.Helper()
+	var telegram = &fakeTelegram{}
+	telegram.server = httptest.NewServer(http.HandlerFunc(telegram.handle))
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", telegram.server.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messageSender = sender.New(bot, sender.Options{})
+	t.Cleanup(func() {
+		messageSender.Close(time.Second)
+		telegram.server.Close()
+	})
+
+	return &TelegramListener{
+		Bot:        bot,
+		Sender:     messageSender,
+		Chat:       testChat,
+		SuperUsers: SuperUser{testSuper},
+		Language:   i18n.En,
+		Location:   time.UTC,
+	}, telegram
+}
+
+// queue adds updates returned by getUpdates
+func (f *fakeTelegram) queue(updates ...tgbotapi.Update) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.updates = append(f.updates, updates...)
+}
+
+// fail makes the next count getUpdates requests fail
+func (f *fakeTelegram) fail(count int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.failures = count
+}
+
+// breakMethod makes requests of the method fail
+func (f *fakeTelegram) breakMethod(method string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.broken == nil {
+		f.broken = make(map[string]bool)
+	}
+	f.broken[method] = true
+}
+
+// requested returns forms of the requests of the method
+func (f *fakeTelegram) requested(method string) []url.Values {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return slices.Clone(f.requests[method])
+}
+
+// sent returns texts of the messages sent so far
+func (f *fakeTelegram) sent() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var texts []string
+	for _, message := range f.messages {
+		texts = append(texts, message.Text)
+	}
+	return texts
+}
+
+func (f *fakeTelegram) handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var method = r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if method != "getUpdates" {
+		f.mutex.Lock()
+		if f.requests == nil {
+			f.requests = make(map[string][]url.Values)
+		}
+		f.requests[method] = append(f.requests[method], r.Form)
+		var broken = f.broken[method]
+		f.mutex.Unlock()
+		if broken {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: false, ErrorCode: http.StatusBadRequest, Description: "Bad Request"})
+			return
+		}
+	}
+
+	switch method {
+	case "getMe":
+		f.respond(w, tgbotapi.User{ID: 1, IsBot: true, UserName: "test_bot"})
+	case "getUpdates":
+		f.getUpdates(w, r)
+	case "sendMessage", "editMessageText", "editMessageReplyMarkup":
+		chatId, _ := strconv.ParseInt(r.Form.Get("chat_id"), 10, 64)
+		f.mutex.Lock()
+		f.messages = append(f.messages, tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatId}, Text: r.Form.Get("text"),
+		})
+		var id = len(f.messages)
+		f.mutex.Unlock()
+		f.respond(w, tgbotapi.Message{MessageID: id, Chat: &tgbotapi.Chat{ID: chatId}, Text: r.Form.Get("text")})
+	case "answerCallbackQuery":
+		f.mutex.Lock()
+		f.answers = append(f.answers, r.Form.Get("text"))
+		f.mutex.Unlock()
+		f.respond(w, true)
+	default:
+		f.respond(w, true)
+	}
+}
+
+// getUpdates fails if failures are left, otherwise it returns queued updates from the offset,
+// it waits a bit without updates like long polling
+func (f *fakeTelegram) getUpdates(w http.ResponseWriter, r *http.Request) {
+	offset, _ := strconv.Atoi(r.Form.Get("offset"))
+
+	f.mutex.Lock()
+	f.polls = append(f.polls, time.Now())
+	if f.failures > 0 {
+		f.failures--
+		f.mutex.Unlock()
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: false, ErrorCode: http.StatusBadGateway, Description: "Bad Gateway"})
+		return
+	}
+	var updates = []tgbotapi.Update{}
+	for _, update := range f.updates {
+		if update.UpdateID >= offset {
+			updates = append(updates, update)
+		}
+	}
+	f.mutex.Unlock()
+
+	if len(updates) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	f.respond(w, updates)
+}
+
+func (f *fakeTelegram) respond(w http.ResponseWriter, result any) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: raw})
+}
+
+// commandMessage is a message of testSuper in testChat sent now
+func commandMessage(id int, text string) *tgbotapi.Message {
+	var message = &tgbotapi.Message{
+		MessageID: id,
+		From:      &tgbotapi.User{ID: 10, UserName: testSuper},
+		Chat:      &tgbotapi.Chat{ID: testChat, Type: "supergroup"},
+		Date:      int(time.Now().Unix()),
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		var length = len(text)
+		if i := strings.IndexByte(text, ' '); i != -1 {
+			length = i
+		}
+		message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: length}}
+	}
+	return message
+}
+
+// assertReplies checks the listener replied with the text, or didn't reply if it is empty
+func assertReplies(t *testing.T, telegram *fakeTelegram, want string) {
+	t.Helper()
+	var wantReplies []string
+	if want != "" {
+		wantReplies = []string{want}
+	}
+	if sent := telegram.sent(); !slices.Equal(sent, wantReplies) {
+		t.Errorf("got replies %q, want %q", sent, wantReplies)
+	}
+}
+
+// waitFor waits until the condition holds or fails the test after a second
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	var deadline = time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition was not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"sort"
+	"strings"
+)
+
+func (l *TelegramListener) ack(ctx *commandContext) {
+	var name = ctx.fields[0]
+	var comment = strings.Join(ctx.fields[1:], " ")
+
+	incident, err := checks.AckIncident(name, userIdentity(ctx.user), comment)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "ack.server_failed", name)
+		return
+	}
+	if incident.AckBy == "" {
+		l.reply(ctx.chatId, "ack.no_incident", name)
+		return
+	}
+
+	l.reply(ctx.chatId, "ack.server_done", name)
+}
+
+func (l *TelegramListener) down(ctx *commandContext) {
+	var checksData = checks.ReadChecksData()
+	var lang = ctx.lang
+
+	var names []string
+	for name, serverCheck := range checksData.HealthChecks {
+		if !serverCheck.IsOk {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var text string
+	for _, name := range names {
+		var serverCheck = checksData.HealthChecks[name]
+		text += fmt.Sprintf("❌ %s [%s]\n", serverCheck.Name, redact.Url(serverCheck.Url))
+		if serverCheck.Incident != nil {
+			text += formatIncident(lang, l.location(), serverCheck.Incident)
+		}
+	}
+
+	if text == "" {
+		text = i18n.T(lang, "down.all_up")
+	}
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, text))
+}
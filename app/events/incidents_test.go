@@ -0,0 +1,90 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"testing"
+	"time"
+)
+
+func TestAck(t *testing.T) {
+	var tests = []struct {
+		name        string
+		user        tgbotapi.User
+		text        string
+		server      string
+		wantReply   string
+		wantAckBy   string
+		wantComment string
+	}{
+		{"username", tgbotapi.User{ID: 10, UserName: testSuper}, "/ack web", "web",
+			"Incident of server web acknowledged", "@admin", ""},
+		{"comment", tgbotapi.User{ID: 10, UserName: testSuper}, "/ack web rolling back the deploy", "web",
+			"Incident of server web acknowledged", "@admin", "rolling back the deploy"},
+		{"quoted name", tgbotapi.User{ID: 10, UserName: testSuper}, `/ack "my server" on it`, "my server",
+			"Incident of server my server acknowledged", "@admin", "on it"},
+		// superusers matched by id may have no username
+		{"user without username", tgbotapi.User{ID: 42, FirstName: "Bob", LastName: "Smith"}, "/ack web", "web",
+			"Incident of server web acknowledged", "Bob Smith", ""},
+		{"user without any name", tgbotapi.User{ID: 42}, "/ack web", "web",
+			"Incident of server web acknowledged", "id 42", ""},
+		{"no incident", tgbotapi.User{ID: 10, UserName: testSuper}, "/ack db", "db",
+			"Server db has no active incident", "", ""},
+		{"unknown server", tgbotapi.User{ID: 10, UserName: testSuper}, "/ack missing", "missing",
+			"Server missing not exists", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var incident = func() *checks.Incident { return &checks.Incident{Start: time.Now(), AlertedAt: time.Now()} }
+			checkstest.UseStorage(t,
+				checks.ServerCheck{Name: "web", Url: "https://example.com", Incident: incident()},
+				checks.ServerCheck{Name: "my server", Url: "https://my.example.com", Incident: incident()},
+				checks.ServerCheck{Name: "db", Url: "https://db.example.com", IsOk: true},
+			)
+			l, telegram := newTestListener(t)
+			l.SuperUsers = SuperUser{testSuper, "42"}
+
+			var message = commandMessage(1, test.text)
+			message.From = &test.user
+			l.handleCommand(message)
+
+			assertReplies(t, telegram, test.wantReply)
+			var stored = checks.ReadChecksData().HealthChecks[test.server].Incident
+			if stored == nil {
+				return
+			}
+			if stored.AckBy != test.wantAckBy || stored.AckComment != test.wantComment {
+				t.Errorf("got ack by %q with comment %q, want %q with %q", stored.AckBy, stored.AckComment, test.wantAckBy, test.wantComment)
+			}
+			if test.wantAckBy != "" && stored.AckAt.IsZero() {
+				t.Error("ack time was not stored")
+			}
+		})
+	}
+}
+
+func TestAckButtonOfUserWithoutUsername(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com",
+		Incident: &checks.Incident{Start: time.Now(), AlertedAt: time.Now()}})
+	l, telegram := newTestListener(t)
+	l.SuperUsers = SuperUser{"42"}
+
+	var alert = commandMessage(100, "❌ Server web is down")
+	l.processUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID: "1", From: &tgbotapi.User{ID: 42, FirstName: "Bob"}, Message: alert, Data: "ack:" + notify.ServerRef("web"),
+	}})
+
+	if incident := checks.ReadChecksData().HealthChecks["web"].Incident; incident.AckBy != "Bob" {
+		t.Errorf("got ack by %q, want Bob", incident.AckBy)
+	}
+	telegram.mutex.Lock()
+	var answers = telegram.answers
+	telegram.mutex.Unlock()
+	if len(answers) != 1 || answers[0] != "Acknowledged" {
+		t.Errorf("got answers %q, want Acknowledged", answers)
+	}
+	assertReplies(t, telegram, "❌ Server web is down\n\nack'd by Bob")
+}
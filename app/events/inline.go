@@ -0,0 +1,77 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const maxInlineResults = 10
+
+// processInlineQuery answers inline queries with status of servers matching the query,
+// non-superusers receive an empty result set
+func (l *TelegramListener) processInlineQuery(inlineQuery *tgbotapi.InlineQuery) {
+	var results = []interface{}{}
+
+	if l.IsSuper(inlineQuery.From) {
+		results = inlineQueryResults(l.Language, l.location(), checks.ReadChecksData(), strings.TrimSpace(inlineQuery.Query))
+	}
+
+	_, err := l.Bot.Request(tgbotapi.InlineConfig{
+		InlineQueryID: inlineQuery.ID,
+		Results:       results,
+		IsPersonal:    true,
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to answer inline query: %v", err)
+	}
+}
+
+func inlineQueryResults(lang i18n.Lang, location *time.Location, checksData checks.Data, query string) []interface{} {
+	var results = []interface{}{}
+
+	if query == "" {
+		var up, down int
+		for _, serverCheck := range checksData.HealthChecks {
+			if serverCheck.IsOk {
+				up++
+			} else {
+				down++
+			}
+		}
+
+		summary := i18n.T(lang, "inline.summary", up, down)
+		return append(results, tgbotapi.NewInlineQueryResultArticle("summary", summary, summary))
+	}
+
+	var names []string
+	for name := range checksData.HealthChecks {
+		if strings.Contains(strings.ToLower(name), strings.ToLower(query)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		if i == maxInlineResults {
+			break
+		}
+
+		var serverCheck = checksData.HealthChecks[name]
+		article := tgbotapi.NewInlineQueryResultArticle(
+			fmt.Sprintf("server-%d", i),
+			fmt.Sprintf("%s %s", serverStatusIcon(serverCheck), name),
+			formatServerDetails(lang, location, serverCheck, checks.DefaultReliabilityWindow),
+		)
+		article.Description = redact.Url(serverCheck.Url)
+		results = append(results, article)
+	}
+
+	return results
+}
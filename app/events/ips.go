@@ -0,0 +1,69 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"log"
+	"time"
+)
+
+// checkAllIps switches checking of each resolved address of the server
+func (l *TelegramListener) checkAllIps(ctx *commandContext) {
+	var name, mode = ctx.fields[0], ctx.fields[1]
+	if clearArg(mode) {
+		mode = "off"
+	}
+
+	var enabled = true
+	switch mode {
+	case "off":
+		enabled = false
+	case string(checks.IpFailAny), string(checks.IpFailAll):
+	default:
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	err := checks.SetCheckAllIps(name, enabled, checks.IpFailMode(mode))
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	if !enabled {
+		l.reply(ctx.chatId, "ips.disabled", name)
+		return
+	}
+	l.reply(ctx.chatId, "ips.enabled_"+mode, name)
+}
+
+// formatIpResults formats status and latency of each address of the server checked by the last check
+func formatIpResults(lang i18n.Lang, serverCheck checks.ServerCheck) string {
+	if !serverCheck.CheckAllIps || len(serverCheck.IpResults) == 0 {
+		return ""
+	}
+
+	var text = i18n.T(lang, "details.ips_any")
+	if serverCheck.IpFailMode == checks.IpFailAll {
+		text = i18n.T(lang, "details.ips_all")
+	}
+	for _, ipResult := range serverCheck.IpResults {
+		var icon = "✅"
+		if ipResult.Status == checks.StatusFailed {
+			icon = "❌"
+		}
+		text += i18n.T(lang, "details.ip", icon, ipResult.Ip, ipResult.ResponseTime.Round(time.Millisecond))
+		if ipResult.Error != "" {
+			text += " " + ipResult.Error
+		}
+		text += "\n"
+	}
+
+	return text
+}
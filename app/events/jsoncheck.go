@@ -0,0 +1,66 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+)
+
+// setJsonCheck adds a numeric assertion on the JSON body of the server, or clears assertions of a path or all of them
+func (l *TelegramListener) setJsonCheck(ctx *commandContext) {
+	var name = ctx.fields[0]
+
+	switch {
+	case len(ctx.fields) == 2 && clearArg(ctx.fields[1]), len(ctx.fields) == 3 && clearArg(ctx.fields[2]):
+		var path string
+		if len(ctx.fields) == 3 {
+			path = ctx.fields[1]
+		}
+		l.clearJsonChecks(ctx, name, path)
+		return
+	case len(ctx.fields) != 4:
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	check, err := checks.ParseJsonCheck(ctx.fields[1], ctx.fields[2], ctx.fields[3])
+	if err != nil {
+		l.reply(ctx.chatId, "jsoncheck.invalid", err)
+		return
+	}
+
+	err = checks.AddJsonCheck(name, check)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	l.reply(ctx.chatId, "jsoncheck.set", name, check)
+}
+
+func (l *TelegramListener) clearJsonChecks(ctx *commandContext, name string, path string) {
+	removed, err := checks.ClearJsonChecks(name, path)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	switch {
+	case path == "":
+		l.reply(ctx.chatId, "jsoncheck.off", name)
+	case removed == 0:
+		l.reply(ctx.chatId, "jsoncheck.not_found", name, path)
+	default:
+		l.reply(ctx.chatId, "jsoncheck.path_off", path, name)
+	}
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"strings"
+)
+
+// lang returns language set for the chat by /setlanguage or the default language
+func (l *TelegramListener) lang(chatId int64) i18n.Lang {
+	return checks.ReadChecksData().Settings.Language(chatId, l.Language)
+}
+
+// t returns the message with the key translated to the language of the chat
+func (l *TelegramListener) t(chatId int64, key string, args ...any) string {
+	return i18n.T(l.lang(chatId), key, args...)
+}
+
+// reply sends the message with the key translated to the language of the chat
+func (l *TelegramListener) reply(chatId int64, key string, args ...any) {
+	l.send(tgbotapi.NewMessage(chatId, l.t(chatId, key, args...)))
+}
+
+func (l *TelegramListener) setLanguage(ctx *commandContext) {
+	var chatId = ctx.chatId
+	var arg = strings.TrimSpace(ctx.args)
+	if arg == "" {
+		l.reply(chatId, "language.usage", strings.Join(i18n.Languages(), "|"), l.lang(chatId))
+		return
+	}
+
+	var code string
+	if arg != "default" {
+		lang, ok := i18n.Parse(arg)
+		if !ok {
+			l.reply(chatId, "language.usage", strings.Join(i18n.Languages(), "|"), l.lang(chatId))
+			return
+		}
+		code = string(lang)
+	}
+
+	saveError := checks.UpdateChecksData(func(checksData *checks.Data) {
+		if code == "" {
+			delete(checksData.Settings.ChatLanguages, chatId)
+			return
+		}
+		if checksData.Settings.ChatLanguages == nil {
+			checksData.Settings.ChatLanguages = make(map[int64]string)
+		}
+		checksData.Settings.ChatLanguages[chatId] = code
+	})
+	if saveError != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", saveError)
+		l.reply(chatId, "language.failed")
+		return
+	}
+
+	l.reply(chatId, "language.set", l.lang(chatId))
+	l.registerChatCommands(chatId)
+}
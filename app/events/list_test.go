@@ -0,0 +1,40 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestListShowsUnicodeHosts(t *testing.T) {
+	var serverUrl = checks.FullServerUrl("xn--mller-shop-9db.de/health")
+	checkstest.UseStorage(t, checks.ServerCheck{Name: checks.UrlName(serverUrl), Url: serverUrl, IsOk: true})
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/list"))
+
+	assertReplies(t, telegram, "✅ müller-shop.de/health [https://müller-shop.de/health]\n")
+}
+
+func TestListBars(t *testing.T) {
+	var history []checks.CheckResult
+	for _, status := range []checks.CheckStatus{checks.StatusOk, checks.StatusFailed, checks.StatusDegraded} {
+		history = append(history, checks.CheckResult{Time: time.Now(), Status: status})
+	}
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com", IsOk: true, History: history})
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/list"))
+	l.ListBars = true
+	l.handleCommand(commandMessage(2, "/list"))
+
+	var want = []string{
+		"✅ web [https://example.com]\n",
+		"✅ web [https://example.com]\n⬜⬜⬜⬜⬜⬜⬜🟩🟥🟨\n",
+	}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got lists %q, want %q", sent, want)
+	}
+}
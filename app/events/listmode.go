@@ -0,0 +1,112 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"log"
+	"strings"
+	"time"
+)
+
+// compactColumns is the number of servers per line of the compact list
+const compactColumns = 3
+
+// maxMessageLength is the maximum length of a Telegram message in UTF-16 code units,
+// longer lists are split into several messages
+const maxMessageLength = 4096
+
+// saveListMode remembers the mode of /list used in the chat
+func (l *TelegramListener) saveListMode(chatId int64, mode string) {
+	err := checks.UpdateChecksData(func(checksData *checks.Data) {
+		if checksData.Settings.ChatListModes == nil {
+			checksData.Settings.ChatListModes = make(map[int64]string)
+		}
+		checksData.Settings.ChatListModes[chatId] = mode
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to save list mode of chat %d: %v", chatId, err)
+	}
+}
+
+// formatCompactList formats the icon and name of each server, compactColumns servers per line
+func formatCompactList(servers []checks.ServerCheck) string {
+	var lines []string
+	var line []string
+	for _, serverCheck := range servers {
+		var item = serverStatusIcon(serverCheck) + " " + serverCheck.Name
+		if serverCheck.InGrace(time.Now()) {
+			item += " ⏳"
+		}
+		line = append(line, item)
+		if len(line) == compactColumns {
+			lines = append(lines, strings.Join(line, "   "))
+			line = nil
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, strings.Join(line, "   "))
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// formatVerboseLine formats the time and response time of the last check and availability over 24 hours
+func formatVerboseLine(lang i18n.Lang, serverCheck checks.ServerCheck) string {
+	lastResult, ok := serverCheck.LastResult()
+	if !ok || serverCheck.LastChecked.IsZero() {
+		return i18n.T(lang, "list.unchecked")
+	}
+
+	var availability = i18n.T(lang, "details.na")
+	if value, ok := checks.Availability(checks.HistorySince(serverCheck.History, time.Now().Add(-24*time.Hour))); ok {
+		availability = fmt.Sprintf("%.2f%%", value)
+	}
+	return i18n.T(lang, "list.verbose", i18n.TimeAgo(lang, serverCheck.LastChecked),
+		lastResult.ResponseTime.Round(time.Millisecond), availability)
+}
+
+// splitMessage splits text into parts of up to limit UTF-16 code units at line ends, longer lines are cut
+func splitMessage(text string, limit int) []string {
+	var parts []string
+	var part string
+	var length int
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if length+messageLength(line) > limit && part != "" {
+			parts = append(parts, part)
+			part, length = "", 0
+		}
+		for _, r := range line {
+			if length+utf16Length(r) > limit {
+				parts = append(parts, part)
+				part, length = "", 0
+			}
+			part += string(r)
+			length += utf16Length(r)
+		}
+	}
+	if part != "" {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// messageLength returns the length of text in UTF-16 code units, like Telegram counts it
+func messageLength(text string) int {
+	var length int
+	for _, r := range text {
+		length += utf16Length(r)
+	}
+	return length
+}
+
+// utf16Length returns the number of UTF-16 code units of the rune, runes outside of the basic plane like emoji take two
+func utf16Length(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,120 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestListCompact(t *testing.T) {
+	checkstest.UseStorage(t, mixedServers()...)
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/list compact sort:name"))
+	// the mode is remembered for the chat
+	l.handleCommand(commandMessage(2, "/list"))
+
+	var want = []string{
+		"✅ api   ⏸ backup   ✅ cache\n❌ db   ❌ mail   ✅ web\n",
+		"❌ db   ❌ mail   ✅ cache\n✅ api   ✅ web   ⏸ backup\n",
+	}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got lists %q, want %q", sent, want)
+	}
+	if mode := checks.ReadChecksData().Settings.ChatListModes[testChat]; mode != checks.ListCompact {
+		t.Errorf("got stored mode %q, want compact", mode)
+	}
+}
+
+func TestListVerbose(t *testing.T) {
+	checkstest.UseStorage(t, mixedServers()...)
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/list verbose sort:name"))
+
+	var want = "✅ api [https://api.example.com]\n" +
+		"    last checked 1h 0m ago, responded in 300ms, 100.00% over 24h\n" +
+		"⏸ backup [https://backup.example.com]\n" +
+		"    not checked yet\n" +
+		"✅ cache [https://cache.example.com]\n" +
+		"    last checked 1h 0m ago, responded in 900ms, 100.00% over 24h\n" +
+		"❌ db [https://db.example.com]\n" +
+		"    last checked 1h 0m ago, responded in 0s, 33.33% over 24h\n" +
+		"❌ mail [https://mail.example.com]\n" +
+		"    last checked 1h 0m ago, responded in 0s, 0.00% over 24h\n" +
+		"✅ web [https://example.com]\n" +
+		"    last checked 1h 0m ago, responded in 50ms, 66.67% over 24h\n"
+	assertReplies(t, telegram, want)
+}
+
+func TestListDefaultMode(t *testing.T) {
+	checkstest.UseStorage(t, mixedServers()...)
+	l, telegram := newTestListener(t)
+	l.ListMode = checks.ListCompact
+
+	l.handleCommand(commandMessage(1, "/list sort:name"))
+	l.handleCommand(commandMessage(2, "/list normal limit:1"))
+
+	var want = []string{
+		"✅ api   ⏸ backup   ✅ cache\n❌ db   ❌ mail   ✅ web\n",
+		"❌ db [https://db.example.com]\n",
+	}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got lists %q, want %q", sent, want)
+	}
+}
+
+func TestListSplitsLongList(t *testing.T) {
+	var servers []checks.ServerCheck
+	for i := 0; i < 150; i++ {
+		var name = fmt.Sprintf("server-%03d", i)
+		servers = append(servers, checks.ServerCheck{Name: name, Url: "https://" + name + ".example.com", IsOk: true})
+	}
+	checkstest.UseStorage(t, servers...)
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/list verbose"))
+
+	var sent = telegram.sent()
+	if len(sent) < 2 {
+		t.Fatalf("got %d messages, want the list split", len(sent))
+	}
+	for i, part := range sent {
+		if messageLength(part) > maxMessageLength {
+			t.Errorf("message %d is %d long", i, messageLength(part))
+		}
+		// a server and its details are not split between messages
+		if !strings.HasPrefix(part, "✅ server-") || !strings.HasSuffix(part, "    not checked yet\n") {
+			t.Errorf("message %d is split inside a server: %q...%q", i, part[:20], part[len(part)-20:])
+		}
+	}
+	if lines := strings.Count(strings.Join(sent, ""), "\n"); lines != 300 {
+		t.Errorf("got %d lines, want 2 per server", lines)
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	var tests = []struct {
+		text  string
+		limit int
+		want  []string
+	}{
+		{"", 10, nil},
+		{"one\ntwo\n", 10, []string{"one\ntwo\n"}},
+		{"one\ntwo\nthree\n", 8, []string{"one\ntwo\n", "three\n"}},
+		// a line longer than the limit is cut
+		{"abcdefghij\n", 4, []string{"abcd", "efgh", "ij\n"}},
+		// emoji take two UTF-16 code units
+		{"✅ a\n✅ b\n", 4, []string{"✅ a\n", "✅ b\n"}},
+		{"😀😀😀\n", 4, []string{"😀😀", "😀\n"}},
+	}
+
+	for _, test := range tests {
+		if got := splitMessage(test.text, test.limit); !slices.Equal(got, test.want) {
+			t.Errorf("splitMessage(%q, %d) = %q, want %q", test.text, test.limit, got, test.want)
+		}
+	}
+}
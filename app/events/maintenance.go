@@ -0,0 +1,33 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+	"time"
+)
+
+// maintenanceAll suppresses all alerts for the duration, "off" ends the maintenance
+func (l *TelegramListener) maintenanceAll(ctx *commandContext) {
+	var until time.Time
+	if ctx.fields[0] != "off" {
+		duration, err := time.ParseDuration(ctx.fields[0])
+		if err != nil || duration <= 0 {
+			l.reply(ctx.chatId, "maintenance.invalid")
+			return
+		}
+		until = time.Now().Add(duration)
+	}
+
+	if err := checks.SetMaintenance(until); err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "maintenance.failed")
+		return
+	}
+
+	if until.IsZero() {
+		l.reply(ctx.chatId, "maintenance.off")
+		return
+	}
+	log.Printf("[INFO] Maintenance until %v set by %s", until, ctx.user.UserName)
+	l.reply(ctx.chatId, "maintenance.on", checks.FormatTime(until, l.location()))
+}
@@ -0,0 +1,113 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+	"runtime/debug"
+)
+
+// middleware wraps the handler of the command
+type middleware func(cmd command, next handlerFunc) handlerFunc
+
+// withMiddleware returns the command handler wrapped in the middleware, the first middleware runs first
+func (l *TelegramListener) withMiddleware(cmd command) handlerFunc {
+	var middlewares = []middleware{l.recoverPanic, l.authorize, l.checkArgs, l.rejectHttpOnly}
+
+	var handler = cmd.handler
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](cmd, handler)
+	}
+	return handler
+}
+
+// recoverPanic logs the panic of the handler and replies with an error instead of crashing the bot
+func (l *TelegramListener) recoverPanic(cmd command, next handlerFunc) handlerFunc {
+	return func(ctx *commandContext) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("[ERROR] Command /%s panicked: %v\n%s", cmd.name, r, debug.Stack())
+				l.reply(ctx.chatId, "command.failed", cmd.name)
+			}
+		}()
+
+		next(ctx)
+	}
+}
+
+// authorize ignores commands of users other than superusers and viewers and rejects commands from chats other than allowed,
+// public commands are answered to everyone in any chat, viewers are denied commands other than read ones
+func (l *TelegramListener) authorize(cmd command, next handlerFunc) handlerFunc {
+	return func(ctx *commandContext) {
+		if cmd.permission == permissionPublic {
+			next(ctx)
+			return
+		}
+
+		// check if is not superuser or viewer, ignore
+		var isSuper = l.IsSuper(ctx.user)
+		if cmd.permission == permissionPrivate {
+			l.authorizePrivate(ctx, isSuper, next)
+			return
+		}
+		if !isSuper && !l.isViewer(ctx.user, ctx.chatId) {
+			return
+		}
+
+		// ignore commands from chats other than allowed
+		if !l.isAllowedChat(ctx.chatId) {
+			l.rejectChat(ctx.chatId)
+			return
+		}
+
+		if !isSuper && cmd.permission != permissionRead {
+			log.Printf("[INFO] Viewer %s (%d) is not allowed to run /%s", ctx.user.UserName, ctx.user.ID, cmd.name)
+			l.reply(ctx.chatId, "permission.denied")
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+// authorizePrivate runs personal commands of superusers and viewers in a private chat with the bot,
+// viewers of PublicRead are not known by name, so they can't use them
+func (l *TelegramListener) authorizePrivate(ctx *commandContext, isSuper bool, next handlerFunc) {
+	if !isSuper && l.Viewers.Match(ctx.user) == "" {
+		return
+	}
+	if !ctx.message.Chat.IsPrivate() {
+		l.reply(ctx.chatId, "subscribe.private_only")
+		return
+	}
+
+	next(ctx)
+}
+
+// rejectHttpOnly replies that the command doesn't apply to the server named by the first argument
+// if it is not an HTTP check, like SSL and SSH checks
+func (l *TelegramListener) rejectHttpOnly(cmd command, next handlerFunc) handlerFunc {
+	return func(ctx *commandContext) {
+		if !cmd.httpOnly {
+			next(ctx)
+			return
+		}
+
+		if serverCheck, ok := checks.ReadChecksData().HealthChecks[ctx.fields[0]]; ok && !serverCheck.IsHttp() {
+			l.reply(ctx.chatId, string(serverCheck.Type)+".http_only", cmd.name, serverCheck.Name)
+			return
+		}
+		next(ctx)
+	}
+}
+
+// checkArgs replies with usage of the command if the number of arguments is out of its limits
+func (l *TelegramListener) checkArgs(cmd command, next handlerFunc) handlerFunc {
+	return func(ctx *commandContext) {
+		if len(ctx.fields) < cmd.minArgs || (cmd.maxArgs > 0 && len(ctx.fields) > cmd.maxArgs) {
+			l.reply(ctx.chatId, "command.usage", cmd.usage)
+			return
+		}
+
+		next(ctx)
+	}
+}
@@ -0,0 +1,177 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"testing"
+)
+
+// testContext is the context of a command of the user in the chat
+func testContext(userName string, chatId int64, args ...string) *commandContext {
+	var message = commandMessage(1, "/test")
+	message.From.UserName = userName
+	message.Chat.ID = chatId
+	return &commandContext{message: message, chatId: chatId, user: message.From, fields: args}
+}
+
+// runMiddleware runs a handler wrapped in the middleware and reports whether the handler ran
+func runMiddleware(mw middleware, cmd command, ctx *commandContext) bool {
+	var ran bool
+	mw(cmd, func(ctx *commandContext) { ran = true })(ctx)
+	return ran
+}
+
+func TestAuthorize(t *testing.T) {
+	var tests = []struct {
+		name       string
+		permission permission
+		user       string
+		chatId     int64
+		wantRun    bool
+		wantReply  string
+	}{
+		{"superuser", permissionSuper, testSuper, testChat, true, ""},
+		{"stranger is ignored", permissionSuper, "stranger", testChat, false, ""},
+		{"viewer runs read command", permissionRead, "viewer", testChat, true, ""},
+		{"viewer is denied", permissionSuper, "viewer", testChat, false,
+			"You don't have permission, the command is available to superusers only"},
+		{"other chat is rejected", permissionSuper, testSuper, 42, false, "This bot only accepts commands in the configured chat"},
+		{"stranger in other chat is ignored", permissionRead, "stranger", 42, false, ""},
+		// only /whoami is public, it answers strangers in any chat
+		{"public command", permissionPublic, "stranger", 42, true, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkstest.UseStorage(t)
+			l, telegram := newTestListener(t)
+			l.Viewers = SuperUser{"viewer"}
+
+			var ran = runMiddleware(l.authorize, command{name: "test", permission: test.permission},
+				testContext(test.user, test.chatId))
+			if ran != test.wantRun {
+				t.Errorf("handler ran: %v, want %v", ran, test.wantRun)
+			}
+			assertReplies(t, telegram, test.wantReply)
+		})
+	}
+}
+
+func TestAuthorizePrivate(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+	var cmd = command{name: "subscribe", permission: permissionPrivate}
+
+	var ctx = testContext(testSuper, 10)
+	ctx.message.Chat.Type = "private"
+	if !runMiddleware(l.authorize, cmd, ctx) {
+		t.Error("private command did not run in a private chat")
+	}
+	if runMiddleware(l.authorize, cmd, testContext(testSuper, testChat)) {
+		t.Error("private command ran in a group")
+	}
+	if len(telegram.sent()) != 1 {
+		t.Errorf("got replies %q, want one reply to the group", telegram.sent())
+	}
+}
+
+func TestCheckArgs(t *testing.T) {
+	var cmd = command{name: "test", usage: "/test <name> [days]", minArgs: 1, maxArgs: 2}
+
+	var tests = []struct {
+		name    string
+		args    []string
+		wantRun bool
+	}{
+		{"too few", nil, false},
+		{"min", []string{"web"}, true},
+		{"max", []string{"web", "7"}, true},
+		{"too many", []string{"web", "7", "extra"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkstest.UseStorage(t)
+			l, telegram := newTestListener(t)
+
+			if ran := runMiddleware(l.checkArgs, cmd, testContext(testSuper, testChat, test.args...)); ran != test.wantRun {
+				t.Errorf("handler ran: %v, want %v", ran, test.wantRun)
+			}
+			if test.wantRun {
+				assertReplies(t, telegram, "")
+			} else {
+				assertReplies(t, telegram, "Usage: /test <name> [days]")
+			}
+		})
+	}
+}
+
+func TestCheckArgsUnlimited(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, _ := newTestListener(t)
+
+	if !runMiddleware(l.checkArgs, command{name: "test"}, testContext(testSuper, testChat, "a", "b", "c")) {
+		t.Error("handler without argument limits did not run")
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	var handler = l.recoverPanic(command{name: "test"}, func(ctx *commandContext) {
+		panic("broken handler")
+	})
+	handler(testContext(testSuper, testChat))
+
+	assertReplies(t, telegram, "Command /test failed, see the bot logs")
+}
+
+func TestRejectHttpOnly(t *testing.T) {
+	var tests = []struct {
+		name      string
+		cmd       command
+		server    string
+		wantRun   bool
+		wantReply string
+	}{
+		{"http server", command{name: "setretries", httpOnly: true}, "web", true, ""},
+		{"ssl server", command{name: "setretries", httpOnly: true}, "cert", false,
+			"/setretries applies only to HTTP checks, cert is an SSL check"},
+		{"ssh server", command{name: "setretries", httpOnly: true}, "shell", false,
+			"/setretries applies only to HTTP checks, shell is an SSH check"},
+		{"unknown server", command{name: "setretries", httpOnly: true}, "missing", true, ""},
+		{"command for all checks", command{name: "pause"}, "cert", true, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkstest.UseStorage(t,
+				checks.ServerCheck{Name: "web", Url: "https://example.com"},
+				checks.ServerCheck{Name: "cert", Url: "example.com:443", Type: checks.CheckSsl},
+				checks.ServerCheck{Name: "shell", Url: "example.com:22", Type: checks.CheckSsh},
+			)
+			l, telegram := newTestListener(t)
+
+			if ran := runMiddleware(l.rejectHttpOnly, test.cmd, testContext(testSuper, testChat, test.server)); ran != test.wantRun {
+				t.Errorf("handler ran: %v, want %v", ran, test.wantRun)
+			}
+			assertReplies(t, telegram, test.wantReply)
+		})
+	}
+}
+
+func TestWithMiddlewareOrder(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	// arguments of commands of strangers are not checked, they get no reply at all
+	var ran bool
+	var cmd = command{name: "test", minArgs: 1, handler: func(ctx *commandContext) { ran = true }}
+	l.withMiddleware(cmd)(testContext("stranger", testChat))
+
+	if ran {
+		t.Error("handler ran for a stranger")
+	}
+	assertReplies(t, telegram, "")
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"strings"
+)
+
+// validateName replies with the rule the name of the new server violates.
+// Names taken from the URL may contain URL characters, other rules apply to them too.
+func (l *TelegramListener) validateName(chatId int64, server Server) bool {
+	err := checks.ValidateName(server.Name)
+	var fromUrl = checks.UrlName(server.Url) == server.Name
+	switch {
+	case err == nil, fromUrl && errors.Is(err, checks.ErrNameChars):
+		return true
+	case errors.Is(err, checks.ErrNameTooLong):
+		l.reply(chatId, "name.too_long", checks.MaxNameLength)
+	case errors.Is(err, checks.ErrNameReserved):
+		l.reply(chatId, "name.reserved", server.Name, strings.Join(checks.ReservedNames(), ", "))
+	case errors.Is(err, checks.ErrNameChars):
+		l.reply(chatId, "name.chars")
+	default:
+		l.reply(chatId, "add.invalid_name")
+	}
+
+	return false
+}
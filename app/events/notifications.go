@@ -0,0 +1,67 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strconv"
+)
+
+const defaultNotificationsCount = 10
+
+// notifications shows recent entries of the notification audit log: /notifications [name] [count]
+func (l *TelegramListener) notifications(ctx *commandContext) {
+	var args = ctx.fields
+	var name string
+	var count = defaultNotificationsCount
+
+	if len(args) > 0 {
+		name = args[0]
+	}
+	// a single number is the count unless there is a server with such name
+	if len(args) == 1 {
+		if _, exists := checks.ReadChecksData().HealthChecks[name]; !exists {
+			if n, err := strconv.Atoi(name); err == nil {
+				name, count = "", n
+			}
+		}
+	}
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			l.reply(ctx.chatId, "notifications.count_invalid")
+			return
+		}
+		count = n
+	}
+	if count <= 0 {
+		l.reply(ctx.chatId, "notifications.count_invalid")
+		return
+	}
+
+	var records = l.AuditLog.Records(name)
+	if len(records) > count {
+		records = records[len(records)-count:]
+	}
+
+	var location = l.location()
+	var text string
+	for i := len(records) - 1; i >= 0; i-- {
+		var record = records[i]
+		var status = "✅"
+		if record.Error != "" {
+			status = "❌"
+		}
+		text += fmt.Sprintf("%s %s %s %s → %s\n", status, record.Time.In(location).Format("2006-01-02 15:04:05 MST"),
+			record.Type, record.Server, record.Channel)
+		if record.Error != "" {
+			text += fmt.Sprintf("   %s\n", record.Error)
+		}
+	}
+
+	if text == "" {
+		text = l.t(ctx.chatId, "notifications.none")
+	}
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, text))
+}
@@ -0,0 +1,43 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+)
+
+// setPin pins the public key fingerprint of the server, "current" captures it from the next check
+// and "clear" removes the pin
+func (l *TelegramListener) setPin(ctx *commandContext) {
+	var name, value = ctx.fields[0], ctx.fields[1]
+
+	var fingerprint string
+	var pending = value == "current"
+	if value != "current" && !clearArg(value) {
+		var err error
+		if fingerprint, err = checks.ParsePin(value); err != nil {
+			l.reply(ctx.chatId, "pin.invalid")
+			return
+		}
+	}
+
+	err := checks.SetPin(name, fingerprint, pending)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	switch {
+	case pending:
+		l.reply(ctx.chatId, "pin.pending", name)
+	case fingerprint == "":
+		l.reply(ctx.chatId, "pin.cleared", name)
+	default:
+		l.reply(ctx.chatId, "pin.set", name, fingerprint)
+	}
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"time"
+)
+
+// ping replies "pong" and edits the reply with the send round-trip time, age of the update and the last check cycle
+func (l *TelegramListener) ping(ctx *commandContext) {
+	var received = time.Now()
+	var updateAge = received.Sub(ctx.message.Time())
+
+	reply, err := l.send(tgbotapi.NewMessage(ctx.chatId, "pong"))
+	if err != nil {
+		log.Printf("[ERROR] Failed to send message: %v", err)
+		return
+	}
+	var roundTrip = time.Since(received)
+
+	var lang = ctx.lang
+	var text = i18n.T(lang, "ping.result", roundTrip.Round(time.Millisecond), updateAge.Round(time.Second),
+		formatTimeAgo(lang, l.location(), checks.LastCycleTime()))
+	if _, err := l.Sender.Send(tgbotapi.NewEditMessageText(ctx.chatId, reply.MessageID, text)); err != nil {
+		log.Printf("[ERROR] Failed to edit message: %v", err)
+	}
+}
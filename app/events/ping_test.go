@@ -0,0 +1,42 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestPing(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	var message = commandMessage(1, "/ping")
+	message.Date = int(time.Now().Add(-30 * time.Second).Unix())
+	l.handleCommand(message)
+
+	var sent = telegram.sent()
+	if len(sent) != 2 || sent[0] != "pong" {
+		t.Fatalf("got messages %q, want pong and its edit", sent)
+	}
+	var edits = telegram.requested("editMessageText")
+	if len(edits) != 1 || edits[0].Get("message_id") != "1" {
+		t.Fatalf("got edits %v, want edit of the pong reply", edits)
+	}
+	var want = regexp.MustCompile(`^pong\nSend round-trip: [0-9.]+[µm]?s\nUpdate age: 3[01]s\nLast check cycle: (never|.+ ago)$`)
+	if !want.MatchString(sent[1]) {
+		t.Errorf("got edit %q, want round-trip, update age and the last cycle", sent[1])
+	}
+}
+
+func TestPingNotEditedIfNotSent(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+	telegram.breakMethod("sendMessage")
+
+	l.handleCommand(commandMessage(1, "/ping"))
+
+	if edits := telegram.requested("editMessageText"); len(edits) != 0 {
+		t.Errorf("got edits %v of the failed reply", edits)
+	}
+}
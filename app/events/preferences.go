@@ -0,0 +1,103 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"slices"
+)
+
+// preferences shows alert preferences of the subscriber with buttons to toggle categories,
+// /preferences quiet 22:00-08:00 sets quiet hours and /preferences quiet off clears them
+func (l *TelegramListener) preferences(ctx *commandContext) {
+	var preferences = checks.ReadChecksData().Preferences[ctx.chatId]
+
+	if len(ctx.fields) > 0 {
+		if ctx.fields[0] != "quiet" || len(ctx.fields) != 2 {
+			l.reply(ctx.chatId, "command.usage", ctx.usage)
+			return
+		}
+
+		var window = ctx.fields[1]
+		if clearArg(window) {
+			window = ""
+		}
+		var err error
+		preferences, err = checks.SetQuietHours(ctx.chatId, window)
+		if errors.Is(err, checks.ErrQuietHours) {
+			l.reply(ctx.chatId, "preferences.quiet_invalid")
+			return
+		}
+		if err != nil {
+			log.Printf("[ERROR] Failed to save checks data: %v", err)
+			l.reply(ctx.chatId, "preferences.failed")
+			return
+		}
+	}
+
+	msg := tgbotapi.NewMessage(ctx.chatId, formatPreferences(ctx.lang, preferences))
+	msg.ReplyMarkup = preferencesKeyboard(ctx.lang, preferences)
+	l.send(msg)
+}
+
+func formatPreferences(lang i18n.Lang, preferences checks.Preferences) string {
+	var text = i18n.T(lang, "preferences.title")
+	if preferences.HasQuietHours() {
+		text += "\n" + i18n.T(lang, "preferences.quiet", preferences.QuietFrom, preferences.QuietTo)
+	} else {
+		text += "\n" + i18n.T(lang, "preferences.no_quiet")
+	}
+	return text
+}
+
+// preferencesKeyboard returns a button per category switching its mode and a button clearing quiet hours
+func preferencesKeyboard(lang i18n.Lang, preferences checks.Preferences) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, category := range checks.Categories {
+		var text = i18n.T(lang, "preferences.category."+category) + ": " +
+			i18n.T(lang, "preferences.mode."+string(preferences.Mode(category)))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(text, "pref:"+category)))
+	}
+	if preferences.HasQuietHours() {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.clear_quiet"), "pref:noquiet")))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// preferencesCallback toggles the category or clears quiet hours and edits the preferences message
+func (l *TelegramListener) preferencesCallback(query *tgbotapi.CallbackQuery, arg string) {
+	var chatId = query.Message.Chat.ID
+	var lang = l.lang(chatId)
+
+	if !query.Message.Chat.IsPrivate() || !l.IsSuper(query.From) && l.Viewers.Match(query.From) == "" {
+		l.answerCallbackAlert(query, i18n.T(lang, "callback.unauthorized"))
+		return
+	}
+
+	var preferences checks.Preferences
+	var err error
+	if arg == "noquiet" {
+		preferences, err = checks.SetQuietHours(chatId, "")
+	} else if slices.Contains(checks.Categories, arg) {
+		preferences, err = checks.ToggleCategory(chatId, arg)
+	} else {
+		l.answerCallbackAlert(query, i18n.T(lang, "callback.stale"))
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.answerCallbackAlert(query, i18n.T(lang, "preferences.failed"))
+		return
+	}
+	l.answerCallback(query, "")
+
+	var edit = tgbotapi.NewEditMessageTextAndMarkup(chatId, query.Message.MessageID, formatPreferences(lang, preferences),
+		preferencesKeyboard(lang, preferences))
+	if _, err := l.Sender.Send(edit); err != nil {
+		log.Printf("[ERROR] Failed to edit message: %v", err)
+	}
+}
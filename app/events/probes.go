@@ -0,0 +1,78 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"log"
+	"strings"
+	"time"
+)
+
+// setProbes sets comma separated locations of agents checking the server, "clear" removes them
+func (l *TelegramListener) setProbes(ctx *commandContext) {
+	var name, value = ctx.fields[0], ctx.fields[1]
+
+	var locations []string
+	if !clearArg(value) {
+		locations = strings.Split(value, ",")
+	}
+
+	err := checks.SetProbeLocations(name, locations)
+	if errors.Is(err, checks.ErrInvalidLocation) {
+		l.reply(ctx.chatId, "probes.invalid")
+		return
+	}
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	if len(locations) == 0 {
+		l.reply(ctx.chatId, "probes.off", name)
+		return
+	}
+	l.reply(ctx.chatId, "probes.set", name, strings.Join(locations, ", "))
+}
+
+// formatProbeResults formats the last result of the bot and of each agent location checking the server
+func formatProbeResults(lang i18n.Lang, serverCheck checks.ServerCheck) string {
+	if len(serverCheck.ProbeLocations) == 0 {
+		return ""
+	}
+
+	var text = i18n.T(lang, "details.locations")
+	if lastResult, ok := serverCheck.LastResult(); ok {
+		text += formatProbeResult(lang, checks.LocalLocation, checks.ProbeResult{Time: lastResult.Time,
+			Status: lastResult.Status, ResponseTime: lastResult.ResponseTime, Error: lastResult.Error})
+	}
+	for _, location := range serverCheck.ProbeLocations {
+		probe, ok := serverCheck.ProbeResults[location]
+		if !ok {
+			text += i18n.T(lang, "details.location_no_data", location)
+			continue
+		}
+		text += formatProbeResult(lang, location, probe)
+	}
+
+	return text
+}
+
+func formatProbeResult(lang i18n.Lang, location string, probe checks.ProbeResult) string {
+	var icon = "✅"
+	if probe.Status == checks.StatusFailed {
+		icon = "❌"
+	}
+
+	var text = i18n.T(lang, "details.location", icon, location, probe.ResponseTime.Round(time.Millisecond),
+		i18n.TimeAgo(lang, probe.Time))
+	if probe.Status == checks.StatusFailed && probe.Error != "" {
+		text += " " + probe.Error
+	}
+	return text + "\n"
+}
@@ -0,0 +1,40 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+)
+
+// setMinProto sets the lowest accepted protocol of the server and whether the check fails or warns below it
+func (l *TelegramListener) setMinProto(ctx *commandContext) {
+	var name, minProto = ctx.fields[0], ctx.fields[1]
+	var action = checks.ProtoFail
+	if len(ctx.fields) > 2 {
+		action = checks.ProtoAction(ctx.fields[2])
+	}
+
+	if clearArg(minProto) {
+		minProto = ""
+	} else if !checks.ValidMinProto(minProto) || action != checks.ProtoFail && action != checks.ProtoWarn {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	err := checks.SetMinProto(name, minProto, action)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	if minProto == "" {
+		l.reply(ctx.chatId, "proto.off", name)
+		return
+	}
+	l.reply(ctx.chatId, "proto."+string(action), name, minProto)
+}
@@ -0,0 +1,121 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var downloadClient = &http.Client{Timeout: time.Minute}
+
+// restore replaces the storage with the backup document the command replies to after confirmation,
+// /restore undo puts back the storage replaced by the last restore
+func (l *TelegramListener) restore(ctx *commandContext) {
+	if len(ctx.fields) == 1 {
+		if ctx.fields[0] != "undo" {
+			l.reply(ctx.chatId, "command.usage", ctx.usage)
+			return
+		}
+		l.undoRestore(ctx)
+		return
+	}
+
+	var reply = ctx.message.ReplyToMessage
+	if reply == nil || reply.Document == nil {
+		l.reply(ctx.chatId, "restore.no_document")
+		return
+	}
+	if reply.Document.FileSize > checks.MaxRestoreSize {
+		l.reply(ctx.chatId, "restore.too_large", checks.MaxRestoreSize>>20)
+		return
+	}
+
+	raw, err := l.downloadFile(reply.Document.FileID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to download backup: %v", err)
+		l.reply(ctx.chatId, "restore.download_failed")
+		return
+	}
+
+	restored, err := checks.ParseRestore(raw)
+	if err != nil {
+		log.Printf("[WARN] Invalid backup %s: %v", reply.Document.FileName, err)
+		l.reply(ctx.chatId, "restore.invalid", err.Error())
+		return
+	}
+
+	var lang = ctx.lang
+	added, updated, removed := checks.RestoreDiff(checks.ReadChecksData(), restored)
+	var text = i18n.T(lang, "restore.confirm", reply.Document.FileName, added, updated, removed)
+	l.requestConfirmation(ctx, text, func() {
+		if err := checks.Restore(restored); err != nil {
+			log.Printf("[ERROR] Failed to restore storage: %v", err)
+			l.reply(ctx.chatId, "restore.failed")
+			return
+		}
+
+		log.Printf("[INFO] Storage restored from %s by %s", reply.Document.FileName, ctx.user.UserName)
+		l.applyRestoredCron()
+		l.reply(ctx.chatId, "restore.done", len(restored.HealthChecks))
+	})
+}
+
+func (l *TelegramListener) undoRestore(ctx *commandContext) {
+	l.requestConfirmation(ctx, i18n.T(ctx.lang, "restore.undo_confirm"), func() {
+		err := checks.UndoRestore()
+		if errors.Is(err, checks.ErrNoPrerestore) {
+			l.reply(ctx.chatId, "restore.nothing_to_undo")
+			return
+		}
+		if err != nil {
+			log.Printf("[ERROR] Failed to undo restore: %v", err)
+			l.reply(ctx.chatId, "restore.failed")
+			return
+		}
+
+		log.Printf("[INFO] Restore undone by %s", ctx.user.UserName)
+		l.applyRestoredCron()
+		l.reply(ctx.chatId, "restore.undone")
+	})
+}
+
+// applyRestoredCron schedules checks on the cron of the restored settings
+func (l *TelegramListener) applyRestoredCron() {
+	var spec = checks.ReadChecksData().Settings.ChecksCron
+	if spec == "" {
+		spec = l.Scheduler.DefaultSpec
+	}
+	if err := l.Scheduler.SetSpec(spec); err != nil {
+		log.Printf("[WARN] Invalid checks cron %q of the restored settings, kept %q: %v", spec, l.Scheduler.Spec(), err)
+	}
+}
+
+// downloadFile downloads the file sent to the bot, the url of the file contains the bot token
+// and is not included in the error
+func (l *TelegramListener) downloadFile(fileId string) ([]byte, error) {
+	fileUrl, err := l.Bot.GetFileDirectURL(fileId)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := downloadClient.Get(fileUrl)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return nil, urlErr.Err
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d", response.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, checks.MaxRestoreSize+1))
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+	"strconv"
+	"time"
+)
+
+// setRetries sets how many times timeouts and dropped connections are retried within a check of the server
+func (l *TelegramListener) setRetries(ctx *commandContext) {
+	var name = ctx.fields[0]
+
+	var retries int
+	if !clearArg(ctx.fields[1]) {
+		var err error
+		retries, err = strconv.Atoi(ctx.fields[1])
+		if err != nil || retries < 0 || retries > checks.MaxRetries {
+			l.reply(ctx.chatId, "retries.invalid", checks.MaxRetries, checks.MaxRetryDelay)
+			return
+		}
+	}
+
+	var delay time.Duration
+	if len(ctx.fields) > 2 {
+		var err error
+		delay, err = time.ParseDuration(ctx.fields[2])
+		if err != nil || delay <= 0 || delay > checks.MaxRetryDelay {
+			l.reply(ctx.chatId, "retries.invalid", checks.MaxRetries, checks.MaxRetryDelay)
+			return
+		}
+	}
+
+	err := checks.SetRetries(name, retries, delay)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	if retries == 0 {
+		l.reply(ctx.chatId, "retries.off", name)
+		return
+	}
+	if delay == 0 {
+		delay = checks.DefaultRetryDelay
+	}
+	l.reply(ctx.chatId, "retries.set", name, retries, delay, checks.CheckDeadline)
+}
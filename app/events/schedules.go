@@ -0,0 +1,31 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+	"strings"
+)
+
+// setSchedule assigns the server to a named check schedule or back to the default one
+func (l *TelegramListener) setSchedule(ctx *commandContext) {
+	var name, schedule = ctx.fields[0], ctx.fields[1]
+	if clearArg(schedule) {
+		schedule = checks.DefaultSchedule
+	}
+
+	err := checks.SetSchedule(name, schedule)
+	switch {
+	case errors.Is(err, checks.ErrScheduleNotExists):
+		l.reply(ctx.chatId, "schedule.not_exists", schedule, strings.Join(checks.ScheduleNames(), ", "))
+	case errors.Is(err, checks.ErrServerNotExists):
+		l.reply(ctx.chatId, "server.not_exists", name)
+	case err != nil:
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+	case schedule == checks.DefaultSchedule:
+		l.reply(ctx.chatId, "schedule.default", name, l.Scheduler.Spec())
+	default:
+		l.reply(ctx.chatId, "schedule.set", name, schedule, checks.Schedules[schedule])
+	}
+}
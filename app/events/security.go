@@ -0,0 +1,48 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"log"
+)
+
+// securityCheck switches the security header audit of the server
+func (l *TelegramListener) securityCheck(ctx *commandContext) {
+	var name, mode = ctx.fields[0], ctx.fields[1]
+	if mode != "on" && mode != "off" {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	err := checks.SetSecurityCheck(name, mode == "on")
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	l.reply(ctx.chatId, "security."+mode, name)
+}
+
+// formatSecurityHeaders formats the last security header audit of the server
+func formatSecurityHeaders(lang i18n.Lang, serverCheck checks.ServerCheck) string {
+	if !serverCheck.SecurityCheck || serverCheck.SecurityHeaders == nil {
+		return ""
+	}
+
+	var text = i18n.T(lang, "details.security")
+	for _, header := range checks.SecurityHeaders() {
+		var icon = "✅"
+		if !serverCheck.SecurityHeaders[header] {
+			icon = "❌"
+		}
+		text += icon + " " + header + "\n"
+	}
+
+	return text
+}
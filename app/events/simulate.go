@@ -0,0 +1,54 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+	"strconv"
+)
+
+// maxSimulatedCycles limits the simulated failure, so a forgotten simulation ends
+const maxSimulatedCycles = 1000
+
+// simulate makes the server fail for the next check cycles to rehearse alerting, /simulate <name> off cancels it
+func (l *TelegramListener) simulate(ctx *commandContext) {
+	var name, mode = ctx.fields[0], ctx.fields[1]
+
+	var cycles int
+	switch {
+	case clearArg(mode):
+		if len(ctx.fields) > 2 {
+			l.reply(ctx.chatId, "command.usage", ctx.usage)
+			return
+		}
+	case mode == "down" && len(ctx.fields) == 3:
+		var err error
+		cycles, err = strconv.Atoi(ctx.fields[2])
+		if err != nil || cycles < 1 || cycles > maxSimulatedCycles {
+			l.reply(ctx.chatId, "simulate.invalid", maxSimulatedCycles)
+			return
+		}
+	default:
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	err := checks.Simulate(name, cycles)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	if cycles == 0 {
+		log.Printf("[INFO] Simulated failure of server %s cancelled by %s", name, ctx.user.UserName)
+		l.reply(ctx.chatId, "simulate.off", name)
+		return
+	}
+	log.Printf("[INFO] Simulated failure of server %s for %d cycles started by %s", name, cycles, ctx.user.UserName)
+	l.reply(ctx.chatId, "simulate.on", name, cycles)
+}
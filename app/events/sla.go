@@ -0,0 +1,87 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"strconv"
+	"time"
+)
+
+// setSla sets the availability target of the server over the window, "clear" removes it
+func (l *TelegramListener) setSla(ctx *commandContext) {
+	var name = ctx.fields[0]
+
+	var target float64
+	var window = checks.DefaultSlaWindow
+	if !clearArg(ctx.fields[1]) {
+		var err error
+		target, err = strconv.ParseFloat(ctx.fields[1], 64)
+		if err == nil && len(ctx.fields) > 2 {
+			window, err = checks.ParseSlaWindow(ctx.fields[2])
+		}
+		if err != nil || target <= 0 || target >= 100 {
+			l.reply(ctx.chatId, "sla.invalid")
+			return
+		}
+	}
+
+	err := checks.SetSla(name, target, window)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	if target == 0 {
+		l.reply(ctx.chatId, "sla.off", name)
+		return
+	}
+	l.reply(ctx.chatId, "sla.set", name, target, i18n.Window(ctx.lang, window))
+}
+
+// sla shows availability of the servers with SLA targets, servers below the target are marked
+func (l *TelegramListener) sla(ctx *commandContext) {
+	var lang = ctx.lang
+	var now = time.Now()
+
+	var text string
+	for _, serverCheck := range sortedServers(checks.ReadChecksData().HealthChecks, listOptions{sort: sortName}) {
+		if serverCheck.SlaTarget == 0 {
+			continue
+		}
+
+		var icon = "✅"
+		if serverCheck.SlaBreached {
+			icon = "⚠️"
+		}
+		text += fmt.Sprintf("%s %s: %s", icon, serverCheck.Name, formatSla(lang, now, l.location(), serverCheck))
+	}
+	if text == "" {
+		l.reply(ctx.chatId, "sla.empty")
+		return
+	}
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, i18n.T(lang, "sla.title")+text))
+}
+
+// formatSla formats availability of the server over its SLA window, the error budget left and MTTR and MTBF
+// of incidents in the window
+func formatSla(lang i18n.Lang, now time.Time, location *time.Location, serverCheck checks.ServerCheck) string {
+	var window = i18n.Window(lang, serverCheck.SlaWindow)
+	availability, ok := serverCheck.SlaAvailability(now, location)
+	if !ok {
+		return i18n.T(lang, "sla.no_data", serverCheck.SlaTarget, window)
+	}
+
+	var mttr, mtbf = formatMttrMtbf(lang, serverCheck, now.Add(-serverCheck.SlaWindow))
+	return i18n.T(lang, "sla.status", availability, serverCheck.SlaTarget, window,
+		checks.ErrorBudgetLeft(availability, serverCheck.SlaTarget)) + i18n.T(lang, "sla.reliability", mttr, mtbf)
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"testing"
+	"time"
+)
+
+func TestSlaReliability(t *testing.T) {
+	var now = time.Now()
+	var history = []checks.CheckResult{{Time: now.Add(-time.Minute), Status: checks.StatusOk}}
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com", IsOk: true, History: history,
+		SlaTarget: 99, SlaWindow: 12 * time.Hour,
+		PastIncidents: []checks.Incident{
+			// the incident before the window is not counted
+			{Start: now.Add(-20 * time.Hour), End: now.Add(-18 * time.Hour)},
+			{Start: now.Add(-6 * time.Hour), End: now.Add(-5 * time.Hour)},
+			{Start: now.Add(-3 * time.Hour), End: now.Add(-150 * time.Minute)},
+		}},
+		checks.ServerCheck{Name: "api", Url: "https://api.example.com", IsOk: true, History: history,
+			SlaTarget: 99.9, SlaWindow: 12 * time.Hour})
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/sla"))
+
+	assertReplies(t, telegram, "SLA targets:\n"+
+		"✅ api: 100.000% of 99.9% over 12 hours 0 minutes, error budget left 100.0%\n  MTTR n/a, MTBF n/a\n"+
+		"✅ web: 100.000% of 99% over 12 hours 0 minutes, error budget left 100.0%\n  MTTR 45m 0s, MTBF 3h 0m\n")
+}
@@ -0,0 +1,149 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sort keys of the servers list
+const (
+	sortName         = "name"
+	sortStatus       = "status"
+	sortAdded        = "added"
+	sortAvailability = "availability"
+	sortLatency      = "latency"
+	sortScore        = "score"
+	sortChanges      = "changes"
+)
+
+// listOptions are optional arguments of /list and /stats like "sort:latency limit:5 tag:prod compact", limit 0 means
+// all servers, an empty tag doesn't filter and an empty mode is the mode of the chat. Location is the timezone
+// of days state changes are counted in.
+type listOptions struct {
+	sort     string
+	limit    int
+	tag      string
+	mode     string
+	location *time.Location
+}
+
+// parseListOptions parses "sort:", "limit:" and "tag:" arguments and the mode, the sort key is defaultSort
+// if it is not set. Returns false if an argument is unknown or invalid.
+func parseListOptions(fields []string, defaultSort string) (listOptions, bool) {
+	var options = listOptions{sort: defaultSort}
+	for _, field := range fields {
+		if slices.Contains(checks.ListModes, field) {
+			options.mode = field
+			continue
+		}
+
+		key, value, _ := strings.Cut(field, ":")
+		switch key {
+		case "sort":
+			if !slices.Contains([]string{sortName, sortStatus, sortAdded, sortAvailability, sortLatency, sortScore, sortChanges}, value) {
+				return options, false
+			}
+			options.sort = value
+		case "limit":
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 {
+				return options, false
+			}
+			options.limit = limit
+		case "tag":
+			if value == "" {
+				return options, false
+			}
+			options.tag = value
+		default:
+			return options, false
+		}
+	}
+
+	return options, true
+}
+
+// sortedServers returns snapshot of the servers with the tag sorted by the key, ties are broken by name.
+// Down servers come first by status, then degraded, up and paused ones. Servers added first come first by added,
+// servers added before the time was stored come before them. Lowest availability, slowest average response time,
+// lowest score and most state changes come first, servers without checks come last.
+func sortedServers(healthChecks map[string]checks.ServerCheck, options listOptions) []checks.ServerCheck {
+	var servers = make([]checks.ServerCheck, 0, len(healthChecks))
+	for _, serverCheck := range healthChecks {
+		if options.tag == "" || serverCheck.HasTag(options.tag) {
+			servers = append(servers, serverCheck)
+		}
+	}
+
+	var keys = make(map[string]float64, len(servers))
+	for _, serverCheck := range servers {
+		keys[serverCheck.Name] = sortValue(serverCheck, options)
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		var a, b = keys[servers[i].Name], keys[servers[j].Name]
+		if a != b {
+			return a < b
+		}
+		return servers[i].Name < servers[j].Name
+	})
+
+	if options.limit > 0 && len(servers) > options.limit {
+		servers = servers[:options.limit]
+	}
+	return servers
+}
+
+// sortValue returns value of the server to sort by ascending, zero for sorting by name
+func sortValue(serverCheck checks.ServerCheck, options listOptions) float64 {
+	switch options.sort {
+	case sortStatus:
+		return float64(statusRank(serverCheck))
+	case sortAdded:
+		if serverCheck.AddedAt.IsZero() {
+			return 0
+		}
+		return float64(serverCheck.AddedAt.UnixNano())
+	case sortAvailability:
+		availability, ok := checks.Availability(serverCheck.History)
+		if !ok {
+			return 101
+		}
+		return availability
+	case sortLatency:
+		_, avg, _, count := checks.ResponseTimeStats(serverCheck.History)
+		if count == 0 {
+			return 1
+		}
+		// negative to put the slowest first
+		return -avg.Seconds()
+	case sortScore:
+		if serverCheck.ScoredAt.IsZero() {
+			return 101
+		}
+		return float64(serverCheck.Score)
+	case sortChanges:
+		// negative to put the most unstable first
+		return -float64(serverCheck.StateChanges(time.Now(), options.location, 7))
+	default:
+		return 0
+	}
+}
+
+// statusRank returns the order of the server state in the list, down first and paused last
+func statusRank(serverCheck checks.ServerCheck) int {
+	switch {
+	case serverCheck.Paused:
+		return 3
+	case !serverCheck.IsOk:
+		return 0
+	}
+	if result, ok := serverCheck.LastResult(); ok && result.Status == checks.StatusDegraded {
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,183 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mixedServers are down, degraded, up and paused servers with different history, add time, score and state changes,
+// servers with checks were last checked an hour ago, api, db and web are tagged prod
+func mixedServers() []checks.ServerCheck {
+	var now = time.Now()
+	var checked = now.Add(-time.Hour)
+	var today = now.UTC().Format("2006-01-02")
+	var result = func(ago time.Duration, status checks.CheckStatus, responseTime time.Duration) checks.CheckResult {
+		return checks.CheckResult{Time: checked.Add(time.Minute - ago), Status: status, ResponseTime: responseTime}
+	}
+	var changes = func(count int) []checks.DayUptime {
+		return []checks.DayUptime{{Date: today, Checks: 3, Successes: 1, StateChanges: count}}
+	}
+
+	return []checks.ServerCheck{
+		{Name: "api", Url: "https://api.example.com", IsOk: true, LastChecked: checked,
+			History: []checks.CheckResult{
+				result(3*time.Minute, checks.StatusOk, 300*time.Millisecond),
+				result(2*time.Minute, checks.StatusOk, 300*time.Millisecond),
+				result(time.Minute, checks.StatusOk, 300*time.Millisecond),
+			},
+			AddedAt: now.Add(-3 * 24 * time.Hour), Score: 90, ScoredAt: now, DailyUptime: changes(1), Tags: []string{"prod"}},
+		{Name: "db", Url: "https://db.example.com", LastChecked: checked,
+			History: []checks.CheckResult{
+				result(3*time.Minute, checks.StatusOk, 100*time.Millisecond),
+				result(2*time.Minute, checks.StatusFailed, 0),
+				result(time.Minute, checks.StatusFailed, 0),
+			},
+			AddedAt: now.Add(-10 * 24 * time.Hour), Score: 20, ScoredAt: now, DailyUptime: changes(3), Tags: []string{"prod", "db"}},
+		{Name: "cache", Url: "https://cache.example.com", IsOk: true, LastChecked: checked,
+			History: []checks.CheckResult{
+				result(2*time.Minute, checks.StatusOk, 200*time.Millisecond),
+				result(time.Minute, checks.StatusDegraded, 900*time.Millisecond),
+			},
+			Score: 70, ScoredAt: now},
+		{Name: "web", Url: "https://example.com", IsOk: true, LastChecked: checked,
+			History: []checks.CheckResult{
+				result(3*time.Minute, checks.StatusFailed, 0),
+				result(2*time.Minute, checks.StatusOk, 50*time.Millisecond),
+				result(time.Minute, checks.StatusOk, 50*time.Millisecond),
+			},
+			AddedAt: now.Add(-24 * time.Hour), Score: 60, ScoredAt: now, DailyUptime: changes(2), Tags: []string{"prod"}},
+		{Name: "backup", Url: "https://backup.example.com", IsOk: true, Paused: true,
+			AddedAt: now.Add(-5 * 24 * time.Hour)},
+		{Name: "mail", Url: "https://mail.example.com", LastChecked: checked,
+			History: []checks.CheckResult{result(time.Minute, checks.StatusFailed, 0)},
+			AddedAt: now.Add(-2 * 24 * time.Hour), Score: 10, ScoredAt: now, DailyUptime: changes(1)},
+	}
+}
+
+// serverNames returns names of the servers in order
+func serverNames(servers []checks.ServerCheck) []string {
+	var names []string
+	for _, serverCheck := range servers {
+		names = append(names, serverCheck.Name)
+	}
+	return names
+}
+
+func TestSortedServers(t *testing.T) {
+	var healthChecks = map[string]checks.ServerCheck{}
+	for _, serverCheck := range mixedServers() {
+		healthChecks[serverCheck.Name] = serverCheck
+	}
+
+	var tests = []struct {
+		sort string
+		want []string
+	}{
+		{sortName, []string{"api", "backup", "cache", "db", "mail", "web"}},
+		// down, degraded, up and paused, by name within a state
+		{sortStatus, []string{"db", "mail", "cache", "api", "web", "backup"}},
+		// cache was added before the add time was stored
+		{sortAdded, []string{"cache", "db", "backup", "api", "mail", "web"}},
+		// backup has no checks
+		{sortAvailability, []string{"mail", "db", "web", "api", "cache", "backup"}},
+		// backup and mail have no response times
+		{sortLatency, []string{"cache", "api", "db", "web", "backup", "mail"}},
+		// backup is not scored
+		{sortScore, []string{"mail", "db", "web", "cache", "api", "backup"}},
+		{sortChanges, []string{"db", "web", "api", "mail", "backup", "cache"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.sort, func(t *testing.T) {
+			// the order doesn't depend on map iteration
+			for i := 0; i < 10; i++ {
+				var servers = sortedServers(healthChecks, listOptions{sort: test.sort, location: time.UTC})
+				if names := serverNames(servers); !slices.Equal(names, test.want) {
+					t.Fatalf("got order %v, want %v", names, test.want)
+				}
+			}
+
+			var limited = sortedServers(healthChecks, listOptions{sort: test.sort, limit: 2, location: time.UTC})
+			if names := serverNames(limited); !slices.Equal(names, test.want[:2]) {
+				t.Errorf("got %v with limit 2, want %v", names, test.want[:2])
+			}
+		})
+	}
+}
+
+func TestSortedServersWithTag(t *testing.T) {
+	var healthChecks = map[string]checks.ServerCheck{}
+	for _, serverCheck := range mixedServers() {
+		healthChecks[serverCheck.Name] = serverCheck
+	}
+
+	var tests = []struct {
+		options listOptions
+		want    []string
+	}{
+		{listOptions{sort: sortName, tag: "prod"}, []string{"api", "db", "web"}},
+		{listOptions{sort: sortName, tag: "PROD"}, []string{"api", "db", "web"}},
+		{listOptions{sort: sortStatus, tag: "prod", limit: 2}, []string{"db", "api"}},
+		{listOptions{sort: sortName, tag: "db"}, []string{"db"}},
+		{listOptions{sort: sortName, tag: "staging"}, nil},
+	}
+
+	for _, test := range tests {
+		if names := serverNames(sortedServers(healthChecks, test.options)); !slices.Equal(names, test.want) {
+			t.Errorf("got %v with %+v, want %v", names, test.options, test.want)
+		}
+	}
+}
+
+func TestParseListOptions(t *testing.T) {
+	var tests = []struct {
+		fields []string
+		want   listOptions
+		wantOk bool
+	}{
+		{nil, listOptions{sort: sortStatus}, true},
+		{[]string{"tag:prod", "limit:2"}, listOptions{sort: sortStatus, limit: 2, tag: "prod"}, true},
+		{[]string{"tag:"}, listOptions{}, false},
+		{[]string{"sort:latency"}, listOptions{sort: sortLatency}, true},
+		{[]string{"sort:added", "limit:5", "compact"}, listOptions{sort: sortAdded, limit: 5, mode: checks.ListCompact}, true},
+		{[]string{"verbose", "sort:name"}, listOptions{sort: sortName, mode: checks.ListVerbose}, true},
+		{[]string{"sort:size"}, listOptions{}, false},
+		{[]string{"limit:0"}, listOptions{}, false},
+		{[]string{"limit:many"}, listOptions{}, false},
+		{[]string{"latency"}, listOptions{}, false},
+	}
+
+	for _, test := range tests {
+		options, ok := parseListOptions(test.fields, sortStatus)
+		if ok != test.wantOk || ok && options != test.want {
+			t.Errorf("parseListOptions(%q) = %+v, %v, want %+v, %v", test.fields, options, ok, test.want, test.wantOk)
+		}
+	}
+}
+
+func TestListDefaultOrder(t *testing.T) {
+	checkstest.UseStorage(t, mixedServers()...)
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/list"))
+
+	var sent = telegram.sent()
+	if len(sent) != 1 {
+		t.Fatalf("got replies %q, want one", sent)
+	}
+	var want = []string{
+		"❌ db [https://db.example.com]",
+		"❌ mail [https://mail.example.com]",
+		"✅ cache [https://cache.example.com]",
+		"✅ api [https://api.example.com]",
+		"✅ web [https://example.com]",
+		"⏸ backup [https://backup.example.com]",
+	}
+	if lines := strings.Split(strings.TrimSuffix(sent[0], "\n"), "\n"); !slices.Equal(lines, want) {
+		t.Errorf("got list %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"strings"
+)
+
+// addSshServer adds the server checked by the SSH banner of host:port, expect= sets the text the banner must contain
+func (l *TelegramListener) addSshServer(ctx *commandContext) {
+	var fields []string
+	var expected string
+	for _, field := range ctx.fields {
+		if value, found := strings.CutPrefix(field, "expect="); found {
+			expected = value
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	sshUrl, err := checks.SshUrl(fields[0])
+	if err != nil {
+		l.reply(ctx.chatId, "ssh.invalid_host", fields[0])
+		return
+	}
+	var server = Server{Url: sshUrl, Name: fields[0], Type: checks.CheckSsh, ExpectedBanner: expected}
+	if len(fields) > 1 {
+		server.Name = fields[1]
+	}
+	if !l.createServer(ctx.chatId, server) {
+		return
+	}
+
+	l.sendAdded(ctx.chatId, server, nil)
+}
+
+// formatSshCheck formats the banner of the SSH check, empty for other servers
+func formatSshCheck(lang i18n.Lang, serverCheck checks.ServerCheck) string {
+	if !serverCheck.IsSsh() {
+		return ""
+	}
+
+	var text = i18n.T(lang, "details.ssh")
+	if serverCheck.SshBanner != "" {
+		text += i18n.T(lang, "details.ssh_banner", serverCheck.SshBanner)
+	}
+	if serverCheck.ExpectedBanner != "" {
+		text += i18n.T(lang, "details.ssh_expect", serverCheck.ExpectedBanner)
+	}
+	return text
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"slices"
+	"strings"
+	"time"
+)
+
+// addSslServer adds the server checked only by the TLS handshake with host:port
+func (l *TelegramListener) addSslServer(ctx *commandContext) {
+	var fields []string
+	var startTls string
+	for _, field := range ctx.fields {
+		value, found := strings.CutPrefix(field, "starttls=")
+		if !found {
+			fields = append(fields, field)
+			continue
+		}
+		if !slices.Contains(checks.StartTlsProtocols, value) {
+			l.reply(ctx.chatId, "ssl.invalid_starttls", strings.Join(checks.StartTlsProtocols, ", "))
+			return
+		}
+		startTls = value
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	sslUrl, err := checks.SslUrl(fields[0])
+	if err != nil {
+		l.reply(ctx.chatId, "ssl.invalid_host", fields[0])
+		return
+	}
+	var server = Server{Url: sslUrl, Name: fields[0], Type: checks.CheckSsl, StartTls: startTls}
+	if len(fields) > 1 {
+		server.Name = fields[1]
+	}
+	if !l.createServer(ctx.chatId, server) {
+		return
+	}
+
+	l.sendAdded(ctx.chatId, server, nil)
+}
+
+// formatSslCheck formats the type of the SSL check and the certificate of the server, empty for servers
+// not checked over TLS
+func formatSslCheck(lang i18n.Lang, location *time.Location, serverCheck checks.ServerCheck) string {
+	var text string
+	if serverCheck.IsSsl() && serverCheck.StartTls != "" {
+		text += i18n.T(lang, "details.ssl_starttls", serverCheck.StartTls)
+	} else if serverCheck.IsSsl() {
+		text += i18n.T(lang, "details.ssl")
+	}
+	if serverCheck.SslExpiry.IsZero() {
+		return text
+	}
+
+	if left := time.Until(serverCheck.SslExpiry); left > 0 {
+		text += i18n.T(lang, "details.ssl_expiry", checks.FormatTime(serverCheck.SslExpiry, location), i18n.Duration(lang, left))
+	} else {
+		text += i18n.T(lang, "details.ssl_expired", checks.FormatTime(serverCheck.SslExpiry, location))
+	}
+	if serverCheck.SslIssuer != "" {
+		text += i18n.T(lang, "details.ssl_issuer", serverCheck.SslIssuer)
+	}
+	return text
+}
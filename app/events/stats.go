@@ -0,0 +1,62 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"time"
+)
+
+// stats shows availability and average response time of each server over the recorded history,
+// sorted by name unless another sort key is set
+func (l *TelegramListener) stats(ctx *commandContext) {
+	options, ok := parseListOptions(ctx.fields, sortName)
+	if !ok || options.mode != "" {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	var checksData = checks.ReadChecksData()
+	options.location = checksData.Settings.Location(l.Location)
+
+	var lang = ctx.lang
+	var text string
+	for _, serverCheck := range sortedServers(checksData.HealthChecks, options) {
+		text += formatStatsLine(lang, serverCheck, options) + "\n"
+	}
+	if text == "" {
+		text = i18n.T(lang, "servers.none")
+	}
+
+	for _, part := range splitMessage(text, maxMessageLength) {
+		l.send(tgbotapi.NewMessage(ctx.chatId, part))
+	}
+}
+
+// formatStatsLine formats the icon and name of the server with its availability, average response time, downtime,
+// health score and state changes of the week
+func formatStatsLine(lang i18n.Lang, serverCheck checks.ServerCheck, options listOptions) string {
+	var prefix = serverStatusIcon(serverCheck) + " " + serverCheck.Name
+	availability, ok := checks.Availability(serverCheck.History)
+	if !ok {
+		return i18n.T(lang, "stats.unchecked", prefix)
+	}
+
+	var responseTime = i18n.T(lang, "details.na")
+	if _, avg, _, count := checks.ResponseTimeStats(serverCheck.History); count > 0 {
+		responseTime = avg.Round(time.Millisecond).String()
+	}
+	var text = i18n.T(lang, "stats.line", prefix, fmt.Sprintf("%.2f%%", availability), responseTime,
+		i18n.Plural(lang, "unit.check", len(serverCheck.History)))
+	if month, total := serverCheck.Downtime(time.Now(), options.location); total > 0 {
+		text += i18n.T(lang, "stats.downtime", i18n.ShortDuration(lang, month), i18n.ShortDuration(lang, total))
+	}
+	if !serverCheck.ScoredAt.IsZero() {
+		text += i18n.T(lang, "stats.score", serverCheck.Score)
+	}
+	if changes := serverCheck.StateChanges(time.Now(), options.location, 7); changes > 0 {
+		text += i18n.T(lang, "stats.changes", i18n.Plural(lang, "unit.change", changes))
+	}
+	return text
+}
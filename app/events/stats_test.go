@@ -0,0 +1,128 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	var lines = map[string]string{
+		"api":    "✅ api: 100.00% available, average response 300ms over 3 checks, score 90, 1 state change this week",
+		"backup": "⏸ backup: not checked yet",
+		"cache":  "✅ cache: 100.00% available, average response 550ms over 2 checks, score 70",
+		"db":     "❌ db: 33.33% available, average response 100ms over 3 checks, score 20, 3 state changes this week",
+		"mail":   "❌ mail: 0.00% available, average response n/a over 1 check, score 10, 1 state change this week",
+		"web":    "✅ web: 66.67% available, average response 50ms over 3 checks, score 60, 2 state changes this week",
+	}
+
+	var tests = []struct {
+		command string
+		want    []string
+	}{
+		{"/stats", []string{"api", "backup", "cache", "db", "mail", "web"}},
+		{"/stats sort:name", []string{"api", "backup", "cache", "db", "mail", "web"}},
+		{"/stats sort:availability", []string{"mail", "db", "web", "api", "cache", "backup"}},
+		{"/stats sort:latency", []string{"cache", "api", "db", "web", "backup", "mail"}},
+		{"/stats sort:score", []string{"mail", "db", "web", "cache", "api", "backup"}},
+		{"/stats sort:changes", []string{"db", "web", "api", "mail", "backup", "cache"}},
+		{"/stats sort:status", []string{"db", "mail", "cache", "api", "web", "backup"}},
+		{"/stats sort:latency limit:2", []string{"cache", "api"}},
+		{"/stats tag:prod sort:availability", []string{"db", "web", "api"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.command, func(t *testing.T) {
+			checkstest.UseStorage(t, mixedServers()...)
+			l, telegram := newTestListener(t)
+
+			l.handleCommand(commandMessage(1, test.command))
+
+			var want []string
+			for _, name := range test.want {
+				want = append(want, lines[name])
+			}
+			var sent = telegram.sent()
+			if len(sent) != 1 {
+				t.Fatalf("got replies %q, want one", sent)
+			}
+			if got := strings.Split(strings.TrimSuffix(sent[0], "\n"), "\n"); !slices.Equal(got, want) {
+				t.Errorf("got stats %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestStatsInvalidOptions(t *testing.T) {
+	for _, command := range []string{"/stats sort:size", "/stats limit:0", "/stats compact"} {
+		t.Run(command, func(t *testing.T) {
+			checkstest.UseStorage(t, mixedServers()...)
+			l, telegram := newTestListener(t)
+
+			l.handleCommand(commandMessage(1, command))
+
+			assertReplies(t, telegram, "Usage: /stats [sort:name|status|added|availability|latency|score|changes] [limit:N] [tag:name]")
+		})
+	}
+}
+
+func TestStatsWithoutServers(t *testing.T) {
+	checkstest.UseStorage(t, mixedServers()...)
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/stats tag:staging"))
+
+	assertReplies(t, telegram, "No servers")
+}
+
+func TestSetTags(t *testing.T) {
+	var tests = []struct {
+		command string
+		want    string
+	}{
+		{"/settags web prod,EU", "Tags of web: prod, eu"},
+		{"/settags web clear", "Tags of web cleared"},
+		{"/settags web prod,,eu", "Tags must be comma separated words of letters, digits, - and _"},
+		{"/settags missing prod", "Server missing not exists"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.command, func(t *testing.T) {
+			checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"})
+			l, telegram := newTestListener(t)
+
+			l.handleCommand(commandMessage(1, test.command))
+
+			assertReplies(t, telegram, test.want)
+		})
+	}
+}
+
+func TestStatsDowntime(t *testing.T) {
+	var month = time.Now().UTC().Format("2006-01")
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com", IsOk: true,
+		History:         []checks.CheckResult{{Time: time.Now(), Status: checks.StatusOk, ResponseTime: 50 * time.Millisecond}},
+		MonthlyDowntime: map[string]time.Duration{month: time.Hour}, TotalDowntime: 3 * time.Hour})
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/stats"))
+
+	assertReplies(t, telegram, "✅ web: 100.00% available, average response 50ms over 1 check, down 1h 0m this month, 3h 0m total\n")
+}
+
+func TestStatsScore(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com", IsOk: true,
+		History: []checks.CheckResult{{Time: time.Now(), Status: checks.StatusOk, ResponseTime: 50 * time.Millisecond}},
+		Score:   87, ScoredAt: time.Now()},
+		checks.ServerCheck{Name: "api", Url: "https://api.example.com", IsOk: true,
+			History: []checks.CheckResult{{Time: time.Now(), Status: checks.StatusOk, ResponseTime: 50 * time.Millisecond}}})
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/stats sort:score"))
+
+	assertReplies(t, telegram, "✅ web: 100.00% available, average response 50ms over 1 check, score 87\n"+
+		"✅ api: 100.00% available, average response 50ms over 1 check\n")
+}
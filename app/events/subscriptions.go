@@ -0,0 +1,105 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"strings"
+)
+
+// subscribe sends down and up alerts of the server, of servers with the tag or of all servers to the private chat
+// of the user
+func (l *TelegramListener) subscribe(ctx *commandContext) {
+	var name = subscriptionName(ctx.fields[0])
+
+	err := checks.Subscribe(ctx.chatId, name)
+	if errors.Is(err, checks.ErrInvalidTag) {
+		l.reply(ctx.chatId, "subscribe.invalid_tag")
+		return
+	}
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "subscribe.failed")
+		return
+	}
+
+	log.Printf("[INFO] %s (%d) subscribed to %s", ctx.user.UserName, ctx.user.ID, name)
+	switch tag, isTag := strings.CutPrefix(name, checks.TagPrefix); {
+	case isTag:
+		l.reply(ctx.chatId, "subscribe.tag", tag)
+	case name == checks.AllServers:
+		l.reply(ctx.chatId, "subscribe.all")
+	default:
+		l.reply(ctx.chatId, "subscribe.server", name)
+	}
+}
+
+func (l *TelegramListener) unsubscribe(ctx *commandContext) {
+	var name = subscriptionName(ctx.fields[0])
+
+	found, err := checks.Unsubscribe(ctx.chatId, name)
+	if errors.Is(err, checks.ErrInvalidTag) {
+		l.reply(ctx.chatId, "subscribe.invalid_tag")
+		return
+	}
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "subscribe.failed")
+		return
+	}
+
+	switch tag, isTag := strings.CutPrefix(name, checks.TagPrefix); {
+	case !found:
+		l.reply(ctx.chatId, "subscribe.not_subscribed", name)
+	case isTag:
+		l.reply(ctx.chatId, "unsubscribe.tag", tag)
+	case name == checks.AllServers:
+		l.reply(ctx.chatId, "unsubscribe.all")
+	default:
+		l.reply(ctx.chatId, "unsubscribe.server", name)
+	}
+}
+
+// subscriptionName returns "all" and "tag:" subscriptions in lower case, names of servers are kept
+func subscriptionName(name string) string {
+	if strings.EqualFold(name, checks.AllServers) {
+		return checks.AllServers
+	}
+	if len(name) >= len(checks.TagPrefix) && strings.EqualFold(name[:len(checks.TagPrefix)], checks.TagPrefix) {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// subscriptions lists subscriptions of the private chat
+func (l *TelegramListener) subscriptions(ctx *commandContext) {
+	var lang = ctx.lang
+	all, names, tags := checks.ReadChecksData().Subscriptions(ctx.chatId)
+	if !all && len(names) == 0 && len(tags) == 0 {
+		l.reply(ctx.chatId, "subscribe.none")
+		return
+	}
+
+	var text = i18n.T(lang, "subscribe.list")
+	if all {
+		text += "\n" + i18n.T(lang, "subscribe.list_all")
+	}
+	for _, tag := range tags {
+		text += "\n" + i18n.T(lang, "subscribe.list_tag", tag)
+	}
+	for _, name := range names {
+		text += "\n• " + name
+	}
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, text))
+}
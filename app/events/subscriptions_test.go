@@ -0,0 +1,41 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"testing"
+)
+
+func TestSubscribeTag(t *testing.T) {
+	var tests = []struct {
+		commands []string
+		want     string
+	}{
+		{[]string{"/subscribe tag:Prod"}, "You will get down and up alerts of servers tagged prod here"},
+		{[]string{"/subscribe tag:no-such_tag"}, "You will get down and up alerts of servers tagged no-such_tag here"},
+		{[]string{"/subscribe tag:"}, "Tag must be a word of letters, digits, - and _ like tag:prod"},
+		{[]string{"/subscribe tag:prod", "/unsubscribe TAG:prod"}, "You won't get alerts of servers tagged prod here anymore"},
+		{[]string{"/unsubscribe tag:prod"}, "You are not subscribed to tag:prod"},
+		{[]string{"/subscribe tag:prod", "/subscribe web", "/subscribe all", "/subscriptions"},
+			"Your subscriptions:\n• all servers\n• servers tagged prod\n• web"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com", Tags: []string{"prod"}})
+			l, telegram := newTestListener(t)
+
+			for i, command := range test.commands {
+				var message = commandMessage(i+1, command)
+				message.Chat.ID = 10
+				message.Chat.Type = "private"
+				l.handleCommand(message)
+			}
+
+			var sent = telegram.sent()
+			if len(sent) != len(test.commands) || sent[len(sent)-1] != test.want {
+				t.Errorf("got replies %q, want the last one %q", sent, test.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,28 @@
 package events
 
-import "strings"
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"strconv"
+	"strings"
+)
 
+// SuperUser is a list of usernames or numeric user ids of superusers
 type SuperUser []string
 
+// ways the user is recognized as superuser
+const (
+	matchUsername = "username"
+	matchId       = "id"
+)
+
 func (s SuperUser) IsSuper(userName string) bool {
+	if userName == "" {
+		return false
+	}
+
 	for _, super := range s {
 		if strings.EqualFold(userName, super) || strings.EqualFold("/"+userName, super) {
 			return true
@@ -12,3 +30,167 @@ func (s SuperUser) IsSuper(userName string) bool {
 	}
 	return false
 }
+
+// Match returns matchUsername or matchId if the user is superuser, empty string otherwise
+func (s SuperUser) Match(user *tgbotapi.User) string {
+	if user == nil {
+		return ""
+	}
+	if s.IsSuper(user.UserName) {
+		return matchUsername
+	}
+
+	var userId = strconv.FormatInt(user.ID, 10)
+	for _, super := range s {
+		if super == userId {
+			return matchId
+		}
+	}
+	return ""
+}
+
+// userIdentity returns @username of the user, or the name or id of users without username like superusers matched by id
+func userIdentity(user *tgbotapi.User) string {
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return "id " + strconv.FormatInt(user.ID, 10)
+}
+
+// superMatch checks flag-provided superusers and superusers added at runtime, returns how the user matched
+func (l *TelegramListener) superMatch(user *tgbotapi.User) string {
+	if match := l.SuperUsers.Match(user); match != "" {
+		return match
+	}
+	return SuperUser(checks.ReadChecksData().SuperUsers).Match(user)
+}
+
+func (l *TelegramListener) IsSuper(user *tgbotapi.User) bool {
+	return l.superMatch(user) != ""
+}
+
+// isViewer returns true if the user may run read commands, everyone in the allowed chats is a viewer with PublicRead
+func (l *TelegramListener) isViewer(user *tgbotapi.User, chatId int64) bool {
+	return l.Viewers.Match(user) != "" || l.PublicRead && l.isAllowedChat(chatId)
+}
+
+// whoami shows identity of the caller and whether they are superuser, available to everyone
+func (l *TelegramListener) whoami(ctx *commandContext) {
+	var lang = ctx.lang
+
+	var userName = "-"
+	if ctx.user.UserName != "" {
+		userName = "@" + ctx.user.UserName
+	}
+
+	var text = i18n.T(lang, "whoami.info", userName, ctx.user.ID, ctx.chatId)
+	switch l.superMatch(ctx.user) {
+	case matchUsername:
+		text += i18n.T(lang, "whoami.super_username")
+	case matchId:
+		text += i18n.T(lang, "whoami.super_id")
+	default:
+		text += i18n.T(lang, "whoami.not_super")
+		if l.isViewer(ctx.user, ctx.chatId) {
+			text += "\n" + i18n.T(lang, "whoami.viewer")
+		}
+	}
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, text))
+}
+
+func (l *TelegramListener) addSuper(ctx *commandContext) {
+	var userName = strings.TrimPrefix(ctx.fields[0], "@")
+
+	if l.SuperUsers.IsSuper(userName) || SuperUser(checks.ReadChecksData().SuperUsers).IsSuper(userName) {
+		l.reply(ctx.chatId, "super.already", userName)
+		return
+	}
+
+	saveError := checks.UpdateChecksData(func(checksData *checks.Data) {
+		checksData.SuperUsers = append(checksData.SuperUsers, userName)
+	})
+	if saveError != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", saveError)
+		l.reply(ctx.chatId, "super.add_failed", userName)
+		return
+	}
+
+	log.Printf("[INFO] Superuser %s added by %s", userName, ctx.user.UserName)
+	l.reply(ctx.chatId, "super.added", userName)
+}
+
+func (l *TelegramListener) removeSuper(ctx *commandContext) {
+	var userName = strings.TrimPrefix(ctx.fields[0], "@")
+
+	if l.SuperUsers.IsSuper(userName) {
+		l.reply(ctx.chatId, "super.flag", userName)
+		return
+	}
+
+	// superusers matched by id may have no username, so the target is compared with the id too
+	if strings.EqualFold(userName, ctx.user.UserName) || userName == strconv.FormatInt(ctx.user.ID, 10) {
+		l.requestConfirmation(ctx, i18n.T(ctx.lang, "super.remove_self", userName), func() {
+			l.deleteSuper(ctx, userName)
+		})
+		return
+	}
+
+	l.deleteSuper(ctx, userName)
+}
+
+// deleteSuper removes the superuser added at runtime, the last superuser is kept
+func (l *TelegramListener) deleteSuper(ctx *commandContext, userName string) {
+	var found, lastSuper bool
+	saveError := checks.UpdateChecksData(func(checksData *checks.Data) {
+		var superUsers []string
+		for _, super := range checksData.SuperUsers {
+			if strings.EqualFold(super, userName) {
+				found = true
+				continue
+			}
+			superUsers = append(superUsers, super)
+		}
+
+		if found && len(superUsers) == 0 && len(l.SuperUsers) == 0 {
+			lastSuper = true
+			return
+		}
+		checksData.SuperUsers = superUsers
+	})
+	if saveError != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", saveError)
+		l.reply(ctx.chatId, "super.remove_failed", userName)
+		return
+	}
+
+	switch {
+	case !found:
+		l.reply(ctx.chatId, "super.not_super", userName)
+	case lastSuper:
+		l.reply(ctx.chatId, "super.last", userName)
+	default:
+		log.Printf("[INFO] Superuser %s removed by %s", userName, ctx.user.UserName)
+		l.reply(ctx.chatId, "super.removed", userName)
+	}
+}
+
+func (l *TelegramListener) listSupers(ctx *commandContext) {
+	var lang = ctx.lang
+	var text string
+	for _, super := range l.SuperUsers {
+		text += i18n.T(lang, "super.list_flag", super)
+	}
+	for _, super := range checks.ReadChecksData().SuperUsers {
+		text += i18n.T(lang, "super.list_runtime", super)
+	}
+
+	if text == "" {
+		text = i18n.T(lang, "super.none")
+	}
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, text))
+}
@@ -0,0 +1,243 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// restart copies the storage to a new location like a new process reading it from disk
+func restart(t *testing.T) {
+	t.Helper()
+	raw, err := os.ReadFile(checks.StorageLocation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var storage = filepath.Join(t.TempDir(), "data", "checks.json")
+	if err := os.MkdirAll(filepath.Dir(storage), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(storage, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+	checks.SetStorageLocation(storage)
+	checks.InitStorage()
+}
+
+func TestSuperUsersPersistAcrossRestart(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/addsuper @bob"))
+	l.handleCommand(commandMessage(2, "/addsuper alice"))
+	l.handleCommand(commandMessage(3, "/removesuper alice"))
+
+	restart(t)
+	restarted, _ := newTestListener(t)
+	if !restarted.IsSuper(&tgbotapi.User{UserName: "Bob"}) {
+		t.Error("superuser added at runtime is not superuser after restart")
+	}
+	if restarted.IsSuper(&tgbotapi.User{UserName: "alice"}) {
+		t.Error("removed superuser is superuser after restart")
+	}
+
+	var want = []string{"Superuser bob added", "Superuser alice added", "Superuser alice removed"}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got replies %q, want %q", sent, want)
+	}
+}
+
+func TestAddSuper(t *testing.T) {
+	checkstest.UseStorage(t)
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/addsuper ADMIN"))
+	l.handleCommand(commandMessage(2, "/addsuper bob"))
+	l.handleCommand(commandMessage(3, "/addsuper Bob"))
+
+	var want = []string{"ADMIN is already a superuser", "Superuser bob added", "Bob is already a superuser"}
+	if sent := telegram.sent(); !slices.Equal(sent, want) {
+		t.Errorf("got replies %q, want %q", sent, want)
+	}
+	if supers := checks.ReadChecksData().SuperUsers; !slices.Equal(supers, []string{"bob"}) {
+		t.Errorf("got stored superusers %v, want bob", supers)
+	}
+}
+
+func TestRemoveSuper(t *testing.T) {
+	var tests = []struct {
+		name       string
+		flagSupers SuperUser
+		stored     []string
+		user       string
+		command    string
+		wantReply  string
+		wantStored []string
+	}{
+		{"runtime superuser", SuperUser{testSuper}, []string{"bob", "carol"}, testSuper,
+			"/removesuper bob", "Superuser bob removed", []string{"carol"}},
+		{"flag superuser is refused", SuperUser{testSuper}, []string{"bob"}, testSuper,
+			"/removesuper admin", "admin is set by --super flag and can't be removed at runtime, change the flag instead", []string{"bob"}},
+		{"not a superuser", SuperUser{testSuper}, []string{"bob"}, testSuper,
+			"/removesuper dave", "dave is not a superuser", []string{"bob"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkstest.UseStorage(t)
+			if err := checks.UpdateChecksData(func(checksData *checks.Data) { checksData.SuperUsers = test.stored }); err != nil {
+				t.Fatal(err)
+			}
+			l, telegram := newTestListener(t)
+			l.SuperUsers = test.flagSupers
+
+			var message = commandMessage(1, test.command)
+			message.From.UserName = test.user
+			l.handleCommand(message)
+
+			assertReplies(t, telegram, test.wantReply)
+			if supers := checks.ReadChecksData().SuperUsers; !slices.Equal(supers, test.wantStored) {
+				t.Errorf("got stored superusers %v, want %v", supers, test.wantStored)
+			}
+		})
+	}
+}
+
+func TestRemoveSelf(t *testing.T) {
+	var tests = []struct {
+		name       string
+		stored     []string
+		user       tgbotapi.User
+		command    string
+		wantEdit   string
+		wantStored []string
+	}{
+		{"username", []string{"bob", "carol"}, tgbotapi.User{ID: 10, UserName: "bob"}, "/removesuper @Bob",
+			"Superuser Bob removed", []string{"carol"}},
+		// superusers matched by id may have no username
+		{"id", []string{"10", "carol"}, tgbotapi.User{ID: 10, FirstName: "Bob"}, "/removesuper 10",
+			"Superuser 10 removed", []string{"carol"}},
+		{"last superuser is kept", []string{"bob"}, tgbotapi.User{ID: 10, UserName: "bob"}, "/removesuper bob",
+			"bob is the last superuser and can't be removed", []string{"bob"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkstest.UseStorage(t)
+			if err := checks.UpdateChecksData(func(checksData *checks.Data) { checksData.SuperUsers = test.stored }); err != nil {
+				t.Fatal(err)
+			}
+			l, telegram := newTestListener(t)
+			l.SuperUsers = nil
+
+			var message = commandMessage(1, test.command)
+			message.From = &test.user
+			l.handleCommand(message)
+
+			var requests = telegram.requested("sendMessage")
+			if len(requests) != 1 || !strings.HasPrefix(requests[0].Get("text"), "⚠️ You are about to remove yourself") {
+				t.Fatalf("got requests %v, want the confirmation", requests)
+			}
+			if supers := checks.ReadChecksData().SuperUsers; !slices.Equal(supers, test.stored) {
+				t.Fatalf("got stored superusers %v before confirmation, want %v", supers, test.stored)
+			}
+
+			var markup tgbotapi.InlineKeyboardMarkup
+			if err := json.Unmarshal([]byte(requests[0].Get("reply_markup")), &markup); err != nil {
+				t.Fatal(err)
+			}
+			l.processUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+				ID:      "1",
+				From:    &test.user,
+				Message: &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: testChat}, Text: requests[0].Get("text")},
+				Data:    *markup.InlineKeyboard[0][0].CallbackData,
+			}})
+
+			if sent := telegram.sent(); sent[len(sent)-1] != test.wantEdit {
+				t.Errorf("got replies %q, want %q last", sent, test.wantEdit)
+			}
+			if supers := checks.ReadChecksData().SuperUsers; !slices.Equal(supers, test.wantStored) {
+				t.Errorf("got stored superusers %v, want %v", supers, test.wantStored)
+			}
+		})
+	}
+}
+
+func TestListSupers(t *testing.T) {
+	checkstest.UseStorage(t)
+	if err := checks.UpdateChecksData(func(checksData *checks.Data) { checksData.SuperUsers = []string{"bob"} }); err != nil {
+		t.Fatal(err)
+	}
+	l, telegram := newTestListener(t)
+
+	l.handleCommand(commandMessage(1, "/listsupers"))
+
+	assertReplies(t, telegram, "admin (flag)\nbob (runtime)\n")
+}
+
+func TestWhoami(t *testing.T) {
+	var tests = []struct {
+		name   string
+		user   tgbotapi.User
+		chatId int64
+		want   string
+	}{
+		{"superuser by username", tgbotapi.User{ID: 10, UserName: testSuper}, testChat,
+			"Username: @admin\nUser ID: 10\nChat ID: -100\nSuperuser: yes, matched by username"},
+		{"superuser by id", tgbotapi.User{ID: 42}, testChat,
+			"Username: -\nUser ID: 42\nChat ID: -100\nSuperuser: yes, matched by user ID"},
+		{"viewer", tgbotapi.User{ID: 20, UserName: "viewer"}, testChat,
+			"Username: @viewer\nUser ID: 20\nChat ID: -100\nSuperuser: no\nViewer: yes, read-only commands are available"},
+		{"stranger in other chat", tgbotapi.User{ID: 30, UserName: "stranger"}, 30,
+			"Username: @stranger\nUser ID: 30\nChat ID: 30\nSuperuser: no"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkstest.UseStorage(t)
+			l, telegram := newTestListener(t)
+			l.SuperUsers = SuperUser{testSuper, "42"}
+			l.Viewers = SuperUser{"viewer"}
+
+			var message = commandMessage(1, "/whoami")
+			message.From = &test.user
+			message.Chat.ID = test.chatId
+			l.processUpdate(tgbotapi.Update{Message: message})
+
+			assertReplies(t, telegram, test.want)
+		})
+	}
+}
+
+func TestStrangersGetNoReply(t *testing.T) {
+	for _, chat := range []tgbotapi.Chat{
+		{ID: testChat, Type: "supergroup"},
+		{ID: 42, Type: "group"},
+		{ID: 30, Type: "private"},
+	} {
+		for _, text := range []string{"/list", "/help", "/help list", "/add web https://example.com", "/remove web",
+			"/subscribe web", "/detials web", "/ping", "hello"} {
+			t.Run(fmt.Sprintf("%s in %d", text, chat.ID), func(t *testing.T) {
+				checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"})
+				l, telegram := newTestListener(t)
+
+				var message = commandMessage(1, text)
+				message.From = &tgbotapi.User{ID: 30, UserName: "stranger"}
+				message.Chat = &chat
+				l.processUpdate(tgbotapi.Update{Message: message})
+
+				assertReplies(t, telegram, "")
+				if len(checks.ReadChecksData().HealthChecks) != 1 {
+					t.Error("servers are changed by a stranger")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+	"strings"
+)
+
+// setTags sets comma separated tags of the server, "clear" removes them
+func (l *TelegramListener) setTags(ctx *commandContext) {
+	var name, value = ctx.fields[0], ctx.fields[1]
+
+	var tags []string
+	if !clearArg(value) {
+		tags = strings.Split(value, ",")
+	}
+
+	err := checks.SetTags(name, tags)
+	if errors.Is(err, checks.ErrInvalidTag) {
+		l.reply(ctx.chatId, "tags.invalid")
+		return
+	}
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.update_failed", name)
+		return
+	}
+
+	if len(tags) == 0 {
+		l.reply(ctx.chatId, "tags.off", name)
+		return
+	}
+	l.reply(ctx.chatId, "tags.set", name, strings.Join(checks.ReadChecksData().HealthChecks[name].Tags, ", "))
+}
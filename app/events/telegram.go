@@ -1,138 +1,305 @@
 package events
 
 import (
+	"errors"
 	"fmt"
 	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/logging"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/release"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/scheduler"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/sender"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"log"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Server struct {
-	Url  string
-	Name string
+	Url       string
+	Name      string
+	BasicAuth string
+	// Grace is the grace period set when the server is added, the default one is used if it is nil
+	Grace *time.Duration
+	// Type is set for checks other than HTTP, StartTls for SSL checks added by /addssl
+	// and ExpectedBanner for SSH checks added by /addssh
+	Type           checks.CheckType
+	StartTls       string
+	ExpectedBanner string
 }
 
-func ListenTelegramUpdates(bot *tgbotapi.BotAPI, superUsers SuperUser) {
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+// TelegramListener listens to telegram updates and handles bot commands
+type TelegramListener struct {
+	Bot            *tgbotapi.BotAPI
+	Sender         *sender.Sender
+	Chat           int64
+	AllowedChats   []int64
+	SuperUsers     SuperUser
+	Scheduler      *scheduler.Scheduler
+	AlertThreshold int
+	DebugDuration  time.Duration
+	ListBars       bool
+	ListMode       string
+	AuditLog       *notify.AuditLog
+	Language       i18n.Lang
+	Location       *time.Location
+	Build          release.Info
+	StartedAt      time.Time
+	// Releases is nil if checking for new releases is disabled
+	Releases *release.Checker
+	// Viewers may run read commands, with PublicRead everyone in the allowed chats may
+	Viewers    SuperUser
+	PublicRead bool
+	// HeartbeatCron is the schedule of the heartbeat message, it is disabled by default if empty
+	HeartbeatCron string
+	// EphemeralReplies is the time after which replies to commands are deleted, 0 keeps them
+	EphemeralReplies time.Duration
+	// SkipPendingAge is the age of messages sent while the bot was offline which are ignored on start, 0 keeps all
+	SkipPendingAge time.Duration
+	// BackupChat is the chat backups of the storage are sent to, backups are disabled if it is 0
+	BackupChat int64
+	// TestAlert sends the test alert through all notification channels and returns the result of each
+	TestAlert func(event notify.Event) []checks.Delivery
 
-	updates := bot.GetUpdatesChan(u)
+	rejectedChats map[int64]bool
+	confirmations confirmations
+	conversations map[conversationKey]*conversation
+	executed      map[messageKey]time.Time
+	ephemeral     ephemeralReplies
+	updates       updatesState
+}
+
+func (l *TelegramListener) processUpdate(update tgbotapi.Update) {
+	if update.InlineQuery != nil {
+		l.processInlineQuery(update.InlineQuery)
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		l.processCallback(update.CallbackQuery)
+		return
+	}
+
+	if update.EditedMessage != nil {
+		l.processEditedMessage(update.EditedMessage)
+		return
+	}
 
-	for update := range updates {
-		// check if is not superuser, ignore
-		if !superUsers.IsSuper(update.Message.From.UserName) {
-			continue
+	if update.Message == nil {
+		return
+	}
+
+	if update.Message.MigrateToChatID != 0 {
+		l.migrateChat(update.Message.Chat.ID, update.Message.MigrateToChatID)
+		return
+	}
+
+	if update.Message.From == nil {
+		return
+	}
+
+	// not a command message can be an answer in the guided /add flow
+	if !update.Message.IsCommand() {
+		if l.IsSuper(update.Message.From) && l.isAllowedChat(update.Message.Chat.ID) {
+			l.processConversation(update.Message)
 		}
+		return
+	}
+
+	l.handleCommand(update.Message)
+}
+
+func (l *TelegramListener) addServer(ctx *commandContext) {
+	if strings.TrimSpace(ctx.args) == "" {
+		l.startAddConversation(ctx.chatId, ctx.user.ID)
+		return
+	}
+
+	var fields, grace, ok = cutGraceArg(splitArgs(ctx.args))
+	if !ok {
+		l.reply(ctx.chatId, "add.invalid_grace")
+		return
+	}
+
+	var server = getServerFields(fields)
+	server.Grace = grace
+	if parsedUrl, err := url.Parse(server.Url); err != nil || parsedUrl.Host == "" {
+		l.reply(ctx.chatId, "add.invalid_url")
+		return
+	}
+	if len(fields) == 1 {
+		server.Name = checks.UrlName(server.Url)
+	}
+	server.Url, server.BasicAuth = checks.ExtractCredentials(server.Url)
+	if !l.createServer(ctx.chatId, server) {
+		return
+	}
+	if server.BasicAuth != "" {
+		l.warnCredentials(ctx.message)
+	}
+
+	l.sendAdded(ctx.chatId, server, nil)
+}
+
+// createServer adds the server to checks, replies with the reason if it fails
+func (l *TelegramListener) createServer(chatId int64, server Server) bool {
+	if !l.validateName(chatId, server) {
+		return false
+	}
+
+	var serverCheck = checks.ServerCheck{
+		Name:      server.Name,
+		Url:       server.Url,
+		BasicAuth: server.BasicAuth,
+		Type:      server.Type,
+		StartTls:  server.StartTls,
+		IsOk:      false,
+
+		ExpectedBanner: server.ExpectedBanner,
+	}
+	if server.Grace != nil {
+		serverCheck.GraceUntil = time.Now().Add(*server.Grace)
+	}
+
+	err := checks.AddServer(serverCheck)
+	if errors.Is(err, checks.ErrServerExists) {
+		l.reply(chatId, "server.exists")
+		return false
+	}
+	if errors.Is(err, checks.ErrServerLimit) {
+		l.reply(chatId, "server.limit", checks.MaxServers, len(checks.ReadChecksData().HealthChecks))
+		return false
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(chatId, "server.add_failed", server.Name, redact.Url(server.Url))
+		return false
+	}
+
+	return true
+}
+
+func (l *TelegramListener) removeServer(ctx *commandContext) {
+	var server = getServer(ctx.args)
+
+	err := checks.RemoveServer(server.Name)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", server.Name)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		l.reply(ctx.chatId, "server.remove_failed", server.Name)
+		return
+	}
+
+	l.reply(ctx.chatId, "server.removed", server.Name)
+}
 
-		if update.Message.IsCommand() {
-			switch update.Message.Command() {
-			case "add":
-				var server = getServer(update.Message)
-				var checksData = checks.ReadChecksData()
-
-				if _, ok := checksData.HealthChecks[server.Name]; ok {
-					msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Server already exists")
-					bot.Send(msg)
-					continue
-				} else {
-					if checksData.HealthChecks == nil {
-						checksData.HealthChecks = make(map[string]checks.ServerCheck)
-					}
-
-					checksData.HealthChecks[server.Name] = checks.ServerCheck{
-						Name: server.Name,
-						Url:  server.Url,
-						IsOk: false,
-					}
-				}
-
-				saveError := checks.SaveChecksData(checksData)
-				if saveError != nil {
-					log.Printf("[ERROR] Failed to save checks data: %v", saveError)
-					bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
-						fmt.Sprintf("Failed to add server %s [%s]", server.Name, server.Url)),
-					)
-					continue
-				}
-
-				bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
-					"Server %s [%s] added", server.Name, server.Url)),
-				)
-
-			case "remove":
-				var server = getServer(update.Message)
-				var checksData = checks.ReadChecksData()
-
-				if _, ok := checksData.HealthChecks[server.Name]; ok {
-					delete(checksData.HealthChecks, server.Name)
-					msg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
-						"Server %s removed", server.Name),
-					)
-					bot.Send(msg)
-				} else {
-					msg := tgbotapi.NewMessage(
-						update.Message.Chat.ID, fmt.Sprintf("Server %s not exists", server.Name),
-					)
-					bot.Send(msg)
-					continue
-				}
-
-				saveError := checks.SaveChecksData(checksData)
-				if saveError != nil {
-					log.Printf("[ERROR] Failed to save checks data: %v", saveError)
-					bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
-						fmt.Sprintf("Failed to remove server %s", server)),
-					)
-					continue
-				}
-
-			case "removeAll":
-				var emptyData = checks.Data{
-					HealthChecks: make(map[string]checks.ServerCheck),
-				}
-
-				saveError := checks.SaveChecksData(emptyData)
-				if saveError != nil {
-					log.Printf("[ERROR] Failed to save checks data: %v", saveError)
-					bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
-						fmt.Sprintf("Failed to remove all servers")),
-					)
-					continue
-				}
-
-				bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "All servers removed"))
-
-			case "list":
-				var checksData = checks.ReadChecksData()
-
-				var serverList string
-				for _, serverCheck := range checksData.HealthChecks {
-					var serverStatus string
-					if serverCheck.IsOk {
-						serverStatus = "✅"
-					} else {
-						serverStatus = "❌"
-					}
-
-					serverList += fmt.Sprintf("%s %s [%s]\n", serverStatus, serverCheck.Name, serverCheck.Url)
-				}
-
-				if serverList == "" {
-					serverList = "No servers"
-				}
-
-				bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, serverList))
-			}
+func (l *TelegramListener) removeAllServers(ctx *commandContext) {
+	var count = len(checks.ReadChecksData().HealthChecks)
+	if count == 0 {
+		l.reply(ctx.chatId, "servers.none")
+		return
+	}
+
+	var lang = ctx.lang
+	var text = i18n.T(lang, "servers.remove_all_confirm", i18n.Plural(lang, "unit.server", count))
+	l.requestConfirmation(ctx, text, func() {
+		saveError := checks.RemoveAllServers()
+		if saveError != nil {
+			log.Printf("[ERROR] Failed to save checks data: %v", saveError)
+			l.reply(ctx.chatId, "servers.remove_all_failed")
+			return
 		}
+
+		log.Printf("[INFO] All servers removed by %s", ctx.user.UserName)
+		l.reply(ctx.chatId, "servers.removed_all")
+	})
+}
+
+const listBarWidth = 10
+
+func (l *TelegramListener) listServers(ctx *commandContext) {
+	options, ok := parseListOptions(ctx.fields, sortStatus)
+	if !ok {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+
+	if options.mode != "" {
+		l.saveListMode(ctx.chatId, options.mode)
 	}
+
+	var checksData = checks.ReadChecksData()
+	options.location = checksData.Settings.Location(l.Location)
+	if options.mode == "" {
+		options.mode = checksData.Settings.ListMode(ctx.chatId, l.ListMode)
+	}
+
+	var lang = l.lang(ctx.chatId)
+	var servers = sortedServers(checksData.HealthChecks, options)
+	var serverList string
+	if options.mode == checks.ListCompact {
+		serverList = formatCompactList(servers)
+	} else {
+		serverList = l.formatServerList(lang, servers, options)
+	}
+	if serverList == "" {
+		serverList = i18n.T(lang, "servers.none")
+	}
+	if until := checksData.Settings.MaintenanceUntil; until.After(time.Now()) {
+		serverList = i18n.T(lang, "maintenance.banner", checks.FormatTime(until, options.location)) + serverList
+	}
+
+	for _, part := range splitMessage(serverList, maxMessageLength) {
+		l.send(tgbotapi.NewMessage(ctx.chatId, part))
+	}
+}
+
+// formatServerList formats a line per server with its icon, name and url, with the details of the verbose mode
+func (l *TelegramListener) formatServerList(lang i18n.Lang, servers []checks.ServerCheck, options listOptions) string {
+	var serverList string
+	for _, serverCheck := range servers {
+		serverList += fmt.Sprintf("%s %s [%s]", serverStatusIcon(serverCheck), serverCheck.Name, redact.Url(serverCheck.Url))
+		if serverCheck.InGrace(time.Now()) {
+			serverList += " ⏳"
+		}
+		if serverCheck.IsSimulated() {
+			serverList += " " + i18n.T(lang, "list.simulated")
+		}
+		if options.sort == sortScore && !serverCheck.ScoredAt.IsZero() {
+			serverList += " " + i18n.T(lang, "list.score", serverCheck.Score)
+		}
+		if options.sort == sortChanges {
+			serverList += " " + i18n.T(lang, "list.changes", serverCheck.StateChanges(time.Now(), options.location, 7))
+		}
+		serverList += "\n"
+		if options.mode == checks.ListVerbose {
+			serverList += formatVerboseLine(lang, serverCheck)
+		}
+		if l.ListBars {
+			serverList += checks.UptimeBar(serverCheck.History, listBarWidth) + "\n"
+		}
+	}
+	return serverList
 }
 
-func getServer(message *tgbotapi.Message) Server {
-	var userArg = strings.Split(message.CommandArguments(), " ")
+func getServer(args string) Server {
+	return getServerFields(splitArgs(args))
+}
+
+func getServerFields(userArg []string) Server {
+	if len(userArg) == 0 {
+		userArg = []string{""}
+	}
 
 	var originalUrl = userArg[0]
-	var fullUrl = getFullServerUrl(userArg[0])
+	var fullUrl = checks.FullServerUrl(userArg[0])
 
 	var serverName string
 	if len(userArg) > 1 {
@@ -150,11 +317,137 @@ func getServer(message *tgbotapi.Message) Server {
 
 }
 
-func getFullServerUrl(serverUrl string) string {
-	if (strings.HasPrefix(serverUrl, "https://") ||
-		strings.HasPrefix(serverUrl, "http://")) == false {
-		serverUrl = "https://" + serverUrl
+func (l *TelegramListener) setCron(ctx *commandContext) {
+	var spec = strings.TrimSpace(ctx.args)
+	if spec == "" {
+		l.reply(ctx.chatId, "setcron.usage", l.Scheduler.Spec())
+		return
+	}
+
+	var storedSpec = spec
+	if spec == "default" {
+		spec = l.Scheduler.DefaultSpec
+		storedSpec = ""
+	}
+
+	if err := l.Scheduler.SetSpec(spec); err != nil {
+		l.reply(ctx.chatId, "setcron.invalid", spec, err)
+		return
 	}
 
-	return serverUrl
+	saveError := checks.UpdateChecksData(func(checksData *checks.Data) {
+		checksData.Settings.ChecksCron = storedSpec
+	})
+	if saveError != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", saveError)
+		l.reply(ctx.chatId, "setcron.save_failed")
+		return
+	}
+
+	var location = l.location()
+	var nextRuns []string
+	for _, run := range l.Scheduler.NextRuns(3) {
+		nextRuns = append(nextRuns, run.In(location).Format("2006-01-02 15:04:05 MST"))
+	}
+
+	l.reply(ctx.chatId, "setcron.done", spec, strings.Join(nextRuns, "\n"))
+}
+
+func (l *TelegramListener) setThresholdGlobal(ctx *commandContext) {
+	// 0 clears the threshold set at runtime, restoring the flag value
+	var threshold int
+	if !clearArg(ctx.fields[0]) {
+		var err error
+		threshold, err = strconv.Atoi(ctx.fields[0])
+		if err != nil || threshold < 1 {
+			l.reply(ctx.chatId, "threshold.usage")
+			return
+		}
+	}
+
+	var previous int
+	saveError := checks.UpdateChecksData(func(checksData *checks.Data) {
+		previous = l.alertThreshold(checksData.Settings)
+		checksData.Settings.AlertThreshold = threshold
+	})
+	if saveError != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", saveError)
+		l.reply(ctx.chatId, "threshold.failed")
+		return
+	}
+
+	if threshold == 0 {
+		l.reply(ctx.chatId, "threshold.cleared", l.AlertThreshold)
+		return
+	}
+	l.reply(ctx.chatId, "threshold.changed", previous, threshold)
+}
+
+func (l *TelegramListener) settings(ctx *commandContext) {
+	var checksData = checks.ReadChecksData()
+	var settings = checksData.Settings
+	var lang = settings.Language(ctx.chatId, l.Language)
+
+	var thresholdSource = i18n.T(lang, "settings.flag")
+	if settings.AlertThreshold > 0 {
+		thresholdSource = i18n.T(lang, "settings.runtime")
+	}
+
+	var cronSource = i18n.T(lang, "settings.flag")
+	if settings.ChecksCron != "" {
+		cronSource = i18n.T(lang, "settings.runtime")
+	}
+
+	var text = i18n.T(lang, "settings.threshold", l.alertThreshold(settings), thresholdSource)
+	text += i18n.T(lang, "settings.cron", l.Scheduler.Spec(), cronSource)
+	text += i18n.T(lang, "settings.log_level", logLevel(lang))
+	text += i18n.T(lang, "settings.language", lang)
+
+	var timezoneSource = i18n.T(lang, "settings.flag")
+	if settings.Timezone != "" {
+		timezoneSource = i18n.T(lang, "settings.runtime")
+	}
+	text += i18n.T(lang, "settings.timezone", settings.Location(l.Location), timezoneSource)
+
+	if l.BackupChat != 0 {
+		var lastBackup = i18n.T(lang, "settings.no_backup")
+		if backupAt := checksData.LastBackupAt; !backupAt.IsZero() {
+			lastBackup = formatTimeAgo(lang, settings.Location(l.Location), backupAt)
+		}
+		text += i18n.T(lang, "settings.backup", lastBackup)
+	}
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, text))
+}
+
+func (l *TelegramListener) debug(ctx *commandContext) {
+	switch strings.TrimSpace(ctx.args) {
+	case "on":
+		logging.EnableDebug(l.DebugDuration)
+		log.Printf("[INFO] Debug logging enabled by %s for %v", ctx.user.UserName, l.DebugDuration)
+		l.reply(ctx.chatId, "debug.enabled", i18n.Duration(ctx.lang, l.DebugDuration))
+	case "off":
+		logging.Setup(false)
+		log.Printf("[INFO] Debug logging disabled by %s", ctx.user.UserName)
+		l.reply(ctx.chatId, "debug.status", logLevel(ctx.lang))
+	case "status":
+		l.reply(ctx.chatId, "debug.status", logLevel(ctx.lang))
+	default:
+		l.reply(ctx.chatId, "debug.usage")
+	}
+}
+
+func logLevel(lang i18n.Lang) string {
+	if logging.IsDebug() {
+		return i18n.T(lang, "log.debug")
+	}
+	return i18n.T(lang, "log.normal")
+}
+
+// alertThreshold returns the threshold set at runtime or the flag value
+func (l *TelegramListener) alertThreshold(settings checks.Settings) int {
+	if settings.AlertThreshold > 0 {
+		return settings.AlertThreshold
+	}
+	return l.AlertThreshold
 }
@@ -0,0 +1,53 @@
+package events
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+)
+
+// testAlert sends an alert with fake data marked as test through all notification channels
+// and replies with the result of each channel, the server is not changed
+func (l *TelegramListener) testAlert(ctx *commandContext) {
+	var name, eventType = "", notify.EventDown
+	var fields = ctx.fields
+	if len(fields) > 0 {
+		if t, ok := checks.TestAlertTypes[fields[len(fields)-1]]; ok {
+			eventType = t
+			fields = fields[:len(fields)-1]
+		}
+	}
+	if len(fields) > 1 {
+		l.reply(ctx.chatId, "command.usage", ctx.usage)
+		return
+	}
+	if len(fields) == 1 {
+		name = fields[0]
+	}
+
+	event, err := checks.TestEvent(eventType, name)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+
+	log.Printf("[INFO] Test %s alert of %s sent by %s", event.Type, event.Server, ctx.user.UserName)
+	l.reply(ctx.chatId, "testalert.sending", event.Type, event.Server)
+
+	// notifiers retry failed sends, so the result is reported when all of them are done
+	go func() {
+		var lang = ctx.lang
+		var text = i18n.T(lang, "testalert.result")
+		for _, delivery := range l.TestAlert(event) {
+			if delivery.Err != nil {
+				text += "\n" + i18n.T(lang, "testalert.failed", delivery.Channel, delivery.Err)
+				continue
+			}
+			text += "\n" + i18n.T(lang, "testalert.delivered", delivery.Channel)
+		}
+		l.send(tgbotapi.NewMessage(ctx.chatId, text))
+	}()
+}
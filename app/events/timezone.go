@@ -0,0 +1,51 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"log"
+	"strings"
+	"time"
+)
+
+// location returns timezone set by /settimezone or the default timezone
+func (l *TelegramListener) location() *time.Location {
+	return checks.ReadChecksData().Settings.Location(l.Location)
+}
+
+// formatTimeAgo formats time relative to now with the absolute time, like "3 hours ago (14:02 CET)"
+func formatTimeAgo(lang i18n.Lang, location *time.Location, t time.Time) string {
+	if t.IsZero() {
+		return i18n.TimeAgo(lang, t)
+	}
+	return i18n.TimeAgo(lang, t) + " (" + checks.FormatTime(t, location) + ")"
+}
+
+func (l *TelegramListener) setTimezone(ctx *commandContext) {
+	var chatId = ctx.chatId
+	var name = strings.TrimSpace(ctx.args)
+	if name == "" {
+		l.reply(chatId, "timezone.usage", l.location())
+		return
+	}
+
+	var storedName = name
+	if name == "default" {
+		storedName = ""
+	} else if _, err := time.LoadLocation(name); err != nil {
+		l.reply(chatId, "timezone.invalid", name)
+		return
+	}
+
+	saveError := checks.UpdateChecksData(func(checksData *checks.Data) {
+		checksData.Settings.Timezone = storedName
+	})
+	if saveError != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", saveError)
+		l.reply(chatId, "timezone.failed")
+		return
+	}
+
+	var location = l.location()
+	l.reply(chatId, "timezone.set", location, checks.FormatTime(time.Now(), location))
+}
@@ -0,0 +1,204 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log"
+	"sync"
+	"time"
+)
+
+// updatesTimeout is the long polling timeout of getUpdates in seconds
+const updatesTimeout = 60
+
+// backoff of failed getUpdates requests doubles from minUpdatesBackoff up to maxUpdatesBackoff
+var minUpdatesBackoff = time.Second
+var maxUpdatesBackoff = time.Minute
+
+// updatesFailureWarning is how long getUpdates fails before the bot is not ready and a warning is sent on recovery
+var updatesFailureWarning = 5 * time.Minute
+
+// updatesState tracks failures of getUpdates
+type updatesState struct {
+	mutex        sync.Mutex
+	stopOnce     sync.Once
+	stop         chan struct{}
+	failingSince time.Time
+	lastErr      error
+}
+
+// Listen polls Telegram for updates and handles them until Stop is called,
+// failed requests are retried with exponential backoff so the bot keeps handling commands after Telegram is back
+func (l *TelegramListener) Listen() {
+	var stop = l.updates.stopChan()
+	var offset = l.skipPendingUpdates()
+	var backoff time.Duration
+
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		u := tgbotapi.NewUpdate(offset)
+		u.Timeout = updatesTimeout
+		updates, err := l.Bot.GetUpdates(u)
+		if err != nil {
+			backoff = min(max(backoff*2, minUpdatesBackoff), maxUpdatesBackoff)
+			l.updatesFailed(err, backoff)
+
+			select {
+			case <-stop:
+				return
+			case <-time.After(backoff):
+			}
+			continue
+		}
+
+		backoff = 0
+		l.updatesRecovered()
+
+		for _, update := range updates {
+			if update.UpdateID >= offset {
+				offset = update.UpdateID + 1
+				l.processUpdate(update)
+			}
+		}
+	}
+}
+
+// skipPendingUpdates ignores messages sent while the bot was offline longer ago than SkipPendingAge,
+// newer updates are processed. It returns the offset of the next update.
+func (l *TelegramListener) skipPendingUpdates() int {
+	var offset int
+	if l.SkipPendingAge <= 0 {
+		return offset
+	}
+
+	var staleAt = time.Now().Add(-l.SkipPendingAge)
+	var skipped = make(map[int64]int)
+	for {
+		u := tgbotapi.NewUpdate(offset)
+		updates, err := l.Bot.GetUpdates(u)
+		if err != nil {
+			log.Printf("[WARN] Failed to get pending updates, they are processed as usual: %v", err)
+			break
+		}
+		if len(updates) == 0 {
+			break
+		}
+
+		for _, update := range updates {
+			if update.UpdateID < offset {
+				continue
+			}
+			offset = update.UpdateID + 1
+
+			if message := updateMessage(update); message != nil && message.Time().Before(staleAt) {
+				if message.IsCommand() {
+					skipped[message.Chat.ID]++
+				}
+				continue
+			}
+			if query := update.CallbackQuery; query != nil && staleCallback(query, staleAt) {
+				if query.Message != nil {
+					skipped[query.Message.Chat.ID]++
+				}
+				continue
+			}
+			l.processUpdate(update)
+		}
+	}
+
+	for chatId, count := range skipped {
+		log.Printf("[INFO] Skipped %d stale commands in chat %d", count, chatId)
+		if l.isAllowedChat(chatId) {
+			l.reply(chatId, "updates.skipped", count)
+		}
+	}
+
+	return offset
+}
+
+// staleCallback returns true if the button may have been pressed before staleAt. Telegram doesn't tell when
+// the button was pressed, only presses on messages sent after staleAt are known to be fresh.
+func staleCallback(query *tgbotapi.CallbackQuery, staleAt time.Time) bool {
+	return query.Message == nil || query.Message.Time().Before(staleAt)
+}
+
+// updateMessage returns the new or edited message of the update, nil if there is none
+func updateMessage(update tgbotapi.Update) *tgbotapi.Message {
+	if update.Message != nil {
+		return update.Message
+	}
+	if update.EditedMessage != nil {
+		var message = *update.EditedMessage
+		message.Date = message.EditDate
+		return &message
+	}
+	return nil
+}
+
+// Stop stops Listen after the current getUpdates request completes
+func (l *TelegramListener) Stop() {
+	close(l.updates.stopChan())
+}
+
+// UpdatesError returns the last getUpdates error if getting updates fails for longer than updatesFailureWarning
+func (l *TelegramListener) UpdatesError() error {
+	l.updates.mutex.Lock()
+	defer l.updates.mutex.Unlock()
+
+	if l.updates.failingSince.IsZero() || time.Since(l.updates.failingSince) < updatesFailureWarning {
+		return nil
+	}
+	return fmt.Errorf("getting updates fails since %s: %v",
+		checks.FormatTimeAgo(l.updates.failingSince), l.updates.lastErr)
+}
+
+func (l *TelegramListener) updatesFailed(err error, backoff time.Duration) {
+	l.updates.mutex.Lock()
+	defer l.updates.mutex.Unlock()
+
+	if l.updates.failingSince.IsZero() {
+		l.updates.failingSince = time.Now()
+		log.Printf("[WARN] Getting Telegram updates failed, commands are not handled until it recovers")
+	}
+	l.updates.lastErr = err
+	log.Printf("[WARN] Failed to get updates, retrying in %v: %v", backoff, err)
+}
+
+// updatesRecovered logs recovery of getUpdates and sends a warning if it was failing for longer than updatesFailureWarning
+func (l *TelegramListener) updatesRecovered() {
+	l.updates.mutex.Lock()
+	var failingSince = l.updates.failingSince
+	l.updates.failingSince = time.Time{}
+	l.updates.lastErr = nil
+	l.updates.mutex.Unlock()
+
+	if failingSince.IsZero() {
+		return
+	}
+
+	var failed = time.Since(failingSince)
+	log.Printf("[INFO] Getting Telegram updates recovered after %v", failed.Round(time.Second))
+	if failed < updatesFailureWarning {
+		return
+	}
+
+	var chatId = checks.ReadChecksData().Settings.MigratedChat(l.Chat)
+	var lang = l.lang(chatId)
+	if _, err := l.Sender.Send(tgbotapi.NewMessage(chatId, i18n.T(lang, "updates.recovered", i18n.Duration(lang, failed)))); err != nil {
+		log.Printf("[ERROR] Failed to send updates warning: %v", err)
+	}
+}
+
+func (s *updatesState) stopChan() chan struct{} {
+	s.stopOnce.Do(func() {
+		s.stop = make(chan struct{})
+	})
+	return s.stop
+}
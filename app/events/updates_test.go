@@ -0,0 +1,172 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useUpdatesBackoff shortens the backoff of failed getUpdates requests and the failure warning for the test
+func useUpdatesBackoff(t *testing.T, backoff time.Duration, maxBackoff time.Duration, warning time.Duration) {
+	var minBackoff, previousMax, previousWarning = minUpdatesBackoff, maxUpdatesBackoff, updatesFailureWarning
+	minUpdatesBackoff, maxUpdatesBackoff, updatesFailureWarning = backoff, maxBackoff, warning
+	t.Cleanup(func() {
+		minUpdatesBackoff, maxUpdatesBackoff, updatesFailureWarning = minBackoff, previousMax, previousWarning
+	})
+}
+
+// listen runs Listen until the end of the test
+func listen(t *testing.T, l *TelegramListener) {
+	var done = make(chan struct{})
+	go func() {
+		l.Listen()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		l.Stop()
+		<-done
+	})
+}
+
+func TestListenBacksOffAndRecovers(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"})
+	useUpdatesBackoff(t, 20*time.Millisecond, 80*time.Millisecond, 50*time.Millisecond)
+	l, telegram := newTestListener(t)
+
+	telegram.fail(4)
+	telegram.queue(tgbotapi.Update{UpdateID: 1, Message: commandMessage(1, "/remove web")})
+	listen(t, l)
+
+	waitFor(t, func() bool { return len(telegram.sent()) == 2 })
+
+	telegram.mutex.Lock()
+	var polls = slices.Clone(telegram.polls)
+	telegram.mutex.Unlock()
+	if len(polls) < 5 {
+		t.Fatalf("got %d getUpdates requests, want 4 failed and a successful one", len(polls))
+	}
+	// the backoff doubles up to the max: 20ms, 40ms, 80ms, 80ms
+	for i, backoff := range []time.Duration{20, 40, 80, 80} {
+		if gap := polls[i+1].Sub(polls[i]); gap < backoff*time.Millisecond {
+			t.Errorf("retry %d came after %v, want at least %vms", i+1, gap, backoff)
+		}
+	}
+
+	var sent = telegram.sent()
+	if len(sent) != 2 || !strings.HasPrefix(sent[0], "⚠️ Bot could not get updates from Telegram for") ||
+		sent[1] != "Server web removed" {
+		t.Errorf("got messages %q, want the recovery warning and the command reply", sent)
+	}
+	if err := l.UpdatesError(); err != nil {
+		t.Errorf("updates error after recovery: %v", err)
+	}
+}
+
+func TestUpdatesErrorWhileFailing(t *testing.T) {
+	checkstest.UseStorage(t)
+	useUpdatesBackoff(t, 5*time.Millisecond, 10*time.Millisecond, 30*time.Millisecond)
+	l, telegram := newTestListener(t)
+
+	telegram.fail(1000)
+	listen(t, l)
+
+	waitFor(t, func() bool { return l.UpdatesError() != nil })
+	if err := l.UpdatesError(); !strings.Contains(err.Error(), "Bad Gateway") {
+		t.Errorf("got error %v, want the last getUpdates error", err)
+	}
+
+	telegram.fail(0)
+	waitFor(t, func() bool { return l.UpdatesError() == nil })
+	waitFor(t, func() bool { return len(telegram.sent()) == 1 })
+}
+
+func TestShortFailureSendsNoWarning(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"})
+	useUpdatesBackoff(t, time.Millisecond, time.Millisecond, time.Minute)
+	l, telegram := newTestListener(t)
+
+	telegram.fail(2)
+	telegram.queue(tgbotapi.Update{UpdateID: 1, Message: commandMessage(1, "/remove web")})
+	listen(t, l)
+
+	waitFor(t, func() bool { return len(telegram.sent()) == 1 })
+	assertReplies(t, telegram, "Server web removed")
+}
+
+// ackUpdate is a press of the ack button of the server on an alert sent at the time
+func ackUpdate(id int, name string, sentAt time.Time) tgbotapi.Update {
+	var alert = commandMessage(100+id, "alert")
+	alert.Date = int(sentAt.Unix())
+	return tgbotapi.Update{UpdateID: id, CallbackQuery: &tgbotapi.CallbackQuery{
+		ID: fmt.Sprint(id), From: alert.From, Message: alert, Data: "ack:" + notify.ServerRef(name),
+	}}
+}
+
+func TestSkipPendingUpdates(t *testing.T) {
+	var incident = &checks.Incident{Start: time.Now().Add(-2 * time.Hour)}
+	checkstest.UseStorage(t,
+		checks.ServerCheck{Name: "web", Url: "https://example.com", Incident: incident},
+		checks.ServerCheck{Name: "api", Url: "https://api.example.com", Incident: incident},
+		checks.ServerCheck{Name: "db", Url: "https://db.example.com"},
+	)
+	l, telegram := newTestListener(t)
+	l.SkipPendingAge = 5 * time.Minute
+
+	var stale = commandMessage(1, "/remove db")
+	stale.Date = int(time.Now().Add(-time.Hour).Unix())
+	var inline = ackUpdate(5, "api", time.Now())
+	inline.CallbackQuery.Message = nil
+	telegram.queue(
+		tgbotapi.Update{UpdateID: 1, Message: stale},
+		tgbotapi.Update{UpdateID: 2, Message: commandMessage(2, "/remove web")},
+		ackUpdate(3, "web", time.Now().Add(-time.Hour)),
+		ackUpdate(4, "api", time.Now().Add(-time.Minute)),
+		inline,
+	)
+
+	if offset := l.skipPendingUpdates(); offset != 6 {
+		t.Errorf("got offset %d, want 6", offset)
+	}
+
+	var healthChecks = checks.ReadChecksData().HealthChecks
+	if _, ok := healthChecks["db"]; !ok {
+		t.Error("stale /remove ran")
+	}
+	if _, ok := healthChecks["web"]; ok {
+		t.Error("fresh /remove did not run")
+	}
+	if healthChecks["api"].Incident.AckBy != "@"+testSuper {
+		t.Error("press of a button on a fresh alert was not handled")
+	}
+	telegram.mutex.Lock()
+	var answers = len(telegram.answers)
+	telegram.mutex.Unlock()
+	if answers != 1 {
+		t.Errorf("got %d answered button presses, want only the fresh one", answers)
+	}
+
+	var sent = telegram.sent()
+	if len(sent) == 0 || sent[len(sent)-1] != "I was offline and ignored 2 stale commands and button presses" {
+		t.Errorf("got messages %q, want the skipped count last", sent)
+	}
+}
+
+func TestSkipPendingUpdatesDisabled(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "db", Url: "https://db.example.com"})
+	l, telegram := newTestListener(t)
+
+	var stale = commandMessage(1, "/remove db")
+	stale.Date = int(time.Now().Add(-time.Hour).Unix())
+	telegram.queue(tgbotapi.Update{UpdateID: 1, Message: stale})
+
+	if offset := l.skipPendingUpdates(); offset != 0 {
+		t.Errorf("got offset %d, want 0 so the backlog is processed by Listen", offset)
+	}
+	assertReplies(t, telegram, "")
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"time"
+)
+
+// uptimeHistoryDays is the number of days shown by /uptimehistory and /details
+const uptimeHistoryDays = 90
+
+// uptimeHistory shows daily uptime of the server for the last 90 days, a line per month
+func (l *TelegramListener) uptimeHistory(ctx *commandContext) {
+	var name = ctx.fields[0]
+
+	serverCheck, ok := checks.ReadChecksData().HealthChecks[name]
+	if !ok {
+		l.reply(ctx.chatId, "server.not_exists", name)
+		return
+	}
+	if len(serverCheck.DailyUptime) == 0 {
+		l.reply(ctx.chatId, "uptimehistory.no_data", name)
+		return
+	}
+
+	var lang = ctx.lang
+	var days = serverCheck.UptimeDays(time.Now(), l.location(), uptimeHistoryDays)
+
+	var text = i18n.T(lang, "uptimehistory.title", name, i18n.Plural(lang, "unit.day", uptimeHistoryDays),
+		formatDailyAvailability(lang, days))
+	for len(days) > 0 {
+		// dates are "2006-01-02", so days of a month share the prefix
+		var month = days[0].Date[:7]
+		var count = 1
+		for count < len(days) && days[count].Date[:7] == month {
+			count++
+		}
+
+		text += fmt.Sprintf("%s %s %s\n", month, checks.DayUptimeBar(days[:count]),
+			formatDailyAvailability(lang, days[:count]))
+		days = days[count:]
+	}
+	text += i18n.T(lang, "uptimehistory.legend")
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, text))
+}
+
+// formatDailyAvailability formats availability of the fully monitored days, n/a if there are none
+func formatDailyAvailability(lang i18n.Lang, days []checks.DayUptime) string {
+	if availability, ok := checks.DailyAvailability(days); ok {
+		return fmt.Sprintf("%.2f%%", availability)
+	}
+	return i18n.T(lang, "details.na")
+}
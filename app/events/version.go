@@ -0,0 +1,23 @@
+package events
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"runtime"
+	"time"
+)
+
+// version shows build info, Go version and uptime of the process
+func (l *TelegramListener) version(ctx *commandContext) {
+	var lang = ctx.lang
+
+	var text = i18n.T(lang, "version.info", l.Build.Version, l.Build.Commit, l.Build.Date, runtime.Version(),
+		i18n.Duration(lang, time.Since(l.StartedAt)))
+	if l.Releases != nil {
+		if newer := l.Releases.Newer(); newer != "" {
+			text += "\n" + i18n.T(lang, "version.newer", newer)
+		}
+	}
+
+	l.send(tgbotapi.NewMessage(ctx.chatId, text))
+}
@@ -0,0 +1,40 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"log"
+	"net/http"
+)
+
+// probeResultsHandler serves /api/probe-results, agents post results of their checks
+// and get the servers assigned to their location in response
+func probeResultsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		var report checks.ProbeReport
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		servers, err := checks.RecordProbeReport(report)
+		if errors.Is(err, checks.ErrInvalidLocation) {
+			writeError(w, http.StatusBadRequest, "invalid location")
+			return
+		}
+		if err != nil {
+			log.Printf("[ERROR] Failed to save checks data: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to save results")
+			return
+		}
+
+		log.Printf("[DEBUG] Probe agent %s reported %d results", report.Location, len(report.Results))
+		writeJson(w, http.StatusOK, checks.ProbeAssignments{Servers: servers})
+	}
+}
@@ -0,0 +1,150 @@
+package healthcheck
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type statusResponse struct {
+	Summary statusSummary  `json:"summary"`
+	Servers []serverStatus `json:"servers"`
+}
+
+type statusSummary struct {
+	Total  int `json:"total"`
+	Up     int `json:"up"`
+	Down   int `json:"down"`
+	Paused int `json:"paused"`
+}
+
+type serverStatus struct {
+	Name               string     `json:"name"`
+	Url                string     `json:"url,omitempty"`
+	State              string     `json:"state"`
+	LastCheck          *time.Time `json:"lastCheck"`
+	LastChecked        *time.Time `json:"lastChecked"`
+	LastResponseTimeMs *int64     `json:"lastResponseTimeMs"`
+	Availability       *float64   `json:"availability"`
+	SslDaysRemaining   *int       `json:"sslDaysRemaining"`
+	MttrSeconds        *float64   `json:"mttrSeconds"`
+	MtbfSeconds        *float64   `json:"mtbfSeconds"`
+	Score              *int       `json:"score"`
+}
+
+func statusApiHandler(hideUrls bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		window, ok := reliabilityWindow(r)
+		if !ok {
+			http.Error(w, "days must be a positive number", http.StatusBadRequest)
+			return
+		}
+
+		// ReadChecksData holds the storage lock, so the snapshot is never read during a save
+		writeJson(w, http.StatusOK, newStatusResponse(checks.ReadChecksData(), hideUrls, window))
+	}
+}
+
+// reliabilityWindow returns the window of MTTR and MTBF from the days query parameter, false if it is invalid
+func reliabilityWindow(r *http.Request) (time.Duration, bool) {
+	var value = r.URL.Query().Get("days")
+	if value == "" {
+		return checks.DefaultReliabilityWindow, true
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		return 0, false
+	}
+	return time.Duration(days) * 24 * time.Hour, true
+}
+
+func newStatusResponse(checksData checks.Data, hideUrls bool, window time.Duration) statusResponse {
+	var response = statusResponse{Servers: []serverStatus{}}
+
+	var names []string
+	for name := range checksData.HealthChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var server = newServerStatus(checksData.HealthChecks[name], hideUrls, window)
+
+		switch server.State {
+		case "paused":
+			response.Summary.Paused++
+		case "up":
+			response.Summary.Up++
+		default:
+			response.Summary.Down++
+		}
+		response.Summary.Total++
+
+		response.Servers = append(response.Servers, server)
+	}
+
+	return response
+}
+
+func newServerStatus(serverCheck checks.ServerCheck, hideUrl bool, window time.Duration) serverStatus {
+	var server = serverStatus{Name: serverCheck.Name}
+
+	switch {
+	case serverCheck.Paused:
+		server.State = "paused"
+	case serverCheck.IsOk:
+		server.State = "up"
+	default:
+		server.State = "down"
+	}
+
+	if !hideUrl {
+		server.Url = redact.Url(serverCheck.Url)
+	}
+
+	if !serverCheck.LastChecked.IsZero() {
+		server.LastChecked = &serverCheck.LastChecked
+	}
+	if lastResult, ok := serverCheck.LastResult(); ok {
+		var responseTimeMs = lastResult.ResponseTime.Milliseconds()
+		server.LastCheck = &lastResult.Time
+		server.LastResponseTimeMs = &responseTimeMs
+	}
+
+	var history = checks.HistorySince(serverCheck.History, time.Now().Add(-24*time.Hour))
+	if availability, ok := checks.Availability(history); ok {
+		server.Availability = &availability
+	}
+
+	if !serverCheck.SslExpiry.IsZero() {
+		var days = int(math.Floor(time.Until(serverCheck.SslExpiry).Hours() / 24))
+		server.SslDaysRemaining = &days
+	}
+
+	var since = time.Now().Add(-window)
+	if mttr, ok := serverCheck.MTTR(since); ok {
+		var seconds = mttr.Seconds()
+		server.MttrSeconds = &seconds
+	}
+	if mtbf, ok := serverCheck.MTBF(since); ok {
+		var seconds = mtbf.Seconds()
+		server.MtbfSeconds = &seconds
+	}
+
+	if !serverCheck.ScoredAt.IsZero() {
+		var score = serverCheck.Score
+		server.Score = &score
+	}
+
+	return server
+}
@@ -0,0 +1,99 @@
+package healthcheck
+
+import (
+	"crypto/subtle"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/scheduler"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Options configures the healthcheck HTTP server
+type Options struct {
+	Listen         string
+	StatusPage     bool
+	StatusHideUrls bool
+	StatusApi      bool
+	ServersApi     bool
+	ApiToken       string
+
+	// AgentToken is required from probe agents, /api/probe-results is disabled if it is empty
+	AgentToken string
+
+	// Announce sends a message about changes of the server made via the API, the message is the i18n key with args,
+	// can be nil
+	Announce func(server, key string, args ...any)
+
+	// TelegramCheck verifies Telegram connectivity for /ready, its result is cached for ReadyCacheTtl
+	TelegramCheck func() error
+	// UpdatesCheck fails if the bot can't get updates from Telegram for a long time, can be nil
+	UpdatesCheck  func() error
+	ReadyCacheTtl time.Duration
+	Scheduler     *scheduler.Scheduler
+	// StalePeriods is the number of cron periods without completed check cycle after which the bot is not ready
+	StalePeriods int
+}
+
+// Start runs the HTTP server in background with probes and enabled pages
+func Start(options Options) {
+	if options.ServersApi && options.ApiToken == "" {
+		log.Printf("[WARN] Servers API requires API token, it is disabled")
+		options.ServersApi = false
+	}
+	if options.Announce == nil {
+		options.Announce = func(string, string, ...any) {}
+	}
+
+	var ready = &readiness{
+		telegramCheck: options.TelegramCheck,
+		updatesCheck:  options.UpdatesCheck,
+		cacheTtl:      options.ReadyCacheTtl,
+		scheduler:     options.Scheduler,
+		stalePeriods:  options.StalePeriods,
+		startedAt:     time.Now(),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/live", liveHandler)
+	mux.HandleFunc("/ready", ready.handler)
+	// kept for compatibility, same as /ready
+	mux.HandleFunc("/health", ready.handler)
+	if options.StatusPage {
+		mux.HandleFunc("/status", statusPageHandler(options.StatusHideUrls))
+	}
+	if options.StatusApi {
+		mux.Handle("/api/status", requireToken(options.ApiToken, statusApiHandler(options.StatusHideUrls)))
+	}
+	if options.ServersApi {
+		var handler = requireToken(options.ApiToken, serversApiHandler(options.Announce))
+		mux.Handle("/api/servers", handler)
+		mux.Handle("/api/servers/", handler)
+	}
+	if options.AgentToken != "" {
+		mux.Handle("/api/probe-results", requireToken(options.AgentToken, probeResultsHandler()))
+	}
+
+	go func() {
+		log.Printf("[INFO] Healthcheck server listening on %s", options.Listen)
+		if err := http.ListenAndServe(options.Listen, mux); err != nil {
+			log.Printf("[ERROR] Healthcheck server failed: %v", err)
+		}
+	}()
+}
+
+// requireToken checks bearer token of the request if token is set
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			requestToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(requestToken), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,32 @@
+package healthcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve sends the request with the token and the JSON body to the handler and returns the response
+func serve(handler http.Handler, method string, target string, token string, body string) *httptest.ResponseRecorder {
+	var r = httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	var w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// waitFor waits until the condition holds or fails the test after a few seconds
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	var deadline = time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition was not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,141 @@
+package healthcheck
+
+import (
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/scheduler"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// the bot is not ready if at least maxErrorRate of the recent Telegram sends or storage writes failed,
+// rates of less than minErrorSamples operations are ignored
+const maxErrorRate = 0.5
+const minErrorSamples = 5
+
+type readyResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]probeResult `json:"checks"`
+}
+
+type probeResult struct {
+	Ok    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// readiness checks dependencies of the bot, the telegram check result is cached for cacheTtl
+type readiness struct {
+	telegramCheck func() error
+	updatesCheck  func() error
+	cacheTtl      time.Duration
+	scheduler     *scheduler.Scheduler
+	stalePeriods  int
+	startedAt     time.Time
+
+	mutex           sync.Mutex
+	telegramErr     error
+	telegramChecked time.Time
+}
+
+func liveHandler(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (rd *readiness) handler(w http.ResponseWriter, r *http.Request) {
+	var response = readyResponse{
+		Status: "ok",
+		Checks: map[string]probeResult{
+			"telegram":   newProbeResult(rd.checkTelegram()),
+			"storage":    newProbeResult(checks.CheckStorage()),
+			"checkCycle": newProbeResult(rd.checkCycle()),
+			"errorRate":  newProbeResult(checkErrorRate()),
+			"updates":    newProbeResult(rd.checkUpdates()),
+		},
+	}
+
+	var status = http.StatusOK
+	for _, result := range response.Checks {
+		if !result.Ok {
+			response.Status = "fail"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	writeJson(w, status, response)
+}
+
+func (rd *readiness) checkTelegram() error {
+	if rd.telegramCheck == nil {
+		return nil
+	}
+
+	rd.mutex.Lock()
+	defer rd.mutex.Unlock()
+
+	if time.Since(rd.telegramChecked) >= rd.cacheTtl {
+		rd.telegramErr = rd.telegramCheck()
+		rd.telegramChecked = time.Now()
+	}
+
+	return rd.telegramErr
+}
+
+func (rd *readiness) checkUpdates() error {
+	if rd.updatesCheck == nil {
+		return nil
+	}
+	return rd.updatesCheck()
+}
+
+// checkCycle fails if no check cycle completed or a server was not checked for stalePeriods cron periods
+func (rd *readiness) checkCycle() error {
+	if rd.scheduler == nil || rd.stalePeriods <= 0 {
+		return nil
+	}
+
+	var interval = rd.scheduler.Interval()
+	if interval == 0 {
+		return fmt.Errorf("checks are not scheduled")
+	}
+
+	var lastCycle = checks.LastCycleTime()
+	if lastCycle.IsZero() {
+		lastCycle = rd.startedAt
+	}
+
+	var stale = time.Duration(rd.stalePeriods) * interval
+	if since := time.Since(lastCycle); since > stale {
+		return fmt.Errorf("last check cycle completed %s, cron period is %s",
+			checks.FormatTimeAgo(lastCycle), checks.FormatDuration(interval))
+	}
+
+	// checks before the start don't count, the first cycle may not have run yet
+	name, lastChecked, ok := checks.OldestCheck(checks.ReadChecksData())
+	if ok && time.Since(lastChecked) > stale && time.Since(rd.startedAt) > stale {
+		return fmt.Errorf("server %s was last checked %s, cron period is %s",
+			name, checks.FormatTimeAgo(lastChecked), checks.FormatDuration(interval))
+	}
+
+	return nil
+}
+
+// checkErrorRate fails if too many of the recent Telegram sends or storage writes failed
+func checkErrorRate() error {
+	if rate, count := metrics.RecentTelegramSends.ErrorRate(); count >= minErrorSamples && rate >= maxErrorRate {
+		return fmt.Errorf("%.0f%% of the last %d telegram sends failed", rate*100, count)
+	}
+	if rate, count := metrics.RecentStorageWrites.ErrorRate(); count >= minErrorSamples && rate >= maxErrorRate {
+		return fmt.Errorf("%.0f%% of the last %d storage writes failed", rate*100, count)
+	}
+
+	return nil
+}
+
+func newProbeResult(err error) probeResult {
+	if err != nil {
+		return probeResult{Ok: false, Error: err.Error()}
+	}
+	return probeResult{Ok: true}
+}
@@ -0,0 +1,155 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/scheduler"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useRecentMetrics replaces recent operation outcomes with empty ones for the test
+func useRecentMetrics(t *testing.T) {
+	var sends, writes = metrics.RecentTelegramSends, metrics.RecentStorageWrites
+	metrics.RecentTelegramSends, metrics.RecentStorageWrites = metrics.NewRecent(20), metrics.NewRecent(20)
+	t.Cleanup(func() { metrics.RecentTelegramSends, metrics.RecentStorageWrites = sends, writes })
+}
+
+// healthyReadiness returns readiness with all checks passing, scheduled every second
+func healthyReadiness(t *testing.T) *readiness {
+	var sched = scheduler.New("@every 1s", func() {})
+	if err := sched.SetSpec("@every 1s"); err != nil {
+		t.Fatal(err)
+	}
+	return &readiness{
+		telegramCheck: func() error { return nil },
+		updatesCheck:  func() error { return nil },
+		cacheTtl:      time.Minute,
+		scheduler:     sched,
+		stalePeriods:  3,
+		startedAt:     time.Now(),
+	}
+}
+
+// ready requests /ready and returns the status code and the response
+func ready(t *testing.T, rd *readiness) (int, readyResponse) {
+	t.Helper()
+	var w = serve(http.HandlerFunc(rd.handler), http.MethodGet, "/ready", "", "")
+	var response readyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, response
+}
+
+func TestReadyOk(t *testing.T) {
+	checkstest.UseStorage(t)
+	useRecentMetrics(t)
+
+	code, response := ready(t, healthyReadiness(t))
+	if code != http.StatusOK || response.Status != "ok" {
+		t.Errorf("got status %d %q, want 200 ok: %+v", code, response.Status, response.Checks)
+	}
+	for _, name := range []string{"telegram", "storage", "checkCycle", "errorRate", "updates"} {
+		if result, ok := response.Checks[name]; !ok || !result.Ok {
+			t.Errorf("check %s: got %+v", name, result)
+		}
+	}
+}
+
+func TestReadyDegraded(t *testing.T) {
+	var tests = []struct {
+		name      string
+		check     string
+		degrade   func(rd *readiness)
+		wantError string
+	}{
+		{"telegram fails", "telegram", func(rd *readiness) {
+			rd.telegramCheck = func() error { return errors.New("unauthorized") }
+		}, "unauthorized"},
+		{"storage is broken", "storage", func(rd *readiness) {
+			// the cycle check reads the storage, the bot exits on reading a broken one
+			rd.stalePeriods = 0
+			if err := os.WriteFile(checks.StorageLocation(), []byte("{broken"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}, "decode "},
+		{"no cycle completed", "checkCycle", func(rd *readiness) {
+			rd.startedAt = time.Now().Add(-time.Hour)
+		}, "last check cycle completed 1h 0m ago, cron period is 1s"},
+		{"not scheduled", "checkCycle", func(rd *readiness) {
+			rd.scheduler = scheduler.New("@every 1s", func() {})
+		}, "checks are not scheduled"},
+		{"telegram sends fail", "errorRate", func(rd *readiness) {
+			for i := 0; i < 5; i++ {
+				metrics.RecentTelegramSends.Add(i < 3)
+			}
+		}, "60% of the last 5 telegram sends failed"},
+		{"storage writes fail", "errorRate", func(rd *readiness) {
+			for i := 0; i < 6; i++ {
+				metrics.RecentStorageWrites.Add(true)
+			}
+		}, "100% of the last 6 storage writes failed"},
+		{"updates fail", "updates", func(rd *readiness) {
+			rd.updatesCheck = func() error { return errors.New("getting updates fails since 10m 0s ago") }
+		}, "getting updates fails since 10m 0s ago"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkstest.UseStorage(t)
+			useRecentMetrics(t)
+			var rd = healthyReadiness(t)
+			test.degrade(rd)
+
+			code, response := ready(t, rd)
+			if code != http.StatusServiceUnavailable || response.Status != "fail" {
+				t.Errorf("got status %d %q, want 503 fail", code, response.Status)
+			}
+			for name, result := range response.Checks {
+				if name == test.check {
+					if result.Ok || !strings.HasPrefix(result.Error, test.wantError) {
+						t.Errorf("check %s: got %+v, want error %q", name, result, test.wantError)
+					}
+				} else if !result.Ok {
+					t.Errorf("check %s failed too: %s", name, result.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestReadyErrorRateNeedsSamples(t *testing.T) {
+	checkstest.UseStorage(t)
+	useRecentMetrics(t)
+	for i := 0; i < 4; i++ {
+		metrics.RecentTelegramSends.Add(true)
+	}
+
+	if code, response := ready(t, healthyReadiness(t)); code != http.StatusOK {
+		t.Errorf("got status %d with fewer than %d sends: %+v", code, minErrorSamples, response.Checks)
+	}
+}
+
+func TestReadyTelegramCached(t *testing.T) {
+	checkstest.UseStorage(t)
+	useRecentMetrics(t)
+	var rd = healthyReadiness(t)
+	var calls int
+	rd.telegramCheck = func() error {
+		calls++
+		return nil
+	}
+
+	ready(t, rd)
+	ready(t, rd)
+	if calls != 1 {
+		t.Errorf("telegram was checked %d times within the cache ttl, want once", calls)
+	}
+}
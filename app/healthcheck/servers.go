@@ -0,0 +1,154 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type serverRequest struct {
+	Url  string `json:"url"`
+	Name string `json:"name"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// serversApiHandler serves /api/servers and /api/servers/{name}, announce is called with the i18n key and its args
+// after each change
+func serversApiHandler(announce func(server, key string, args ...any)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var name = strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/servers"), "/")
+
+		switch {
+		case name == "" && r.Method == http.MethodGet:
+			window, ok := reliabilityWindow(r)
+			if !ok {
+				writeError(w, http.StatusBadRequest, "days must be a positive number")
+				return
+			}
+			writeJson(w, http.StatusOK, newStatusResponse(checks.ReadChecksData(), false, window).Servers)
+		case name == "" && r.Method == http.MethodPost:
+			addServer(w, r, announce)
+		case name != "" && r.Method == http.MethodGet:
+			getServer(w, r, name)
+		case name != "" && r.Method == http.MethodDelete:
+			removeServer(w, name, announce)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	}
+}
+
+func addServer(w http.ResponseWriter, r *http.Request, announce func(server, key string, args ...any)) {
+	var request serverRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var rawUrl = strings.TrimSpace(request.Url)
+	var serverUrl = checks.FullServerUrl(rawUrl)
+	if parsedUrl, err := url.Parse(serverUrl); rawUrl == "" || strings.ContainsAny(rawUrl, " \t\n") ||
+		err != nil || parsedUrl.Host == "" {
+		writeError(w, http.StatusBadRequest, "invalid url")
+		return
+	}
+
+	var serverName = strings.TrimSpace(request.Name)
+	var fromUrl = serverName == ""
+	if fromUrl {
+		serverName = checks.UrlName(serverUrl)
+	}
+	// the name is a part of /api/servers/{name} path
+	if strings.Contains(serverName, "/") {
+		writeError(w, http.StatusBadRequest, "invalid name, it must not contain /")
+		return
+	}
+	// names taken from the url may contain url characters
+	if err := checks.ValidateName(serverName); err != nil && !(fromUrl && errors.Is(err, checks.ErrNameChars)) {
+		writeError(w, http.StatusBadRequest, "invalid name: "+err.Error())
+		return
+	}
+
+	// credentials of the url are sent as basic auth, so the stored url is shown without them
+	var serverCheck = checks.ServerCheck{Name: serverName}
+	serverCheck.Url, serverCheck.BasicAuth = checks.ExtractCredentials(serverUrl)
+	err := checks.AddServer(serverCheck)
+	if errors.Is(err, checks.ErrServerExists) {
+		writeError(w, http.StatusConflict, "server already exists")
+		return
+	}
+	if errors.Is(err, checks.ErrServerLimit) {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("limit of %d servers reached", checks.MaxServers))
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to add server")
+		return
+	}
+
+	log.Printf("[INFO] Server %s [%s] added via API", serverCheck.Name, redact.Url(serverCheck.Url))
+	// the first check runs in background, it doesn't count toward the alert threshold
+	go func() {
+		if _, _, err := checks.RecheckServer(serverCheck.Name); err != nil && !errors.Is(err, checks.ErrServerNotExists) {
+			log.Printf("[ERROR] Failed first check of server %s: %v", serverCheck.Name, err)
+		}
+	}()
+	announce(serverCheck.Name, "api.server_added", serverCheck.Name, redact.Url(serverCheck.Url))
+	writeJson(w, http.StatusCreated, newServerStatus(serverCheck, false, checks.DefaultReliabilityWindow))
+}
+
+func getServer(w http.ResponseWriter, r *http.Request, name string) {
+	window, ok := reliabilityWindow(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "days must be a positive number")
+		return
+	}
+
+	serverCheck, ok := checks.ReadChecksData().HealthChecks[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "server not exists")
+		return
+	}
+
+	writeJson(w, http.StatusOK, newServerStatus(serverCheck, false, window))
+}
+
+func removeServer(w http.ResponseWriter, name string, announce func(server, key string, args ...any)) {
+	err := checks.RemoveServer(name)
+	if errors.Is(err, checks.ErrServerNotExists) {
+		writeError(w, http.StatusNotFound, "server not exists")
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save checks data: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to remove server")
+		return
+	}
+
+	log.Printf("[INFO] Server %s removed via API", name)
+	announce(name, "api.server_removed", name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJson(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("[ERROR] Failed to encode response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJson(w, status, errorResponse{Error: message})
+}
@@ -0,0 +1,198 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks/checkstest"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// serversApi is the servers API handler with the token, it records announcements
+type serversApi struct {
+	handler       http.Handler
+	announcements []string
+}
+
+func newServersApi() *serversApi {
+	var api = &serversApi{}
+	api.handler = requireToken("secret", serversApiHandler(func(server, key string, args ...any) {
+		api.announcements = append(api.announcements, i18n.T(i18n.En, key, args...))
+	}))
+	return api
+}
+
+func (a *serversApi) serve(method string, target string, body string) *httptest.ResponseRecorder {
+	return serve(a.handler, method, target, "secret", body)
+}
+
+// errorOf returns the error message of the response
+func errorOf(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var response errorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response %q is not an error: %v", w.Body.String(), err)
+	}
+	return response.Error
+}
+
+func TestServersApiAuth(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"})
+	var api = newServersApi()
+
+	var requests = []struct{ method, target, body string }{
+		{http.MethodGet, "/api/servers", ""},
+		{http.MethodGet, "/api/servers/web", ""},
+		{http.MethodPost, "/api/servers", `{"url": "https://new.example.com"}`},
+		{http.MethodDelete, "/api/servers/web", ""},
+	}
+	for _, request := range requests {
+		var w = serve(api.handler, request.method, request.target, "wrong", request.body)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s: got status %d, want 401", request.method, request.target, w.Code)
+		}
+	}
+
+	if len(checks.ReadChecksData().HealthChecks) != 1 || len(api.announcements) != 0 {
+		t.Error("unauthorized requests changed servers")
+	}
+}
+
+func TestServersApiValidation(t *testing.T) {
+	checkstest.UseStorage(t)
+	var api = newServersApi()
+
+	var tests = []struct {
+		name      string
+		body      string
+		wantError string
+	}{
+		{"invalid json", `{"url": `, "invalid request body"},
+		{"empty url", `{"url": ""}`, "invalid url"},
+		{"url with spaces", `{"url": "exa mple.com"}`, "invalid url"},
+		{"url without host", `{"url": "https://"}`, "invalid url"},
+		{"reserved name", `{"url": "https://example.com", "name": "all"}`, "invalid name: name is reserved"},
+		{"name with slash", `{"url": "https://example.com", "name": "web/api"}`, "invalid name, it must not contain /"},
+		{"name with not allowed characters", `{"url": "https://example.com", "name": "web!"}`,
+			"invalid name: name contains not allowed characters"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var w = api.serve(http.MethodPost, "/api/servers", test.body)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("got status %d, want 400", w.Code)
+			}
+			if message := errorOf(t, w); !strings.HasPrefix(message, test.wantError) {
+				t.Errorf("got error %q, want %q", message, test.wantError)
+			}
+		})
+	}
+
+	if len(checks.ReadChecksData().HealthChecks) != 0 || len(api.announcements) != 0 {
+		t.Error("invalid requests added servers")
+	}
+}
+
+func TestServersApiDuplicate(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com"})
+	var api = newServersApi()
+
+	var w = api.serve(http.MethodPost, "/api/servers", `{"url": "https://other.example.com", "name": "web"}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("got status %d, want 409", w.Code)
+	}
+	if message := errorOf(t, w); message != "server already exists" {
+		t.Errorf("got error %q", message)
+	}
+	if checks.ReadChecksData().HealthChecks["web"].Url != "https://example.com" {
+		t.Error("existing server was replaced")
+	}
+}
+
+func TestServersApiLifecycle(t *testing.T) {
+	checkstest.UseStorage(t, checks.ServerCheck{Name: "web", Url: "https://example.com", IsOk: true})
+	var api = newServersApi()
+	var target = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	var w = api.serve(http.MethodPost, "/api/servers", `{"url": "`+target.URL+`", "name": "local"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want 201: %s", w.Code, w.Body)
+	}
+	var created serverStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Name != "local" || created.Url != target.URL {
+		t.Errorf("got created server %+v", created)
+	}
+	// the first check runs in background, it must complete before the storage is removed
+	waitFor(t, func() bool { return !checks.ReadChecksData().HealthChecks["local"].LastChecked.IsZero() })
+	if !checks.ReadChecksData().HealthChecks["local"].IsOk {
+		t.Error("first check of the added server failed")
+	}
+
+	w = api.serve(http.MethodGet, "/api/servers", "")
+	var servers []serverStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &servers); err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 2 || servers[0].Name != "local" || servers[1].Name != "web" {
+		t.Errorf("got servers %+v", servers)
+	}
+
+	w = api.serve(http.MethodGet, "/api/servers/web", "")
+	var server serverStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &server); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || server.Name != "web" || server.State != "up" {
+		t.Errorf("got status %d and server %+v", w.Code, server)
+	}
+
+	if w = api.serve(http.MethodDelete, "/api/servers/web", ""); w.Code != http.StatusNoContent {
+		t.Errorf("got status %d for delete, want 204", w.Code)
+	}
+	if _, ok := checks.ReadChecksData().HealthChecks["web"]; ok {
+		t.Error("deleted server is stored")
+	}
+
+	var wantAnnouncements = []string{"Server local [" + target.URL + "] added via API", "Server web removed via API"}
+	if !slices.Equal(api.announcements, wantAnnouncements) {
+		t.Errorf("got announcements %q, want %q", api.announcements, wantAnnouncements)
+	}
+}
+
+func TestServersApiNameFromUrl(t *testing.T) {
+	checkstest.UseStorage(t)
+	var api = newServersApi()
+
+	var target = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	var w = api.serve(http.MethodPost, "/api/servers", `{"url": "`+target.URL+`"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want 201: %s", w.Code, w.Body)
+	}
+	var name = checks.UrlName(target.URL)
+	waitFor(t, func() bool { return !checks.ReadChecksData().HealthChecks[name].LastChecked.IsZero() })
+}
+
+func TestServersApiNotFound(t *testing.T) {
+	checkstest.UseStorage(t)
+	var api = newServersApi()
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		if w := api.serve(method, "/api/servers/missing", ""); w.Code != http.StatusNotFound {
+			t.Errorf("%s: got status %d, want 404", method, w.Code)
+		}
+	}
+	if w := api.serve(http.MethodPut, "/api/servers/missing", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT: got status %d, want 405", w.Code)
+	}
+}
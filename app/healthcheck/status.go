@@ -0,0 +1,123 @@
+package healthcheck
+
+import (
+	"embed"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+//go:embed templates/status.html
+var templates embed.FS
+
+var statusTemplate = template.Must(template.ParseFS(templates, "templates/status.html"))
+
+const statusRefreshSeconds = 60
+
+// statusDays is the number of days in the daily uptime strip
+const statusDays = 90
+
+type statusPage struct {
+	RefreshSeconds int
+	Total          int
+	Down           int
+	Servers        []statusServer
+	Updated        string
+}
+
+type statusServer struct {
+	Icon         string
+	Name         string
+	Url          string
+	Availability string
+	LastCheck    string
+	Days         []statusDay
+}
+
+// statusDay is a block of the daily uptime strip, Level is its css class
+type statusDay struct {
+	Level string
+	Title string
+}
+
+func statusPageHandler(hideUrls bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var page = newStatusPage(checks.ReadChecksData(), hideUrls)
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := statusTemplate.Execute(w, page); err != nil {
+			log.Printf("[ERROR] Failed to render status page: %v", err)
+		}
+	}
+}
+
+func newStatusPage(checksData checks.Data, hideUrls bool) statusPage {
+	var page = statusPage{
+		RefreshSeconds: statusRefreshSeconds,
+		Updated:        time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	var location = checksData.Settings.Location(checks.Location)
+
+	var names []string
+	for name := range checksData.HealthChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var serverCheck = checksData.HealthChecks[name]
+		var server = statusServer{Name: serverCheck.Name, Availability: "n/a", LastCheck: "never"}
+
+		switch {
+		case serverCheck.Paused:
+			server.Icon = "⏸"
+		case serverCheck.IsOk:
+			server.Icon = "✅"
+			page.Total++
+		default:
+			server.Icon = "❌"
+			page.Total++
+			page.Down++
+		}
+
+		if !hideUrls {
+			server.Url = redact.Url(serverCheck.Url)
+		}
+
+		var history = checks.HistorySince(serverCheck.History, time.Now().Add(-24*time.Hour))
+		if availability, ok := checks.Availability(history); ok {
+			server.Availability = fmt.Sprintf("%.2f%%", availability)
+		}
+		if !serverCheck.LastChecked.IsZero() {
+			server.LastCheck = checks.FormatTimeAgo(serverCheck.LastChecked)
+		}
+		if len(serverCheck.DailyUptime) > 0 {
+			server.Days = newStatusDays(serverCheck.UptimeDays(time.Now(), location, statusDays))
+		}
+
+		page.Servers = append(page.Servers, server)
+	}
+
+	return page
+}
+
+func newStatusDays(days []checks.DayUptime) []statusDay {
+	var statusDays []statusDay
+	for _, day := range days {
+		var title = day.Date + ": no checks"
+		if day.Checks > 0 {
+			title = fmt.Sprintf("%s: %.2f%%", day.Date, day.Availability())
+			if day.Partial {
+				title += ", partial day"
+			}
+		}
+		statusDays = append(statusDays, statusDay{Level: string(checks.DayUptimeLevel(day)), Title: title})
+	}
+	return statusDays
+}
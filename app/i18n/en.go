@@ -0,0 +1,550 @@
+package i18n
+
+// en is the default catalog, plural forms are separated by "|"
+var en = map[string]string{
+	"unit.day":    "day|days",
+	"unit.hour":   "hour|hours",
+	"unit.minute": "minute|minutes",
+	"unit.second": "second|seconds",
+	"unit.server": "server|servers",
+	"unit.check":  "check|checks",
+	"unit.change": "state change|state changes",
+
+	"unit.short_day":    "d",
+	"unit.short_hour":   "h",
+	"unit.short_minute": "m",
+	"unit.short_second": "s",
+
+	"time.never": "never",
+	"time.ago":   "%s ago",
+
+	"alert.down":                "❗❗❗ Server %s is down ❗❗❗",
+	"alert.down_status":         "Status: %d %s",
+	"alert.down_reason":         "Reason: %s",
+	"alert.down_error":          "Error: %s",
+	"alert.down_time":           "Response time: %v",
+	"alert.down_body":           "Response: %s",
+	"alert.category_timeout":    "timeout",
+	"alert.category_dns":        "DNS resolution failed",
+	"alert.category_refused":    "connection refused",
+	"alert.category_tls":        "TLS error",
+	"alert.category_banner":     "malformed SSH banner",
+	"alert.up":                  "✅ Server %s is up 🎉",
+	"alert.up_ack":              "acknowledged by %s %s after alert",
+	"alert.escalated":           "🚨 Server %s is down for %s, the alert is not acknowledged",
+	"alert.escalation_resolved": "✅ Server %s is up after %s down",
+	"alert.protocol":            "⚠️ Server %s is served over %s, expected %s",
+	"alert.compression":         "⚠️ Server %s responds without compression",
+	"alert.security":            "⚠️ Server %s stopped sending security headers: %s",
+	"alert.sla_breach":          "⚠️ SLA breach risk: availability of %s is %.3f%%, below the target %g%% over %s. Error budget left %.1f%%",
+	"alert.sla_recovered":       "✅ Availability of %s is %.3f%%, back above the target %g%% over %s",
+	"alert.pin":                 "🔐❗ Public key of %s changed: presented %s, pinned %s",
+	"alert.unexpected_ip":       "🌐❗ Server %s is connected on %s, expected %s",
+	"alert.test":                "🧪 TEST, this is not a real alert",
+	"alert.simulated":           "(simulated)",
+	"alert.agent_offline":       "⚠️ Probe agent %s doesn't report for %s",
+	"alert.agent_online":        "✅ Probe agent %s reports again after %s",
+	"alert.maintenance_up":      "🛠 Maintenance ended, all servers are up",
+	"alert.maintenance_down":    "🛠 Maintenance ended, servers still down: %s",
+	"alert.summary":             "📋 Incident summary of %s\nStarted: %s\nEnded: %s\nDuration: %s\nFailed checks: %d",
+	"alert.summary_errors":      "Errors:",
+	"alert.summary_ack":         "Acknowledged by %s %s after alert",
+	"alert.summary_no_ack":      "Not acknowledged",
+	"alert.ended_streak":        "This ended an uptime streak of %s",
+	"alert.ended_record":        "🏆 This ended the record uptime streak of %s",
+
+	"button.ack":            "Ack",
+	"button.check_now":      "Check now",
+	"button.snooze_1h":      "Snooze 1h",
+	"button.details":        "Details",
+	"button.confirm":        "Confirm",
+	"button.cancel":         "Cancel",
+	"button.url_as_name":    "Use URL as name",
+	"button.undo":           "Undo",
+	"button.pause":          "Pause",
+	"button.resume":         "Resume",
+	"button.edit_threshold": "Edit threshold",
+	"button.remove":         "Remove",
+	"button.clear_quiet":    "Clear quiet hours",
+
+	"cmd.add":                "Add server to monitor",
+	"cmd.addssl":             "Add server checked only by the TLS handshake",
+	"cmd.addssh":             "Add server checked by its SSH banner",
+	"cmd.remove":             "Remove server from monitor",
+	"cmd.removeall":          "Remove all servers from monitor",
+	"cmd.list":               "Show list of monitored servers",
+	"cmd.stats":              "Show availability and response time of servers",
+	"cmd.details":            "Show server details",
+	"cmd.settags":            "Set tags of server for filters of /list and /stats",
+	"cmd.checkallips":        "Check each resolved address of server",
+	"cmd.addendpoint":        "Check another URL of server, like /readyz",
+	"cmd.removeendpoint":     "Remove URL checked with server",
+	"cmd.endpointmode":       "Set if server is down when any or all of its URLs fail",
+	"cmd.setschedule":        "Check server on a named schedule",
+	"cmd.setsoft404":         "Fail server on error pages with status 200",
+	"cmd.setjsoncheck":       "Fail server when a number in the JSON response crosses a limit",
+	"cmd.setretries":         "Retry timeouts within a check of server",
+	"cmd.setbackoff":         "Set when checks of a long down server are stretched",
+	"cmd.setminproto":        "Check protocol served by server, like HTTP/2",
+	"cmd.setcompression":     "Warn when server responses are not compressed",
+	"cmd.securitycheck":      "Audit security headers of server",
+	"cmd.setsla":             "Set availability target of server",
+	"cmd.sla":                "Show availability of servers with targets",
+	"cmd.setexpectedip":      "Check address the server is connected on",
+	"cmd.setpin":             "Pin public key of server certificate",
+	"cmd.setprobes":          "Check server from probe agent locations",
+	"cmd.uptimehistory":      "Show daily uptime for 90 days",
+	"cmd.chart":              "Show response time chart",
+	"cmd.down":               "Show servers which are down",
+	"cmd.ack":                "Acknowledge incident",
+	"cmd.notifications":      "Show sent notifications",
+	"cmd.maintenanceall":     "Suppress all alerts for a duration",
+	"cmd.setcron":            "Change checks cron",
+	"cmd.setthresholdglobal": "Change alert threshold",
+	"cmd.settings":           "Show runtime settings",
+	"cmd.setlanguage":        "Change language of the chat",
+	"cmd.settimezone":        "Change timezone of timestamps",
+	"cmd.restore":            "Restore storage from a backup document, send as a reply",
+	"cmd.simulate":           "Simulate failure of a server to rehearse alerting",
+	"cmd.testalert":          "Send a test alert to all channels",
+	"cmd.subscribe":          "Get alerts of a server in the private chat",
+	"cmd.unsubscribe":        "Stop personal alerts of a server",
+	"cmd.subscriptions":      "Show personal alert subscriptions",
+	"cmd.preferences":        "Choose personal alerts and quiet hours",
+	"cmd.debug":              "Toggle debug logging",
+	"cmd.keep":               "Keep the bot reply from deletion, send as a reply",
+	"cmd.heartbeat":          "Send message when all servers are up on schedule",
+	"cmd.botstats":           "Show internal metrics of the bot",
+	"cmd.version":            "Show version of the bot",
+	"cmd.ping":               "Measure bot and Telegram latency",
+	"cmd.help":               "Show commands",
+	"cmd.whoami":             "Show your username, ids and permissions",
+	"cmd.addsuper":           "Add superuser",
+	"cmd.removesuper":        "Remove superuser",
+	"cmd.listsupers":         "Show list of superusers",
+
+	"server.not_exists":    "Server %s not exists",
+	"server.exists":        "Server already exists",
+	"server.limit":         "Limit of %d servers reached, %d servers are monitored",
+	"server.added":         "Server %s [%s] added",
+	"server.add_failed":    "Failed to add server %s [%s]",
+	"server.removed":       "Server %s removed",
+	"server.remove_failed": "Failed to remove server %s",
+	"server.update_failed": "Failed to update server %s",
+	"server.check_failed":  "Failed to check server %s",
+
+	"api.server_added":   "Server %s [%s] added via API",
+	"api.server_removed": "Server %s removed via API",
+
+	"name.too_long": "Name must be at most %d characters",
+	"name.reserved": "Name %s is reserved as a command argument, reserved names: %s",
+	"name.chars":    "Name may contain only letters, digits, dash, underscore, dot and spaces between words in quotes like \"my server\"",
+
+	"servers.none":               "No servers",
+	"servers.remove_all_confirm": "⚠️ This will delete %s",
+	"servers.remove_all_failed":  "Failed to remove all servers",
+	"servers.removed_all":        "All servers removed",
+
+	"setcron.usage":       "Usage: /setcron <spec> or /setcron default\nCurrent: %s",
+	"setcron.invalid":     "Invalid cron spec %q: %v",
+	"setcron.save_failed": "Cron spec applied, but failed to save it",
+	"setcron.done":        "Checks cron set to %s\nNext runs:\n%s",
+
+	"threshold.usage":   "Usage: /setthresholdglobal <n>|clear, n must be 1 or greater",
+	"threshold.failed":  "Failed to set alert threshold",
+	"threshold.changed": "Alert threshold changed from %d to %d",
+	"threshold.cleared": "Alert threshold cleared, using default (%d)",
+
+	"settings.flag":      "flag",
+	"settings.runtime":   "runtime",
+	"settings.threshold": "Alert threshold: %d (%s)\n",
+	"settings.cron":      "Checks cron: %s (%s)\n",
+	"settings.log_level": "Log level: %s\n",
+	"settings.language":  "Language: %s\n",
+	"settings.timezone":  "Timezone: %s (%s)\n",
+	"settings.backup":    "Last backup: %s\n",
+	"settings.no_backup": "never",
+
+	"log.debug":     "debug",
+	"log.normal":    "normal",
+	"debug.enabled": "Log level: debug, reverts to normal in %s",
+	"debug.status":  "Log level: %s",
+	"debug.usage":   "Usage: /debug on|off|status",
+
+	"botstats.last_cycle":     "Last check cycle: %s\n",
+	"botstats.cycle_duration": "Cycle duration: %v, cron period %v\n",
+	"botstats.scheduler_lag":  "Scheduler lag: %v\n",
+	"botstats.checks":         "Checks: %d in %d cycles\n",
+	"botstats.telegram":       "Telegram sends: %d, errors %d, queued %d\n",
+	"botstats.storage":        "Storage writes: %d, errors %d\n",
+	"botstats.goroutines":     "Goroutines: %d\n",
+	"botstats.slow_cycle":     "⚠️ Check cycle took %s, close to the cron period %s. Increase the checks interval with /setcron",
+	"botstats.skipped":        "⚠️ %d check cycles in a row reached the deadline before checking all servers, not checked by the last one: %s. Increase the checks interval with /setcron or CYCLE_DEADLINE",
+	"updates.skipped":         "I was offline and ignored %d stale commands and button presses",
+	"updates.recovered":       "⚠️ Bot could not get updates from Telegram for %s, commands sent meanwhile are handled now",
+	"watchdog.stalled":        "⚠️ Health checks have not run for %s",
+
+	"version.info":  "Version: %s\nCommit: %s\nBuilt: %s\nGo: %s\nUptime: %s",
+	"version.newer": "A newer version %s is available",
+
+	"ping.result": "pong\nSend round-trip: %v\nUpdate age: %v\nLast check cycle: %s",
+
+	"whoami.info":           "Username: %s\nUser ID: %d\nChat ID: %d\n",
+	"whoami.super_username": "Superuser: yes, matched by username",
+	"whoami.super_id":       "Superuser: yes, matched by user ID",
+	"whoami.not_super":      "Superuser: no",
+	"whoami.viewer":         "Viewer: yes, read-only commands are available",
+	"permission.denied":     "You don't have permission, the command is available to superusers only",
+
+	"category.servers":   "Servers",
+	"category.incidents": "Incidents",
+	"category.settings":  "Settings",
+	"category.bot":       "Bot",
+	"category.supers":    "Users",
+
+	"help.command":   "%s\n%s",
+	"help.not_found": "No command %s, send /help to see the commands",
+	"help.suggest":   "Unknown command /%s — did you mean /%s?",
+	"help.unknown":   "Unknown command /%s, send /help to see the commands",
+
+	"command.usage":  "Usage: %s",
+	"command.failed": "Command /%s failed, see the bot logs",
+
+	"language.usage":  "Usage: /setlanguage %s|default\nCurrent: %s",
+	"language.set":    "Language set to %s",
+	"language.failed": "Failed to set language",
+
+	"timezone.usage":   "Usage: /settimezone <name> or /settimezone default, name is IANA timezone like Europe/Berlin\nCurrent: %s",
+	"timezone.invalid": "Unknown timezone %q",
+	"timezone.set":     "Timezone set to %s, current time %s",
+	"timezone.failed":  "Failed to set timezone",
+
+	"ack.failed":        "Failed to acknowledge",
+	"ack.server_failed": "Failed to acknowledge server %s",
+	"ack.no_incident":   "Server %s has no active incident",
+	"ack.done":          "Acknowledged",
+	"ack.server_done":   "Incident of server %s acknowledged",
+	"ack.by":            "ack'd by %s",
+
+	"down.all_up": "All servers are up",
+
+	"recheck.checking":   "Checking...",
+	"recheck.still_down": "❌ still down",
+	"recheck.up":         "✅ up",
+	"recheck.checked":    "Checked at %s: %s",
+
+	"snooze.invalid": "Invalid snooze duration",
+	"snooze.failed":  "Failed to snooze",
+	"snooze.done":    "Snoozed for %s",
+	"snooze.by":      "snoozed by %s until %s",
+
+	"tags.set":     "Tags of %s: %s",
+	"tags.off":     "Tags of %s cleared",
+	"tags.invalid": "Tags must be comma separated words of letters, digits, - and _",
+
+	"stats.line":      "%s: %s available, average response %s over %s",
+	"stats.downtime":  ", down %s this month, %s total",
+	"stats.score":     ", score %d",
+	"stats.changes":   ", %s this week",
+	"stats.unchecked": "%s: not checked yet",
+
+	"ips.enabled_any": "Each address of %s is checked, the server is down if any of them fails",
+	"ips.enabled_all": "Each address of %s is checked, the server is down if all of them fail and degraded if some fail",
+	"ips.disabled":    "Address checks of %s cleared, using default (any address)",
+
+	"endpoint.added":         "Endpoint %s [%s] is checked with %s",
+	"endpoint.exists":        "%s already has endpoint %s",
+	"endpoint.not_exists":    "%s has no endpoint %s",
+	"endpoint.none":          "%s has no endpoints, add one with /addendpoint",
+	"endpoint.limit":         "A server can have up to %d endpoints",
+	"endpoint.invalid_label": "Invalid label %s, it must be a single word of letters, digits, dashes, underscores or dots, not %s",
+	"endpoint.credentials":   "Endpoints are checked with basic auth of the server, send the URL without credentials",
+	"endpoint.removed":       "Endpoint %s of %s removed",
+	"endpoint.removed_all":   "Endpoints of %s removed",
+	"endpoint.mode_any":      "%s is down if any of its endpoints fails",
+	"endpoint.mode_all":      "%s is down if all of its endpoints fail and degraded if some fail",
+
+	"schedule.set":        "%s is checked on schedule %s (%s)",
+	"schedule.default":    "%s is checked on the default schedule (%s)",
+	"schedule.not_exists": "Unknown schedule %s, available: %s",
+
+	"soft404.on":  "Responses of %s with an error page body are failed",
+	"soft404.off": "Error page detection of %s cleared, using default (off)",
+
+	"jsoncheck.set":       "Check of %s fails unless %s",
+	"jsoncheck.off":       "JSON checks of %s cleared, using default (none)",
+	"jsoncheck.path_off":  "JSON checks of %s cleared for %s",
+	"jsoncheck.not_found": "%s has no JSON checks of %s",
+	"jsoncheck.invalid":   "%v. Use like /setjsoncheck api queue_depth < 5000, comparators are < <= > >= ==",
+
+	"retries.set":     "Timeouts and dropped connections of %s are retried up to %d times %v apart within %v of a check",
+	"retries.off":     "Retries of %s cleared, using default (no retries)",
+	"retries.invalid": "Count must be from 0 to %d, delay a duration up to %v like 2s",
+
+	"backoff.set":         "Checks of %s are stretched after %v down, up to every %v",
+	"backoff.off":         "Backoff of checks of %s is off",
+	"backoff.default":     "Backoff of %s reset to default, checks are stretched after %v down, up to every %v",
+	"backoff.default_off": "Backoff of %s reset to default (off)",
+	"backoff.invalid":     "Durations must be positive, like /setbackoff api 1h 15m",
+
+	"proto.fail": "Check of %s fails when it is served over a protocol lower than %s",
+	"proto.warn": "A warning is sent once when %s is served over a protocol lower than %s",
+	"proto.off":  "Minimum protocol of %s cleared, using default (any protocol)",
+
+	"compression.on":  "A warning is sent once when text responses of %s are not compressed",
+	"compression.off": "Compression check of %s cleared, using default (off)",
+
+	"security.on":  "Security headers of %s are audited, a warning is sent when a header disappears",
+	"security.off": "Security headers of %s are not audited",
+
+	"sla.set":         "SLA target of %s is %g%% over %s",
+	"sla.off":         "SLA target of %s cleared, using default (no target)",
+	"sla.invalid":     "Target must be a percent below 100 like 99.9, window a duration like 12h or 30d up to 90d",
+	"sla.empty":       "No servers with SLA targets, set one with /setsla",
+	"sla.title":       "SLA targets:\n",
+	"sla.status":      "%.3f%% of %g%% over %s, error budget left %.1f%%\n",
+	"sla.no_data":     "no checks yet, target %g%% over %s\n",
+	"sla.reliability": "  MTTR %s, MTBF %s\n",
+
+	"expectedip.fail":    "Check of %s fails when it is connected on an address outside %s",
+	"expectedip.warn":    "A warning is sent once when %s is connected on a new address outside %s",
+	"expectedip.off":     "Expected addresses of %s cleared, using default (any address)",
+	"expectedip.invalid": "%v. Use IPv4 or IPv6 addresses and CIDRs separated by commas, like 203.0.113.0/24,2001:db8::/32",
+
+	"ssl.invalid_host":     "Invalid address %s, use host:port like mail.example.com:465",
+	"ssl.invalid_starttls": "Unknown STARTTLS protocol, use one of: %s",
+	"ssl.http_only":        "/%s applies only to HTTP checks, %s is an SSL check",
+
+	"ssh.invalid_host": "Invalid address %s, use host:port like example.com:22",
+	"ssh.http_only":    "/%s applies only to HTTP checks, %s is an SSH check",
+
+	"pin.set":     "Public key of %s is pinned to %s",
+	"pin.pending": "Public key of %s will be pinned on the next check",
+	"pin.cleared": "Pin of %s cleared, using default (no pin)",
+	"pin.invalid": "Fingerprint must be SHA-256 of the public key in hex or base64, current or clear",
+
+	"probes.set":     "%s is checked from %s and by the bot",
+	"probes.off":     "Locations of %s cleared, using default (checked only by the bot)",
+	"probes.invalid": "Locations must be comma separated agent locations without spaces, local is reserved",
+
+	"maintenance.on":      "🛠 Maintenance until %s, checks keep running but no alerts are sent",
+	"maintenance.off":     "Maintenance ended, the summary is sent after the next check cycle",
+	"maintenance.invalid": "Duration must be like 30m or 2h, or off",
+	"maintenance.failed":  "Failed to save maintenance",
+	"maintenance.banner":  "🛠 Maintenance until %s, alerts are not sent\n\n",
+
+	"keep.usage":         "Send /keep as a reply to the bot message to keep",
+	"keep.not_scheduled": "The message is not scheduled for deletion",
+	"keep.done":          "The message is kept",
+
+	"heartbeat.message": "✅ %d/%d servers up, bot healthy, last check %s",
+	"heartbeat.on":      "Heartbeat is sent silently on %s when all servers are up",
+	"heartbeat.off":     "Heartbeat is disabled",
+	"heartbeat.failed":  "Failed to save heartbeat setting",
+
+	"backup.caption": "💾 Backup of %s, %s",
+
+	"restore.no_document":     "Send /restore as a reply to a backup document",
+	"restore.too_large":       "The document is larger than %d MB",
+	"restore.download_failed": "Failed to download the document",
+	"restore.invalid":         "The document is not a valid backup: %s",
+	"restore.confirm":         "Restore from %s? It will add %d, update %d and remove %d servers, settings are replaced too. The current storage is kept for /restore undo",
+	"restore.failed":          "Failed to restore storage",
+	"restore.done":            "Storage restored, %d servers are checked",
+	"restore.undo_confirm":    "Put back the storage replaced by the last restore?",
+	"restore.nothing_to_undo": "There is no restore to undo",
+	"restore.undone":          "Restore undone",
+
+	"subscribe.private_only":   "Subscriptions are personal, send the command in a private chat with the bot",
+	"subscribe.server":         "You will get down and up alerts of %s here",
+	"subscribe.all":            "You will get down and up alerts of all servers here",
+	"subscribe.tag":            "You will get down and up alerts of servers tagged %s here",
+	"subscribe.invalid_tag":    "Tag must be a word of letters, digits, - and _ like tag:prod",
+	"subscribe.failed":         "Failed to save subscription",
+	"subscribe.not_subscribed": "You are not subscribed to %s",
+	"subscribe.none":           "You have no subscriptions",
+	"subscribe.list":           "Your subscriptions:",
+	"subscribe.list_all":       "• all servers",
+	"subscribe.list_tag":       "• servers tagged %s",
+	"unsubscribe.server":       "You won't get alerts of %s here anymore",
+	"unsubscribe.tag":          "You won't get alerts of servers tagged %s here anymore",
+	"unsubscribe.all":          "You won't get alerts of all servers here anymore, subscriptions to single servers are kept",
+
+	"preferences.title":             "Personal alerts, the alert chat always gets all alerts. Tap a category to switch it.",
+	"preferences.quiet":             "Quiet hours: %s-%s",
+	"preferences.no_quiet":          "No quiet hours, set them with /preferences quiet 22:00-08:00",
+	"preferences.quiet_invalid":     "Quiet hours must look like 22:00-08:00",
+	"preferences.failed":            "Failed to save preferences",
+	"preferences.category.down":     "Down and up",
+	"preferences.category.warnings": "Warnings",
+	"preferences.category.sla":      "SLA",
+	"preferences.mode.on":           "✅ always",
+	"preferences.mode.awake":        "🌙 outside quiet hours",
+	"preferences.mode.off":          "❌ off",
+
+	"testalert.sending":   "Sending test %s alert of %s...",
+	"testalert.result":    "Test alert results:",
+	"testalert.delivered": "✅ %s",
+	"testalert.failed":    "❌ %s: %v",
+
+	"simulate.on":      "🧪 Server %s fails in simulation for the next %d check cycles, alerts are marked simulated",
+	"simulate.off":     "Simulation of %s is cancelled, the next check is real",
+	"simulate.invalid": "Number of cycles must be from 1 to %d",
+	"list.simulated":   "🧪 simulated",
+	"list.verbose":     "    last checked %s, responded in %v, %s over 24h\n",
+	"list.unchecked":   "    not checked yet\n",
+	"list.score":       "score %d",
+	"list.changes":     "%d state changes this week",
+
+	"uptimehistory.no_data": "No daily uptime of %s yet",
+	"uptimehistory.title":   "%s uptime for %s: %s\n",
+	"uptimehistory.legend":  "🟩 100% 🟨 99%+ 🟥 below 99% 🟦 partial day ⬜ no checks",
+
+	"chart.hours_invalid": "Hours must be a positive number",
+	"chart.no_checks":     "No checks of %s in the last %s",
+	"chart.failed":        "Failed to render chart of %s",
+	"chart.caption":       "%s response time since %s, %s",
+	"chart.pruned":        "Older checks are pruned, the chart covers less than %s",
+	"chart.stats":         "min %v, avg %v, p95 %v",
+
+	"chat.rejected": "This bot only accepts commands in the configured chat",
+	"chat.migrated": "Chat migrated to supergroup, chat id changed from %d to %d. Update chat id in the bot configuration",
+
+	"confirm.timeout":      "⌛ Not confirmed in time",
+	"confirm.expired":      "Confirmation expired",
+	"confirm.other_user":   "Only the user who issued the command can confirm it",
+	"confirm.cancelled":    "Cancelled",
+	"confirm.confirmed":    "Confirmed",
+	"confirm.confirmed_by": "Confirmed by %s",
+
+	"callback.unauthorized": "Not authorized",
+	"callback.stale":        "Already handled",
+
+	"add.ask_url":           "Send the server URL, for example: github.com",
+	"add.invalid_url":       "Invalid URL, send the server URL again",
+	"add.invalid_grace":     "Invalid grace period, use a duration like grace=30m",
+	"add.ask_name":          "Send the server name",
+	"add.invalid_name":      "Name must be a single word or quoted, send the server name again",
+	"add.expired":           "Conversation expired, send /add again",
+	"add.cancelled":         "Adding server cancelled",
+	"add.url_first":         "Send the server URL first",
+	"add.undo_removed":      "Server removed",
+	"add.undone":            "Adding server %s undone",
+	"add.checking":          "⏳ Checking...",
+	"add.first_ok":          "✅ %d %s in %s",
+	"add.first_ssl":         "✅ TLS handshake in %s, certificate expires in %s",
+	"add.first_ssh":         "✅ %s in %s",
+	"add.first_failed":      "⚠️ First check failed: %s. Alerting will start after %d failures",
+	"add.first_grace":       "⚠️ First check failed: %s. Alerting will start after %d failures once the grace period ends in %s",
+	"add.threshold_invalid": "Threshold must be a number, 0 to use the global threshold",
+	"add.credentials":       "🔑 Credentials were removed from the URL and will be sent as basic auth. The message with them is deleted if the bot is allowed to",
+
+	"default.soft404":     "error page detection",
+	"default.json":        "JSON checks",
+	"default.proto":       "minimum protocol",
+	"default.compression": "compression check",
+	"default.ips":         "address checks",
+	"default.sla":         "SLA",
+	"default.pin":         "pinned key",
+	"default.probes":      "locations",
+	"default.endpoints":   "endpoints",
+
+	"details.paused_answer":    "Paused",
+	"details.resumed_answer":   "Resumed",
+	"details.ask_threshold":    "Send alert threshold for %s, 0 to use the global threshold",
+	"details.remove_confirm":   "⚠️ Remove server %s?",
+	"details.threshold_failed": "Failed to set alert threshold of %s",
+	"details.url":              "URL: %s\n",
+	"details.tags":             "Tags: %s\n",
+	"details.auth":             "Auth: configured (%s)\n",
+	"details.soft404":          "Error page detection: on\n",
+	"details.json":             "JSON check: %s\n",
+	"details.proto":            "Protocol: %s\n",
+	"details.compression":      "Compression: checked\n",
+	"details.uncompressed":     "⚠️ Compression: responses are not compressed\n",
+	"details.security":         "Security headers:\n",
+	"details.sla":              "SLA: %s",
+	"details.pin":              "Pinned key: %s\n",
+	"details.pin_pending":      "Pinned key: captured on the next check\n",
+	"details.pin_mismatch":     "⚠️ Presented key: %s\n",
+	"details.locations":        "Locations:\n",
+	"details.location":         "%s %s %v, %s",
+	"details.location_no_data": "⬜ %s no reports\n",
+	"details.proto_fail":       "Minimum protocol: %s, the check fails below it\n",
+	"details.proto_warn":       "Minimum protocol: %s, a warning is sent below it\n",
+	"details.remote_ip":        "Connected address: %s\n",
+	"details.ssl":              "Check: TLS handshake only\n",
+	"details.ssl_starttls":     "Check: TLS handshake after STARTTLS (%s)\n",
+	"details.ssl_expiry":       "Certificate expires: %s (in %s)\n",
+	"details.ssl_expired":      "⚠️ Certificate expired: %s\n",
+	"details.ssl_issuer":       "Certificate issuer: %s\n",
+	"details.ssh":              "Check: SSH banner, no authentication\n",
+	"details.ssh_banner":       "Banner: %s\n",
+	"details.ssh_expect":       "Expected banner: contains %s\n",
+	"details.expect_fail":      "Expected addresses: %s, the check fails outside them\n",
+	"details.expect_warn":      "Expected addresses: %s, a warning is sent outside them\n",
+	"details.last_checked":     "Last checked: %s\n",
+	"details.attempt_ok":       "Last check succeeded on attempt %d/%d\n",
+	"details.attempt_failed":   "Last check failed after %d attempts\n",
+	"details.retries":          "Retries: %d, %v apart\n",
+	"details.backoff":          "🐢 Checking every %s due to prolonged outage\n",
+	"details.backoff_set":      "Backoff: after %s down, up to every %s\n",
+	"details.backoff_off":      "Backoff: off\n",
+	"details.last_success":     "Last success: %s\n",
+	"details.last_failure":     "Last failure: %s\n",
+	"details.threshold":        "Alert threshold: %d\n",
+	"details.threshold_global": "Alert threshold: global\n",
+	"details.downtime":         "Downtime: %s this month, %s total\n",
+	"details.state_changes":    "State changes: %d today, %d this week, %d in 30 days\n",
+	"details.streak_up":        "Up for %s\n",
+	"details.streak_down":      "Down for %s\n",
+	"details.record_up":        "Longest uptime: %s (%s – %s)\n",
+	"details.record_down":      "Longest downtime: %s (%s – %s)\n",
+	"details.recent_errors":    "Recent errors:\n",
+	"details.recent_error":     "• %s ×%d, last %s\n",
+	"details.grace":            "⏳ Grace period: alerts start in %s\n",
+	"details.schedule":         "Schedule: %s (%s)\n",
+	"details.schedule_default": "Schedule: default\n",
+	"details.score":            "Health score: %d/100\n",
+	"details.score_change":     "Health score: %d/100, %+d in 24h\n",
+	"details.reliability":      "MTTR %s, MTBF %s in the last %s\n",
+	"details.na":               "n/a",
+	"details.pruned":           "Incidents before %s are pruned, MTTR and MTBF cover a shorter window\n",
+	"details.ips_any":          "Addresses, down if any fails:\n",
+	"details.ips_all":          "Addresses, down if all fail:\n",
+	"details.ip":               "%s %s %v",
+	"details.endpoints_any":    "Endpoints, down if any fails:\n",
+	"details.endpoints_all":    "Endpoints, down if all fail:\n",
+	"details.endpoint":         "%s %s [%s] %v, %s",
+	"details.endpoint_no_data": "⬜ %s [%s] not checked yet\n",
+	"details.daily_uptime":     "Uptime for %s: %s\n%s\n",
+	"details.days_invalid":     "Days must be a positive number",
+	"details.defaults":         "Not set (default): %s\n",
+	"details.paused":           "Paused\n",
+	"details.snoozed":          "Snoozed until %s\n",
+	"details.simulated":        "🧪 Fails in simulation for %d more check cycles\n",
+	"details.simulated_ended":  "🧪 Simulation ended, the failure ends on the next successful check\n",
+
+	"incident.since":     "Down since: %s\n",
+	"incident.not_acked": "Not acknowledged\n",
+	"incident.acked":     "Acknowledged by %s %s\n",
+	"incident.comment":   "Comment: %s\n",
+
+	"inline.summary": "✅ %d up, ❌ %d down",
+
+	"super.already":       "%s is already a superuser",
+	"super.add_failed":    "Failed to add superuser %s",
+	"super.added":         "Superuser %s added",
+	"super.flag":          "%s is set by --super flag and can't be removed at runtime, change the flag instead",
+	"super.remove_self":   "⚠️ You are about to remove yourself (%s) from superusers",
+	"super.remove_failed": "Failed to remove superuser %s",
+	"super.not_super":     "%s is not a superuser",
+	"super.last":          "%s is the last superuser and can't be removed",
+	"super.removed":       "Superuser %s removed",
+	"super.list_flag":     "%s (flag)\n",
+	"super.list_runtime":  "%s (runtime)\n",
+	"super.none":          "No superusers",
+
+	"notifications.count_invalid": "Count must be a positive number",
+	"notifications.none":          "No notifications",
+}
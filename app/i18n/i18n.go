@@ -0,0 +1,137 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Lang string
+
+const (
+	En Lang = "en"
+	Ru Lang = "ru"
+)
+
+var catalogs = map[Lang]map[string]string{
+	En: en,
+	Ru: ru,
+}
+
+// Parse returns the language with the code, false if the language is not supported
+func Parse(code string) (Lang, bool) {
+	var lang = Lang(strings.ToLower(strings.TrimSpace(code)))
+	_, ok := catalogs[lang]
+	return lang, ok
+}
+
+// Languages returns codes of the supported languages
+func Languages() []string {
+	return []string{string(En), string(Ru)}
+}
+
+// T returns the message with the key formatted with args, untranslated keys fall back to English
+func T(lang Lang, key string, args ...any) string {
+	message, ok := catalogs[lang][key]
+	if !ok {
+		if message, ok = en[key]; !ok {
+			message = key
+		}
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Plural returns n with the plural form of the key, forms are separated by "|"
+func Plural(lang Lang, key string, n int) string {
+	var forms = strings.Split(T(lang, key), "|")
+	return fmt.Sprintf("%d %s", n, forms[pluralForm(lang, n, len(forms))])
+}
+
+// pluralForm returns index of the plural form: one, other in English and one, few, many in Russian
+func pluralForm(lang Lang, n int, count int) int {
+	var form int
+	switch lang {
+	case Ru:
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			form = 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			form = 1
+		default:
+			form = 2
+		}
+	default:
+		if n != 1 {
+			form = 1
+		}
+	}
+
+	if form >= count {
+		return count - 1
+	}
+	return form
+}
+
+// Duration formats duration with two most significant units, like "1 day 4 hours"
+func Duration(lang Lang, d time.Duration) string {
+	d = d.Round(time.Second)
+
+	var days = int(d.Hours()) / 24
+	var hours = int(d.Hours()) % 24
+	var minutes = int(d.Minutes()) % 60
+	var seconds = int(d.Seconds()) % 60
+
+	switch {
+	case days > 0:
+		return Plural(lang, "unit.day", days) + " " + Plural(lang, "unit.hour", hours)
+	case hours > 0:
+		return Plural(lang, "unit.hour", hours) + " " + Plural(lang, "unit.minute", minutes)
+	case minutes > 0:
+		return Plural(lang, "unit.minute", minutes) + " " + Plural(lang, "unit.second", seconds)
+	default:
+		return Plural(lang, "unit.second", seconds)
+	}
+}
+
+// Window formats duration of a time window, whole days without hours like "30 days"
+func Window(lang Lang, d time.Duration) string {
+	const day = 24 * time.Hour
+	if d >= day && d%day == 0 {
+		return Plural(lang, "unit.day", int(d/day))
+	}
+	return Duration(lang, d)
+}
+
+// ShortDuration formats duration with two most significant units in short form, like "1d 4h" or "3m 12s"
+func ShortDuration(lang Lang, d time.Duration) string {
+	d = d.Round(time.Second)
+
+	var days = int(d.Hours()) / 24
+	var hours = int(d.Hours()) % 24
+	var minutes = int(d.Minutes()) % 60
+	var seconds = int(d.Seconds()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d%s %d%s", days, T(lang, "unit.short_day"), hours, T(lang, "unit.short_hour"))
+	case hours > 0:
+		return fmt.Sprintf("%d%s %d%s", hours, T(lang, "unit.short_hour"), minutes, T(lang, "unit.short_minute"))
+	case minutes > 0:
+		return fmt.Sprintf("%d%s %d%s", minutes, T(lang, "unit.short_minute"), seconds, T(lang, "unit.short_second"))
+	default:
+		return fmt.Sprintf("%d%s", seconds, T(lang, "unit.short_second"))
+	}
+}
+
+// TimeAgo formats time relative to now, like "1d 4h ago" or "3m 12s ago"
+func TimeAgo(lang Lang, t time.Time) string {
+	if t.IsZero() {
+		return T(lang, "time.never")
+	}
+
+	return T(lang, "time.ago", ShortDuration(lang, time.Since(t)))
+}
@@ -0,0 +1,104 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestT(t *testing.T) {
+	en["test.english_only"] = "Only %s"
+	t.Cleanup(func() { delete(en, "test.english_only") })
+
+	var tests = []struct {
+		lang Lang
+		key  string
+		args []any
+		want string
+	}{
+		{lang: En, key: "time.never", want: "never"},
+		{lang: Ru, key: "time.never", want: "никогда"},
+		{lang: Ru, key: "test.english_only", args: []any{"English"}, want: "Only English"},
+		{lang: Lang("de"), key: "time.never", want: "never"},
+		{lang: Ru, key: "unknown.key", want: "unknown.key"},
+	}
+
+	for _, test := range tests {
+		if got := T(test.lang, test.key, test.args...); got != test.want {
+			t.Errorf("got %q of %s in %s, want %q", got, test.key, test.lang, test.want)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	var tests = []struct {
+		lang Lang
+		n    int
+		want string
+	}{
+		{lang: En, n: 0, want: "0 minutes"},
+		{lang: En, n: 1, want: "1 minute"},
+		{lang: En, n: 2, want: "2 minutes"},
+		{lang: En, n: 11, want: "11 minutes"},
+		{lang: Ru, n: 1, want: "1 минута"},
+		{lang: Ru, n: 2, want: "2 минуты"},
+		{lang: Ru, n: 4, want: "4 минуты"},
+		{lang: Ru, n: 5, want: "5 минут"},
+		{lang: Ru, n: 11, want: "11 минут"},
+		{lang: Ru, n: 12, want: "12 минут"},
+		{lang: Ru, n: 21, want: "21 минута"},
+		{lang: Ru, n: 22, want: "22 минуты"},
+		{lang: Ru, n: 111, want: "111 минут"},
+	}
+
+	for _, test := range tests {
+		if got := Plural(test.lang, "unit.minute", test.n); got != test.want {
+			t.Errorf("got %q of %d in %s, want %q", got, test.n, test.lang, test.want)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	var tests = []struct {
+		lang Lang
+		d    time.Duration
+		want string
+	}{
+		{lang: En, d: 28 * time.Hour, want: "1 day 4 hours"},
+		{lang: En, d: 3*time.Minute + 12*time.Second, want: "3 minutes 12 seconds"},
+		{lang: Ru, d: 26 * time.Hour, want: "1 день 2 часа"},
+		{lang: Ru, d: 5 * time.Second, want: "5 секунд"},
+	}
+
+	for _, test := range tests {
+		if got := Duration(test.lang, test.d); got != test.want {
+			t.Errorf("got %q of %s in %s, want %q", got, test.d, test.lang, test.want)
+		}
+	}
+}
+
+// verbs matches formatting verbs of messages
+var verbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for key, message := range en {
+		translation, ok := ru[key]
+		if !ok {
+			t.Errorf("%s is not translated to Russian", key)
+			continue
+		}
+		if !slices.Equal(verbs.FindAllString(message, -1), verbs.FindAllString(translation, -1)) {
+			t.Errorf("%s has different formatting verbs: %q and %q", key, message, translation)
+		}
+		if strings.HasSuffix(message, "\n") != strings.HasSuffix(translation, "\n") {
+			t.Errorf("%s ends with a line break in one language only: %q and %q", key, message, translation)
+		}
+	}
+	for key := range ru {
+		if _, ok := en[key]; !ok {
+			t.Errorf("%s has no English message", key)
+		}
+	}
+}
@@ -0,0 +1,550 @@
+package i18n
+
+// ru has plural forms for one, few and many, like "1 минута", "2 минуты", "5 минут"
+var ru = map[string]string{
+	"unit.day":    "день|дня|дней",
+	"unit.hour":   "час|часа|часов",
+	"unit.minute": "минута|минуты|минут",
+	"unit.second": "секунда|секунды|секунд",
+	"unit.server": "сервер|сервера|серверов",
+	"unit.check":  "проверка|проверки|проверок",
+	"unit.change": "смена состояния|смены состояния|смен состояния",
+
+	"unit.short_day":    "д",
+	"unit.short_hour":   "ч",
+	"unit.short_minute": "м",
+	"unit.short_second": "с",
+
+	"time.never": "никогда",
+	"time.ago":   "%s назад",
+
+	"alert.down":                "❗❗❗ Сервер %s недоступен ❗❗❗",
+	"alert.down_status":         "Статус: %d %s",
+	"alert.down_reason":         "Причина: %s",
+	"alert.down_error":          "Ошибка: %s",
+	"alert.down_time":           "Время ответа: %v",
+	"alert.down_body":           "Ответ: %s",
+	"alert.category_timeout":    "таймаут",
+	"alert.category_dns":        "ошибка DNS",
+	"alert.category_refused":    "соединение отклонено",
+	"alert.category_tls":        "ошибка TLS",
+	"alert.category_banner":     "неверный SSH-баннер",
+	"alert.up":                  "✅ Сервер %s снова доступен 🎉",
+	"alert.up_ack":              "принято %s через %s после оповещения",
+	"alert.escalated":           "🚨 Сервер %s недоступен уже %s, оповещение не принято",
+	"alert.escalation_resolved": "✅ Сервер %s снова доступен, простой %s",
+	"alert.protocol":            "⚠️ Сервер %s отвечает по %s, ожидается %s",
+	"alert.compression":         "⚠️ Сервер %s отвечает без сжатия",
+	"alert.security":            "⚠️ Сервер %s перестал отправлять заголовки безопасности: %s",
+	"alert.sla_breach":          "⚠️ Риск нарушения SLA: доступность %s %.3f%%, ниже цели %g%% за %s. Остаток бюджета ошибок %.1f%%",
+	"alert.sla_recovered":       "✅ Доступность %s %.3f%%, снова выше цели %g%% за %s",
+	"alert.pin":                 "🔐❗ Открытый ключ %s изменился: получен %s, закреплен %s",
+	"alert.unexpected_ip":       "🌐❗ Сервер %s подключён по адресу %s, ожидается %s",
+	"alert.test":                "🧪 ТЕСТ, это не настоящее оповещение",
+	"alert.simulated":           "(симуляция)",
+	"alert.agent_offline":       "⚠️ Агент %s не отвечает %s",
+	"alert.agent_online":        "✅ Агент %s снова отвечает после %s",
+	"alert.maintenance_up":      "🛠 Обслуживание завершено, все серверы доступны",
+	"alert.maintenance_down":    "🛠 Обслуживание завершено, недоступны: %s",
+	"alert.summary":             "📋 Итоги инцидента %s\nНачало: %s\nОкончание: %s\nДлительность: %s\nНеудачных проверок: %d",
+	"alert.summary_errors":      "Ошибки:",
+	"alert.summary_ack":         "Подтверждён %s через %s после оповещения",
+	"alert.summary_no_ack":      "Не подтверждён",
+	"alert.ended_streak":        "Это прервало непрерывную работу длительностью %s",
+	"alert.ended_record":        "🏆 Это прервало рекордную непрерывную работу длительностью %s",
+
+	"button.ack":            "Принять",
+	"button.check_now":      "Проверить",
+	"button.snooze_1h":      "Отложить на 1 ч",
+	"button.details":        "Подробности",
+	"button.confirm":        "Подтвердить",
+	"button.cancel":         "Отмена",
+	"button.url_as_name":    "Имя по URL",
+	"button.undo":           "Отменить",
+	"button.pause":          "Пауза",
+	"button.resume":         "Возобновить",
+	"button.edit_threshold": "Изменить порог",
+	"button.remove":         "Удалить",
+	"button.clear_quiet":    "Убрать тихие часы",
+
+	"cmd.add":                "Добавить сервер",
+	"cmd.addssl":             "Добавить сервер с проверкой только TLS-рукопожатия",
+	"cmd.addssh":             "Добавить сервер с проверкой SSH-баннера",
+	"cmd.remove":             "Удалить сервер",
+	"cmd.removeall":          "Удалить все серверы",
+	"cmd.list":               "Список серверов",
+	"cmd.stats":              "Доступность и время ответа серверов",
+	"cmd.details":            "Подробности о сервере",
+	"cmd.settags":            "Задать теги сервера для фильтров /list и /stats",
+	"cmd.checkallips":        "Проверять каждый адрес сервера",
+	"cmd.addendpoint":        "Проверять ещё один URL сервера, например /readyz",
+	"cmd.removeendpoint":     "Удалить URL, проверяемый вместе с сервером",
+	"cmd.endpointmode":       "Недоступен ли сервер при отказе любого или всех его URL",
+	"cmd.setschedule":        "Проверять сервер по именованному расписанию",
+	"cmd.setsoft404":         "Считать недоступным при странице ошибки с кодом 200",
+	"cmd.setjsoncheck":       "Считать недоступным, когда число в JSON-ответе выходит за предел",
+	"cmd.setretries":         "Повторять таймауты в рамках проверки сервера",
+	"cmd.setbackoff":         "Настроить разрежение проверок долго недоступного сервера",
+	"cmd.setminproto":        "Проверять протокол сервера, например HTTP/2",
+	"cmd.setcompression":     "Предупреждать об ответах сервера без сжатия",
+	"cmd.securitycheck":      "Проверять заголовки безопасности сервера",
+	"cmd.setsla":             "Задать целевую доступность сервера",
+	"cmd.sla":                "Показать доступность серверов с целями",
+	"cmd.setexpectedip":      "Проверять адрес подключения к серверу",
+	"cmd.setpin":             "Закрепить открытый ключ сертификата сервера",
+	"cmd.setprobes":          "Проверять сервер из локаций агентов",
+	"cmd.uptimehistory":      "Доступность по дням за 90 дней",
+	"cmd.chart":              "График времени ответа",
+	"cmd.down":               "Недоступные серверы",
+	"cmd.ack":                "Принять инцидент",
+	"cmd.notifications":      "Отправленные оповещения",
+	"cmd.maintenanceall":     "Отключить все оповещения на время",
+	"cmd.setcron":            "Изменить расписание проверок",
+	"cmd.setthresholdglobal": "Изменить порог оповещений",
+	"cmd.settings":           "Текущие настройки",
+	"cmd.setlanguage":        "Изменить язык чата",
+	"cmd.settimezone":        "Изменить часовой пояс",
+	"cmd.restore":            "Восстановить данные из резервной копии, ответом на документ",
+	"cmd.simulate":           "Симулировать сбой сервера для проверки оповещений",
+	"cmd.testalert":          "Отправить тестовое оповещение во все каналы",
+	"cmd.subscribe":          "Получать оповещения о сервере в личном чате",
+	"cmd.unsubscribe":        "Отключить личные оповещения о сервере",
+	"cmd.subscriptions":      "Показать личные подписки на оповещения",
+	"cmd.preferences":        "Выбрать личные оповещения и тихие часы",
+	"cmd.debug":              "Отладочное логирование",
+	"cmd.keep":               "Не удалять ответ бота, отправьте в ответ на сообщение",
+	"cmd.heartbeat":          "Отправлять сообщение, когда все серверы доступны",
+	"cmd.botstats":           "Показать внутренние метрики бота",
+	"cmd.version":            "Показать версию бота",
+	"cmd.ping":               "Измерить задержку бота и Telegram",
+	"cmd.help":               "Показать команды",
+	"cmd.whoami":             "Показать ваше имя пользователя, id и права",
+	"cmd.addsuper":           "Добавить суперпользователя",
+	"cmd.removesuper":        "Удалить суперпользователя",
+	"cmd.listsupers":         "Список суперпользователей",
+
+	"server.not_exists":    "Сервер %s не существует",
+	"server.exists":        "Сервер уже существует",
+	"server.limit":         "Достигнут лимит в %d серверов, отслеживается серверов: %d",
+	"server.added":         "Сервер %s [%s] добавлен",
+	"server.add_failed":    "Не удалось добавить сервер %s [%s]",
+	"server.removed":       "Сервер %s удален",
+	"server.remove_failed": "Не удалось удалить сервер %s",
+	"server.update_failed": "Не удалось изменить сервер %s",
+	"server.check_failed":  "Не удалось проверить сервер %s",
+
+	"api.server_added":   "Сервер %s [%s] добавлен через API",
+	"api.server_removed": "Сервер %s удален через API",
+
+	"name.too_long": "Имя должно быть не длиннее %d символов",
+	"name.reserved": "Имя %s зарезервировано как аргумент команд, зарезервированные имена: %s",
+	"name.chars":    "Имя может содержать только буквы, цифры, дефис, подчеркивание, точку и пробелы между словами в кавычках, например \"my server\"",
+
+	"servers.none":               "Нет серверов",
+	"servers.remove_all_confirm": "⚠️ Будет удалено: %s",
+	"servers.remove_all_failed":  "Не удалось удалить все серверы",
+	"servers.removed_all":        "Все серверы удалены",
+
+	"setcron.usage":       "Использование: /setcron <spec> или /setcron default\nСейчас: %s",
+	"setcron.invalid":     "Неверное расписание %q: %v",
+	"setcron.save_failed": "Расписание применено, но не сохранено",
+	"setcron.done":        "Расписание проверок: %s\nСледующие запуски:\n%s",
+
+	"threshold.usage":   "Использование: /setthresholdglobal <n>|clear, n должно быть 1 или больше",
+	"threshold.failed":  "Не удалось изменить порог оповещений",
+	"threshold.changed": "Порог оповещений изменен с %d на %d",
+	"threshold.cleared": "Порог оповещений сброшен, используется значение по умолчанию (%d)",
+
+	"settings.flag":      "флаг",
+	"settings.runtime":   "изменено в боте",
+	"settings.threshold": "Порог оповещений: %d (%s)\n",
+	"settings.cron":      "Расписание проверок: %s (%s)\n",
+	"settings.log_level": "Уровень логирования: %s\n",
+	"settings.language":  "Язык: %s\n",
+	"settings.timezone":  "Часовой пояс: %s (%s)\n",
+	"settings.backup":    "Последняя резервная копия: %s\n",
+	"settings.no_backup": "не было",
+
+	"log.debug":     "отладочный",
+	"log.normal":    "обычный",
+	"debug.enabled": "Уровень логирования: отладочный, вернется к обычному через %s",
+	"debug.status":  "Уровень логирования: %s",
+	"debug.usage":   "Использование: /debug on|off|status",
+
+	"botstats.last_cycle":     "Последний цикл проверок: %s\n",
+	"botstats.cycle_duration": "Длительность цикла: %v, период расписания %v\n",
+	"botstats.scheduler_lag":  "Задержка расписания: %v\n",
+	"botstats.checks":         "Проверок: %d за %d циклов\n",
+	"botstats.telegram":       "Отправок в Telegram: %d, ошибок %d, в очереди %d\n",
+	"botstats.storage":        "Записей в хранилище: %d, ошибок %d\n",
+	"botstats.goroutines":     "Горутин: %d\n",
+	"botstats.slow_cycle":     "⚠️ Цикл проверок занял %s, почти весь период расписания %s. Увеличьте интервал проверок командой /setcron",
+	"botstats.skipped":        "⚠️ %d цикла проверок подряд не успели проверить все серверы, в последнем не проверены: %s. Увеличьте интервал проверок командой /setcron или CYCLE_DEADLINE",
+	"updates.skipped":         "Бот был недоступен и пропустил устаревшие команды и нажатия кнопок: %d",
+	"updates.recovered":       "⚠️ Бот не получал обновления от Telegram %s, отправленные за это время команды обрабатываются сейчас",
+	"watchdog.stalled":        "⚠️ Проверки не выполнялись %s",
+
+	"version.info":  "Версия: %s\nКоммит: %s\nСобрано: %s\nGo: %s\nРаботает: %s",
+	"version.newer": "Доступна новая версия %s",
+
+	"ping.result": "pong\nОтправка: %v\nВозраст обновления: %v\nПоследний цикл проверок: %s",
+
+	"whoami.info":           "Имя пользователя: %s\nID пользователя: %d\nID чата: %d\n",
+	"whoami.super_username": "Суперпользователь: да, по имени пользователя",
+	"whoami.super_id":       "Суперпользователь: да, по ID пользователя",
+	"whoami.not_super":      "Суперпользователь: нет",
+	"whoami.viewer":         "Наблюдатель: да, доступны команды только для чтения",
+	"permission.denied":     "Нет доступа, команда доступна только суперпользователям",
+
+	"category.servers":   "Серверы",
+	"category.incidents": "Инциденты",
+	"category.settings":  "Настройки",
+	"category.bot":       "Бот",
+	"category.supers":    "Пользователи",
+
+	"help.command":   "%s\n%s",
+	"help.not_found": "Команды %s нет, отправьте /help, чтобы увидеть команды",
+	"help.suggest":   "Неизвестная команда /%s — может быть, /%s?",
+	"help.unknown":   "Неизвестная команда /%s, отправьте /help, чтобы увидеть команды",
+
+	"command.usage":  "Использование: %s",
+	"command.failed": "Команда /%s завершилась с ошибкой, подробности в логах бота",
+
+	"language.usage":  "Использование: /setlanguage %s|default\nСейчас: %s",
+	"language.set":    "Язык изменен на %s",
+	"language.failed": "Не удалось изменить язык",
+
+	"timezone.usage":   "Использование: /settimezone <name> или /settimezone default, name - часовой пояс IANA, например Europe/Moscow\nСейчас: %s",
+	"timezone.invalid": "Неизвестный часовой пояс %q",
+	"timezone.set":     "Часовой пояс изменен на %s, текущее время %s",
+	"timezone.failed":  "Не удалось изменить часовой пояс",
+
+	"ack.failed":        "Не удалось принять",
+	"ack.server_failed": "Не удалось принять инцидент сервера %s",
+	"ack.no_incident":   "У сервера %s нет активного инцидента",
+	"ack.done":          "Принято",
+	"ack.server_done":   "Инцидент сервера %s принят",
+	"ack.by":            "принято %s",
+
+	"down.all_up": "Все серверы доступны",
+
+	"recheck.checking":   "Проверяю...",
+	"recheck.still_down": "❌ все еще недоступен",
+	"recheck.up":         "✅ доступен",
+	"recheck.checked":    "Проверено в %s: %s",
+
+	"snooze.invalid": "Неверная длительность",
+	"snooze.failed":  "Не удалось отложить",
+	"snooze.done":    "Отложено на %s",
+	"snooze.by":      "отложено %s до %s",
+
+	"tags.set":     "Теги %s: %s",
+	"tags.off":     "Теги %s сброшены",
+	"tags.invalid": "Теги должны быть словами из букв, цифр, - и _ через запятую",
+
+	"stats.line":      "%s: доступность %s, среднее время ответа %s за %s",
+	"stats.downtime":  ", простой %s в этом месяце, %s всего",
+	"stats.score":     ", оценка %d",
+	"stats.changes":   ", %s за неделю",
+	"stats.unchecked": "%s: ещё не проверялся",
+
+	"ips.enabled_any": "Каждый адрес %s проверяется, сервер недоступен если недоступен любой из них",
+	"ips.enabled_all": "Каждый адрес %s проверяется, сервер недоступен если недоступны все, и деградирован если часть",
+	"ips.disabled":    "Проверка адресов %s сброшена, по умолчанию (любой адрес)",
+
+	"endpoint.added":         "Эндпоинт %s [%s] проверяется вместе с %s",
+	"endpoint.exists":        "У %s уже есть эндпоинт %s",
+	"endpoint.not_exists":    "У %s нет эндпоинта %s",
+	"endpoint.none":          "У %s нет эндпоинтов, добавьте через /addendpoint",
+	"endpoint.limit":         "У сервера может быть до %d эндпоинтов",
+	"endpoint.invalid_label": "Неверная метка %s, это должно быть одно слово из букв, цифр, дефисов, подчёркиваний или точек, не %s",
+	"endpoint.credentials":   "Эндпоинты проверяются с basic auth сервера, отправьте URL без учётных данных",
+	"endpoint.removed":       "Эндпоинт %s сервера %s удалён",
+	"endpoint.removed_all":   "Эндпоинты %s удалены",
+	"endpoint.mode_any":      "%s недоступен при отказе любого эндпоинта",
+	"endpoint.mode_all":      "%s недоступен при отказе всех эндпоинтов и частично доступен при отказе некоторых",
+
+	"schedule.set":        "%s проверяется по расписанию %s (%s)",
+	"schedule.default":    "%s проверяется по расписанию по умолчанию (%s)",
+	"schedule.not_exists": "Неизвестное расписание %s, доступны: %s",
+
+	"soft404.on":  "Ответы %s со страницей ошибки считаются неудачными",
+	"soft404.off": "Распознавание страниц ошибок %s сброшено, по умолчанию (выключено)",
+
+	"jsoncheck.set":       "Проверка %s не проходит, если не выполнено %s",
+	"jsoncheck.off":       "JSON-проверки %s сброшены, по умолчанию (нет)",
+	"jsoncheck.path_off":  "JSON-проверки %s сброшены для %s",
+	"jsoncheck.not_found": "У %s нет JSON-проверок %s",
+	"jsoncheck.invalid":   "%v. Пример: /setjsoncheck api queue_depth < 5000, сравнения < <= > >= ==",
+
+	"retries.set":     "Таймауты и разрывы соединения %s повторяются до %d раз с интервалом %v в пределах %v проверки",
+	"retries.off":     "Повторы %s сброшены, по умолчанию (без повторов)",
+	"retries.invalid": "Количество от 0 до %d, интервал — длительность до %v, например 2s",
+
+	"backoff.set":         "Проверки %s реже после %v недоступности, до одной в %v",
+	"backoff.off":         "Разрежение проверок %s выключено",
+	"backoff.default":     "Разрежение проверок %s по умолчанию: реже после %v недоступности, до одной в %v",
+	"backoff.default_off": "Разрежение проверок %s по умолчанию (выключено)",
+	"backoff.invalid":     "Длительности должны быть положительными, например /setbackoff api 1h 15m",
+
+	"proto.fail": "Проверка %s не пройдет, если протокол ниже %s",
+	"proto.warn": "Если протокол %s ниже %s, будет отправлено одно предупреждение",
+	"proto.off":  "Минимальный протокол %s сброшен, по умолчанию (любой протокол)",
+
+	"compression.on":  "Если текстовые ответы %s не сжаты, будет отправлено одно предупреждение",
+	"compression.off": "Проверка сжатия %s сброшена, по умолчанию (выключена)",
+
+	"security.on":  "Заголовки безопасности %s проверяются, при пропаже заголовка будет отправлено предупреждение",
+	"security.off": "Заголовки безопасности %s не проверяются",
+
+	"sla.set":         "Цель SLA %s: %g%% за %s",
+	"sla.off":         "Цель SLA %s сброшена, по умолчанию (без цели)",
+	"sla.invalid":     "Цель должна быть процентом меньше 100, например 99.9, окно - длительностью, например 12h или 30d, до 90d",
+	"sla.empty":       "Нет серверов с целями SLA, задайте цель командой /setsla",
+	"sla.title":       "Цели SLA:\n",
+	"sla.status":      "%.3f%% при цели %g%% за %s, остаток бюджета ошибок %.1f%%\n",
+	"sla.no_data":     "проверок еще нет, цель %g%% за %s\n",
+	"sla.reliability": "  MTTR %s, MTBF %s\n",
+
+	"expectedip.fail":    "Проверка %s не пройдет при подключении по адресу вне %s",
+	"expectedip.warn":    "Если %s подключён по новому адресу вне %s, будет отправлено одно предупреждение",
+	"expectedip.off":     "Ожидаемые адреса %s сброшены, по умолчанию (любой адрес)",
+	"expectedip.invalid": "%v. Укажите адреса и CIDR IPv4 или IPv6 через запятую, например 203.0.113.0/24,2001:db8::/32",
+
+	"ssl.invalid_host":     "Неверный адрес %s, укажите host:port, например mail.example.com:465",
+	"ssl.invalid_starttls": "Неизвестный протокол STARTTLS, используйте один из: %s",
+	"ssl.http_only":        "/%s применяется только к HTTP-проверкам, %s — SSL-проверка",
+
+	"ssh.invalid_host": "Неверный адрес %s, укажите host:port, например example.com:22",
+	"ssh.http_only":    "/%s применяется только к HTTP-проверкам, %s — SSH-проверка",
+
+	"pin.set":     "Открытый ключ %s закреплен: %s",
+	"pin.pending": "Открытый ключ %s будет закреплен при следующей проверке",
+	"pin.cleared": "Закрепление ключа %s сброшено, по умолчанию (без закрепления)",
+	"pin.invalid": "Отпечаток должен быть SHA-256 открытого ключа в hex или base64, current или clear",
+
+	"probes.set":     "%s проверяется из %s и ботом",
+	"probes.off":     "Локации %s сброшены, по умолчанию (проверяется только ботом)",
+	"probes.invalid": "Локации должны быть перечислены через запятую без пробелов, local зарезервирована",
+
+	"maintenance.on":      "🛠 Обслуживание до %s, проверки продолжаются, но оповещения не отправляются",
+	"maintenance.off":     "Обслуживание завершено, сводка будет отправлена после следующего цикла проверок",
+	"maintenance.invalid": "Длительность должна быть вида 30m или 2h, или off",
+	"maintenance.failed":  "Не удалось сохранить обслуживание",
+	"maintenance.banner":  "🛠 Обслуживание до %s, оповещения не отправляются\n\n",
+
+	"keep.usage":         "Отправьте /keep в ответ на сообщение бота, которое нужно сохранить",
+	"keep.not_scheduled": "Сообщение не будет удалено",
+	"keep.done":          "Сообщение сохранено",
+
+	"heartbeat.message": "✅ %d/%d серверов доступны, бот работает, последняя проверка %s",
+	"heartbeat.on":      "Сообщение без звука отправляется по расписанию %s, когда все серверы доступны",
+	"heartbeat.off":     "Сообщение о состоянии отключено",
+	"heartbeat.failed":  "Не удалось сохранить настройку",
+
+	"backup.caption": "💾 Резервная копия, %s, %s",
+
+	"restore.no_document":     "Отправьте /restore ответом на документ с резервной копией",
+	"restore.too_large":       "Документ больше %d МБ",
+	"restore.download_failed": "Не удалось скачать документ",
+	"restore.invalid":         "Документ не является резервной копией: %s",
+	"restore.confirm":         "Восстановить из %s? Будет добавлено %d, изменено %d и удалено %d серверов, настройки тоже заменятся. Текущие данные сохранятся для /restore undo",
+	"restore.failed":          "Не удалось восстановить данные",
+	"restore.done":            "Данные восстановлены, проверяется серверов: %d",
+	"restore.undo_confirm":    "Вернуть данные, замененные последним восстановлением?",
+	"restore.nothing_to_undo": "Нет восстановления для отмены",
+	"restore.undone":          "Восстановление отменено",
+
+	"subscribe.private_only":   "Подписки личные, отправьте команду в личном чате с ботом",
+	"subscribe.server":         "Оповещения о падении и восстановлении %s будут приходить сюда",
+	"subscribe.all":            "Оповещения о падении и восстановлении всех серверов будут приходить сюда",
+	"subscribe.tag":            "Оповещения о падении и восстановлении серверов с тегом %s будут приходить сюда",
+	"subscribe.invalid_tag":    "Тег должен быть словом из букв, цифр, - и _, например tag:prod",
+	"subscribe.failed":         "Не удалось сохранить подписку",
+	"subscribe.not_subscribed": "Вы не подписаны на %s",
+	"subscribe.none":           "У вас нет подписок",
+	"subscribe.list":           "Ваши подписки:",
+	"subscribe.list_all":       "• все серверы",
+	"subscribe.list_tag":       "• серверы с тегом %s",
+	"unsubscribe.server":       "Оповещения о %s больше не будут приходить сюда",
+	"unsubscribe.tag":          "Оповещения о серверах с тегом %s больше не будут приходить сюда",
+	"unsubscribe.all":          "Оповещения о всех серверах больше не будут приходить сюда, подписки на отдельные серверы сохранены",
+
+	"preferences.title":             "Личные оповещения, в чат оповещений всегда приходят все. Нажмите на категорию, чтобы переключить её.",
+	"preferences.quiet":             "Тихие часы: %s-%s",
+	"preferences.no_quiet":          "Тихие часы не заданы, задайте их командой /preferences quiet 22:00-08:00",
+	"preferences.quiet_invalid":     "Тихие часы задаются как 22:00-08:00",
+	"preferences.failed":            "Не удалось сохранить настройки",
+	"preferences.category.down":     "Падение и восстановление",
+	"preferences.category.warnings": "Предупреждения",
+	"preferences.category.sla":      "SLA",
+	"preferences.mode.on":           "✅ всегда",
+	"preferences.mode.awake":        "🌙 вне тихих часов",
+	"preferences.mode.off":          "❌ выключено",
+
+	"testalert.sending":   "Отправка тестового оповещения %s о %s...",
+	"testalert.result":    "Результаты тестового оповещения:",
+	"testalert.delivered": "✅ %s",
+	"testalert.failed":    "❌ %s: %v",
+
+	"simulate.on":      "🧪 Сервер %s недоступен в симуляции следующие %d проверок, оповещения помечены как симуляция",
+	"simulate.off":     "Симуляция %s отменена, следующая проверка настоящая",
+	"simulate.invalid": "Число проверок должно быть от 1 до %d",
+	"list.simulated":   "🧪 симуляция",
+	"list.verbose":     "    проверен %s, ответ за %v, %s за 24ч\n",
+	"list.unchecked":   "    ещё не проверен\n",
+	"list.score":       "оценка %d",
+	"list.changes":     "смен состояния за неделю: %d",
+
+	"uptimehistory.no_data": "Пока нет доступности %s по дням",
+	"uptimehistory.title":   "Доступность %s за %s: %s\n",
+	"uptimehistory.legend":  "🟩 100% 🟨 99%+ 🟥 ниже 99% 🟦 неполный день ⬜ нет проверок",
+
+	"chart.hours_invalid": "Количество часов должно быть положительным числом",
+	"chart.no_checks":     "Нет проверок %s за последние %s",
+	"chart.failed":        "Не удалось построить график %s",
+	"chart.caption":       "Время ответа %s с %s, %s",
+	"chart.pruned":        "Старые проверки удалены, график охватывает меньше %s",
+	"chart.stats":         "мин %v, сред %v, p95 %v",
+
+	"chat.rejected": "Бот принимает команды только в настроенном чате",
+	"chat.migrated": "Чат преобразован в супергруппу, id чата изменился с %d на %d. Обновите id чата в настройках бота",
+
+	"confirm.timeout":      "⌛ Не подтверждено вовремя",
+	"confirm.expired":      "Время подтверждения истекло",
+	"confirm.other_user":   "Подтвердить может только автор команды",
+	"confirm.cancelled":    "Отменено",
+	"confirm.confirmed":    "Подтверждено",
+	"confirm.confirmed_by": "Подтверждено %s",
+
+	"callback.unauthorized": "Нет доступа",
+	"callback.stale":        "Уже обработано",
+
+	"add.ask_url":           "Отправьте URL сервера, например: github.com",
+	"add.invalid_url":       "Неверный URL, отправьте URL сервера еще раз",
+	"add.invalid_grace":     "Неверный льготный период, укажите длительность, например grace=30m",
+	"add.ask_name":          "Отправьте имя сервера",
+	"add.invalid_name":      "Имя должно быть одним словом или в кавычках, отправьте имя сервера еще раз",
+	"add.expired":           "Диалог устарел, отправьте /add еще раз",
+	"add.cancelled":         "Добавление сервера отменено",
+	"add.url_first":         "Сначала отправьте URL сервера",
+	"add.undo_removed":      "Сервер удален",
+	"add.checking":          "⏳ Проверка...",
+	"add.first_ok":          "✅ %d %s за %s",
+	"add.first_ssl":         "✅ TLS-рукопожатие за %s, сертификат истекает через %s",
+	"add.first_ssh":         "✅ %s за %s",
+	"add.first_failed":      "⚠️ Первая проверка не прошла: %s. Оповещения начнутся после %d неудачных проверок",
+	"add.first_grace":       "⚠️ Первая проверка не прошла: %s. Оповещения начнутся после %d неудачных проверок по окончании льготного периода через %s",
+	"add.undone":            "Добавление сервера %s отменено",
+	"add.threshold_invalid": "Порог должен быть числом, 0 для общего порога",
+	"add.credentials":       "🔑 Учётные данные убраны из URL и будут отправляться как basic auth. Сообщение с ними удаляется, если у бота есть права",
+
+	"default.soft404":     "распознавание страниц ошибок",
+	"default.json":        "JSON-проверки",
+	"default.proto":       "минимальный протокол",
+	"default.compression": "проверка сжатия",
+	"default.ips":         "проверка адресов",
+	"default.sla":         "SLA",
+	"default.pin":         "закрепленный ключ",
+	"default.probes":      "локации",
+	"default.endpoints":   "эндпоинты",
+
+	"details.paused_answer":    "Приостановлено",
+	"details.resumed_answer":   "Возобновлено",
+	"details.ask_threshold":    "Отправьте порог оповещений для %s, 0 для общего порога",
+	"details.remove_confirm":   "⚠️ Удалить сервер %s?",
+	"details.threshold_failed": "Не удалось изменить порог оповещений %s",
+	"details.url":              "URL: %s\n",
+	"details.tags":             "Теги: %s\n",
+	"details.auth":             "Авторизация: настроена (%s)\n",
+	"details.soft404":          "Обнаружение страниц ошибок: включено\n",
+	"details.json":             "JSON-проверка: %s\n",
+	"details.proto":            "Протокол: %s\n",
+	"details.compression":      "Сжатие: проверяется\n",
+	"details.uncompressed":     "⚠️ Сжатие: ответы не сжаты\n",
+	"details.security":         "Заголовки безопасности:\n",
+	"details.sla":              "SLA: %s",
+	"details.pin":              "Закрепленный ключ: %s\n",
+	"details.pin_pending":      "Закрепленный ключ: будет получен при следующей проверке\n",
+	"details.pin_mismatch":     "⚠️ Полученный ключ: %s\n",
+	"details.locations":        "Локации:\n",
+	"details.location":         "%s %s %v, %s",
+	"details.location_no_data": "⬜ %s нет отчетов\n",
+	"details.proto_fail":       "Минимальный протокол: %s, ниже него проверка не проходит\n",
+	"details.proto_warn":       "Минимальный протокол: %s, ниже него отправляется предупреждение\n",
+	"details.remote_ip":        "Адрес подключения: %s\n",
+	"details.ssl":              "Проверка: только TLS-рукопожатие\n",
+	"details.ssl_starttls":     "Проверка: TLS-рукопожатие после STARTTLS (%s)\n",
+	"details.ssl_expiry":       "Сертификат истекает: %s (через %s)\n",
+	"details.ssl_expired":      "⚠️ Сертификат истек: %s\n",
+	"details.ssl_issuer":       "Издатель сертификата: %s\n",
+	"details.ssh":              "Проверка: SSH-баннер, без аутентификации\n",
+	"details.ssh_banner":       "Баннер: %s\n",
+	"details.ssh_expect":       "Ожидаемый баннер: содержит %s\n",
+	"details.expect_fail":      "Ожидаемые адреса: %s, вне них проверка не проходит\n",
+	"details.expect_warn":      "Ожидаемые адреса: %s, вне них отправляется предупреждение\n",
+	"details.last_checked":     "Последняя проверка: %s\n",
+	"details.attempt_ok":       "Последняя проверка прошла с попытки %d/%d\n",
+	"details.attempt_failed":   "Последняя проверка не прошла после %d попыток\n",
+	"details.retries":          "Повторы: %d, интервал %v\n",
+	"details.backoff":          "🐢 Проверка раз в %s из-за длительной недоступности\n",
+	"details.backoff_set":      "Разрежение: после %s недоступности, до одной проверки в %s\n",
+	"details.backoff_off":      "Разрежение: выключено\n",
+	"details.last_success":     "Последний успех: %s\n",
+	"details.last_failure":     "Последний сбой: %s\n",
+	"details.threshold":        "Порог оповещений: %d\n",
+	"details.threshold_global": "Порог оповещений: общий\n",
+	"details.downtime":         "Простой: %s в этом месяце, %s всего\n",
+	"details.state_changes":    "Смены состояния: %d сегодня, %d за неделю, %d за 30 дней\n",
+	"details.streak_up":        "Работает %s\n",
+	"details.streak_down":      "Недоступен %s\n",
+	"details.record_up":        "Рекорд непрерывной работы: %s (%s – %s)\n",
+	"details.record_down":      "Самый долгий простой: %s (%s – %s)\n",
+	"details.recent_errors":    "Последние ошибки:\n",
+	"details.recent_error":     "• %s ×%d, последняя %s\n",
+	"details.grace":            "⏳ Льготный период: оповещения начнутся через %s\n",
+	"details.schedule":         "Расписание: %s (%s)\n",
+	"details.schedule_default": "Расписание: по умолчанию\n",
+	"details.score":            "Оценка здоровья: %d/100\n",
+	"details.score_change":     "Оценка здоровья: %d/100, %+d за 24ч\n",
+	"details.reliability":      "MTTR %s, MTBF %s за последние %s\n",
+	"details.na":               "н/д",
+	"details.pruned":           "Инциденты до %s удалены, MTTR и MTBF охватывают меньший период\n",
+	"details.ips_any":          "Адреса, недоступен если недоступен любой:\n",
+	"details.ips_all":          "Адреса, недоступен если недоступны все:\n",
+	"details.ip":               "%s %s %v",
+	"details.endpoints_any":    "Эндпоинты, недоступен если недоступен любой:\n",
+	"details.endpoints_all":    "Эндпоинты, недоступен если недоступны все:\n",
+	"details.endpoint":         "%s %s [%s] %v, %s",
+	"details.endpoint_no_data": "⬜ %s [%s] ещё не проверен\n",
+	"details.daily_uptime":     "Доступность за %s: %s\n%s\n",
+	"details.days_invalid":     "Количество дней должно быть положительным числом",
+	"details.defaults":         "Не задано (по умолчанию): %s\n",
+	"details.paused":           "Приостановлен\n",
+	"details.snoozed":          "Отложен до %s\n",
+	"details.simulated":        "🧪 Недоступен в симуляции ещё %d проверок\n",
+	"details.simulated_ended":  "🧪 Симуляция завершена, сбой закончится при следующей успешной проверке\n",
+
+	"incident.since":     "Недоступен с: %s\n",
+	"incident.not_acked": "Не принят\n",
+	"incident.acked":     "Принят %s %s\n",
+	"incident.comment":   "Комментарий: %s\n",
+
+	"inline.summary": "✅ %d доступно, ❌ %d недоступно",
+
+	"super.already":       "%s уже суперпользователь",
+	"super.add_failed":    "Не удалось добавить суперпользователя %s",
+	"super.added":         "Суперпользователь %s добавлен",
+	"super.flag":          "%s задан флагом --super и не может быть удален в боте, измените флаг",
+	"super.remove_self":   "⚠️ Вы собираетесь удалить себя (%s) из суперпользователей",
+	"super.remove_failed": "Не удалось удалить суперпользователя %s",
+	"super.not_super":     "%s не суперпользователь",
+	"super.last":          "%s последний суперпользователь и не может быть удален",
+	"super.removed":       "Суперпользователь %s удален",
+	"super.list_flag":     "%s (флаг)\n",
+	"super.list_runtime":  "%s (добавлен в боте)\n",
+	"super.none":          "Нет суперпользователей",
+
+	"notifications.count_invalid": "Количество должно быть положительным числом",
+	"notifications.none":          "Нет оповещений",
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"github.com/go-pkgz/lgr"
+	"sync"
+	"time"
+)
+
+var mutex sync.Mutex
+var debug bool
+var revertTimer *time.Timer
+
+// Setup configures the standard logger with normal or debug options, can be called again at runtime
+func Setup(dbg bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	stopRevertTimer()
+	setup(dbg)
+}
+
+// EnableDebug switches to debug logging and reverts to normal logging after revertAfter
+func EnableDebug(revertAfter time.Duration) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	stopRevertTimer()
+	setup(true)
+
+	revertTimer = time.AfterFunc(revertAfter, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		setup(false)
+		revertTimer = nil
+	})
+}
+
+func IsDebug() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return debug
+}
+
+func setup(dbg bool) {
+	logOpts := []lgr.Option{lgr.Msec, lgr.LevelBraces, lgr.StackTraceOnError}
+	if dbg {
+		logOpts = []lgr.Option{lgr.Debug, lgr.CallerFile, lgr.CallerFunc, lgr.Msec, lgr.LevelBraces, lgr.StackTraceOnError}
+	}
+	// credentials in urls and configured secrets never reach the log output,
+	// lgr calls every function of the mapper, so the rest keep the text as is
+	logOpts = append(logOpts, lgr.Map(lgr.Mapper{
+		MessageFunc: redact.Text,
+		ErrorFunc:   keep,
+		WarnFunc:    keep,
+		InfoFunc:    keep,
+		DebugFunc:   keep,
+		CallerFunc:  keep,
+		TimeFunc:    keep,
+	}))
+	lgr.SetupStdLogger(logOpts...)
+	debug = dbg
+}
+
+func keep(s string) string {
+	return s
+}
+
+func stopRevertTimer() {
+	if revertTimer != nil {
+		revertTimer.Stop()
+		revertTimer = nil
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+)
+
+var ChecksPerformed = expvar.NewInt("checks_performed")
+var ChecksSkipped = expvar.NewInt("checks_skipped")
+var CheckCycles = expvar.NewInt("check_cycles")
+var TelegramSends = expvar.NewInt("telegram_sends")
+var StorageWrites = expvar.NewInt("storage_writes")
+var QueueDepth = expvar.NewInt("telegram_queue_depth")
+var TelegramSendErrors = expvar.NewInt("telegram_send_errors")
+var StorageWriteErrors = expvar.NewInt("storage_write_errors")
+var CycleDuration = expvar.NewInt("check_cycle_duration_ms")
+var SchedulerLag = expvar.NewInt("scheduler_lag_ms")
+
+// recentSize is the number of the last operations used to calculate error rates
+const recentSize = 20
+
+var RecentTelegramSends = NewRecent(recentSize)
+var RecentStorageWrites = NewRecent(recentSize)
+
+func init() {
+	expvar.Publish("goroutines", expvar.Func(func() any {
+		return runtime.NumGoroutine()
+	}))
+}
+
+// StartDebugServer serves pprof and expvar on listen in background, listen must not be public
+func StartDebugServer(listen string) {
+	go func() {
+		log.Printf("[INFO] Debug server listening on %s", listen)
+		if err := http.ListenAndServe(listen, debugMux()); err != nil {
+			log.Printf("[ERROR] Debug server failed: %v", err)
+		}
+	}()
+}
+
+// debugMux routes pprof and expvar. The command line is not served, it contains tokens and passwords passed by flags.
+func debugMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/vars", varsHandler)
+	return mux
+}
+
+// varsHandler writes expvar variables as expvar.Handler does, except the cmdline variable
+func varsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprintf(w, "{\n")
+	var first = true
+	expvar.Do(func(kv expvar.KeyValue) {
+		if kv.Key == "cmdline" {
+			return
+		}
+		if !first {
+			fmt.Fprintf(w, ",\n")
+		}
+		first = false
+		key, _ := json.Marshal(kv.Key)
+		fmt.Fprintf(w, "%s: %s", key, kv.Value)
+	})
+	fmt.Fprintf(w, "\n}\n")
+}
+
+// TelegramTransport counts send* requests to the Telegram Bot API and their errors
+type TelegramTransport struct {
+	Transport http.RoundTripper
+}
+
+func (t TelegramTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var path = request.URL.Path
+	if method := path[strings.LastIndex(path, "/")+1:]; !strings.HasPrefix(method, "send") {
+		return t.Transport.RoundTrip(request)
+	}
+
+	TelegramSends.Add(1)
+	response, err := t.Transport.RoundTrip(request)
+
+	var failed = err != nil || response.StatusCode >= http.StatusBadRequest
+	if failed {
+		TelegramSendErrors.Add(1)
+	}
+	RecentTelegramSends.Add(failed)
+
+	return response, err
+}
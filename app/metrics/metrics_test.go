@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDebugServerHidesCommandLine(t *testing.T) {
+	var server = httptest.NewServer(debugMux())
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/debug/vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	var vars map[string]json.RawMessage
+	if err := json.NewDecoder(response.Body).Decode(&vars); err != nil {
+		t.Fatalf("vars are not valid JSON: %v", err)
+	}
+	if _, ok := vars["cmdline"]; ok {
+		t.Error("vars contain cmdline")
+	}
+	for _, name := range []string{"checks_performed", "check_cycles", "memstats", "goroutines"} {
+		if _, ok := vars[name]; !ok {
+			t.Errorf("vars don't contain %s", name)
+		}
+	}
+	for _, value := range vars {
+		if strings.Contains(string(value), os.Args[0]) {
+			t.Errorf("vars contain the command line: %s", value)
+		}
+	}
+
+	response, err = http.Get(server.URL + "/debug/pprof/cmdline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode == http.StatusOK {
+		t.Error("command line is served by pprof")
+	}
+}
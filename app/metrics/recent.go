@@ -0,0 +1,46 @@
+package metrics
+
+import "sync"
+
+// Recent keeps outcomes of the last operations to detect error spikes
+type Recent struct {
+	mutex    sync.Mutex
+	outcomes []bool
+	next     int
+	count    int
+}
+
+func NewRecent(size int) *Recent {
+	return &Recent{outcomes: make([]bool, size)}
+}
+
+// Add records outcome of the operation, the oldest outcome is dropped when the window is full
+func (r *Recent) Add(failed bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.outcomes[r.next] = failed
+	r.next = (r.next + 1) % len(r.outcomes)
+	if r.count < len(r.outcomes) {
+		r.count++
+	}
+}
+
+// ErrorRate returns share of failed operations in the window and the number of operations in it
+func (r *Recent) ErrorRate() (float64, int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.count == 0 {
+		return 0, 0
+	}
+
+	var failed int
+	for i := 0; i < r.count; i++ {
+		if r.outcomes[i] {
+			failed++
+		}
+	}
+
+	return float64(failed) / float64(r.count), r.count
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// auditLimit is the maximal number of records kept in the audit log regardless of retention
+const auditLimit = 1000
+
+// Record is an entry of the notification audit log
+type Record struct {
+	Time    time.Time `json:"time"`
+	Channel string    `json:"channel"`
+	Type    EventType `json:"type"`
+	Server  string    `json:"server"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// AuditLog keeps records of sent notifications in a JSON file, records older than retention are pruned
+type AuditLog struct {
+	Path      string
+	Retention time.Duration
+
+	mutex sync.Mutex
+}
+
+// Add appends the record and prunes old records
+func (a *AuditLog) Add(record Record) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var records = append(a.read(), record)
+
+	var since = time.Now().Add(-a.Retention)
+	var start = 0
+	for start < len(records) && (records[start].Time.Before(since) || len(records)-start > auditLimit) {
+		start++
+	}
+	records = records[start:]
+
+	data, err := json.Marshal(records)
+	if err == nil {
+		err = os.WriteFile(a.Path, data, 0o644)
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to save notification audit log: %v", err)
+	}
+}
+
+// Records returns records of the server or all records if server is empty, newest last
+func (a *AuditLog) Records(server string) []Record {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var records []Record
+	for _, record := range a.read() {
+		if server == "" || record.Server == server {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func (a *AuditLog) read() []Record {
+	data, err := os.ReadFile(a.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("[ERROR] Failed to read notification audit log: %v", err)
+		return nil
+	}
+
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		log.Printf("[ERROR] Failed to decode notification audit log: %v", err)
+		return nil
+	}
+	return records
+}
+
+// audited records each event sent by the notifier to the audit log
+type audited struct {
+	Notifier
+	auditLog *AuditLog
+}
+
+// Audited returns notifier which records sent events to the audit log, the notifier is returned as is if log is nil.
+// Test alerts are not recorded.
+func Audited(notifier Notifier, auditLog *AuditLog) Notifier {
+	if auditLog == nil {
+		return notifier
+	}
+	return audited{Notifier: notifier, auditLog: auditLog}
+}
+
+func (a audited) Send(event Event) error {
+	if event.Test {
+		return a.Notifier.Send(event)
+	}
+
+	return a.auditLog.Notification(a.Name(), event.Type, event.Server, func() error {
+		return a.Notifier.Send(event)
+	})
+}
+
+// Notification calls send and records the notification of the type about the server sent to the channel,
+// it is used for messages sent without notifiers. The log may be nil, then the notification is not recorded.
+func (a *AuditLog) Notification(channel string, eventType EventType, server string, send func() error) error {
+	err := send()
+	if a == nil {
+		return err
+	}
+
+	var record = Record{Time: time.Now(), Channel: channel, Type: eventType, Server: server}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	a.Add(record)
+
+	return err
+}
@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Discord posts events as embeds to a Discord webhook
+type Discord struct {
+	WebhookUrl string
+	Client     *http.Client
+	Backoff    time.Duration
+}
+
+type discordMessage struct {
+	Embeds          []discordEmbed         `json:"embeds"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+type discordEmbed struct {
+	Title     string         `json:"title"`
+	Url       string         `json:"url,omitempty"`
+	Color     int            `json:"color"`
+	Fields    []discordField `json:"fields"`
+	Timestamp string         `json:"timestamp"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func NewDiscord(webhookUrl string, timeout time.Duration) *Discord {
+	return &Discord{
+		WebhookUrl: webhookUrl,
+		Client:     &http.Client{Timeout: timeout},
+		Backoff:    time.Second,
+	}
+}
+
+func (d *Discord) Name() string {
+	return "discord"
+}
+
+func (d *Discord) Send(event Event) error {
+	body, err := json.Marshal(newDiscordMessage(event))
+	if err != nil {
+		return err
+	}
+
+	return retry(d.Name(), d.Backoff, func() (int, error) {
+		return postJson(d.Client, d.WebhookUrl, body, nil)
+	})
+}
+
+func newDiscordMessage(event Event) discordMessage {
+	var server = discordEscaper.Replace(event.Server)
+	var embed = discordEmbed{
+		Url:       event.Url,
+		Timestamp: event.Time.Format(time.RFC3339),
+	}
+	switch event.Type {
+	case EventDown:
+		embed.Title, embed.Color = fmt.Sprintf("❌ Server %s is down", server), 0xd50200
+	case EventUp:
+		embed.Title, embed.Color = fmt.Sprintf("✅ Server %s is up", server), 0x2eb886
+	default:
+		embed.Title, embed.Color = fmt.Sprintf("Server %s: %s", server, event.Type), 0x808080
+	}
+	if event.Test {
+		embed.Title = testPrefix + embed.Title
+	}
+	if event.Simulated {
+		embed.Title += simulatedSuffix
+	}
+
+	var statusCode = "-"
+	if event.StatusCode != 0 {
+		statusCode = fmt.Sprint(event.StatusCode)
+	}
+	embed.Fields = []discordField{
+		{Name: "Status code", Value: statusCode, Inline: true},
+		{Name: "Response time", Value: event.ResponseTime.Round(time.Millisecond).String(), Inline: true},
+		{Name: "Duration", Value: formatDuration(event.Duration), Inline: true},
+	}
+	if event.Error != "" {
+		embed.Fields = append(embed.Fields, discordField{Name: "Error", Value: discordEscaper.Replace(event.Error)})
+	}
+	if event.BodyExcerpt != "" {
+		embed.Fields = append(embed.Fields, discordField{Name: "Response", Value: discordEscaper.Replace(event.BodyExcerpt)})
+	}
+
+	return discordMessage{
+		Embeds:          []discordEmbed{embed},
+		AllowedMentions: discordAllowedMentions{Parse: []string{}},
+	}
+}
+
+// discordEscaper escapes Discord markdown
+var discordEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`, "[", `\[`, "]", `\]`,
+)
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Email sends down and up events as plain text emails via SMTP, STARTTLS is used if the server supports it
+type Email struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+	To       []string
+	Timeout  time.Duration
+	Language i18n.Lang
+}
+
+func (e *Email) Name() string {
+	return "email"
+}
+
+// Send connects to the SMTP server for each event, so a failed connection is retried with the next event
+func (e *Email) Send(event Event) error {
+	if event.Type != EventDown && event.Type != EventUp {
+		return nil
+	}
+
+	var address = net.JoinHostPort(e.Host, fmt.Sprint(e.Port))
+	conn, err := net.DialTimeout("tcp", address, e.Timeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(e.Timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: e.Host}); err != nil {
+			return err
+		}
+	}
+	if e.User != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.User, e.Password, e.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(e.From); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write([]byte(e.message(event))); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (e *Email) message(event Event) string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", e.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(e.To, ", "))
+	var tag = strings.ToUpper(string(event.Type))
+	if event.Test {
+		tag = "TEST " + tag
+	}
+	var subject = oneLine(event.Server)
+	if event.Simulated {
+		subject += simulatedSuffix
+	}
+	fmt.Fprintf(&message, "Subject: [%s] %s\r\n", tag, subject)
+	fmt.Fprintf(&message, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+
+	fmt.Fprintf(&message, "%s\r\n\r\n", AlertText(e.Language, event))
+	fmt.Fprintf(&message, "Server: %s\r\n", event.Server)
+	fmt.Fprintf(&message, "URL: %s\r\n", event.Url)
+	if event.Error != "" {
+		fmt.Fprintf(&message, "Error: %s\r\n", event.Error)
+	}
+	fmt.Fprintf(&message, "Down since: %s\r\n", event.Since.Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Duration: %s\r\n", formatDuration(event.Duration))
+
+	return message.String()
+}
+
+// oneLine removes line breaks, so the value can't inject headers
+func oneLine(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,114 @@
+package notify
+
+import "time"
+
+type EventType string
+
+const (
+	EventDown EventType = "down"
+	EventUp   EventType = "up"
+
+	// EventEscalated and EventEscalationResolved are sent to the escalation chat only
+	EventEscalated          EventType = "escalated"
+	EventEscalationResolved EventType = "escalationResolved"
+
+	// EventProtocol is sent once when the server is served over a protocol lower than its minimum
+	EventProtocol EventType = "protocol"
+	// EventCompression is sent once when text responses of the server are not compressed
+	EventCompression EventType = "compression"
+	// EventSecurityHeaders is sent when security headers present in the previous check are missing
+	EventSecurityHeaders EventType = "securityHeaders"
+
+	// EventSlaBreach is sent when availability drops below the SLA target, EventSlaRecovered when it is back
+	EventSlaBreach    EventType = "slaBreach"
+	EventSlaRecovered EventType = "slaRecovered"
+
+	// EventPinMismatch is sent when the public key of the server doesn't match the pinned fingerprint
+	EventPinMismatch EventType = "pinMismatch"
+	// EventUnexpectedIp is sent once for each address outside the expected ones the server is connected on
+	EventUnexpectedIp EventType = "unexpectedIp"
+
+	// EventAgentOffline is sent when a probe agent stops reporting, EventAgentOnline when it reports again
+	EventAgentOffline EventType = "agentOffline"
+	EventAgentOnline  EventType = "agentOnline"
+
+	// EventMaintenanceEnded is sent when the global maintenance ends with servers which are still down
+	EventMaintenanceEnded EventType = "maintenanceEnded"
+
+	// EventIncidentSummary is sent to the chat after the up event when the incident summary is enabled
+	EventIncidentSummary EventType = "incidentSummary"
+
+	// EventStalled, EventChecksSkipped, EventSlowCycle and EventApiChange are types of warnings and announcements
+	// sent as plain messages, they are only recorded in the audit log
+	EventStalled       EventType = "stalled"
+	EventChecksSkipped EventType = "checksSkipped"
+	EventSlowCycle     EventType = "slowCycle"
+	EventApiChange     EventType = "apiChange"
+)
+
+// Event is an alert event sent to notification channels
+type Event struct {
+	Type   EventType `json:"type"`
+	Server string    `json:"server"`
+	Url    string    `json:"url"`
+	Error  string    `json:"error,omitempty"`
+
+	// ErrorCategory is the category of the request error like timeout or dns, BodyExcerpt is the beginning
+	// of the response body of the failed check, both are set for down events
+	ErrorCategory string `json:"errorCategory,omitempty"`
+	BodyExcerpt   string `json:"bodyExcerpt,omitempty"`
+
+	StatusCode   int           `json:"statusCode,omitempty"`
+	ResponseTime time.Duration `json:"responseTime"`
+
+	Time time.Time `json:"time"`
+
+	// Since is the time the server went down
+	Since    time.Time     `json:"since"`
+	Duration time.Duration `json:"duration"`
+
+	// AckBy is the user who acknowledged the incident, AckDelay is the time from the alert to the acknowledgement
+	AckBy    string        `json:"ackBy,omitempty"`
+	AckDelay time.Duration `json:"ackDelay,omitempty"`
+
+	// Proto is the negotiated protocol of the protocol event, ExpectedProto is the minimum set for the server
+	Proto         string `json:"proto,omitempty"`
+	ExpectedProto string `json:"expectedProto,omitempty"`
+
+	// Headers are the security headers missing since the previous check
+	Headers []string `json:"headers,omitempty"`
+
+	// Availability over SlaWindow, SlaTarget and ErrorBudget left in percent are set for SLA events
+	Availability float64       `json:"availability,omitempty"`
+	SlaTarget    float64       `json:"slaTarget,omitempty"`
+	SlaWindow    time.Duration `json:"slaWindow,omitempty"`
+	ErrorBudget  float64       `json:"errorBudget,omitempty"`
+
+	// Fingerprint is the presented public key fingerprint of the pin event, PinnedFingerprint is the pinned one
+	Fingerprint       string `json:"fingerprint,omitempty"`
+	PinnedFingerprint string `json:"pinnedFingerprint,omitempty"`
+
+	// Ip is the address the server was connected on of the unexpected address event, ExpectedIps are the expected CIDRs
+	Ip          string   `json:"ip,omitempty"`
+	ExpectedIps []string `json:"expectedIps,omitempty"`
+
+	// Agent is the location of the probe agent of agent events
+	Agent string `json:"agent,omitempty"`
+
+	// Servers are the servers still down when the maintenance ended
+	Servers []string `json:"servers,omitempty"`
+
+	// FailedChecks and Errors are the number of failed checks and distinct errors of the summarized incident
+	FailedChecks int      `json:"failedChecks,omitempty"`
+	Errors       []string `json:"errors,omitempty"`
+
+	// EndedUptime is the uptime streak ended by the incident of up and summary events, EndedRecord is set
+	// if it was the longest one
+	EndedUptime time.Duration `json:"endedUptime,omitempty"`
+	EndedRecord bool          `json:"endedRecord,omitempty"`
+
+	// Test is set for test alerts with fake data sent by /testalert
+	Test bool `json:"test,omitempty"`
+	// Simulated is set for alerts of failures simulated by /simulate
+	Simulated bool `json:"simulated,omitempty"`
+}
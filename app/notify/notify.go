@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Notifier sends alert events to a notification channel
+type Notifier interface {
+	Send(event Event) error
+	Name() string
+}
+
+const sendAttempts = 3
+
+// SendAll sends the event to the notifiers in background, so a slow or failing notifier
+// never delays the Telegram alert or other notifiers
+func SendAll(notifiers []Notifier, event Event) {
+	for _, notifier := range notifiers {
+		go func(notifier Notifier) {
+			if err := notifier.Send(event); err != nil {
+				log.Printf("[ERROR] Failed to send %s event of %s to %s: %v", event.Type, event.Server, notifier.Name(), err)
+			}
+		}(notifier)
+	}
+}
+
+// rateLimitError is returned by postJson on 429 response
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e rateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.retryAfter)
+}
+
+// retry calls send until it succeeds, retries with doubling backoff on 5xx responses and network errors,
+// and after the requested delay on 429 responses
+func retry(name string, backoff time.Duration, send func() (int, error)) error {
+	for attempt := 1; ; attempt++ {
+		statusCode, err := send()
+		if err == nil && statusCode < 300 {
+			log.Printf("[INFO] %s responded %d", name, statusCode)
+			return nil
+		}
+
+		if err == nil {
+			err = fmt.Errorf("status code %d", statusCode)
+		}
+		log.Printf("[WARN] %s attempt %d failed: %v", name, attempt, err)
+
+		var rateLimited rateLimitError
+		var isRateLimited = errors.As(err, &rateLimited)
+		if attempt == sendAttempts || !isRateLimited && statusCode >= 300 && statusCode < 500 {
+			return err
+		}
+
+		if isRateLimited {
+			time.Sleep(rateLimited.retryAfter)
+			continue
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// postJson posts the JSON body and returns the response status code
+func postJson(client *http.Client, url string, body []byte, headers map[string]string) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return response.StatusCode, rateLimitError{retryAfter: retryAfter(response)}
+	}
+
+	return response.StatusCode, nil
+}
+
+// retryAfter returns the delay requested by Retry-After header or retry_after field of JSON body used by Discord
+func retryAfter(response *http.Response) time.Duration {
+	var body struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if err := json.NewDecoder(io.LimitReader(response.Body, 64*1024)).Decode(&body); err == nil && body.RetryAfter > 0 {
+		return time.Duration(body.RetryAfter * float64(time.Second))
+	}
+
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return time.Second
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Slack posts events to a Slack incoming webhook
+type Slack struct {
+	WebhookUrl string
+	Channel    string
+	Client     *http.Client
+	Backoff    time.Duration
+}
+
+type slackMessage struct {
+	Channel     string            `json:"channel,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text,omitempty"`
+	Fields []slackText `json:"fields,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func NewSlack(webhookUrl, channel string, timeout time.Duration) *Slack {
+	return &Slack{
+		WebhookUrl: webhookUrl,
+		Channel:    channel,
+		Client:     &http.Client{Timeout: timeout},
+		Backoff:    time.Second,
+	}
+}
+
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+func (s *Slack) Send(event Event) error {
+	body, err := json.Marshal(newSlackMessage(s.Channel, event))
+	if err != nil {
+		return err
+	}
+
+	return retry(s.Name(), s.Backoff, func() (int, error) {
+		return postJson(s.Client, s.WebhookUrl, body, nil)
+	})
+}
+
+func newSlackMessage(channel string, event Event) slackMessage {
+	var server = slackEscaper.Replace(event.Server)
+	var title, color string
+	switch event.Type {
+	case EventDown:
+		title, color = fmt.Sprintf("❌ Server %s is down", server), "#d50200"
+	case EventUp:
+		title, color = fmt.Sprintf("✅ Server %s is up", server), "#2eb886"
+	default:
+		title, color = fmt.Sprintf("Server %s: %s", server, event.Type), "#808080"
+	}
+	if event.Test {
+		title = testPrefix + title
+	}
+	if event.Simulated {
+		title += simulatedSuffix
+	}
+
+	var fields = []slackText{
+		{Type: "mrkdwn", Text: "*URL*\n" + slackEscaper.Replace(event.Url)},
+		{Type: "mrkdwn", Text: "*Duration*\n" + formatDuration(event.Duration)},
+	}
+	if event.Error != "" {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: "*Error*\n" + slackEscaper.Replace(event.Error)})
+	}
+	if event.BodyExcerpt != "" {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: "*Response*\n" + slackEscaper.Replace(event.BodyExcerpt)})
+	}
+
+	return slackMessage{
+		Channel: channel,
+		Text:    title,
+		Attachments: []slackAttachment{{
+			Color: color,
+			Blocks: []slackBlock{
+				{Type: "section", Text: &slackText{Type: "mrkdwn", Text: "*" + title + "*"}},
+				{Type: "section", Fields: fields},
+			},
+		}},
+	}
+}
+
+// slackEscaper escapes control characters of Slack mrkdwn
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// testPrefix marks titles of test alerts, simulatedSuffix marks alerts of simulated failures
+const testPrefix = "🧪 TEST: "
+const simulatedSuffix = " (simulated)"
+
+// formatDuration formats duration rounded to seconds
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slackServer records messages posted to it and responds with statuses in turn, 200 when they run out
+func slackServer(t *testing.T, statuses ...int) (*httptest.Server, func() []slackMessage) {
+	var lock sync.Mutex
+	var messages []slackMessage
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message slackMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got content type %q", r.Header.Get("Content-Type"))
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		messages = append(messages, message)
+		if len(statuses) >= len(messages) {
+			w.WriteHeader(statuses[len(messages)-1])
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []slackMessage {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]slackMessage(nil), messages...)
+	}
+}
+
+func TestSlackPayload(t *testing.T) {
+	var tests = []struct {
+		name   string
+		event  Event
+		title  string
+		color  string
+		fields []string
+	}{
+		{
+			name:   "down",
+			event:  Event{Type: EventDown, Server: "api", Url: "https://api.example.com", Error: "timeout", Duration: 90 * time.Second},
+			title:  "❌ Server api is down",
+			color:  "#d50200",
+			fields: []string{"*URL*\nhttps://api.example.com", "*Duration*\n1m30s", "*Error*\ntimeout"},
+		},
+		{
+			name:   "up",
+			event:  Event{Type: EventUp, Server: "api", Url: "https://api.example.com", Duration: 5 * time.Minute},
+			title:  "✅ Server api is up",
+			color:  "#2eb886",
+			fields: []string{"*URL*\nhttps://api.example.com", "*Duration*\n5m0s"},
+		},
+		{
+			name:   "other",
+			event:  Event{Type: EventProtocol, Server: "api", Url: "https://api.example.com"},
+			title:  "Server api: protocol",
+			color:  "#808080",
+			fields: []string{"*URL*\nhttps://api.example.com", "*Duration*\n0s"},
+		},
+		{
+			name:   "escaped",
+			event:  Event{Type: EventDown, Server: "<api>", Url: "https://api.example.com/?a=1&b=2", BodyExcerpt: "<html>"},
+			title:  "❌ Server &lt;api&gt; is down",
+			color:  "#d50200",
+			fields: []string{"*URL*\nhttps://api.example.com/?a=1&amp;b=2", "*Duration*\n0s", "*Response*\n&lt;html&gt;"},
+		},
+		{
+			name:   "test",
+			event:  Event{Type: EventDown, Server: "api", Test: true, Simulated: true},
+			title:  "🧪 TEST: ❌ Server api is down (simulated)",
+			color:  "#d50200",
+			fields: []string{"*URL*\n", "*Duration*\n0s"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, messages := slackServer(t)
+
+			if err := NewSlack(server.URL, "#alerts", time.Second).Send(test.event); err != nil {
+				t.Fatal(err)
+			}
+
+			var sent = messages()
+			if len(sent) != 1 {
+				t.Fatalf("got %d messages, want 1", len(sent))
+			}
+			var message = sent[0]
+			if message.Channel != "#alerts" || message.Text != test.title || len(message.Attachments) != 1 {
+				t.Fatalf("got message %+v", message)
+			}
+			var attachment = message.Attachments[0]
+			if attachment.Color != test.color {
+				t.Errorf("got color %s, want %s", attachment.Color, test.color)
+			}
+			if len(attachment.Blocks) != 2 || attachment.Blocks[0].Type != "section" || attachment.Blocks[1].Type != "section" {
+				t.Fatalf("got blocks %+v", attachment.Blocks)
+			}
+			if text := attachment.Blocks[0].Text; text == nil || text.Type != "mrkdwn" || text.Text != "*"+test.title+"*" {
+				t.Errorf("got title block %+v", text)
+			}
+			var fields = attachment.Blocks[1].Fields
+			if len(fields) != len(test.fields) {
+				t.Fatalf("got fields %+v, want %q", fields, test.fields)
+			}
+			for i, field := range fields {
+				if field.Type != "mrkdwn" || field.Text != test.fields[i] {
+					t.Errorf("got field %+v, want %q", field, test.fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSlackWithoutChannel(t *testing.T) {
+	var body, _ = json.Marshal(newSlackMessage("", Event{Type: EventUp, Server: "api"}))
+
+	var message map[string]any
+	if err := json.Unmarshal(body, &message); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := message["channel"]; ok {
+		t.Error("message without channel overrides the channel of the webhook")
+	}
+}
+
+func TestSlackRetries(t *testing.T) {
+	var tests = []struct {
+		name     string
+		statuses []int
+		attempts int
+		fails    bool
+	}{
+		{name: "ok", attempts: 1},
+		{name: "server error", statuses: []int{http.StatusBadGateway}, attempts: 2},
+		{name: "persistent server error", statuses: []int{500, 500, 500}, attempts: sendAttempts, fails: true},
+		{name: "client error", statuses: []int{http.StatusNotFound}, attempts: 1, fails: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, messages := slackServer(t, test.statuses...)
+			var slack = NewSlack(server.URL, "", time.Second)
+			slack.Backoff = time.Millisecond
+
+			var err = slack.Send(Event{Type: EventDown, Server: "api"})
+
+			if (err != nil) != test.fails {
+				t.Errorf("got error %v, want failure %t", err, test.fails)
+			}
+			if len(messages()) != test.attempts {
+				t.Errorf("got %d attempts, want %d", len(messages()), test.attempts)
+			}
+		})
+	}
+}
+
+// channel is a notifier which records events and fails with err
+type channel struct {
+	err  error
+	sent chan Event
+}
+
+func (c channel) Send(event Event) error {
+	c.sent <- event
+	return c.err
+}
+
+func (c channel) Name() string {
+	return "channel"
+}
+
+func TestSendAllFailureDoesNotSuppressOthers(t *testing.T) {
+	var failing = channel{err: errors.New("unavailable"), sent: make(chan Event, 1)}
+	var working = channel{sent: make(chan Event, 1)}
+
+	SendAll([]Notifier{failing, working}, Event{Type: EventDown, Server: "api"})
+
+	for _, notifier := range []channel{failing, working} {
+		select {
+		case event := <-notifier.sent:
+			if event.Server != "api" {
+				t.Errorf("got event of %s", event.Server)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("notifier got no event")
+		}
+	}
+}
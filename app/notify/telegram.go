@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrChatBlocked is returned when the bot is blocked by the user or removed from the chat
+var ErrChatBlocked = errors.New("bot is blocked in the chat")
+
+// Telegram sends text of events to the chat, down alerts get management buttons
+type Telegram struct {
+	Sender *sender.Sender
+
+	// Chat returns id of the chat, it changes when the chat is migrated to supergroup
+	Chat func() int64
+	// Language returns language of the chat
+	Language func(chatId int64) i18n.Lang
+	// NoButtons disables management buttons, e.g. in private chats of subscribers who may not be superusers
+	NoButtons bool
+}
+
+func (t *Telegram) Name() string {
+	return fmt.Sprintf("telegram chat %d", t.Chat())
+}
+
+func (t *Telegram) Send(event Event) error {
+	var chatId = t.Chat()
+	var lang = t.Language(chatId)
+
+	msg := tgbotapi.NewMessage(chatId, AlertText(lang, event))
+	if event.Type == EventDown && !t.NoButtons && !event.Test {
+		msg.ReplyMarkup = AlertKeyboard(lang, event.Server)
+	}
+
+	_, err := t.Sender.SendAlert(msg)
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden {
+		return fmt.Errorf("%w: %v", ErrChatBlocked, err)
+	}
+	return err
+}
+
+// AlertText returns text of the alert about the event, test and simulated alerts are marked
+func AlertText(lang i18n.Lang, event Event) string {
+	if event.Test {
+		var alert = event
+		alert.Test = false
+		return i18n.T(lang, "alert.test") + "\n" + AlertText(lang, alert)
+	}
+	if event.Simulated {
+		var alert = event
+		alert.Simulated = false
+		return AlertText(lang, alert) + " " + i18n.T(lang, "alert.simulated")
+	}
+
+	switch event.Type {
+	case EventDown:
+		return downText(lang, event)
+	case EventUp:
+		var text = i18n.T(lang, "alert.up", event.Url)
+		if event.AckBy != "" {
+			text += "\n" + i18n.T(lang, "alert.up_ack", event.AckBy, i18n.Duration(lang, event.AckDelay))
+		}
+		return text + endedUptimeText(lang, event)
+	case EventEscalated:
+		return i18n.T(lang, "alert.escalated", event.Url, i18n.Duration(lang, event.Duration))
+	case EventEscalationResolved:
+		return i18n.T(lang, "alert.escalation_resolved", event.Url, i18n.Duration(lang, event.Duration))
+	case EventProtocol:
+		return i18n.T(lang, "alert.protocol", event.Url, event.Proto, event.ExpectedProto)
+	case EventCompression:
+		return i18n.T(lang, "alert.compression", event.Url)
+	case EventSecurityHeaders:
+		return i18n.T(lang, "alert.security", event.Url, strings.Join(event.Headers, ", "))
+	case EventSlaBreach:
+		return i18n.T(lang, "alert.sla_breach", event.Url, event.Availability, event.SlaTarget,
+			i18n.Window(lang, event.SlaWindow), event.ErrorBudget)
+	case EventSlaRecovered:
+		return i18n.T(lang, "alert.sla_recovered", event.Url, event.Availability, event.SlaTarget,
+			i18n.Window(lang, event.SlaWindow))
+	case EventPinMismatch:
+		return i18n.T(lang, "alert.pin", event.Url, event.Fingerprint, event.PinnedFingerprint)
+	case EventUnexpectedIp:
+		return i18n.T(lang, "alert.unexpected_ip", event.Url, event.Ip, strings.Join(event.ExpectedIps, ", "))
+	case EventAgentOffline:
+		return i18n.T(lang, "alert.agent_offline", event.Agent, i18n.Duration(lang, event.Duration))
+	case EventAgentOnline:
+		return i18n.T(lang, "alert.agent_online", event.Agent, i18n.Duration(lang, event.Duration))
+	case EventMaintenanceEnded:
+		if len(event.Servers) == 0 {
+			return i18n.T(lang, "alert.maintenance_up")
+		}
+		return i18n.T(lang, "alert.maintenance_down", strings.Join(event.Servers, ", "))
+	case EventIncidentSummary:
+		return summaryText(lang, event)
+	default:
+		return fmt.Sprintf("%s: %s", event.Type, event.Url)
+	}
+}
+
+// maxErrorSize is the number of characters of the error shown in the down alert, so the alert fits in a message
+const maxErrorSize = 300
+
+// downText returns the down alert with the status code, the error or its category, the response time
+// and the body excerpt of the failed check
+func downText(lang i18n.Lang, event Event) string {
+	var text = i18n.T(lang, "alert.down", event.Url)
+	if event.StatusCode != 0 {
+		text += "\n" + i18n.T(lang, "alert.down_status", event.StatusCode, http.StatusText(event.StatusCode))
+	}
+	switch {
+	case event.ErrorCategory != "":
+		text += "\n" + i18n.T(lang, "alert.down_reason", i18n.T(lang, "alert.category_"+event.ErrorCategory))
+	case event.Error != "" && event.Error != fmt.Sprintf("status code %d", event.StatusCode):
+		text += "\n" + i18n.T(lang, "alert.down_error", Truncate(event.Error, maxErrorSize))
+	}
+	if event.ResponseTime > 0 {
+		text += "\n" + i18n.T(lang, "alert.down_time", event.ResponseTime.Round(time.Millisecond))
+	}
+	if event.BodyExcerpt != "" {
+		text += "\n" + i18n.T(lang, "alert.down_body", event.BodyExcerpt)
+	}
+	return text
+}
+
+// Truncate cuts text to size characters
+func Truncate(text string, size int) string {
+	if utf8.RuneCountInString(text) <= size {
+		return text
+	}
+	return string([]rune(text)[:size]) + "…"
+}
+
+// summaryText returns the postmortem of the incident with its times, failed checks, errors and acknowledgement
+func summaryText(lang i18n.Lang, event Event) string {
+	const layout = "2006-01-02 15:04:05 MST"
+	var text = i18n.T(lang, "alert.summary", event.Url, event.Since.Format(layout), event.Time.Format(layout),
+		i18n.Duration(lang, event.Duration), event.FailedChecks)
+	if len(event.Errors) > 0 {
+		text += "\n" + i18n.T(lang, "alert.summary_errors")
+		for _, e := range event.Errors {
+			text += "\n• " + e
+		}
+	}
+	if event.AckBy != "" {
+		text += "\n" + i18n.T(lang, "alert.summary_ack", event.AckBy, i18n.Duration(lang, event.AckDelay))
+	} else {
+		text += "\n" + i18n.T(lang, "alert.summary_no_ack")
+	}
+	return text + endedUptimeText(lang, event)
+}
+
+// endedUptimeText returns the line about the uptime streak ended by the incident, empty if there was none
+func endedUptimeText(lang i18n.Lang, event Event) string {
+	switch {
+	case event.EndedUptime == 0:
+		return ""
+	case event.EndedRecord:
+		return "\n" + i18n.T(lang, "alert.ended_record", i18n.Duration(lang, event.EndedUptime))
+	default:
+		return "\n" + i18n.T(lang, "alert.ended_streak", i18n.Duration(lang, event.EndedUptime))
+	}
+}
+
+// AlertKeyboard returns buttons attached to the down alert
+func AlertKeyboard(lang i18n.Lang, name string) tgbotapi.InlineKeyboardMarkup {
+	var ref = ServerRef(name)
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.ack"), "ack:"+ref),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.check_now"), "recheck:"+ref),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.snooze_1h"), "snooze:"+ref+":1h"),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.details"), "details:"+ref),
+		),
+	)
+}
+
+// ServerRef returns the short stable reference of the server used in callback data of buttons instead of its name,
+// Telegram rejects messages with callback data longer than 64 bytes and names may be longer
+func ServerRef(name string) string {
+	var sum = sha256.Sum256([]byte(name))
+	return base64.RawURLEncoding.EncodeToString(sum[:9])
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"strings"
+	"testing"
+)
+
+func TestAlertKeyboardCallbackDataLimit(t *testing.T) {
+	for _, name := range []string{
+		"api",
+		"example.com/" + strings.Repeat("path/", 12),
+		strings.Repeat("сервер", 11),
+	} {
+		var keyboard = AlertKeyboard(i18n.En, name)
+		for _, row := range keyboard.InlineKeyboard {
+			for _, button := range row {
+				if len(*button.CallbackData) > 64 {
+					t.Errorf("callback data %q of %q is %d bytes, Telegram allows 64", *button.CallbackData, name,
+						len(*button.CallbackData))
+				}
+			}
+		}
+	}
+}
+
+func TestServerRef(t *testing.T) {
+	if ServerRef("api") != ServerRef("api") {
+		t.Error("reference of the same name differs")
+	}
+	if ServerRef("api") == ServerRef("web") {
+		t.Error("references of different names are equal")
+	}
+	if strings.Contains(ServerRef("api"), ":") {
+		t.Errorf("reference %q contains the callback data separator", ServerRef("api"))
+	}
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Webhook posts events as JSON to the url, the body is signed with HMAC-SHA256 in X-Signature-256 header if secret is set
+type Webhook struct {
+	Url    string
+	Secret string
+	Client *http.Client
+
+	// Backoff is the delay before the first retry, it is doubled for each next retry
+	Backoff time.Duration
+}
+
+func NewWebhook(url, secret string, timeout time.Duration) *Webhook {
+	return &Webhook{
+		Url:     url,
+		Secret:  secret,
+		Client:  &http.Client{Timeout: timeout},
+		Backoff: time.Second,
+	}
+}
+
+// Name returns the scheme and host of the url, the path, query and user info may contain secrets
+func (w *Webhook) Name() string {
+	u, err := url.Parse(w.Url)
+	if err != nil || u.Host == "" {
+		return "webhook"
+	}
+	return "webhook " + u.Scheme + "://" + u.Host
+}
+
+// Send posts the event, retries with backoff on 5xx responses and network errors
+func (w *Webhook) Send(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var headers = map[string]string{}
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		headers["X-Signature-256"] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	return retry(w.Name(), w.Backoff, func() (int, error) {
+		return postJson(w.Client, w.Url, body, headers)
+	})
+}
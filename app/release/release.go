@@ -0,0 +1,124 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const latestReleaseUrl = "https://api.github.com/repos/Romancha/server-healthcheck-telegram-bot/releases/latest"
+
+// Info describes the running build, fields are set by -ldflags
+type Info struct {
+	Version string
+	Commit  string
+	Date    string
+}
+
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", i.Version, i.Commit, i.Date)
+}
+
+// Checker periodically looks up the latest GitHub release
+type Checker struct {
+	Current string
+	Client  *http.Client
+
+	mutex  sync.Mutex
+	latest string
+}
+
+func NewChecker(current string) *Checker {
+	return &Checker{Current: current, Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Start checks the latest release in background every interval
+func (c *Checker) Start(interval time.Duration) {
+	go func() {
+		for {
+			c.check()
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// Newer returns version of the latest release if it is newer than the current version, empty string otherwise
+func (c *Checker) Newer() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.latest == "" || !isNewer(c.latest, c.Current) {
+		return ""
+	}
+	return c.latest
+}
+
+func (c *Checker) check() {
+	resp, err := c.Client.Get(latestReleaseUrl)
+	if err != nil {
+		log.Printf("[WARN] Failed to check the latest release: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[WARN] Failed to check the latest release: status code %d", resp.StatusCode)
+		return
+	}
+
+	var latestRelease struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&latestRelease); err != nil {
+		log.Printf("[WARN] Failed to decode the latest release: %v", err)
+		return
+	}
+
+	c.mutex.Lock()
+	c.latest = latestRelease.TagName
+	c.mutex.Unlock()
+}
+
+// isNewer compares versions like "v1.2.3" by numeric parts, versions which are not numeric are never older
+func isNewer(latest string, current string) bool {
+	latestParts, ok := versionParts(latest)
+	if !ok {
+		return false
+	}
+	currentParts, ok := versionParts(current)
+	if !ok {
+		return false
+	}
+
+	for i := 0; i < len(latestParts) || i < len(currentParts); i++ {
+		var l, c int
+		if i < len(latestParts) {
+			l = latestParts[i]
+		}
+		if i < len(currentParts) {
+			c = currentParts[i]
+		}
+		if l != c {
+			return l > c
+		}
+	}
+
+	return false
+}
+
+func versionParts(version string) ([]int, bool) {
+	var parts []int
+	for _, part := range strings.Split(strings.TrimPrefix(version, "v"), ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	"github.com/robfig/cron/v3"
+	"log"
+	"sync"
+	"time"
+)
+
+var parser = cron.NewParser(
+	cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// recoverJobs logs panics of jobs instead of crashing the bot, the next run is scheduled as usual
+var recoverJobs = cron.WithChain(cron.Recover(cron.PrintfLogger(log.Default())))
+
+// slowJobPercent is the share of the cron period the job may take before SlowJob is called
+const slowJobPercent = 80
+
+// Scheduler runs the checks job on a cron spec which can be replaced at runtime
+type Scheduler struct {
+	DefaultSpec string
+
+	// SlowJob is called once when the job takes most of the cron period, again after the spec is changed
+	SlowJob func(duration time.Duration, interval time.Duration)
+
+	cron      *cron.Cron
+	job       cron.Job
+	mutex     sync.Mutex
+	entryID   cron.EntryID
+	spec      string
+	slowShown bool
+
+	completedAt time.Time
+}
+
+func New(defaultSpec string, job func()) *Scheduler {
+	s := &Scheduler{
+		DefaultSpec: defaultSpec,
+		cron:        cron.New(cron.WithParser(parser), recoverJobs),
+	}
+	s.job = cron.FuncJob(func() {
+		s.run(job)
+	})
+	return s
+}
+
+// run runs the job and records the lag behind the scheduled time and duration of the job
+func (s *Scheduler) run(job func()) {
+	var started = time.Now()
+
+	s.mutex.Lock()
+	var entryID = s.entryID
+	s.mutex.Unlock()
+
+	// Prev of the entry is the time the job was scheduled at
+	if entry := s.cron.Entry(entryID); entry.Valid() && !entry.Prev.IsZero() {
+		metrics.SchedulerLag.Set(started.Sub(entry.Prev).Milliseconds())
+	}
+
+	job()
+
+	s.mutex.Lock()
+	s.completedAt = time.Now()
+	s.mutex.Unlock()
+
+	var duration = time.Since(started)
+	metrics.CycleDuration.Set(duration.Milliseconds())
+
+	var interval = s.Interval()
+	if interval == 0 || duration <= interval*slowJobPercent/100 || s.SlowJob == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	var shown = s.slowShown
+	s.slowShown = true
+	s.mutex.Unlock()
+
+	if !shown {
+		s.SlowJob(duration, interval)
+	}
+}
+
+// Start schedules the job with spec and starts the cron
+func (s *Scheduler) Start(spec string) error {
+	if err := s.SetSpec(spec); err != nil {
+		return err
+	}
+	s.cron.Start()
+	return nil
+}
+
+// SetSpec validates spec and replaces the scheduled entry. The old entry stays in place if spec is invalid.
+func (s *Scheduler) SetSpec(spec string) error {
+	schedule, err := parser.Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	oldID := s.entryID
+	s.entryID = s.cron.Schedule(schedule, s.job)
+	s.spec = spec
+	s.slowShown = false
+	if oldID != 0 {
+		s.cron.Remove(oldID)
+	}
+
+	return nil
+}
+
+func (s *Scheduler) Spec() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.spec
+}
+
+// NextRuns returns the next count run times of the current schedule
+func (s *Scheduler) NextRuns(count int) []time.Time {
+	s.mutex.Lock()
+	entry := s.cron.Entry(s.entryID)
+	s.mutex.Unlock()
+
+	if !entry.Valid() {
+		return nil
+	}
+
+	var runs []time.Time
+	next := time.Now()
+	for i := 0; i < count; i++ {
+		next = entry.Schedule.Next(next)
+		runs = append(runs, next)
+	}
+
+	return runs
+}
+
+// Interval returns the time between the next two runs, zero if the job is not scheduled
+func (s *Scheduler) Interval() time.Duration {
+	var runs = s.NextRuns(2)
+	if len(runs) < 2 {
+		return 0
+	}
+
+	return runs[1].Sub(runs[0])
+}
+
+// SpecInterval returns the time between the next two runs of the spec, zero if the spec is invalid
+func SpecInterval(spec string) time.Duration {
+	schedule, err := parser.Parse(spec)
+	if err != nil {
+		return 0
+	}
+
+	var next = schedule.Next(time.Now())
+	return schedule.Next(next).Sub(next)
+}
+
+// StartJob runs the job on the spec in background, unlike Scheduler the job is not recorded in metrics
+func StartJob(spec string, job func()) (*cron.Cron, error) {
+	schedule, err := parser.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var c = cron.New(cron.WithParser(parser), recoverJobs)
+	c.Schedule(schedule, cron.FuncJob(job))
+	c.Start()
+	return c, nil
+}
+
+// Stop stops scheduling the job and waits for the running job to complete
+func (s *Scheduler) Stop() {
+	<-s.cron.Stop().Done()
+}
@@ -0,0 +1,26 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartJobRecoversPanics(t *testing.T) {
+	var runs = make(chan struct{}, 2)
+	c, err := StartJob("@every 1s", func() {
+		runs <- struct{}{}
+		panic("job failed")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(3 * time.Second):
+			t.Fatalf("job ran %d times, want it to run again after a panic", i)
+		}
+	}
+}
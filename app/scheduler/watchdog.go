@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/logging"
+	"log"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// watchdogTick is how often the watchdog checks the scheduler and retries a failed alert
+const watchdogTick = time.Minute
+
+// Watchdog alerts when no job of the scheduler completed for Periods cron periods,
+// e.g. if the job panicked or is stuck on a lock
+type Watchdog struct {
+	Scheduler *Scheduler
+	Periods   int
+
+	// OldestCheck returns the time of the oldest last check of the scheduled servers, so a cycle completing
+	// without checking all of them is detected too. It can be nil.
+	OldestCheck func() time.Time
+
+	// Stalled is called once per stall with the time since the last completed job, it is retried while it fails
+	Stalled func(stalled time.Duration) error
+
+	startedAt time.Time
+	alerted   bool
+	stop      chan struct{}
+	done      sync.WaitGroup
+}
+
+// Start checks the scheduler in background every minute
+func (w *Watchdog) Start() {
+	w.startedAt = time.Now()
+	w.stop = make(chan struct{})
+	w.done.Add(1)
+
+	go func() {
+		defer w.done.Done()
+
+		ticker := time.NewTicker(watchdogTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.check()
+			}
+		}
+	}()
+}
+
+// Stop stops the watchdog and waits for the running check to complete
+func (w *Watchdog) Stop() {
+	close(w.stop)
+	w.done.Wait()
+}
+
+// check calls Stalled if the scheduler is stalled, the watchdog keeps running if Stalled panics
+func (w *Watchdog) check() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[ERROR] Watchdog panic: %v", r)
+		}
+	}()
+
+	var stalled = w.Scheduler.Stalled(w.startedAt, w.Periods)
+	if stalled == 0 && w.OldestCheck != nil {
+		var since = w.startedAt
+		if checked := w.OldestCheck(); checked.After(since) {
+			since = checked
+		}
+		stalled = w.Scheduler.Overdue(since, w.Periods)
+	}
+	if stalled == 0 {
+		w.alerted = false
+		return
+	}
+	if w.alerted {
+		return
+	}
+
+	log.Printf("[WARN] Check cycle has not completed for %v", stalled.Round(time.Second))
+	// the dump takes up to 1 MB, it is only built when it is logged
+	if logging.IsDebug() {
+		log.Printf("[DEBUG] Goroutine dump:\n%s", goroutineDump())
+	}
+
+	if err := w.Stalled(stalled); err != nil {
+		log.Printf("[ERROR] Failed to send watchdog alert, retrying in %v: %v", watchdogTick, err)
+		return
+	}
+	w.alerted = true
+}
+
+// Stalled returns the time since the last completed job if it is longer than periods cron periods, zero otherwise.
+// The time is counted from since if no job completed yet.
+func (s *Scheduler) Stalled(since time.Time, periods int) time.Duration {
+	s.mutex.Lock()
+	var completed = s.completedAt
+	s.mutex.Unlock()
+	if completed.After(since) {
+		since = completed
+	}
+
+	return s.Overdue(since, periods)
+}
+
+// Overdue returns the time since the time if it is longer than periods cron periods, zero otherwise
+func (s *Scheduler) Overdue(since time.Time, periods int) time.Duration {
+	var interval = s.Interval()
+	if interval == 0 || periods <= 0 {
+		return 0
+	}
+
+	if stalled := time.Since(since); stalled > time.Duration(periods)*interval {
+		return stalled
+	}
+	return 0
+}
+
+// goroutineDump returns stack traces of all goroutines
+func goroutineDump() []byte {
+	var buf = make([]byte, 1<<20)
+	return buf[:runtime.Stack(buf, true)]
+}
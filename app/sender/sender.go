@@ -0,0 +1,238 @@
+package sender
+
+import (
+	"errors"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"sync"
+	"time"
+)
+
+var ErrClosed = errors.New("sender is closed")
+
+// Options configures rate limits of the Sender
+type Options struct {
+	// ChatInterval is the minimal interval between messages to the same chat
+	ChatInterval time.Duration
+	// GroupPerMinute is the maximal number of messages to a group chat per minute
+	GroupPerMinute int
+}
+
+type request struct {
+	chattable tgbotapi.Chattable
+	chatId    int64
+	result    chan result
+}
+
+type result struct {
+	message tgbotapi.Message
+	err     error
+}
+
+// Sender sends all outgoing messages from a single goroutine in order, respecting the rate limits.
+// Alerts are sent ahead of other messages when the queue backs up.
+type Sender struct {
+	bot     *tgbotapi.BotAPI
+	options Options
+
+	mutex   sync.Mutex
+	wake    chan struct{}
+	alerts  []request
+	replies []request
+	closed  bool
+	done    chan struct{}
+
+	lastSent   map[int64]time.Time
+	groupSends map[int64][]time.Time
+}
+
+func New(bot *tgbotapi.BotAPI, options Options) *Sender {
+	s := &Sender{
+		bot:        bot,
+		options:    options,
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		lastSent:   make(map[int64]time.Time),
+		groupSends: make(map[int64][]time.Time),
+	}
+	go s.run()
+	return s
+}
+
+// Send queues the message and waits until it is sent
+func (s *Sender) Send(chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return s.enqueue(chattable, false)
+}
+
+// SendAlert queues the message ahead of messages sent by Send and waits until it is sent
+func (s *Sender) SendAlert(chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return s.enqueue(chattable, true)
+}
+
+// Close stops accepting messages and waits until the queued messages are sent or timeout passes
+func (s *Sender) Close(timeout time.Duration) {
+	s.mutex.Lock()
+	s.closed = true
+	s.mutex.Unlock()
+	s.notify()
+
+	select {
+	case <-s.done:
+	case <-time.After(timeout):
+	}
+}
+
+func (s *Sender) enqueue(chattable tgbotapi.Chattable, alert bool) (tgbotapi.Message, error) {
+	chattable = redactText(chattable)
+	var req = request{chattable: chattable, chatId: chatId(chattable), result: make(chan result, 1)}
+
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return tgbotapi.Message{}, ErrClosed
+	}
+	if alert {
+		s.alerts = append(s.alerts, req)
+	} else {
+		s.replies = append(s.replies, req)
+	}
+	metrics.QueueDepth.Set(int64(len(s.alerts) + len(s.replies)))
+	s.mutex.Unlock()
+	s.notify()
+
+	res := <-req.result
+	return res.message, res.err
+}
+
+func (s *Sender) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Sender) run() {
+	defer close(s.done)
+
+	for {
+		req, wait, ok, closed := s.next()
+		switch {
+		case !ok && closed:
+			return
+		case !ok:
+			<-s.wake
+		case wait > 0:
+			// message queued while waiting may be sent first
+			select {
+			case <-s.wake:
+			case <-time.After(wait):
+			}
+		default:
+			message, err := s.bot.Send(req.chattable)
+			s.recordSent(req.chatId)
+			req.result <- result{message: message, err: err}
+		}
+	}
+}
+
+// next pops the first alert or the first reply if there are no alerts, whose chat is not rate limited.
+// Messages to a rate limited chat are skipped together with the later messages to the same chat to keep
+// their order, wait is the time until the first of them can be sent if all queued chats are rate limited
+func (s *Sender) next() (req request, wait time.Duration, ok bool, closed bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.alerts)+len(s.replies) == 0 {
+		return req, 0, false, s.closed
+	}
+
+	var limited = make(map[int64]bool)
+	for _, queue := range []*[]request{&s.alerts, &s.replies} {
+		for i, queued := range *queue {
+			if limited[queued.chatId] {
+				continue
+			}
+			if chatWait := s.rateLimitWait(queued.chatId); chatWait > 0 {
+				limited[queued.chatId] = true
+				if wait == 0 || chatWait < wait {
+					wait = chatWait
+				}
+				continue
+			}
+
+			*queue = append((*queue)[:i:i], (*queue)[i+1:]...)
+			metrics.QueueDepth.Set(int64(len(s.alerts) + len(s.replies)))
+			return queued, 0, true, s.closed
+		}
+	}
+
+	return req, wait, true, s.closed
+}
+
+func (s *Sender) rateLimitWait(chatId int64) time.Duration {
+	if chatId == 0 {
+		return 0
+	}
+
+	var now = time.Now()
+	var wait = s.lastSent[chatId].Add(s.options.ChatInterval).Sub(now)
+
+	// group chats are limited per minute, ids of groups and channels are negative
+	if sends := s.groupSends[chatId]; chatId < 0 && s.options.GroupPerMinute > 0 && len(sends) >= s.options.GroupPerMinute {
+		if groupWait := sends[len(sends)-s.options.GroupPerMinute].Add(time.Minute).Sub(now); groupWait > wait {
+			wait = groupWait
+		}
+	}
+
+	return wait
+}
+
+func (s *Sender) recordSent(chatId int64) {
+	if chatId == 0 {
+		return
+	}
+
+	var now = time.Now()
+	s.lastSent[chatId] = now
+	if chatId < 0 && s.options.GroupPerMinute > 0 {
+		var sends = append(s.groupSends[chatId], now)
+		if len(sends) > s.options.GroupPerMinute {
+			sends = sends[len(sends)-s.options.GroupPerMinute:]
+		}
+		s.groupSends[chatId] = sends
+	}
+}
+
+// redactText removes credentials and configured secrets from text of the message, e.g. echoed in error replies
+func redactText(chattable tgbotapi.Chattable) tgbotapi.Chattable {
+	switch c := chattable.(type) {
+	case tgbotapi.MessageConfig:
+		c.Text = redact.Text(c.Text)
+		return c
+	case tgbotapi.PhotoConfig:
+		c.Caption = redact.Text(c.Caption)
+		return c
+	case tgbotapi.EditMessageTextConfig:
+		c.Text = redact.Text(c.Text)
+		return c
+	default:
+		return chattable
+	}
+}
+
+// chatId returns the chat the message is sent to, 0 if it is unknown
+func chatId(chattable tgbotapi.Chattable) int64 {
+	switch c := chattable.(type) {
+	case tgbotapi.MessageConfig:
+		return c.ChatID
+	case tgbotapi.PhotoConfig:
+		return c.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return c.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return c.ChatID
+	default:
+		return 0
+	}
+}
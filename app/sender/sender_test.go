@@ -0,0 +1,135 @@
+package sender
+
+import (
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// queued returns requests with texts of the messages to the chats, chat and text are separated by a colon
+func queued(messages ...string) []request {
+	var requests []request
+	for _, message := range messages {
+		var chat int64
+		var text string
+		fmt.Sscanf(message, "%d:%s", &chat, &text)
+		requests = append(requests, request{chattable: tgbotapi.NewMessage(chat, text), chatId: chat})
+	}
+	return requests
+}
+
+func TestNext(t *testing.T) {
+	var tests = []struct {
+		name    string
+		alerts  []string
+		replies []string
+		limited []int64
+		want    string
+		wait    bool
+	}{
+		{name: "alert first", alerts: []string{"1:alert"}, replies: []string{"2:reply"}, want: "alert"},
+		{name: "first in order", replies: []string{"1:first", "2:second"}, want: "first"},
+		{name: "ready chat", replies: []string{"1:first", "1:second", "2:third"}, limited: []int64{1}, want: "third"},
+		{name: "reply of ready chat", alerts: []string{"1:alert"}, replies: []string{"2:reply"}, limited: []int64{1}, want: "reply"},
+		{name: "order of chat", alerts: []string{"1:alert"}, replies: []string{"1:reply"}, limited: []int64{1}, wait: true},
+		{name: "all limited", replies: []string{"1:first", "-2:second"}, limited: []int64{1, -2}, wait: true},
+		{name: "unknown chat", replies: []string{"1:first", "0:edit"}, limited: []int64{1}, want: "edit"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var s = &Sender{
+				options:  Options{ChatInterval: time.Minute},
+				alerts:   queued(test.alerts...),
+				replies:  queued(test.replies...),
+				lastSent: make(map[int64]time.Time),
+			}
+			for _, chat := range test.limited {
+				s.lastSent[chat] = time.Now()
+			}
+
+			req, wait, ok, _ := s.next()
+
+			if !ok {
+				t.Fatal("nothing to send")
+			}
+			if test.wait {
+				if wait <= 0 || wait > time.Minute {
+					t.Errorf("got wait %s, want up to a minute", wait)
+				}
+				if len(s.alerts)+len(s.replies) != len(test.alerts)+len(test.replies) {
+					t.Error("message is removed from the queue")
+				}
+				return
+			}
+			if wait != 0 {
+				t.Fatalf("got wait %s", wait)
+			}
+			if text := req.chattable.(tgbotapi.MessageConfig).Text; text != test.want {
+				t.Errorf("got %q, want %q", text, test.want)
+			}
+			if len(s.alerts)+len(s.replies) != len(test.alerts)+len(test.replies)-1 {
+				t.Error("message is kept in the queue")
+			}
+		})
+	}
+}
+
+func TestNextEmpty(t *testing.T) {
+	var s = &Sender{closed: true}
+
+	if _, _, ok, closed := s.next(); ok || !closed {
+		t.Errorf("got ok %t, closed %t", ok, closed)
+	}
+}
+
+func TestRateLimitedChatDoesNotBlockOthers(t *testing.T) {
+	var lock sync.Mutex
+	var sent []string
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bottoken/getMe" {
+			fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"username":"test_bot"}}`)
+			return
+		}
+		lock.Lock()
+		sent = append(sent, r.FormValue("chat_id")+":"+r.FormValue("text"))
+		lock.Unlock()
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1}}`)
+	}))
+	defer server.Close()
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", server.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s = New(bot, Options{ChatInterval: 300 * time.Millisecond})
+	defer s.Close(time.Second)
+
+	if _, err = s.Send(tgbotapi.NewMessage(1, "first")); err != nil {
+		t.Fatal(err)
+	}
+	var done = make(chan string, 2)
+	for _, chat := range []int64{1, 2} {
+		go func(chat int64) {
+			s.Send(tgbotapi.NewMessage(chat, "next"))
+			done <- strconv.FormatInt(chat, 10)
+		}(chat)
+	}
+
+	if first := <-done; first != "2" {
+		t.Errorf("message to chat %s is sent first, want the chat which is not rate limited", first)
+	}
+	<-done
+
+	lock.Lock()
+	defer lock.Unlock()
+	var want = []string{"1:first", "2:next", "1:next"}
+	if fmt.Sprint(sent) != fmt.Sprint(want) {
+		t.Errorf("got %q, want %q", sent, want)
+	}
+}
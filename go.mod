@@ -7,6 +7,8 @@ require (
 	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
 	github.com/jessevdk/go-flags v1.5.0
 	github.com/robfig/cron/v3 v3.0.1
+	golang.org/x/net v0.17.0
+	golang.org/x/sys v0.13.0
 )
 
-require golang.org/x/sys v0.13.0 // indirect
+require golang.org/x/text v0.13.0 // indirect
@@ -1,67 +1,692 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/agent"
 	"github.com/Romancha/server-healthcheck-telegram-bot/app/checks"
 	"github.com/Romancha/server-healthcheck-telegram-bot/app/events"
-	"github.com/go-pkgz/lgr"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/healthcheck"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/i18n"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/logging"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/metrics"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/notify"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/redact"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/release"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/scheduler"
+	"github.com/Romancha/server-healthcheck-telegram-bot/app/sender"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/jessevdk/go-flags"
 	"github.com/robfig/cron/v3"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
 )
 
+// set by -ldflags "-X main.version=... -X main.commit=... -X main.date=..."
+var version = "dev"
+var commit = "unknown"
+var date = "unknown"
+
+// secretOptions configure encryption of credentials in server urls, they are shared with rotate-secrets
+type secretOptions struct {
+	SecretKey     string   `long:"secret-key" env:"SECRET_KEY" description:"Key to encrypt credentials in server urls in the storage"`
+	SecretKeyFile string   `long:"secret-key-file" env:"SECRET_KEY_FILE" description:"File with the key to encrypt credentials, used if secret key is empty"`
+	OldSecretKeys []string `long:"old-secret-key" env:"OLD_SECRET_KEYS" env-delim:"," description:"Previous keys to decrypt credentials saved before rotation, can be repeated"`
+}
+
 var opts struct {
 	Telegram struct {
-		Token string `long:"token" env:"TOKEN" description:"Telegram bot token" required:"true"`
-		Chat  int64  `long:"chat" env:"CHAT" description:"Telegram chat id" required:"true"`
+		Token        string  `long:"token" env:"TOKEN" description:"Telegram bot token" required:"true"`
+		Chat         int64   `long:"chat" env:"CHAT" description:"Telegram chat id" required:"true"`
+		AllowedChats []int64 `long:"allowed-chats" env:"ALLOWED_CHATS" env-delim:"," description:"Chat ids to accept commands from, default is chat"`
 	} `group:"Telegram" namespace:"telegram" env-namespace:"TELEGRAM"`
 
 	AlertThreshold int              `long:"alert-threshold" env:"ALERT_THRESHOLD" description:"Alert threshold" default:"3"`
 	ChecksCron     string           `long:"checks-cron" env:"CHECKS_CRON" description:"Cron spec for checks" default:"*/30 * * * * *"`
-	SuperUsers     events.SuperUser `long:"super" description:"Users names who can manage bot"`
+	Schedules      []string         `long:"schedule" env:"SCHEDULES" env-delim:";" description:"Named cron spec servers can be assigned to, like fast=*/15 * * * * *, can be repeated"`
+	SuperUsers     events.SuperUser `long:"super" description:"Users names or numeric user ids who can manage bot"`
+	Viewers        events.SuperUser `long:"viewer" description:"Users names or numeric user ids who can run read-only commands"`
+	PublicRead     bool             `long:"public-read" env:"PUBLIC_READ" description:"Everyone in the allowed chats can run read-only commands"`
+
+	ListBars bool   `long:"list-bars" env:"LIST_BARS" description:"Show uptime bars in /list"`
+	ListMode string `long:"list-mode" env:"LIST_MODE" description:"Default mode of /list, normal, compact or verbose, a chat keeps the mode it used last" default:"normal"`
+	Language string `long:"language" env:"BOT_LANGUAGE" description:"Default language of bot messages, en or ru" default:"en"`
+	Timezone string `long:"timezone" env:"TIMEZONE" description:"IANA timezone name of timestamps in messages, like Europe/Berlin" default:"Local"`
+
+	HttpListen     string `long:"http-listen" env:"HTTP_LISTEN" description:"Address of the healthcheck HTTP server" default:":8080"`
+	StatusPage     bool   `long:"status-page" env:"STATUS_PAGE" description:"Serve HTML status page on /status"`
+	StatusHideUrls bool   `long:"status-hide-urls" env:"STATUS_HIDE_URLS" description:"Hide server URLs on the status page and API"`
+	StatusApi      bool   `long:"status-api" env:"STATUS_API" description:"Serve JSON status on /api/status"`
+	ServersApi     bool   `long:"servers-api" env:"SERVERS_API" description:"Serve servers management API on /api/servers, requires api token"`
+	ApiToken       string `long:"api-token" env:"API_TOKEN" description:"Bearer token required by the API"`
+	ApiAnnounce    bool   `long:"api-announce" env:"API_ANNOUNCE" description:"Announce changes made via the API in the chat"`
+
+	AgentToken     string        `long:"agent-token" env:"AGENT_TOKEN" description:"Bearer token of probe agents, /api/probe-results is disabled if empty"`
+	ProbeQuorum    int           `long:"probe-quorum" env:"PROBE_QUORUM" description:"Number of locations which must report a server with agents down" default:"2"`
+	AgentHeartbeat time.Duration `long:"agent-heartbeat" env:"AGENT_HEARTBEAT" description:"Agents not reporting for this duration are offline" default:"5m"`
+
+	ChatInterval    time.Duration `long:"chat-interval" env:"CHAT_INTERVAL" description:"Minimal interval between messages to the same chat" default:"1s"`
+	GroupPerMinute  int           `long:"group-per-minute" env:"GROUP_PER_MINUTE" description:"Maximal number of messages to a group chat per minute" default:"20"`
+	ShutdownTimeout time.Duration `long:"shutdown-timeout" env:"SHUTDOWN_TIMEOUT" description:"Time to send queued messages on shutdown" default:"30s"`
+
+	StartupMessage    string `long:"startup-message" env:"STARTUP_MESSAGE" description:"Template of the message sent on start, with .Version, .Servers and .Down" default:"Server health check bot {{.Version}} started{{if .Down}}, {{.Down}} of {{.Servers}} servers are down{{end}}"`
+	NoStartupMessage  bool   `long:"no-startup-message" env:"NO_STARTUP_MESSAGE" description:"Don't send the message on start"`
+	ShutdownMessage   string `long:"shutdown-message" env:"SHUTDOWN_MESSAGE" description:"Template of the message sent on shutdown, with .Version, .Servers and .Down" default:"Server health check bot {{.Version}} is stopping"`
+	NoShutdownMessage bool   `long:"no-shutdown-message" env:"NO_SHUTDOWN_MESSAGE" description:"Don't send the message on shutdown"`
+
+	ReadyCacheTtl time.Duration `long:"ready-cache-ttl" env:"READY_CACHE_TTL" description:"Cache duration of Telegram check in /ready" default:"30s"`
+	StalePeriods  int           `long:"stale-periods" env:"STALE_PERIODS" description:"Cron periods without completed check cycle after which the watchdog alerts and /ready fails, 0 disables" default:"3"`
+
+	WebhookUrls    []string      `long:"webhook-url" env:"WEBHOOK_URLS" env-delim:"," description:"URLs to post alert events to, can be repeated"`
+	WebhookTimeout time.Duration `long:"webhook-timeout" env:"WEBHOOK_TIMEOUT" description:"Timeout of webhook requests" default:"10s"`
+	WebhookSecret  string        `long:"webhook-secret" env:"WEBHOOK_SECRET" description:"Secret to sign webhook payloads with HMAC-SHA256"`
+
+	SlackWebhookUrl string `long:"slack-webhook-url" env:"SLACK_WEBHOOK_URL" description:"Slack incoming webhook URL to send alert events to"`
+	SlackChannel    string `long:"slack-channel" env:"SLACK_CHANNEL" description:"Slack channel, default is the channel of the webhook"`
+
+	DiscordWebhookUrl string `long:"discord-webhook-url" env:"DISCORD_WEBHOOK_URL" description:"Discord webhook URL to send alert events to"`
+
+	Smtp struct {
+		Host     string   `long:"host" env:"HOST" description:"SMTP host, email alerts are disabled if empty"`
+		Port     int      `long:"port" env:"PORT" description:"SMTP port" default:"587"`
+		User     string   `long:"user" env:"USER" description:"SMTP user"`
+		Password string   `long:"pass" env:"PASS" description:"SMTP password"`
+		From     string   `long:"from" env:"FROM" description:"Sender of alert emails"`
+		To       []string `long:"to" env:"TO" env-delim:"," description:"Recipients of alert emails"`
+	} `group:"SMTP" namespace:"smtp" env-namespace:"SMTP"`
+
+	NotificationsRetention time.Duration    `long:"notifications-retention" env:"NOTIFICATIONS_RETENTION" description:"How long sent notifications are kept in the audit log" default:"168h"`
+	HistoryRetention       checks.Retention `long:"history-retention" env:"HISTORY_RETENTION" description:"Check results kept per server, count like 1000 or age like 720h or 30d" default:"1000"`
+	IncidentRetention      checks.Retention `long:"incident-retention" env:"INCIDENT_RETENTION" description:"Closed incidents kept per server, count like 100 or age like 720h or 30d" default:"100"`
+
+	EscalationChat  int64         `long:"escalation-chat" env:"ESCALATION_CHAT" description:"Chat id to escalate unacknowledged incidents to"`
+	EscalationAfter time.Duration `long:"escalation-after" env:"ESCALATION_AFTER" description:"Escalate incidents unacknowledged for this duration" default:"30m"`
+
+	IncidentSummary bool `long:"incident-summary" env:"INCIDENT_SUMMARY" description:"Send a summary of the incident to the chat when the server recovers"`
+
+	WaitForLock bool `long:"wait-for-lock" env:"WAIT_FOR_LOCK" description:"Wait for another instance to release the storage instead of exiting"`
+
+	MaxServers     int           `long:"max-servers" env:"MAX_SERVERS" description:"Maximum number of servers, unlimited if 0"`
+	NewServerGrace time.Duration `long:"new-server-grace" env:"NEW_SERVER_GRACE" description:"Down alerts of added servers are not sent until they succeed or the duration passes, 0 disables" default:"5m"`
+	CheckDeadline  time.Duration `long:"check-deadline" env:"CHECK_DEADLINE" description:"Time limit of all attempts of a check of a server with retries" default:"30s"`
+	CycleDeadline  time.Duration `long:"cycle-deadline" env:"CYCLE_DEADLINE" description:"Time limit of the checks of a cycle not counting sent alerts, servers not checked by then are skipped for the cycle. The cron period if 0"`
+	DownBackoff    time.Duration `long:"down-backoff" env:"DOWN_BACKOFF" description:"Checks of servers down longer than the duration are stretched up to the maximum interval, 0 disables"`
+	DownBackoffMax time.Duration `long:"down-backoff-max" env:"DOWN_BACKOFF_MAX" description:"Longest interval of checks of long down servers" default:"15m"`
+
+	Soft404Signatures string `long:"soft404-signatures" env:"SOFT404_SIGNATURES" description:"File with additional error page signatures, a regular expression per line"`
+
+	AlertBodyExcerpt bool `long:"alert-body-excerpt" env:"ALERT_BODY_EXCERPT" description:"Add the beginning of the response body of the failed check to down alerts"`
 
-	Debug bool `long:"debug" env:"DEBUG" description:"debug mode"`
+	Score struct {
+		AvailabilityWeight float64       `long:"availability-weight" env:"AVAILABILITY_WEIGHT" description:"Weight of availability over the last 24 hours in the health score" default:"40"`
+		LatencyWeight      float64       `long:"latency-weight" env:"LATENCY_WEIGHT" description:"Weight of average response time over the last 24 hours in the health score" default:"20"`
+		SslWeight          float64       `long:"ssl-weight" env:"SSL_WEIGHT" description:"Weight of days until the certificate expires in the health score" default:"20"`
+		IncidentsWeight    float64       `long:"incidents-weight" env:"INCIDENTS_WEIGHT" description:"Weight of incidents over the last 7 days in the health score" default:"20"`
+		LatencyThreshold   time.Duration `long:"latency-threshold" env:"LATENCY_THRESHOLD" description:"Average response time scoring full, twice of it scores 0" default:"1s"`
+	} `group:"Health score" namespace:"score" env-namespace:"SCORE"`
+
+	secretOptions
+
+	Debug         bool          `long:"debug" env:"DEBUG" description:"debug mode"`
+	DebugDuration time.Duration `long:"debug-duration" env:"DEBUG_DURATION" description:"Duration of debug mode enabled by /debug" default:"1h"`
+	DebugListen   string        `long:"debug-listen" env:"DEBUG_LISTEN" description:"Address to serve pprof and expvar on, disabled if empty"`
+	PromTextfile  string        `long:"prom-textfile" env:"PROM_TEXTFILE" description:"Path of the Prometheus textfile written after each check cycle, disabled if empty"`
+
+	EphemeralReplies time.Duration `long:"ephemeral-replies" env:"EPHEMERAL_REPLIES" description:"Delete replies to commands after the duration, alerts are kept, disabled if 0"`
+	SkipPendingAge   time.Duration `long:"skip-pending-age" env:"SKIP_PENDING_AGE" description:"Commands and button presses sent while the bot was offline longer ago are ignored on start, 0 processes all" default:"5m"`
+
+	HeartbeatCron string `long:"heartbeat-cron" env:"HEARTBEAT_CRON" description:"Cron spec of the message sent silently when all servers are up, disabled if empty"`
+
+	BackupCron string `long:"backup-to-telegram-cron" env:"BACKUP_TO_TELEGRAM_CRON" description:"Cron spec of sending the storage file to the backup chat, disabled if empty"`
+	BackupChat int64  `long:"backup-chat" env:"BACKUP_CHAT" description:"Chat backups are sent to, the private chat of the first superuser set by numeric id by default"`
+
+	CheckUpdates bool `long:"check-updates" env:"CHECK_UPDATES" description:"Check GitHub releases for a newer version once a day"`
+	Version      bool `long:"version" description:"Print version and exit"`
 }
 
 func main() {
-	fmt.Println("Server health check bot started")
+	var build = release.Info{Version: version, Commit: commit, Date: date}
+
+	// checked before parsing, so required flags are not needed to print the version
+	for _, arg := range os.Args[1:] {
+		if arg == "--version" {
+			fmt.Println(build)
+			return
+		}
+	}
+
+	// rotate-secrets is a subcommand with its own flags, the bot flags are not required for it
+	if len(os.Args) > 1 && os.Args[1] == "rotate-secrets" {
+		rotateSecrets(os.Args[2:])
+		return
+	}
+	// agent mode has its own flags too
+	if slices.Contains(os.Args[1:], "--agent") {
+		runAgent(os.Args[1:])
+		return
+	}
+
+	fmt.Printf("Server health check bot %s started\n", build)
 	if _, err := flags.Parse(&opts); err != nil {
 		log.Printf("[ERROR] failed to parse flags: %v", err)
 		os.Exit(1)
 	}
 
-	setupLog(opts.Debug)
+	logging.Setup(opts.Debug)
+
+	if err := setSecretKeys(opts.secretOptions); err != nil {
+		log.Printf("[ERROR] %v", err)
+		os.Exit(1)
+	}
+	redact.SetSecrets(append([]string{opts.Telegram.Token, opts.ApiToken, opts.AgentToken, opts.WebhookSecret,
+		opts.Smtp.Password, opts.SecretKey}, opts.OldSecretKeys...)...)
+
+	if opts.Soft404Signatures != "" {
+		if err := checks.LoadSoft404Signatures(opts.Soft404Signatures); err != nil {
+			log.Printf("[ERROR] failed to load soft 404 signatures: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	var storageLock = lockStorage(opts.WaitForLock)
 	checks.InitStorage()
+	checks.UserAgent = "server-healthcheck-telegram-bot/" + version
+
+	lang, ok := i18n.Parse(opts.Language)
+	if !ok {
+		log.Printf("[ERROR] unsupported language %q, supported: %s", opts.Language, strings.Join(i18n.Languages(), ", "))
+		os.Exit(1)
+	}
+	location, err := time.LoadLocation(opts.Timezone)
+	if err != nil {
+		log.Printf("[ERROR] invalid timezone %q, use IANA name like Europe/Berlin: %v", opts.Timezone, err)
+		os.Exit(1)
+	}
+
+	if !slices.Contains(checks.ListModes, opts.ListMode) {
+		log.Printf("[ERROR] unsupported list mode %q, supported: %s", opts.ListMode, strings.Join(checks.ListModes, ", "))
+		os.Exit(1)
+	}
+	if opts.CycleDeadline < 0 {
+		log.Printf("[ERROR] cycle deadline must not be negative, got %v", opts.CycleDeadline)
+		os.Exit(1)
+	}
+	if opts.CheckDeadline <= 0 {
+		log.Printf("[ERROR] check deadline must be positive, got %v", opts.CheckDeadline)
+		os.Exit(1)
+	}
+	if opts.DownBackoff < 0 || opts.DownBackoffMax <= 0 {
+		log.Printf("[ERROR] down backoff must not be negative and its maximum must be positive, got %v and %v",
+			opts.DownBackoff, opts.DownBackoffMax)
+		os.Exit(1)
+	}
+
+	for _, value := range opts.Schedules {
+		name, spec, err := checks.ParseSchedule(value)
+		if err != nil {
+			log.Printf("[ERROR] %v", err)
+			os.Exit(1)
+		}
+		checks.Schedules[name] = spec
+	}
+
+	checks.Location = location
+	checks.MaxServers = opts.MaxServers
+	checks.NewServerGrace = opts.NewServerGrace
+	checks.CheckDeadline = opts.CheckDeadline
+	checks.DownBackoffAfter = opts.DownBackoff
+	checks.DownBackoffMax = opts.DownBackoffMax
+	checks.BodyExcerpt = opts.AlertBodyExcerpt
+
+	checks.Scoring = checks.ScoreWeights{Availability: opts.Score.AvailabilityWeight, Latency: opts.Score.LatencyWeight,
+		Ssl: opts.Score.SslWeight, Incidents: opts.Score.IncidentsWeight, LatencyThreshold: opts.Score.LatencyThreshold}
+	if err := checks.Scoring.Validate(); err != nil {
+		log.Printf("[ERROR] invalid health score weights: %v", err)
+		os.Exit(1)
+	}
+
+	var chatLanguage = func(chatId int64) i18n.Lang {
+		return checks.ReadChecksData().Settings.Language(chatId, lang)
+	}
+
+	if opts.DebugListen != "" {
+		metrics.StartDebugServer(opts.DebugListen)
+	}
 
-	bot, err := tgbotapi.NewBotAPI(opts.Telegram.Token)
+	// the timeout is longer than long polling of getUpdates, so a stuck connection doesn't stop handling updates
+	var client = &http.Client{Transport: metrics.TelegramTransport{Transport: http.DefaultTransport}, Timeout: 2 * time.Minute}
+	bot, err := tgbotapi.NewBotAPIWithClient(opts.Telegram.Token, tgbotapi.APIEndpoint, client)
 	if err != nil {
 		log.Fatalf("failed to create bot: %v", err)
 	}
 	bot.Debug = opts.Debug
 
-	_, err = bot.Send(tgbotapi.NewMessage(opts.Telegram.Chat, "Server health check bot started"))
+	messageSender := sender.New(bot, sender.Options{
+		ChatInterval:   opts.ChatInterval,
+		GroupPerMinute: opts.GroupPerMinute,
+	})
+
+	startupMessage, err := template.New("startup").Parse(opts.StartupMessage)
 	if err != nil {
-		log.Printf("[ERROR] Failed to send start message: %v", err)
+		log.Printf("[ERROR] invalid startup message template: %v", err)
+		os.Exit(1)
+	}
+	shutdownMessage, err := template.New("shutdown").Parse(opts.ShutdownMessage)
+	if err != nil {
+		log.Printf("[ERROR] invalid shutdown message template: %v", err)
+		os.Exit(1)
+	}
+
+	if !opts.NoStartupMessage {
+		sendBotMessage(messageSender, startupMessage)
+	}
+
+	var auditLog = &notify.AuditLog{Path: "data/notifications.json", Retention: opts.NotificationsRetention}
+
+	var notifiers []notify.Notifier
+	for _, webhookUrl := range opts.WebhookUrls {
+		notifiers = append(notifiers, notify.Audited(notify.NewWebhook(webhookUrl, opts.WebhookSecret, opts.WebhookTimeout), auditLog))
+	}
+	if opts.SlackWebhookUrl != "" {
+		notifiers = append(notifiers, notify.Audited(notify.NewSlack(opts.SlackWebhookUrl, opts.SlackChannel, opts.WebhookTimeout), auditLog))
+	}
+	if opts.DiscordWebhookUrl != "" {
+		notifiers = append(notifiers, notify.Audited(notify.NewDiscord(opts.DiscordWebhookUrl, opts.WebhookTimeout), auditLog))
+	}
+	if opts.Smtp.Host != "" {
+		notifiers = append(notifiers, notify.Audited(&notify.Email{
+			Host:     opts.Smtp.Host,
+			Port:     opts.Smtp.Port,
+			User:     opts.Smtp.User,
+			Password: opts.Smtp.Password,
+			From:     opts.Smtp.From,
+			To:       opts.Smtp.To,
+			Timeout:  opts.WebhookTimeout,
+			Language: lang,
+		}, auditLog))
+	}
+
+	var telegramNotifier = &notify.Telegram{
+		Sender:   messageSender,
+		Chat:     migratedChat(opts.Telegram.Chat),
+		Language: chatLanguage,
+	}
+	var options = checks.Options{
+		AlertThreshold:    opts.AlertThreshold,
+		EscalationAfter:   opts.EscalationAfter,
+		Notifier:          notify.Audited(telegramNotifier, auditLog),
+		Notifiers:         notifiers,
+		HistoryRetention:  opts.HistoryRetention,
+		IncidentRetention: opts.IncidentRetention,
+		ProbeQuorum:       opts.ProbeQuorum,
+		AgentHeartbeat:    opts.AgentHeartbeat,
+		PromTextfile:      opts.PromTextfile,
+		IncidentSummary:   opts.IncidentSummary,
+	}
+	if opts.EscalationChat != 0 {
+		options.EscalationNotifier = notify.Audited(&notify.Telegram{
+			Sender:   messageSender,
+			Chat:     migratedChat(opts.EscalationChat),
+			Language: chatLanguage,
+		}, auditLog)
+	}
+
+	options.SubscriberNotifier = func(chatId int64) notify.Notifier {
+		return notify.Audited(&notify.Telegram{
+			Sender:    messageSender,
+			Chat:      func() int64 { return chatId },
+			Language:  chatLanguage,
+			NoButtons: true,
+		}, auditLog)
+	}
+
+	options.ChecksSkipped = func(skipped []string, cycles int) {
+		chat := checks.ReadChecksData().Settings.MigratedChat(opts.Telegram.Chat)
+		chatLang := chatLanguage(chat)
+		text := i18n.T(chatLang, "botstats.skipped", cycles, strings.Join(skipped, ", "))
+		err := sendAudited(auditLog, chat, notify.EventChecksSkipped, "", func() error {
+			_, err := messageSender.Send(tgbotapi.NewMessage(chat, text))
+			return err
+		})
+		if err != nil {
+			log.Printf("[ERROR] Failed to send skipped checks warning: %v", err)
+		}
+	}
+
+	var sched *scheduler.Scheduler
+	sched = scheduler.New(opts.ChecksCron, func() {
+		var cycleOptions = options
+		cycleOptions.Interval = sched.Interval()
+		cycleOptions.CycleDeadline = cycleDeadline(opts.CycleDeadline, cycleOptions.Interval)
+		checks.PerformCheck(context.Background(), cycleOptions)
+	})
+	sched.SlowJob = func(duration time.Duration, interval time.Duration) {
+		log.Printf("[WARN] Check cycle took %v, close to the cron period %v", duration, interval)
+		chat := checks.ReadChecksData().Settings.MigratedChat(opts.Telegram.Chat)
+		chatLang := chatLanguage(chat)
+		text := i18n.T(chatLang, "botstats.slow_cycle", i18n.Duration(chatLang, duration), i18n.Duration(chatLang, interval))
+		err := sendAudited(auditLog, chat, notify.EventSlowCycle, "", func() error {
+			_, err := messageSender.Send(tgbotapi.NewMessage(chat, text))
+			return err
+		})
+		if err != nil {
+			log.Printf("[ERROR] Failed to send slow cycle warning: %v", err)
+		}
+	}
+
+	// cron spec set by /setcron overrides the flag value
+	checksCron := opts.ChecksCron
+	if storedCron := checks.ReadChecksData().Settings.ChecksCron; storedCron != "" {
+		checksCron = storedCron
 	}
 
-	c := cron.New(cron.WithSeconds())
-	_, err = c.AddFunc(opts.ChecksCron, func() {
-		checks.PerformCheck(bot, opts.Telegram.Chat, opts.AlertThreshold)
+	if err = sched.Start(checksCron); err != nil {
+		log.Printf("[ERROR] Invalid stored cron spec %q, using %q: %v", checksCron, opts.ChecksCron, err)
+		if err = sched.Start(opts.ChecksCron); err != nil {
+			log.Fatalf("failed to add cron: %v", err)
+		}
+	}
+
+	// cycles of named schedules only check their servers, they are not recorded in cycle metrics and the watchdog
+	var schedules []*cron.Cron
+	for name, spec := range checks.Schedules {
+		var scheduleOptions = options
+		scheduleOptions.Schedule = name
+		var interval = scheduler.SpecInterval(spec)
+		scheduleOptions.Interval = interval
+		scheduleOptions.CycleDeadline = cycleDeadline(opts.CycleDeadline, interval)
+		scheduled, err := scheduler.StartJob(spec, func() {
+			checks.PerformCheck(context.Background(), scheduleOptions)
+		})
+		if err != nil {
+			log.Printf("[ERROR] invalid cron spec %q of schedule %s: %v", spec, name, err)
+			os.Exit(1)
+		}
+		schedules = append(schedules, scheduled)
+	}
+
+	watchdog := &scheduler.Watchdog{
+		Scheduler: sched,
+		Periods:   opts.StalePeriods,
+		OldestCheck: func() time.Time {
+			_, checked, _ := checks.OldestCheck(checks.ReadChecksData())
+			return checked
+		},
+		// sent by the bot directly, so the alert is not stuck behind the sender queue
+		Stalled: func(stalled time.Duration) error {
+			chat := checks.ReadChecksData().Settings.MigratedChat(opts.Telegram.Chat)
+			chatLang := chatLanguage(chat)
+			return sendAudited(auditLog, chat, notify.EventStalled, "", func() error {
+				_, err := bot.Send(tgbotapi.NewMessage(chat, i18n.T(chatLang, "watchdog.stalled", i18n.Duration(chatLang, stalled))))
+				return err
+			})
+		},
+	}
+	watchdog.Start()
+
+	listener := events.TelegramListener{
+		Bot:              bot,
+		Sender:           messageSender,
+		Chat:             opts.Telegram.Chat,
+		AllowedChats:     opts.Telegram.AllowedChats,
+		SuperUsers:       opts.SuperUsers,
+		Viewers:          opts.Viewers,
+		PublicRead:       opts.PublicRead,
+		Scheduler:        sched,
+		AlertThreshold:   opts.AlertThreshold,
+		DebugDuration:    opts.DebugDuration,
+		ListBars:         opts.ListBars,
+		ListMode:         opts.ListMode,
+		Language:         lang,
+		Location:         location,
+		Build:            build,
+		StartedAt:        time.Now(),
+		AuditLog:         auditLog,
+		HeartbeatCron:    opts.HeartbeatCron,
+		SkipPendingAge:   opts.SkipPendingAge,
+		EphemeralReplies: opts.EphemeralReplies,
+		TestAlert: func(event notify.Event) []checks.Delivery {
+			return checks.SendTestAlert(options, event)
+		},
+	}
+	if opts.CheckUpdates {
+		listener.Releases = release.NewChecker(version)
+		listener.Releases.Start(24 * time.Hour)
+	}
+	listener.RegisterCommands()
+
+	healthcheck.Start(healthcheck.Options{
+		Listen:         opts.HttpListen,
+		StatusPage:     opts.StatusPage,
+		StatusHideUrls: opts.StatusHideUrls,
+		StatusApi:      opts.StatusApi,
+		ServersApi:     opts.ServersApi,
+		ApiToken:       opts.ApiToken,
+		AgentToken:     opts.AgentToken,
+		Announce: func(server, key string, args ...any) {
+			if !opts.ApiAnnounce {
+				return
+			}
+			chat := checks.ReadChecksData().Settings.MigratedChat(opts.Telegram.Chat)
+			text := i18n.T(chatLanguage(chat), key, args...)
+			err := sendAudited(auditLog, chat, notify.EventApiChange, server, func() error {
+				_, err := messageSender.Send(tgbotapi.NewMessage(chat, text))
+				return err
+			})
+			if err != nil {
+				log.Printf("[ERROR] Failed to send API announcement: %v", err)
+			}
+		},
+		TelegramCheck: func() error {
+			_, err := bot.GetMe()
+			return err
+		},
+		UpdatesCheck:  listener.UpdatesError,
+		ReadyCacheTtl: opts.ReadyCacheTtl,
+		Scheduler:     sched,
+		StalePeriods:  opts.StalePeriods,
 	})
+
+	heartbeatCron := opts.HeartbeatCron
+	if heartbeatCron == "" {
+		// the heartbeat can be enabled by /heartbeat on
+		heartbeatCron = events.DefaultHeartbeatCron
+	}
+	heartbeat, err := scheduler.StartJob(heartbeatCron, listener.SendHeartbeat)
+	if err != nil {
+		log.Printf("[ERROR] invalid heartbeat cron %q: %v", heartbeatCron, err)
+		os.Exit(1)
+	}
+
+	var backup *cron.Cron
+	if opts.BackupCron != "" {
+		listener.BackupChat = backupChat(opts.BackupChat, opts.SuperUsers)
+		if listener.BackupChat == 0 {
+			log.Printf("[ERROR] backup chat is not set and no superuser is set by numeric id")
+			os.Exit(1)
+		}
+		backup, err = scheduler.StartJob(opts.BackupCron, listener.SendBackup)
+		if err != nil {
+			log.Printf("[ERROR] invalid backup cron %q: %v", opts.BackupCron, err)
+			os.Exit(1)
+		}
+	}
+
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+		<-stop
+		log.Printf("[INFO] Shutting down")
+		listener.Stop()
+	}()
+
+	listener.Listen()
+
+	watchdog.Stop()
+	sched.Stop()
+	for _, scheduled := range schedules {
+		<-scheduled.Stop().Done()
+	}
+	<-heartbeat.Stop().Done()
+	if backup != nil {
+		<-backup.Stop().Done()
+	}
+	if !opts.NoShutdownMessage {
+		sendBotMessage(messageSender, shutdownMessage)
+	}
+	messageSender.Close(opts.ShutdownTimeout)
+
+	if err := storageLock.Unlock(); err != nil {
+		log.Printf("[ERROR] Failed to release storage lock: %v", err)
+	}
+}
+
+// sendBotMessage sends the startup or shutdown message to the chat, the template gets version and server counts
+func sendBotMessage(messageSender *sender.Sender, tmpl *template.Template) {
+	var checksData = checks.ReadChecksData()
+	var data = struct {
+		Version string
+		Servers int
+		Down    int
+	}{Version: version, Servers: len(checksData.HealthChecks)}
+	for _, serverCheck := range checksData.HealthChecks {
+		if !serverCheck.IsOk && !serverCheck.Paused {
+			data.Down++
+		}
+	}
+
+	var text strings.Builder
+	if err := tmpl.Execute(&text, data); err != nil {
+		log.Printf("[ERROR] Failed to render %s message: %v", tmpl.Name(), err)
+		return
+	}
+
+	chat := checksData.Settings.MigratedChat(opts.Telegram.Chat)
+	if _, err := messageSender.Send(tgbotapi.NewMessage(chat, text.String())); err != nil {
+		log.Printf("[ERROR] Failed to send %s message: %v", tmpl.Name(), err)
+	}
+}
+
+// sendAudited calls send of the message to the chat and records it in the notification audit log
+func sendAudited(auditLog *notify.AuditLog, chat int64, eventType notify.EventType, server string, send func() error) error {
+	return auditLog.Notification(fmt.Sprintf("telegram chat %d", chat), eventType, server, send)
+}
+
+// cycleDeadline returns the time limit of the checks of a cycle, the cron period if the deadline is zero
+func cycleDeadline(deadline time.Duration, interval time.Duration) time.Duration {
+	if deadline == 0 {
+		return interval
+	}
+	return deadline
+}
+
+// lockStorage locks the storage or exits if another instance holds the lock and wait is false
+func lockStorage(wait bool) *checks.StorageLock {
+	storageLock, err := checks.LockStorage(false)
+	if errors.Is(err, checks.ErrStorageLocked) && wait {
+		log.Printf("[INFO] %v, waiting for the lock", err)
+		storageLock, err = checks.LockStorage(true)
+	}
 	if err != nil {
-		log.Fatalf("failed to add cron: %v", err)
+		log.Printf("[ERROR] %v", err)
+		os.Exit(1)
+	}
+	return storageLock
+}
+
+// setSecretKeys sets keys to encrypt credentials with, the key file is read if the key is empty
+func setSecretKeys(options secretOptions) error {
+	var secretKey = options.SecretKey
+	if secretKey == "" && options.SecretKeyFile != "" {
+		data, err := os.ReadFile(options.SecretKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read secret key file: %w", err)
+		}
+		secretKey = strings.TrimSpace(string(data))
+	}
+
+	return checks.SetSecretKeys(secretKey, options.OldSecretKeys...)
+}
+
+// rotateSecrets re-encrypts credentials in the storage with the secret key, old keys decrypt the stored credentials
+func rotateSecrets(args []string) {
+	var rotateOpts struct {
+		secretOptions
+		WaitForLock bool `long:"wait-for-lock" env:"WAIT_FOR_LOCK" description:"Wait for the running bot to exit instead of exiting"`
+	}
+	if _, err := flags.ParseArgs(&rotateOpts, args); err != nil {
+		log.Printf("[ERROR] failed to parse flags: %v", err)
+		os.Exit(1)
 	}
-	c.Start()
 
-	events.ListenTelegramUpdates(bot, opts.SuperUsers)
+	if err := setSecretKeys(rotateOpts.secretOptions); err != nil {
+		log.Printf("[ERROR] %v", err)
+		os.Exit(1)
+	}
+
+	var storageLock = lockStorage(rotateOpts.WaitForLock)
+	defer storageLock.Unlock()
+
+	checks.InitStorage()
+	if err := checks.ReencryptSecrets(); err != nil {
+		log.Printf("[ERROR] failed to save checks.json: %v", err)
+		os.Exit(1)
+	}
+	log.Printf("[INFO] Credentials re-encrypted")
+}
+
+// runAgent runs the bot as a probe agent checking servers assigned to its location by the controller bot
+func runAgent(args []string) {
+	var agentOpts struct {
+		Agent         bool          `long:"agent" description:"Run as a probe agent reporting checks to the controller bot"`
+		ControllerUrl string        `long:"controller-url" env:"CONTROLLER_URL" description:"URL of the HTTP server of the controller bot" required:"true"`
+		AgentToken    string        `long:"agent-token" env:"AGENT_TOKEN" description:"Bearer token of the controller probe results API" required:"true"`
+		Location      string        `long:"agent-location" env:"AGENT_LOCATION" description:"Location name of the agent, like eu-west" required:"true"`
+		Interval      time.Duration `long:"agent-interval" env:"AGENT_INTERVAL" description:"Interval of checks and reports" default:"30s"`
+		Debug         bool          `long:"debug" env:"DEBUG" description:"debug mode"`
+	}
+	if _, err := flags.ParseArgs(&agentOpts, args); err != nil {
+		log.Printf("[ERROR] failed to parse flags: %v", err)
+		os.Exit(1)
+	}
+
+	logging.Setup(agentOpts.Debug)
+	redact.SetSecrets(agentOpts.AgentToken)
+	checks.UserAgent = "server-healthcheck-telegram-bot/" + version
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	log.Printf("[INFO] Probe agent %s reporting to %s", agentOpts.Location, agentOpts.ControllerUrl)
+	agent.New(agentOpts.ControllerUrl, agentOpts.AgentToken, agentOpts.Location, agentOpts.Interval).Run(ctx)
+}
+
+// backupChat returns the chat of backups, the private chat of the first superuser set by numeric id by default,
+// 0 if there is no such superuser
+func backupChat(chatId int64, superUsers events.SuperUser) int64 {
+	if chatId != 0 {
+		return chatId
+	}
+	for _, super := range superUsers {
+		if userId, err := strconv.ParseInt(super, 10, 64); err == nil && userId > 0 {
+			return userId
+		}
+	}
+	return 0
 }
 
-func setupLog(dbg bool) {
-	logOpts := []lgr.Option{lgr.Msec, lgr.LevelBraces, lgr.StackTraceOnError}
-	if dbg {
-		logOpts = []lgr.Option{lgr.Debug, lgr.CallerFile, lgr.CallerFunc, lgr.Msec, lgr.LevelBraces, lgr.StackTraceOnError}
+// migratedChat returns function resolving id of the supergroup the chat was migrated to
+func migratedChat(chatId int64) func() int64 {
+	return func() int64 {
+		return checks.ReadChecksData().Settings.MigratedChat(chatId)
 	}
-	lgr.SetupStdLogger(logOpts...)
 }